@@ -2,65 +2,44 @@ package main
 
 import (
 	"context"
-	"log"
 	"log/slog"
-	"os"
+	"slices"
+	"time"
 
 	server "github.com/HolySxn/To-Do/internal"
 	"github.com/HolySxn/To-Do/internal/config"
-	"github.com/HolySxn/To-Do/internal/db"
+	"github.com/HolySxn/To-Do/internal/csvfile"
+	"github.com/HolySxn/To-Do/internal/history"
+	"github.com/HolySxn/To-Do/internal/ical"
+	"github.com/HolySxn/To-Do/internal/markdown"
+	"github.com/HolySxn/To-Do/internal/todotxt"
+	"github.com/HolySxn/To-Do/internal/validate"
 )
 
+// trashPurgeInterval is how often items past the trash retention are removed
+const trashPurgeInterval = time.Hour
+
 // App struct
 type App struct {
-	ctx    context.Context
-	db     *db.DB
-	config *config.Config
-	logger *slog.Logger
+	ctx       context.Context
+	store     server.Store
+	config    *config.Config
+	logger    *slog.Logger
+	history   *history.History
+	stopPurge context.CancelFunc
+	// tagIDs maps the ids of tags that undo has recreated to their new ids;
+	// it is only used by history ops, which run one at a time
+	tagIDs map[string]string
 }
 
-// NewApp creates a new App application struct
-func NewApp() *App {
-	// Load configuration
-	cfg, err := config.LoadConfig()
-	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
-	}
-
-	// Initialize structured logger with config
-	logLevel := slog.LevelInfo
-	switch cfg.App.LogLevel {
-	case "debug":
-		logLevel = slog.LevelDebug
-	case "warn":
-		logLevel = slog.LevelWarn
-	case "error":
-		logLevel = slog.LevelError
-	}
-
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: logLevel,
-	}))
-
-	logger.Info("Initializing To-Do application", "config", cfg)
-
-	// Get database connection string from config
-	connStr := cfg.GetConnectionString()
-	logger.Info("Connecting to database", "host", cfg.Database.Host, "port", cfg.Database.Port, "database", cfg.Database.Name)
-
-	// Initialize database connection
-	database, err := db.NewDB(connStr)
-	if err != nil {
-		logger.Error("Failed to connect to database", "error", err)
-		log.Fatalf("Failed to connect to database: %v", err)
-	}
-
-	logger.Info("Database connection established successfully")
-
+// NewApp creates a new App application struct backed by the given store
+func NewApp(store server.Store, cfg *config.Config, logger *slog.Logger) *App {
 	return &App{
-		db:     database,
-		config: cfg,
-		logger: logger,
+		store:   store,
+		config:  cfg,
+		logger:  logger,
+		history: history.New(cfg.App.HistoryLimit),
+		tagIDs:  make(map[string]string),
 	}
 }
 
@@ -68,14 +47,25 @@ func NewApp() *App {
 // so we can call the runtime methods
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
+	if err := a.store.SetActor(ctx, a.config.App.Actor); err != nil {
+		a.logger.Error("Failed to set activity actor", "actor", a.config.App.Actor, "error", err)
+	}
+	if days := a.config.App.TrashRetentionDays; days > 0 {
+		purgeCtx, cancel := context.WithCancel(ctx)
+		a.stopPurge = cancel
+		go a.purgeTrashLoop(purgeCtx, days)
+	}
 	a.logger.Info("Application started successfully")
 }
 
 // shutdown is called when the app is shutting down
 func (a *App) shutdown(ctx context.Context) {
 	a.logger.Info("Application shutting down")
-	if a.db != nil {
-		a.db.Close()
+	if a.stopPurge != nil {
+		a.stopPurge()
+	}
+	if a.store != nil {
+		a.store.Close()
 		a.logger.Info("Database connection closed")
 	}
 }
@@ -83,18 +73,31 @@ func (a *App) shutdown(ctx context.Context) {
 // List CRUD operations
 func (a *App) CreateList(title string) (*server.List, error) {
 	a.logger.Info("Creating new list", "title", title)
-	list, err := a.db.CreateList(a.ctx, title)
+	v := validate.New()
+	title = v.Name("title", title)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid list input", "title", title, "error", err)
+		return nil, err
+	}
+	list, err := a.store.CreateList(a.ctx, title)
 	if err != nil {
 		a.logger.Error("Failed to create list", "title", title, "error", err)
 		return nil, err
 	}
+	a.history.Record(quoted("Create list", list.Title), a.deleteListOp(*list), a.restoreListOp(list.ID))
 	a.logger.Info("List created successfully", "list_id", list.ID, "title", list.Title)
 	return list, nil
 }
 
 func (a *App) GetList(id string) (*server.List, error) {
 	a.logger.Info("Getting list", "list_id", id)
-	list, err := a.db.GetList(a.ctx, id)
+	v := validate.New()
+	id = v.ID("id", id)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid list input", "list_id", id, "error", err)
+		return nil, err
+	}
+	list, err := a.store.GetList(a.ctx, id)
 	if err != nil {
 		a.logger.Error("Failed to get list", "list_id", id, "error", err)
 		return nil, err
@@ -105,7 +108,7 @@ func (a *App) GetList(id string) (*server.List, error) {
 
 func (a *App) GetAllLists() ([]server.List, error) {
 	a.logger.Info("Getting all lists")
-	lists, err := a.db.GetAllLists(a.ctx)
+	lists, err := a.store.GetAllLists(a.ctx)
 	if err != nil {
 		a.logger.Error("Failed to get all lists", "error", err)
 		return nil, err
@@ -114,35 +117,99 @@ func (a *App) GetAllLists() ([]server.List, error) {
 	return lists, nil
 }
 
-func (a *App) UpdateList(id string, title string) (*server.List, error) {
-	a.logger.Info("Updating list", "list_id", id, "new_title", title)
-	list, err := a.db.UpdateList(a.ctx, id, title)
+// UpdateList renames a list; version is the version last seen by the caller, or 0 to skip the check
+func (a *App) UpdateList(id string, title string, version int64) (*server.List, error) {
+	a.logger.Info("Updating list", "list_id", id, "new_title", title, "version", version)
+	v := validate.New()
+	id = v.ID("id", id)
+	title = v.Name("title", title)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid list input", "list_id", id, "new_title", title, "version", version, "error", err)
+		return nil, err
+	}
+	before, err := a.store.GetList(a.ctx, id)
+	if err != nil {
+		a.logger.Error("Failed to update list", "list_id", id, "new_title", title, "version", version, "error", err)
+		return nil, err
+	}
+	list, err := a.store.UpdateList(a.ctx, id, title, version)
 	if err != nil {
-		a.logger.Error("Failed to update list", "list_id", id, "new_title", title, "error", err)
+		a.logger.Error("Failed to update list", "list_id", id, "new_title", title, "version", version, "error", err)
 		return nil, err
 	}
+	a.recordListEdit(quoted("Rename list", before.Title), before, list)
 	a.logger.Info("List updated successfully", "list_id", id, "title", list.Title)
 	return list, nil
 }
 
-func (a *App) DeleteList(id string) error {
-	a.logger.Info("Deleting list", "list_id", id)
-	err := a.db.DeleteList(a.ctx, id)
+// PatchList updates only the fields present in patch
+func (a *App) PatchList(id string, patch server.ListPatch) (*server.List, error) {
+	a.logger.Info("Patching list", "id", id)
+	v := validate.New()
+	id = v.ID("id", id)
+	patch.Title = v.OptionalName("title", patch.Title)
+	patch.Query = v.OptionalQuery("query", patch.Query)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid list input", "id", id, "error", err)
+		return nil, err
+	}
+	before, err := a.store.GetList(a.ctx, id)
+	if err != nil {
+		a.logger.Error("Failed to patch list", "id", id, "error", err)
+		return nil, err
+	}
+	list, err := a.store.PatchList(a.ctx, id, patch)
+	if err != nil {
+		a.logger.Error("Failed to patch list", "id", id, "error", err)
+		return nil, err
+	}
+	a.recordListEdit(quoted("Edit list", before.Title), before, list)
+	a.logger.Info("List patched successfully", "id", list.ID)
+	return list, nil
+}
+
+func (a *App) DeleteList(id string, version int64) error {
+	a.logger.Info("Deleting list", "list_id", id, "version", version)
+	v := validate.New()
+	id = v.ID("id", id)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid list input", "list_id", id, "version", version, "error", err)
+		return err
+	}
+	before, err := a.store.GetList(a.ctx, id)
+	if err != nil {
+		a.logger.Error("Failed to delete list", "list_id", id, "version", version, "error", err)
+		return err
+	}
+	err = a.store.DeleteList(a.ctx, id, version)
 	if err != nil {
-		a.logger.Error("Failed to delete list", "list_id", id, "error", err)
+		a.logger.Error("Failed to delete list", "list_id", id, "version", version, "error", err)
 		return err
 	}
+	a.history.Record(quoted("Delete list", before.Title), a.restoreListOp(id), a.deleteListOp(*before))
 	a.logger.Info("List deleted successfully", "list_id", id)
 	return nil
 }
 
 func (a *App) ReorderLists(listIDs []string) error {
 	a.logger.Info("Reordering lists", "list_ids", listIDs)
-	err := a.db.ReorderLists(a.ctx, listIDs)
+	v := validate.New()
+	listIDs = v.IDs("list_ids", listIDs)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid list order", "list_ids", listIDs, "error", err)
+		return err
+	}
+	before, err := a.listOrder(a.ctx)
+	if err != nil {
+		a.logger.Error("Failed to reorder lists", "list_ids", listIDs, "error", err)
+		return err
+	}
+	err = a.store.ReorderLists(a.ctx, listIDs)
 	if err != nil {
 		a.logger.Error("Failed to reorder lists", "list_ids", listIDs, "error", err)
 		return err
 	}
+	a.recordListOrder("Reorder lists", before, listIDs)
 	a.logger.Info("Lists reordered successfully", "list_ids", listIDs)
 	return nil
 }
@@ -150,18 +217,32 @@ func (a *App) ReorderLists(listIDs []string) error {
 // Task CRUD operations
 func (a *App) CreateTask(listID string, taskName string) (*server.Task, error) {
 	a.logger.Info("Creating new task", "list_id", listID, "task_name", taskName)
-	task, err := a.db.CreateTask(a.ctx, listID, taskName)
+	v := validate.New()
+	listID = v.ID("list_id", listID)
+	taskName = v.Name("task_name", taskName)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid task input", "list_id", listID, "task_name", taskName, "error", err)
+		return nil, err
+	}
+	task, err := a.store.CreateTask(a.ctx, listID, taskName)
 	if err != nil {
 		a.logger.Error("Failed to create task", "list_id", listID, "task_name", taskName, "error", err)
 		return nil, err
 	}
+	a.history.Record(quoted("Create task", task.TaskName), a.deleteTaskOp(*task), a.restoreTaskOp(task.ID))
 	a.logger.Info("Task created successfully", "task_id", task.ID, "list_id", listID, "task_name", task.TaskName)
 	return task, nil
 }
 
 func (a *App) GetTask(id string) (*server.Task, error) {
 	a.logger.Info("Getting task", "task_id", id)
-	task, err := a.db.GetTask(a.ctx, id)
+	v := validate.New()
+	id = v.ID("id", id)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid task input", "task_id", id, "error", err)
+		return nil, err
+	}
+	task, err := a.store.GetTask(a.ctx, id)
 	if err != nil {
 		a.logger.Error("Failed to get task", "task_id", id, "error", err)
 		return nil, err
@@ -172,7 +253,13 @@ func (a *App) GetTask(id string) (*server.Task, error) {
 
 func (a *App) GetTasksByListID(listID string) ([]server.Task, error) {
 	a.logger.Info("Getting tasks by list ID", "list_id", listID)
-	tasks, err := a.db.GetTasksByListID(a.ctx, listID)
+	v := validate.New()
+	listID = v.ID("list_id", listID)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid task input", "list_id", listID, "error", err)
+		return nil, err
+	}
+	tasks, err := a.store.GetTasksByListID(a.ctx, listID)
 	if err != nil {
 		a.logger.Error("Failed to get tasks by list ID", "list_id", listID, "error", err)
 		return nil, err
@@ -183,7 +270,7 @@ func (a *App) GetTasksByListID(listID string) ([]server.Task, error) {
 
 func (a *App) GetAllTasks() ([]server.Task, error) {
 	a.logger.Info("Getting all tasks")
-	tasks, err := a.db.GetAllTasks(a.ctx)
+	tasks, err := a.store.GetAllTasks(a.ctx)
 	if err != nil {
 		a.logger.Error("Failed to get all tasks", "error", err)
 		return nil, err
@@ -192,113 +279,1610 @@ func (a *App) GetAllTasks() ([]server.Task, error) {
 	return tasks, nil
 }
 
-func (a *App) UpdateTask(id string, taskName string, completed bool) (*server.Task, error) {
-	a.logger.Info("Updating task", "task_id", id, "task_name", taskName, "completed", completed)
-	task, err := a.db.UpdateTask(a.ctx, id, taskName, completed)
+// GetTasksPage returns up to size tasks of a list, starting after cursor.
+// A size of 0 uses the default; an empty cursor starts at the first page.
+func (a *App) GetTasksPage(listID string, size int, cursor string) (*server.TaskPage, error) {
+	a.logger.Info("Getting tasks page", "list_id", listID, "size", size)
+	v := validate.New()
+	listID = v.ID("list_id", listID)
+	size = v.PageSize("size", size)
+	cursor = v.Cursor("cursor", cursor)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid page input", "list_id", listID, "size", size, "error", err)
+		return nil, err
+	}
+	page, err := a.store.GetTasksPageByListID(a.ctx, listID, size, cursor)
+	if err != nil {
+		a.logger.Error("Failed to get tasks page", "list_id", listID, "error", err)
+		return nil, err
+	}
+	a.logger.Info("Tasks page retrieved successfully", "list_id", listID, "count", len(page.Tasks))
+	return page, nil
+}
+
+// GetAllTasksPage returns up to size tasks across all lists, newest first; see GetTasksPage
+func (a *App) GetAllTasksPage(size int, cursor string) (*server.TaskPage, error) {
+	a.logger.Info("Getting all tasks page", "size", size)
+	v := validate.New()
+	size = v.PageSize("size", size)
+	cursor = v.Cursor("cursor", cursor)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid page input", "size", size, "error", err)
+		return nil, err
+	}
+	page, err := a.store.GetAllTasksPage(a.ctx, size, cursor)
+	if err != nil {
+		a.logger.Error("Failed to get all tasks page", "error", err)
+		return nil, err
+	}
+	a.logger.Info("All tasks page retrieved successfully", "count", len(page.Tasks))
+	return page, nil
+}
+
+func (a *App) UpdateTask(id string, taskName string, completed bool, version int64) (*server.Task, error) {
+	a.logger.Info("Updating task", "task_id", id, "task_name", taskName, "completed", completed, "version", version)
+	v := validate.New()
+	id = v.ID("id", id)
+	taskName = v.Name("task_name", taskName)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid task input", "task_id", id, "task_name", taskName, "completed", completed, "version", version, "error", err)
+		return nil, err
+	}
+	before, err := a.store.GetTask(a.ctx, id)
+	if err != nil {
+		a.logger.Error("Failed to update task", "task_id", id, "task_name", taskName, "completed", completed, "version", version, "error", err)
+		return nil, err
+	}
+	task, err := a.store.UpdateTask(a.ctx, id, taskName, completed, version)
 	if err != nil {
-		a.logger.Error("Failed to update task", "task_id", id, "task_name", taskName, "completed", completed, "error", err)
+		a.logger.Error("Failed to update task", "task_id", id, "task_name", taskName, "completed", completed, "version", version, "error", err)
 		return nil, err
 	}
+	a.recordTaskEdit(quoted("Edit task", before.TaskName), before, task)
 	a.logger.Info("Task updated successfully", "task_id", id, "task_name", task.TaskName, "completed", task.Completed)
 	return task, nil
 }
 
+// PatchTask updates only the fields present in patch
+func (a *App) PatchTask(id string, patch server.TaskPatch) (*server.Task, error) {
+	a.logger.Info("Patching task", "id", id)
+	v := validate.New()
+	id = v.ID("id", id)
+	patch.TaskName = v.OptionalName("task_name", patch.TaskName)
+	if patch.Notes != nil {
+		notes := validate.CleanText(*patch.Notes)
+		patch.Notes = &notes
+	}
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid task input", "id", id, "error", err)
+		return nil, err
+	}
+	before, err := a.store.GetTask(a.ctx, id)
+	if err != nil {
+		a.logger.Error("Failed to patch task", "id", id, "error", err)
+		return nil, err
+	}
+	task, err := a.store.PatchTask(a.ctx, id, patch)
+	if err != nil {
+		a.logger.Error("Failed to patch task", "id", id, "error", err)
+		return nil, err
+	}
+	a.recordTaskEdit(quoted("Edit task", before.TaskName), before, task)
+	a.logger.Info("Task patched successfully", "id", task.ID)
+	return task, nil
+}
+
 func (a *App) ToggleTaskCompletion(id string) (*server.Task, error) {
 	a.logger.Info("Toggling task completion", "task_id", id)
-	task, err := a.db.ToggleTaskCompletion(a.ctx, id)
+	v := validate.New()
+	id = v.ID("id", id)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid task input", "task_id", id, "error", err)
+		return nil, err
+	}
+	before, err := a.store.GetTask(a.ctx, id)
+	if err != nil {
+		a.logger.Error("Failed to toggle task completion", "task_id", id, "error", err)
+		return nil, err
+	}
+	// Completing a recurring task spawns its next occurrence, which undoing
+	// the toggle has to remove, so go through CompleteTask to learn about it
+	if !before.Completed && before.Recurrence != nil {
+		completion, err := a.store.CompleteTask(a.ctx, id)
+		if err != nil {
+			a.logger.Error("Failed to toggle task completion", "task_id", id, "error", err)
+			return nil, err
+		}
+		a.recordCompletion(quoted("Complete", before.TaskName), before, completion)
+		a.logger.Info("Task completion toggled successfully", "task_id", id, "completed", completion.Task.Completed)
+		return &completion.Task, nil
+	}
+	task, err := a.store.ToggleTaskCompletion(a.ctx, id)
 	if err != nil {
 		a.logger.Error("Failed to toggle task completion", "task_id", id, "error", err)
 		return nil, err
 	}
+	a.recordTaskEdit(quoted("Toggle", before.TaskName), before, task)
 	a.logger.Info("Task completion toggled successfully", "task_id", id, "completed", task.Completed)
 	return task, nil
 }
 
-func (a *App) DeleteTask(id string) error {
-	a.logger.Info("Deleting task", "task_id", id)
-	err := a.db.DeleteTask(a.ctx, id)
+func (a *App) DeleteTask(id string, version int64) error {
+	a.logger.Info("Deleting task", "task_id", id, "version", version)
+	v := validate.New()
+	id = v.ID("id", id)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid task input", "task_id", id, "version", version, "error", err)
+		return err
+	}
+	before, err := a.store.GetTask(a.ctx, id)
+	if err != nil {
+		a.logger.Error("Failed to delete task", "task_id", id, "version", version, "error", err)
+		return err
+	}
+	err = a.store.DeleteTask(a.ctx, id, version)
 	if err != nil {
-		a.logger.Error("Failed to delete task", "task_id", id, "error", err)
+		a.logger.Error("Failed to delete task", "task_id", id, "version", version, "error", err)
 		return err
 	}
+	a.history.Record(quoted("Delete task", before.TaskName), a.restoreTaskOp(id), a.deleteTaskOp(*before))
 	a.logger.Info("Task deleted successfully", "task_id", id)
 	return nil
 }
 
-// SubTask CRUD operations
-func (a *App) CreateSubTask(taskID string, subTaskName string) (*server.SubTask, error) {
-	a.logger.Info("Creating new subtask", "task_id", taskID, "subtask_name", subTaskName)
-	subtask, err := a.db.CreateSubTask(a.ctx, taskID, subTaskName)
+func (a *App) ReorderTasks(listID string, taskIDs []string) error {
+	a.logger.Info("Reordering tasks", "list_id", listID, "task_ids", taskIDs)
+	v := validate.New()
+	listID = v.ID("list_id", listID)
+	taskIDs = v.IDs("task_ids", taskIDs)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid task order", "list_id", listID, "task_ids", taskIDs, "error", err)
+		return err
+	}
+	before, err := a.taskOrder(a.ctx, listID)
 	if err != nil {
-		a.logger.Error("Failed to create subtask", "task_id", taskID, "subtask_name", subTaskName, "error", err)
+		a.logger.Error("Failed to reorder tasks", "list_id", listID, "task_ids", taskIDs, "error", err)
+		return err
+	}
+	err = a.store.ReorderTasks(a.ctx, listID, taskIDs)
+	if err != nil {
+		a.logger.Error("Failed to reorder tasks", "list_id", listID, "task_ids", taskIDs, "error", err)
+		return err
+	}
+	a.recordTaskOrder("Reorder tasks", listID, before, taskIDs)
+	a.logger.Info("Tasks reordered successfully", "list_id", listID, "task_ids", taskIDs)
+	return nil
+}
+
+func (a *App) MoveTask(taskID string, targetListID string, position int) (*server.Task, error) {
+	a.logger.Info("Moving task", "task_id", taskID, "target_list_id", targetListID, "position", position)
+	v := validate.New()
+	taskID = v.ID("task_id", taskID)
+	targetListID = v.ID("target_list_id", targetListID)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid task input", "task_id", taskID, "target_list_id", targetListID, "position", position, "error", err)
 		return nil, err
 	}
-	a.logger.Info("Subtask created successfully", "subtask_id", subtask.ID, "task_id", taskID, "subtask_name", subtask.SubTaskName)
-	return subtask, nil
+	before, err := a.store.GetTask(a.ctx, taskID)
+	if err != nil {
+		a.logger.Error("Failed to move task", "task_id", taskID, "target_list_id", targetListID, "position", position, "error", err)
+		return nil, err
+	}
+	from, err := a.taskPosition(a.ctx, before)
+	if err != nil {
+		a.logger.Error("Failed to move task", "task_id", taskID, "target_list_id", targetListID, "position", position, "error", err)
+		return nil, err
+	}
+	task, err := a.store.MoveTask(a.ctx, taskID, targetListID, position)
+	if err != nil {
+		a.logger.Error("Failed to move task", "task_id", taskID, "target_list_id", targetListID, "position", position, "error", err)
+		return nil, err
+	}
+	a.recordTaskMove(quoted("Move task", before.TaskName), taskID, before.ListID, from, targetListID, position)
+	a.logger.Info("Task moved successfully", "task_id", taskID, "list_id", task.ListID, "position", task.Position)
+	return task, nil
 }
 
-func (a *App) GetSubTask(id string) (*server.SubTask, error) {
-	a.logger.Info("Getting subtask", "subtask_id", id)
-	subtask, err := a.db.GetSubTask(a.ctx, id)
+func (a *App) DemoteTaskToSubTask(taskID string, targetTaskID string) (*server.SubTask, error) {
+	a.logger.Info("Demoting task to subtask", "task_id", taskID, "target_task_id", targetTaskID)
+	v := validate.New()
+	taskID = v.ID("task_id", taskID)
+	targetTaskID = v.ID("target_task_id", targetTaskID)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid task input", "task_id", taskID, "target_task_id", targetTaskID, "error", err)
+		return nil, err
+	}
+	before, err := a.store.GetTask(a.ctx, taskID)
 	if err != nil {
-		a.logger.Error("Failed to get subtask", "subtask_id", id, "error", err)
+		a.logger.Error("Failed to demote task to subtask", "task_id", taskID, "target_task_id", targetTaskID, "error", err)
 		return nil, err
 	}
-	a.logger.Info("Subtask retrieved successfully", "subtask_id", id, "subtask_name", subtask.SubTaskName)
+	from, err := a.taskPosition(a.ctx, before)
+	if err != nil {
+		a.logger.Error("Failed to demote task to subtask", "task_id", taskID, "target_task_id", targetTaskID, "error", err)
+		return nil, err
+	}
+	subtask, err := a.store.DemoteTaskToSubTask(a.ctx, taskID, targetTaskID)
+	if err != nil {
+		a.logger.Error("Failed to demote task to subtask", "task_id", taskID, "target_task_id", targetTaskID, "error", err)
+		return nil, err
+	}
+	a.recordDemotion(quoted("Make subtask of", before.TaskName), before, from, targetTaskID)
+	a.logger.Info("Task demoted successfully", "subtask_id", subtask.ID, "task_id", subtask.TaskID)
 	return subtask, nil
 }
 
-func (a *App) GetSubTasksByTaskID(taskID string) ([]server.SubTask, error) {
-	a.logger.Info("Getting subtasks by task ID", "task_id", taskID)
-	subtasks, err := a.db.GetSubTasksByTaskID(a.ctx, taskID)
+// Task notes, priority and starring
+
+func (a *App) SetTaskNotes(id string, notes string) (*server.Task, error) {
+	a.logger.Info("Setting task notes", "id", id, "length", len(notes))
+	v := validate.New()
+	id = v.ID("id", id)
+	notes = validate.CleanText(notes)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid task input", "id", id, "length", len(notes), "error", err)
+		return nil, err
+	}
+	before, err := a.store.GetTask(a.ctx, id)
 	if err != nil {
-		a.logger.Error("Failed to get subtasks by task ID", "task_id", taskID, "error", err)
+		a.logger.Error("Failed to set task notes", "id", id, "error", err)
 		return nil, err
 	}
-	a.logger.Info("Subtasks retrieved successfully", "task_id", taskID, "count", len(subtasks))
-	return subtasks, nil
+	task, err := a.store.SetTaskNotes(a.ctx, id, notes)
+	if err != nil {
+		a.logger.Error("Failed to set task notes", "id", id, "error", err)
+		return nil, err
+	}
+	a.recordTaskEdit(quoted("Edit notes of", before.TaskName), before, task)
+	a.logger.Info("Task notes set successfully", "id", id)
+	return task, nil
 }
 
-func (a *App) GetAllSubTasks() ([]server.SubTask, error) {
-	a.logger.Info("Getting all subtasks")
-	subtasks, err := a.db.GetAllSubTasks(a.ctx)
+// SetTaskPriority sets the priority to one of "none", "low", "medium" or "high"
+func (a *App) SetTaskPriority(id string, priority string) (*server.Task, error) {
+	a.logger.Info("Setting task priority", "id", id, "priority", priority)
+	v := validate.New()
+	id = v.ID("id", id)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid task input", "id", id, "priority", priority, "error", err)
+		return nil, err
+	}
+	before, err := a.store.GetTask(a.ctx, id)
 	if err != nil {
-		a.logger.Error("Failed to get all subtasks", "error", err)
+		a.logger.Error("Failed to set task priority", "id", id, "priority", priority, "error", err)
 		return nil, err
 	}
-	a.logger.Info("All subtasks retrieved successfully", "count", len(subtasks))
-	return subtasks, nil
+	task, err := a.store.SetTaskPriority(a.ctx, id, server.Priority(priority))
+	if err != nil {
+		a.logger.Error("Failed to set task priority", "id", id, "priority", priority, "error", err)
+		return nil, err
+	}
+	a.recordTaskEdit(quoted("Set priority of", before.TaskName), before, task)
+	a.logger.Info("Task priority set successfully", "id", id, "priority", task.Priority)
+	return task, nil
 }
 
-func (a *App) UpdateSubTask(id string, subTaskName string, completed bool) (*server.SubTask, error) {
-	a.logger.Info("Updating subtask", "subtask_id", id, "subtask_name", subTaskName, "completed", completed)
-	subtask, err := a.db.UpdateSubTask(a.ctx, id, subTaskName, completed)
+func (a *App) StarTask(id string, starred bool) (*server.Task, error) {
+	a.logger.Info("Starring task", "id", id, "starred", starred)
+	v := validate.New()
+	id = v.ID("id", id)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid task input", "id", id, "starred", starred, "error", err)
+		return nil, err
+	}
+	before, err := a.store.GetTask(a.ctx, id)
 	if err != nil {
-		a.logger.Error("Failed to update subtask", "subtask_id", id, "subtask_name", subTaskName, "completed", completed, "error", err)
+		a.logger.Error("Failed to star task", "id", id, "starred", starred, "error", err)
 		return nil, err
 	}
-	a.logger.Info("Subtask updated successfully", "subtask_id", id, "subtask_name", subtask.SubTaskName, "completed", subtask.Completed)
-	return subtask, nil
+	task, err := a.store.StarTask(a.ctx, id, starred)
+	if err != nil {
+		a.logger.Error("Failed to star task", "id", id, "starred", starred, "error", err)
+		return nil, err
+	}
+	a.recordTaskEdit(quoted("Star", before.TaskName), before, task)
+	a.logger.Info("Task starred successfully", "id", id, "starred", task.Starred)
+	return task, nil
 }
 
-func (a *App) ToggleSubTaskCompletion(id string) (*server.SubTask, error) {
-	a.logger.Info("Toggling subtask completion", "subtask_id", id)
-	subtask, err := a.db.ToggleSubTaskCompletion(a.ctx, id)
+func (a *App) GetStarredTasks() ([]server.Task, error) {
+	a.logger.Info("Getting starred tasks")
+	tasks, err := a.store.GetStarredTasks(a.ctx)
 	if err != nil {
-		a.logger.Error("Failed to toggle subtask completion", "subtask_id", id, "error", err)
+		a.logger.Error("Failed to get starred tasks", "error", err)
 		return nil, err
 	}
-	a.logger.Info("Subtask completion toggled successfully", "subtask_id", id, "completed", subtask.Completed)
-	return subtask, nil
+	a.logger.Info("Starred tasks retrieved successfully", "count", len(tasks))
+	return tasks, nil
 }
 
-func (a *App) DeleteSubTask(id string) error {
-	a.logger.Info("Deleting subtask", "subtask_id", id)
-	err := a.db.DeleteSubTask(a.ctx, id)
+// Due dates and reminders
+
+// SetTaskDueDate sets a whole-day due date formatted as YYYY-MM-DD; an empty date clears it
+func (a *App) SetTaskDueDate(id string, date string) (*server.Task, error) {
+	a.logger.Info("Setting task due date", "id", id, "due_date", date)
+	v := validate.New()
+	id = v.ID("id", id)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid task input", "id", id, "due_date", date, "error", err)
+		return nil, err
+	}
+	var dueDate *string
+	if date != "" {
+		dueDate = &date
+	}
+	before, err := a.store.GetTask(a.ctx, id)
 	if err != nil {
-		a.logger.Error("Failed to delete subtask", "subtask_id", id, "error", err)
-		return err
+		a.logger.Error("Failed to set task due date", "id", id, "due_date", date, "error", err)
+		return nil, err
+	}
+	task, err := a.store.SetTaskDue(a.ctx, id, dueDate, nil)
+	if err != nil {
+		a.logger.Error("Failed to set task due date", "id", id, "due_date", date, "error", err)
+		return nil, err
+	}
+	a.recordTaskEdit(quoted("Set due date of", before.TaskName), before, task)
+	a.logger.Info("Task due date set successfully", "id", id)
+	return task, nil
+}
+
+// SetTaskDueDateTime sets a due moment formatted as RFC 3339; an empty value clears it
+func (a *App) SetTaskDueDateTime(id string, dateTime string) (*server.Task, error) {
+	a.logger.Info("Setting task due time", "id", id, "due_at", dateTime)
+	v := validate.New()
+	id = v.ID("id", id)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid task input", "id", id, "due_at", dateTime, "error", err)
+		return nil, err
+	}
+	dueAt, err := parseOptionalTime(dateTime)
+	if err != nil {
+		a.logger.Error("Invalid task due time", "id", id, "due_at", dateTime, "error", err)
+		return nil, err
+	}
+	before, err := a.store.GetTask(a.ctx, id)
+	if err != nil {
+		a.logger.Error("Failed to set task due time", "id", id, "due_at", dateTime, "error", err)
+		return nil, err
+	}
+	task, err := a.store.SetTaskDue(a.ctx, id, nil, dueAt)
+	if err != nil {
+		a.logger.Error("Failed to set task due time", "id", id, "due_at", dateTime, "error", err)
+		return nil, err
+	}
+	a.recordTaskEdit(quoted("Set due date of", before.TaskName), before, task)
+	a.logger.Info("Task due time set successfully", "id", id)
+	return task, nil
+}
+
+// SetTaskReminder sets the reminder time formatted as RFC 3339; an empty value clears it
+func (a *App) SetTaskReminder(id string, remindAt string) (*server.Task, error) {
+	a.logger.Info("Setting task reminder", "id", id, "remind_at", remindAt)
+	v := validate.New()
+	id = v.ID("id", id)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid task input", "id", id, "remind_at", remindAt, "error", err)
+		return nil, err
+	}
+	at, err := parseOptionalTime(remindAt)
+	if err != nil {
+		a.logger.Error("Invalid task reminder time", "id", id, "remind_at", remindAt, "error", err)
+		return nil, err
+	}
+	before, err := a.store.GetTask(a.ctx, id)
+	if err != nil {
+		a.logger.Error("Failed to set task reminder", "id", id, "remind_at", remindAt, "error", err)
+		return nil, err
+	}
+	task, err := a.store.SetTaskReminder(a.ctx, id, at)
+	if err != nil {
+		a.logger.Error("Failed to set task reminder", "id", id, "remind_at", remindAt, "error", err)
+		return nil, err
+	}
+	a.recordTaskEdit(quoted("Set reminder of", before.TaskName), before, task)
+	a.logger.Info("Task reminder set successfully", "id", id)
+	return task, nil
+}
+
+// GetTasksDueBetween returns tasks due in [from, to), both formatted as RFC 3339.
+// Whole-day tasks are matched against the calendar days in from's time zone.
+func (a *App) GetTasksDueBetween(from string, to string) ([]server.Task, error) {
+	a.logger.Info("Getting tasks due between", "from", from, "to", to)
+	start, err := time.Parse(time.RFC3339, from)
+	if err != nil {
+		a.logger.Error("Invalid range start", "from", from, "error", err)
+		return nil, server.InvalidInput("invalid time %q: %w", from, err)
+	}
+	end, err := time.Parse(time.RFC3339, to)
+	if err != nil {
+		a.logger.Error("Invalid range end", "to", to, "error", err)
+		return nil, server.InvalidInput("invalid time %q: %w", to, err)
+	}
+	tasks, err := a.store.GetTasksDueBetween(a.ctx, start, end)
+	if err != nil {
+		a.logger.Error("Failed to get tasks due between", "from", from, "to", to, "error", err)
+		return nil, err
+	}
+	a.logger.Info("Tasks due between retrieved successfully", "count", len(tasks))
+	return tasks, nil
+}
+
+// GetTasksDueToday returns tasks due on the current local day
+func (a *App) GetTasksDueToday() ([]server.Task, error) {
+	a.logger.Info("Getting tasks due today")
+	now := time.Now()
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	tasks, err := a.store.GetTasksDueBetween(a.ctx, start, start.AddDate(0, 0, 1))
+	if err != nil {
+		a.logger.Error("Failed to get tasks due today", "error", err)
+		return nil, err
+	}
+	a.logger.Info("Tasks due today retrieved successfully", "count", len(tasks))
+	return tasks, nil
+}
+
+func (a *App) GetOverdueTasks() ([]server.Task, error) {
+	a.logger.Info("Getting overdue tasks")
+	tasks, err := a.store.GetOverdueTasks(a.ctx, time.Now())
+	if err != nil {
+		a.logger.Error("Failed to get overdue tasks", "error", err)
+		return nil, err
+	}
+	a.logger.Info("Overdue tasks retrieved successfully", "count", len(tasks))
+	return tasks, nil
+}
+
+func (a *App) GetDueReminders() ([]server.Task, error) {
+	a.logger.Info("Getting due reminders")
+	tasks, err := a.store.GetDueReminders(a.ctx, time.Now())
+	if err != nil {
+		a.logger.Error("Failed to get due reminders", "error", err)
+		return nil, err
+	}
+	a.logger.Info("Due reminders retrieved successfully", "count", len(tasks))
+	return tasks, nil
+}
+
+// Recurring tasks
+
+// SetTaskRecurrence sets an RRULE such as "FREQ=WEEKLY;BYDAY=MO"; an empty rule clears it
+func (a *App) SetTaskRecurrence(id string, rule string) (*server.Task, error) {
+	a.logger.Info("Setting task recurrence", "id", id, "rule", rule)
+	v := validate.New()
+	id = v.ID("id", id)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid task input", "id", id, "rule", rule, "error", err)
+		return nil, err
+	}
+	var recurrence *string
+	if rule != "" {
+		recurrence = &rule
+	}
+	before, err := a.store.GetTask(a.ctx, id)
+	if err != nil {
+		a.logger.Error("Failed to set task recurrence", "id", id, "rule", rule, "error", err)
+		return nil, err
+	}
+	task, err := a.store.SetTaskRecurrence(a.ctx, id, recurrence)
+	if err != nil {
+		a.logger.Error("Failed to set task recurrence", "id", id, "rule", rule, "error", err)
+		return nil, err
+	}
+	a.recordTaskEdit(quoted("Set recurrence of", before.TaskName), before, task)
+	a.logger.Info("Task recurrence set successfully", "id", id)
+	return task, nil
+}
+
+// CompleteTask completes a task and returns the next occurrence if the task recurs
+func (a *App) CompleteTask(id string) (*server.TaskCompletion, error) {
+	a.logger.Info("Completing task", "id", id)
+	v := validate.New()
+	id = v.ID("id", id)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid task input", "id", id, "error", err)
+		return nil, err
+	}
+	before, err := a.store.GetTask(a.ctx, id)
+	if err != nil {
+		a.logger.Error("Failed to complete task", "id", id, "error", err)
+		return nil, err
+	}
+	completion, err := a.store.CompleteTask(a.ctx, id)
+	if err != nil {
+		a.logger.Error("Failed to complete task", "id", id, "error", err)
+		return nil, err
+	}
+	if !before.Completed {
+		a.recordCompletion(quoted("Complete", before.TaskName), before, completion)
+	}
+	if completion.Next != nil {
+		a.logger.Info("Task completed successfully", "id", id, "next_id", completion.Next.ID)
+	} else {
+		a.logger.Info("Task completed successfully", "id", id)
+	}
+	return completion, nil
+}
+
+// parseOptionalTime parses an RFC 3339 time, treating an empty string as no time
+func parseOptionalTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, server.InvalidInput("invalid time %q: %w", value, err)
+	}
+	return &t, nil
+}
+
+// SubTask CRUD operations
+func (a *App) CreateSubTask(taskID string, subTaskName string) (*server.SubTask, error) {
+	a.logger.Info("Creating new subtask", "task_id", taskID, "subtask_name", subTaskName)
+	v := validate.New()
+	taskID = v.ID("task_id", taskID)
+	subTaskName = v.Name("subtask_name", subTaskName)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid subtask input", "task_id", taskID, "subtask_name", subTaskName, "error", err)
+		return nil, err
+	}
+	subtask, err := a.store.CreateSubTask(a.ctx, taskID, subTaskName)
+	if err != nil {
+		a.logger.Error("Failed to create subtask", "task_id", taskID, "subtask_name", subTaskName, "error", err)
+		return nil, err
+	}
+	a.history.Record(quoted("Create subtask", subtask.SubTaskName), a.deleteSubTaskOp(*subtask), a.restoreSubTaskOp(subtask.ID))
+	a.logger.Info("Subtask created successfully", "subtask_id", subtask.ID, "task_id", taskID, "subtask_name", subtask.SubTaskName)
+	return subtask, nil
+}
+
+func (a *App) GetSubTask(id string) (*server.SubTask, error) {
+	a.logger.Info("Getting subtask", "subtask_id", id)
+	v := validate.New()
+	id = v.ID("id", id)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid subtask input", "subtask_id", id, "error", err)
+		return nil, err
+	}
+	subtask, err := a.store.GetSubTask(a.ctx, id)
+	if err != nil {
+		a.logger.Error("Failed to get subtask", "subtask_id", id, "error", err)
+		return nil, err
+	}
+	a.logger.Info("Subtask retrieved successfully", "subtask_id", id, "subtask_name", subtask.SubTaskName)
+	return subtask, nil
+}
+
+func (a *App) GetSubTasksByTaskID(taskID string) ([]server.SubTask, error) {
+	a.logger.Info("Getting subtasks by task ID", "task_id", taskID)
+	v := validate.New()
+	taskID = v.ID("task_id", taskID)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid subtask input", "task_id", taskID, "error", err)
+		return nil, err
+	}
+	subtasks, err := a.store.GetSubTasksByTaskID(a.ctx, taskID)
+	if err != nil {
+		a.logger.Error("Failed to get subtasks by task ID", "task_id", taskID, "error", err)
+		return nil, err
+	}
+	a.logger.Info("Subtasks retrieved successfully", "task_id", taskID, "count", len(subtasks))
+	return subtasks, nil
+}
+
+func (a *App) GetAllSubTasks() ([]server.SubTask, error) {
+	a.logger.Info("Getting all subtasks")
+	subtasks, err := a.store.GetAllSubTasks(a.ctx)
+	if err != nil {
+		a.logger.Error("Failed to get all subtasks", "error", err)
+		return nil, err
+	}
+	a.logger.Info("All subtasks retrieved successfully", "count", len(subtasks))
+	return subtasks, nil
+}
+
+// GetAllSubTasksPage returns up to size subtasks across all tasks, newest first; see GetTasksPage
+func (a *App) GetAllSubTasksPage(size int, cursor string) (*server.SubTaskPage, error) {
+	a.logger.Info("Getting all subtasks page", "size", size)
+	v := validate.New()
+	size = v.PageSize("size", size)
+	cursor = v.Cursor("cursor", cursor)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid page input", "size", size, "error", err)
+		return nil, err
+	}
+	page, err := a.store.GetAllSubTasksPage(a.ctx, size, cursor)
+	if err != nil {
+		a.logger.Error("Failed to get all subtasks page", "error", err)
+		return nil, err
+	}
+	a.logger.Info("All subtasks page retrieved successfully", "count", len(page.SubTasks))
+	return page, nil
+}
+
+func (a *App) UpdateSubTask(id string, subTaskName string, completed bool, version int64) (*server.SubTask, error) {
+	a.logger.Info("Updating subtask", "subtask_id", id, "subtask_name", subTaskName, "completed", completed, "version", version)
+	v := validate.New()
+	id = v.ID("id", id)
+	subTaskName = v.Name("subtask_name", subTaskName)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid subtask input", "subtask_id", id, "subtask_name", subTaskName, "completed", completed, "version", version, "error", err)
+		return nil, err
+	}
+	before, err := a.store.GetSubTask(a.ctx, id)
+	if err != nil {
+		a.logger.Error("Failed to update subtask", "subtask_id", id, "subtask_name", subTaskName, "completed", completed, "version", version, "error", err)
+		return nil, err
+	}
+	subtask, err := a.store.UpdateSubTask(a.ctx, id, subTaskName, completed, version)
+	if err != nil {
+		a.logger.Error("Failed to update subtask", "subtask_id", id, "subtask_name", subTaskName, "completed", completed, "version", version, "error", err)
+		return nil, err
+	}
+	a.recordSubTaskEdit(quoted("Edit subtask", before.SubTaskName), before, subtask)
+	a.logger.Info("Subtask updated successfully", "subtask_id", id, "subtask_name", subtask.SubTaskName, "completed", subtask.Completed)
+	return subtask, nil
+}
+
+// PatchSubTask updates only the fields present in patch
+func (a *App) PatchSubTask(id string, patch server.SubTaskPatch) (*server.SubTask, error) {
+	a.logger.Info("Patching subtask", "id", id)
+	v := validate.New()
+	id = v.ID("id", id)
+	patch.SubTaskName = v.OptionalName("subtask_name", patch.SubTaskName)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid subtask input", "id", id, "error", err)
+		return nil, err
+	}
+	before, err := a.store.GetSubTask(a.ctx, id)
+	if err != nil {
+		a.logger.Error("Failed to patch subtask", "id", id, "error", err)
+		return nil, err
+	}
+	subtask, err := a.store.PatchSubTask(a.ctx, id, patch)
+	if err != nil {
+		a.logger.Error("Failed to patch subtask", "id", id, "error", err)
+		return nil, err
+	}
+	a.recordSubTaskEdit(quoted("Edit subtask", before.SubTaskName), before, subtask)
+	a.logger.Info("Subtask patched successfully", "id", subtask.ID)
+	return subtask, nil
+}
+
+func (a *App) ToggleSubTaskCompletion(id string) (*server.SubTask, error) {
+	a.logger.Info("Toggling subtask completion", "subtask_id", id)
+	v := validate.New()
+	id = v.ID("id", id)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid subtask input", "subtask_id", id, "error", err)
+		return nil, err
+	}
+	before, err := a.store.GetSubTask(a.ctx, id)
+	if err != nil {
+		a.logger.Error("Failed to toggle subtask completion", "subtask_id", id, "error", err)
+		return nil, err
+	}
+	subtask, err := a.store.ToggleSubTaskCompletion(a.ctx, id)
+	if err != nil {
+		a.logger.Error("Failed to toggle subtask completion", "subtask_id", id, "error", err)
+		return nil, err
+	}
+	a.recordSubTaskEdit(quoted("Toggle subtask", before.SubTaskName), before, subtask)
+	a.logger.Info("Subtask completion toggled successfully", "subtask_id", id, "completed", subtask.Completed)
+	return subtask, nil
+}
+
+func (a *App) DeleteSubTask(id string, version int64) error {
+	a.logger.Info("Deleting subtask", "subtask_id", id, "version", version)
+	v := validate.New()
+	id = v.ID("id", id)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid subtask input", "subtask_id", id, "version", version, "error", err)
+		return err
+	}
+	before, err := a.store.GetSubTask(a.ctx, id)
+	if err != nil {
+		a.logger.Error("Failed to delete subtask", "subtask_id", id, "version", version, "error", err)
+		return err
+	}
+	err = a.store.DeleteSubTask(a.ctx, id, version)
+	if err != nil {
+		a.logger.Error("Failed to delete subtask", "subtask_id", id, "version", version, "error", err)
+		return err
+	}
+	a.history.Record(quoted("Delete subtask", before.SubTaskName), a.restoreSubTaskOp(id), a.deleteSubTaskOp(*before))
+	a.logger.Info("Subtask deleted successfully", "subtask_id", id)
+	return nil
+}
+
+func (a *App) ReorderSubTasks(taskID string, subTaskIDs []string) error {
+	a.logger.Info("Reordering subtasks", "task_id", taskID, "subtask_ids", subTaskIDs)
+	v := validate.New()
+	taskID = v.ID("task_id", taskID)
+	subTaskIDs = v.IDs("subtask_ids", subTaskIDs)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid subtask order", "task_id", taskID, "subtask_ids", subTaskIDs, "error", err)
+		return err
+	}
+	before, err := a.subTaskOrder(a.ctx, taskID)
+	if err != nil {
+		a.logger.Error("Failed to reorder subtasks", "task_id", taskID, "subtask_ids", subTaskIDs, "error", err)
+		return err
+	}
+	err = a.store.ReorderSubTasks(a.ctx, taskID, subTaskIDs)
+	if err != nil {
+		a.logger.Error("Failed to reorder subtasks", "task_id", taskID, "subtask_ids", subTaskIDs, "error", err)
+		return err
+	}
+	a.recordSubTaskOrder("Reorder subtasks", taskID, before, subTaskIDs)
+	a.logger.Info("Subtasks reordered successfully", "task_id", taskID, "subtask_ids", subTaskIDs)
+	return nil
+}
+
+func (a *App) MoveSubTask(subTaskID string, targetTaskID string, position int) (*server.SubTask, error) {
+	a.logger.Info("Moving subtask", "subtask_id", subTaskID, "target_task_id", targetTaskID, "position", position)
+	v := validate.New()
+	subTaskID = v.ID("subtask_id", subTaskID)
+	targetTaskID = v.ID("target_task_id", targetTaskID)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid subtask input", "subtask_id", subTaskID, "target_task_id", targetTaskID, "position", position, "error", err)
+		return nil, err
+	}
+	before, err := a.store.GetSubTask(a.ctx, subTaskID)
+	if err != nil {
+		a.logger.Error("Failed to move subtask", "subtask_id", subTaskID, "target_task_id", targetTaskID, "position", position, "error", err)
+		return nil, err
+	}
+	from, err := a.subTaskPosition(a.ctx, before)
+	if err != nil {
+		a.logger.Error("Failed to move subtask", "subtask_id", subTaskID, "target_task_id", targetTaskID, "position", position, "error", err)
+		return nil, err
+	}
+	subtask, err := a.store.MoveSubTask(a.ctx, subTaskID, targetTaskID, position)
+	if err != nil {
+		a.logger.Error("Failed to move subtask", "subtask_id", subTaskID, "target_task_id", targetTaskID, "position", position, "error", err)
+		return nil, err
+	}
+	a.recordSubTaskMove(quoted("Move subtask", before.SubTaskName), subTaskID, before.TaskID, from, targetTaskID, position)
+	a.logger.Info("Subtask moved successfully", "subtask_id", subTaskID, "task_id", subtask.TaskID, "position", subtask.Position)
+	return subtask, nil
+}
+
+func (a *App) PromoteSubTaskToTask(subTaskID string) (*server.Task, error) {
+	a.logger.Info("Promoting subtask to task", "subtask_id", subTaskID)
+	v := validate.New()
+	subTaskID = v.ID("subtask_id", subTaskID)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid subtask input", "subtask_id", subTaskID, "error", err)
+		return nil, err
+	}
+	before, err := a.store.GetSubTask(a.ctx, subTaskID)
+	if err != nil {
+		a.logger.Error("Failed to promote subtask to task", "subtask_id", subTaskID, "error", err)
+		return nil, err
+	}
+	from, err := a.subTaskPosition(a.ctx, before)
+	if err != nil {
+		a.logger.Error("Failed to promote subtask to task", "subtask_id", subTaskID, "error", err)
+		return nil, err
+	}
+	task, err := a.store.PromoteSubTaskToTask(a.ctx, subTaskID)
+	if err != nil {
+		a.logger.Error("Failed to promote subtask to task", "subtask_id", subTaskID, "error", err)
+		return nil, err
+	}
+	a.recordPromotion(quoted("Make task of", before.SubTaskName), before, from)
+	a.logger.Info("Subtask promoted successfully", "task_id", task.ID, "list_id", task.ListID)
+	return task, nil
+}
+
+// Trees
+
+// GetListTree returns a list with its tasks and their subtasks in one call,
+// with completed and total counts
+func (a *App) GetListTree(listID string) (*server.ListTree, error) {
+	a.logger.Info("Getting list tree", "list_id", listID)
+	v := validate.New()
+	listID = v.ID("list_id", listID)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid list input", "list_id", listID, "error", err)
+		return nil, err
+	}
+	tree, err := a.store.GetListTree(a.ctx, listID)
+	if err != nil {
+		a.logger.Error("Failed to get list tree", "list_id", listID, "error", err)
+		return nil, err
+	}
+	a.logger.Info("List tree retrieved successfully", "list_id", listID, "tasks", tree.TotalTasks)
+	return tree, nil
+}
+
+// GetWorkspaceTree returns the tree of every list, ordered by position
+func (a *App) GetWorkspaceTree() ([]server.ListTree, error) {
+	a.logger.Info("Getting workspace tree")
+	trees, err := a.store.GetWorkspaceTree(a.ctx)
+	if err != nil {
+		a.logger.Error("Failed to get workspace tree", "error", err)
+		return nil, err
+	}
+	a.logger.Info("Workspace tree retrieved successfully", "lists", len(trees))
+	return trees, nil
+}
+
+// Trash
+
+// GetTrash returns the deleted lists, tasks and subtasks that can be restored
+func (a *App) GetTrash() (*server.Trash, error) {
+	a.logger.Info("Getting trash")
+	trash, err := a.store.GetTrash(a.ctx)
+	if err != nil {
+		a.logger.Error("Failed to get trash", "error", err)
+		return nil, err
+	}
+	a.logger.Info("Trash retrieved successfully", "lists", len(trash.Lists), "tasks", len(trash.Tasks), "subtasks", len(trash.SubTasks))
+	return trash, nil
+}
+
+// RestoreList brings a deleted list back with the tasks deleted along with it
+func (a *App) RestoreList(id string) (*server.List, error) {
+	a.logger.Info("Restoring list", "id", id)
+	v := validate.New()
+	id = v.ID("id", id)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid list input", "id", id, "error", err)
+		return nil, err
+	}
+	list, err := a.store.RestoreList(a.ctx, id)
+	if err != nil {
+		a.logger.Error("Failed to restore list", "id", id, "error", err)
+		return nil, err
+	}
+	a.history.Record(quoted("Restore list", list.Title), a.deleteListOp(*list), a.restoreListOp(id))
+	a.logger.Info("List restored successfully", "id", id)
+	return list, nil
+}
+
+// RestoreTask brings a deleted task back, restoring its list too if it was deleted
+func (a *App) RestoreTask(id string) (*server.Task, error) {
+	a.logger.Info("Restoring task", "id", id)
+	v := validate.New()
+	id = v.ID("id", id)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid task input", "id", id, "error", err)
+		return nil, err
+	}
+	task, err := a.store.RestoreTask(a.ctx, id)
+	if err != nil {
+		a.logger.Error("Failed to restore task", "id", id, "error", err)
+		return nil, err
+	}
+	a.history.Record(quoted("Restore task", task.TaskName), a.deleteTaskOp(*task), a.restoreTaskOp(id))
+	a.logger.Info("Task restored successfully", "id", id, "list_id", task.ListID)
+	return task, nil
+}
+
+// RestoreSubTask brings a deleted subtask back, restoring its task and list too if they were deleted
+func (a *App) RestoreSubTask(id string) (*server.SubTask, error) {
+	a.logger.Info("Restoring subtask", "subtask_id", id)
+	v := validate.New()
+	id = v.ID("id", id)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid subtask input", "subtask_id", id, "error", err)
+		return nil, err
+	}
+	subtask, err := a.store.RestoreSubTask(a.ctx, id)
+	if err != nil {
+		a.logger.Error("Failed to restore subtask", "subtask_id", id, "error", err)
+		return nil, err
+	}
+	a.history.Record(quoted("Restore subtask", subtask.SubTaskName), a.deleteSubTaskOp(*subtask), a.restoreSubTaskOp(id))
+	a.logger.Info("Subtask restored successfully", "subtask_id", id, "task_id", subtask.TaskID)
+	return subtask, nil
+}
+
+// Tags
+
+// CreateTag creates a tag; color is formatted as #rrggbb, or empty for the default color
+func (a *App) CreateTag(name string, color string) (*server.Tag, error) {
+	a.logger.Info("Creating new tag", "name", name, "color", color)
+	v := validate.New()
+	name = v.Name("name", name)
+	color = v.Color("color", color)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid tag input", "name", name, "color", color, "error", err)
+		return nil, err
+	}
+	tag, err := a.store.CreateTag(a.ctx, name, color)
+	if err != nil {
+		a.logger.Error("Failed to create tag", "name", name, "error", err)
+		return nil, err
+	}
+	a.recordTagCreation(quoted("Create tag", tag.Name), tag)
+	a.logger.Info("Tag created successfully", "tag_id", tag.ID, "name", tag.Name)
+	return tag, nil
+}
+
+func (a *App) GetAllTags() ([]server.Tag, error) {
+	a.logger.Info("Getting all tags")
+	tags, err := a.store.GetAllTags(a.ctx)
+	if err != nil {
+		a.logger.Error("Failed to get all tags", "error", err)
+		return nil, err
+	}
+	a.logger.Info("All tags retrieved successfully", "count", len(tags))
+	return tags, nil
+}
+
+func (a *App) RenameTag(id string, name string) (*server.Tag, error) {
+	a.logger.Info("Renaming tag", "tag_id", id, "new_name", name)
+	v := validate.New()
+	id = v.ID("id", id)
+	name = v.Name("name", name)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid tag input", "tag_id", id, "new_name", name, "error", err)
+		return nil, err
+	}
+	before, err := a.store.GetTag(a.ctx, id)
+	if err != nil {
+		a.logger.Error("Failed to rename tag", "tag_id", id, "new_name", name, "error", err)
+		return nil, err
+	}
+	tag, err := a.store.RenameTag(a.ctx, id, name)
+	if err != nil {
+		a.logger.Error("Failed to rename tag", "tag_id", id, "new_name", name, "error", err)
+		return nil, err
+	}
+	a.recordTagEdit(quoted("Rename tag", before.Name), before, tag)
+	a.logger.Info("Tag renamed successfully", "tag_id", id, "name", tag.Name)
+	return tag, nil
+}
+
+// SetTagColor sets a color formatted as #rrggbb; an empty color restores the default
+func (a *App) SetTagColor(id string, color string) (*server.Tag, error) {
+	a.logger.Info("Setting tag color", "tag_id", id, "color", color)
+	v := validate.New()
+	id = v.ID("id", id)
+	color = v.Color("color", color)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid tag input", "tag_id", id, "color", color, "error", err)
+		return nil, err
+	}
+	before, err := a.store.GetTag(a.ctx, id)
+	if err != nil {
+		a.logger.Error("Failed to set tag color", "tag_id", id, "color", color, "error", err)
+		return nil, err
+	}
+	tag, err := a.store.SetTagColor(a.ctx, id, color)
+	if err != nil {
+		a.logger.Error("Failed to set tag color", "tag_id", id, "color", color, "error", err)
+		return nil, err
+	}
+	a.recordTagEdit(quoted("Set color of tag", before.Name), before, tag)
+	a.logger.Info("Tag color set successfully", "tag_id", id, "color", tag.Color)
+	return tag, nil
+}
+
+// DeleteTag deletes a tag and takes it off every task
+func (a *App) DeleteTag(id string) error {
+	a.logger.Info("Deleting tag", "tag_id", id)
+	v := validate.New()
+	id = v.ID("id", id)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid tag input", "tag_id", id, "error", err)
+		return err
+	}
+	tag, err := a.store.GetTag(a.ctx, id)
+	if err != nil {
+		a.logger.Error("Failed to delete tag", "tag_id", id, "error", err)
+		return err
+	}
+	taskIDs, err := a.taggedTasks(a.ctx, id)
+	if err != nil {
+		a.logger.Error("Failed to delete tag", "tag_id", id, "error", err)
+		return err
+	}
+	if err := a.store.DeleteTag(a.ctx, id); err != nil {
+		a.logger.Error("Failed to delete tag", "tag_id", id, "error", err)
+		return err
+	}
+	a.recordTagDeletion(quoted("Delete tag", tag.Name), tag, taskIDs)
+	a.logger.Info("Tag deleted successfully", "tag_id", id)
+	return nil
+}
+
+// MergeTags moves every task tagged with the source tag to the target tag and deletes the source
+func (a *App) MergeTags(sourceID string, targetID string) (*server.Tag, error) {
+	a.logger.Info("Merging tags", "source_id", sourceID, "target_id", targetID)
+	v := validate.New()
+	sourceID = v.ID("source_id", sourceID)
+	targetID = v.ID("target_id", targetID)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid tag input", "source_id", sourceID, "target_id", targetID, "error", err)
+		return nil, err
+	}
+	source, err := a.store.GetTag(a.ctx, sourceID)
+	if err != nil {
+		a.logger.Error("Failed to merge tags", "source_id", sourceID, "target_id", targetID, "error", err)
+		return nil, err
+	}
+	sourceTasks, err := a.taggedTasks(a.ctx, sourceID)
+	if err != nil {
+		a.logger.Error("Failed to merge tags", "source_id", sourceID, "target_id", targetID, "error", err)
+		return nil, err
+	}
+	targetTasks, err := a.taggedTasks(a.ctx, targetID)
+	if err != nil {
+		a.logger.Error("Failed to merge tags", "source_id", sourceID, "target_id", targetID, "error", err)
+		return nil, err
+	}
+	tag, err := a.store.MergeTags(a.ctx, sourceID, targetID)
+	if err != nil {
+		a.logger.Error("Failed to merge tags", "source_id", sourceID, "target_id", targetID, "error", err)
+		return nil, err
+	}
+	a.recordTagMerge(quoted("Merge tag", source.Name), source, sourceTasks, targetID, targetTasks)
+	a.logger.Info("Tags merged successfully", "source_id", sourceID, "target_id", targetID)
+	return tag, nil
+}
+
+func (a *App) AddTagToTask(taskID string, tagID string) error {
+	a.logger.Info("Adding tag to task", "task_id", taskID, "tag_id", tagID)
+	v := validate.New()
+	taskID = v.ID("task_id", taskID)
+	tagID = v.ID("tag_id", tagID)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid tag input", "task_id", taskID, "tag_id", tagID, "error", err)
+		return err
+	}
+	task, tagged, err := a.taskTagged(a.ctx, taskID, tagID)
+	if err != nil {
+		a.logger.Error("Failed to add tag to task", "task_id", taskID, "tag_id", tagID, "error", err)
+		return err
+	}
+	if err := a.store.AddTagToTask(a.ctx, taskID, tagID); err != nil {
+		a.logger.Error("Failed to add tag to task", "task_id", taskID, "tag_id", tagID, "error", err)
+		return err
+	}
+	if !tagged {
+		a.recordTagAssignment(quoted("Tag", task.TaskName), taskID, tagID, true)
+	}
+	a.logger.Info("Tag added to task successfully", "task_id", taskID, "tag_id", tagID)
+	return nil
+}
+
+func (a *App) RemoveTagFromTask(taskID string, tagID string) error {
+	a.logger.Info("Removing tag from task", "task_id", taskID, "tag_id", tagID)
+	v := validate.New()
+	taskID = v.ID("task_id", taskID)
+	tagID = v.ID("tag_id", tagID)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid tag input", "task_id", taskID, "tag_id", tagID, "error", err)
+		return err
+	}
+	task, tagged, err := a.taskTagged(a.ctx, taskID, tagID)
+	if err != nil {
+		a.logger.Error("Failed to remove tag from task", "task_id", taskID, "tag_id", tagID, "error", err)
+		return err
+	}
+	if err := a.store.RemoveTagFromTask(a.ctx, taskID, tagID); err != nil {
+		a.logger.Error("Failed to remove tag from task", "task_id", taskID, "tag_id", tagID, "error", err)
+		return err
+	}
+	if tagged {
+		a.recordTagAssignment(quoted("Untag", task.TaskName), taskID, tagID, false)
+	}
+	a.logger.Info("Tag removed from task successfully", "task_id", taskID, "tag_id", tagID)
+	return nil
+}
+
+func (a *App) GetTaskTags(taskID string) ([]server.Tag, error) {
+	a.logger.Info("Getting task tags", "task_id", taskID)
+	v := validate.New()
+	taskID = v.ID("task_id", taskID)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid task input", "task_id", taskID, "error", err)
+		return nil, err
+	}
+	tags, err := a.store.GetTaskTags(a.ctx, taskID)
+	if err != nil {
+		a.logger.Error("Failed to get task tags", "task_id", taskID, "error", err)
+		return nil, err
+	}
+	a.logger.Info("Task tags retrieved successfully", "task_id", taskID, "count", len(tags))
+	return tags, nil
+}
+
+// GetTasksByTag returns the tasks with a tag across all lists, newest first
+func (a *App) GetTasksByTag(tagID string) ([]server.Task, error) {
+	return a.GetTasksByTags([]string{tagID}, false)
+}
+
+// GetTasksByTags returns the tasks across all lists that have every one of the
+// tags when matchAll is set, or any of them otherwise, newest first
+func (a *App) GetTasksByTags(tagIDs []string, matchAll bool) ([]server.Task, error) {
+	a.logger.Info("Getting tasks by tags", "tag_ids", tagIDs, "match_all", matchAll)
+	v := validate.New()
+	tagIDs = v.IDs("tag_ids", tagIDs)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid tag input", "tag_ids", tagIDs, "error", err)
+		return nil, err
+	}
+	tasks, err := a.store.GetTasksByTags(a.ctx, tagIDs, matchAll)
+	if err != nil {
+		a.logger.Error("Failed to get tasks by tags", "tag_ids", tagIDs, "match_all", matchAll, "error", err)
+		return nil, err
+	}
+	a.logger.Info("Tasks by tags retrieved successfully", "count", len(tasks))
+	return tasks, nil
+}
+
+// Smart lists
+
+// CreateSmartList creates a list that shows the tasks matching a query, such
+// as "completed:false due:<7d tag:work sort:due"
+func (a *App) CreateSmartList(title, query string) (*server.List, error) {
+	a.logger.Info("Creating new smart list", "title", title, "query", query)
+	v := validate.New()
+	title = v.Name("title", title)
+	query = v.Query("query", query)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid list input", "title", title, "query", query, "error", err)
+		return nil, err
+	}
+	list, err := a.store.CreateSmartList(a.ctx, title, query)
+	if err != nil {
+		a.logger.Error("Failed to create smart list", "title", title, "query", query, "error", err)
+		return nil, err
+	}
+	a.history.Record(quoted("Create smart list", list.Title), a.deleteListOp(*list), a.restoreListOp(list.ID))
+	a.logger.Info("Smart list created successfully", "list_id", list.ID, "title", list.Title)
+	return list, nil
+}
+
+// SetListQuery changes the query of a smart list
+func (a *App) SetListQuery(id, query string) (*server.List, error) {
+	a.logger.Info("Setting list query", "list_id", id, "query", query)
+	v := validate.New()
+	id = v.ID("id", id)
+	query = v.Query("query", query)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid list input", "list_id", id, "query", query, "error", err)
+		return nil, err
+	}
+	before, err := a.store.GetList(a.ctx, id)
+	if err != nil {
+		a.logger.Error("Failed to set list query", "list_id", id, "error", err)
+		return nil, err
+	}
+	list, err := a.store.PatchList(a.ctx, id, server.ListPatch{Query: &query})
+	if err != nil {
+		a.logger.Error("Failed to set list query", "list_id", id, "error", err)
+		return nil, err
+	}
+	a.recordListEdit(quoted("Edit query of", before.Title), before, list)
+	a.logger.Info("List query set successfully", "list_id", id)
+	return list, nil
+}
+
+// GetSmartListTasks returns the tasks matching the query of a smart list, in
+// the order the query asks for
+func (a *App) GetSmartListTasks(id string) ([]server.Task, error) {
+	a.logger.Info("Getting smart list tasks", "list_id", id)
+	v := validate.New()
+	id = v.ID("id", id)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid list input", "list_id", id, "error", err)
+		return nil, err
+	}
+	tasks, err := a.store.GetSmartListTasks(a.ctx, id, time.Now())
+	if err != nil {
+		a.logger.Error("Failed to get smart list tasks", "list_id", id, "error", err)
+		return nil, err
+	}
+	a.logger.Info("Smart list tasks retrieved successfully", "list_id", id, "count", len(tasks))
+	return tasks, nil
+}
+
+// QueryTasks returns the tasks across all lists that match a query
+func (a *App) QueryTasks(query string) ([]server.Task, error) {
+	a.logger.Info("Querying tasks", "query", query)
+	v := validate.New()
+	query = v.Query("query", query)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid query input", "query", query, "error", err)
+		return nil, err
+	}
+	tasks, err := a.store.QueryTasks(a.ctx, query, time.Now())
+	if err != nil {
+		a.logger.Error("Failed to query tasks", "query", query, "error", err)
+		return nil, err
+	}
+	a.logger.Info("Tasks queried successfully", "query", query, "count", len(tasks))
+	return tasks, nil
+}
+
+// Import and export
+
+// ExportWorkspace returns every list, task, subtask and tag, without the
+// trash, as a versioned document that ImportWorkspace reads back
+func (a *App) ExportWorkspace() (*server.Workspace, error) {
+	a.logger.Info("Exporting workspace")
+	doc, err := a.store.ExportWorkspace(a.ctx)
+	if err != nil {
+		a.logger.Error("Failed to export workspace", "error", err)
+		return nil, err
+	}
+	a.logger.Info("Workspace exported successfully", "lists", len(doc.Lists), "tags", len(doc.Tags))
+	return doc, nil
+}
+
+// ImportWorkspace imports a document written by ExportWorkspace in one
+// transaction. Mode "merge" updates the lists, tasks and subtasks whose ids
+// match existing ones and adds the others after them; mode "replace" deletes
+// everything, including the trash, first. With dryRun set nothing is changed
+// and the report tells, row by row, what the import would do.
+func (a *App) ImportWorkspace(doc server.Workspace, mode string, dryRun bool) (*server.ImportReport, error) {
+	a.logger.Info("Importing workspace", "mode", mode, "dry_run", dryRun, "lists", len(doc.Lists))
+	v := validate.New()
+	v.Workspace("doc", &doc)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid workspace input", "mode", mode, "error", err)
+		return nil, err
+	}
+	var before *importSnapshot
+	if !dryRun && server.ImportMode(mode) == server.ImportMerge {
+		snapshot, err := a.snapshotImport(a.ctx, &doc)
+		if err != nil {
+			a.logger.Error("Failed to import workspace", "mode", mode, "error", err)
+			return nil, err
+		}
+		before = snapshot
+	}
+	report, err := a.store.ImportWorkspace(a.ctx, &doc, server.ImportMode(mode), dryRun)
+	if err != nil {
+		a.logger.Error("Failed to import workspace", "mode", mode, "dry_run", dryRun, "error", err)
+		return nil, err
+	}
+	switch {
+	case dryRun:
+	case report.Mode == server.ImportReplace:
+		// Recorded changes refer to rows that are gone
+		a.history.Clear()
+	default:
+		a.recordImport("Import workspace", before, report)
+	}
+	a.logger.Info("Workspace imported successfully", "mode", mode, "dry_run", dryRun,
+		"lists", report.Created.Lists, "tasks", report.Created.Tasks, "subtasks", report.Created.SubTasks,
+		"updated_lists", report.Updated.Lists, "updated_tasks", report.Updated.Tasks, "updated_subtasks", report.Updated.SubTasks,
+		"remapped", len(report.Remapped))
+	return report, nil
+}
+
+// ExportListMarkdown renders a list as a Markdown checklist with a heading,
+// "- [ ]" and "- [x]" tasks and indented subtasks
+func (a *App) ExportListMarkdown(listID string) (string, error) {
+	a.logger.Info("Exporting list as Markdown", "list_id", listID)
+	v := validate.New()
+	listID = v.ID("list_id", listID)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid list input", "list_id", listID, "error", err)
+		return "", err
+	}
+	tree, err := a.store.GetListTree(a.ctx, listID)
+	if err != nil {
+		a.logger.Error("Failed to export list as Markdown", "list_id", listID, "error", err)
+		return "", err
+	}
+	a.logger.Info("List exported as Markdown successfully", "list_id", listID, "tasks", len(tree.Tasks))
+	return markdown.Render(tree), nil
+}
+
+// ImportMarkdown imports a Markdown checklist in one transaction. Headings
+// create lists, items become tasks and nested items subtasks. Items before the
+// first heading are added to the end of the list with targetListID, which may
+// be empty when there are none.
+func (a *App) ImportMarkdown(text string, targetListID string) (*server.ImportReport, error) {
+	a.logger.Info("Importing Markdown", "list_id", targetListID, "length", len(text))
+	v := validate.New()
+	if targetListID != "" {
+		targetListID = v.ID("list_id", targetListID)
+	}
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid Markdown input", "list_id", targetListID, "error", err)
+		return nil, err
+	}
+	doc, err := markdown.Parse(text, targetListID)
+	if err != nil {
+		a.logger.Error("Failed to parse Markdown", "list_id", targetListID, "error", err)
+		return nil, err
+	}
+	return a.importDocument("Import Markdown", "markdown", doc, false)
+}
+
+// ExportTodoTxt writes every task and subtask in the todo.txt format, with
+// lists as +projects, tags as @contexts and dates in the local time zone
+func (a *App) ExportTodoTxt() (string, error) {
+	a.logger.Info("Exporting todo.txt")
+	doc, err := a.store.ExportWorkspace(a.ctx)
+	if err != nil {
+		a.logger.Error("Failed to export todo.txt", "error", err)
+		return "", err
+	}
+	text := todotxt.Render(doc, time.Local)
+	a.logger.Info("todo.txt exported successfully", "lists", len(doc.Lists))
+	return text, nil
+}
+
+// ImportTodoTxt imports todo.txt lines in one transaction. Tasks go to the
+// list whose title matches their +project, or to a new list named after it;
+// tasks without a project are added to the end of the list with targetListID,
+// which may be empty when there are none.
+func (a *App) ImportTodoTxt(text string, targetListID string) (*server.ImportReport, error) {
+	a.logger.Info("Importing todo.txt", "list_id", targetListID, "length", len(text))
+	v := validate.New()
+	if targetListID != "" {
+		targetListID = v.ID("list_id", targetListID)
+	}
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid todo.txt input", "list_id", targetListID, "error", err)
+		return nil, err
+	}
+	lists, err := a.store.GetAllLists(a.ctx)
+	if err != nil {
+		a.logger.Error("Failed to import todo.txt", "error", err)
+		return nil, err
+	}
+	tags, err := a.store.GetAllTags(a.ctx)
+	if err != nil {
+		a.logger.Error("Failed to import todo.txt", "error", err)
+		return nil, err
+	}
+	doc, err := todotxt.Parse(text, todotxt.Options{ListID: targetListID, Lists: lists, Tags: tags, Location: time.Local})
+	if err != nil {
+		a.logger.Error("Failed to parse todo.txt", "list_id", targetListID, "error", err)
+		return nil, err
+	}
+	return a.importDocument("Import todo.txt", "todotxt", doc, false)
+}
+
+// ExportICal writes tasks and subtasks as iCalendar VTODOs of a single
+// calendar; an empty listID exports every list
+func (a *App) ExportICal(listID string) (string, error) {
+	a.logger.Info("Exporting iCalendar", "list_id", listID)
+	v := validate.New()
+	if listID != "" {
+		listID = v.ID("list_id", listID)
+	}
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid list input", "list_id", listID, "error", err)
+		return "", err
+	}
+	doc, err := a.exportLists(listID)
+	if err != nil {
+		a.logger.Error("Failed to export iCalendar", "list_id", listID, "error", err)
+		return "", err
+	}
+	text := ical.Render(doc)
+	a.logger.Info("iCalendar exported successfully", "list_id", listID, "lists", len(doc.Lists))
+	return text, nil
+}
+
+// ImportICal imports the VTODOs of an .ics file in one transaction. Tasks go to
+// the list whose title matches their X-WR-CALNAME, or that of their calendar,
+// or to a new list named after it; tasks without one are added to the end of
+// the list with targetListID, which may be empty when there are none.
+func (a *App) ImportICal(text string, targetListID string) (*server.ImportReport, error) {
+	a.logger.Info("Importing iCalendar", "list_id", targetListID, "length", len(text))
+	v := validate.New()
+	if targetListID != "" {
+		targetListID = v.ID("list_id", targetListID)
+	}
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid iCalendar input", "list_id", targetListID, "error", err)
+		return nil, err
+	}
+	lists, err := a.store.GetAllLists(a.ctx)
+	if err != nil {
+		a.logger.Error("Failed to import iCalendar", "error", err)
+		return nil, err
+	}
+	doc, err := ical.Parse(text, ical.Options{ListID: targetListID, Lists: lists, Location: time.Local})
+	if err != nil {
+		a.logger.Error("Failed to parse iCalendar", "list_id", targetListID, "error", err)
+		return nil, err
+	}
+	return a.importDocument("Import iCalendar", "ical", doc, false)
+}
+
+// ExportCSV writes tasks and subtasks as CSV rows of list, task, subtask,
+// completed, created and updated, with times in the local time zone; an empty
+// listID exports every list
+func (a *App) ExportCSV(listID string) (string, error) {
+	a.logger.Info("Exporting CSV", "list_id", listID)
+	v := validate.New()
+	if listID != "" {
+		listID = v.ID("list_id", listID)
+	}
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid list input", "list_id", listID, "error", err)
+		return "", err
+	}
+	doc, err := a.exportLists(listID)
+	if err != nil {
+		a.logger.Error("Failed to export CSV", "list_id", listID, "error", err)
+		return "", err
+	}
+	text := csvfile.Render(doc, time.Local)
+	a.logger.Info("CSV exported successfully", "list_id", listID, "lists", len(doc.Lists))
+	return text, nil
+}
+
+// ImportCSV imports the rows of a CSV file in one transaction, laid out as
+// format says; its zero value detects the header, delimiter and encoding.
+// Rows go to the list whose title matches their list column, or to a new list
+// named after it; rows without a list are added to the end of the list with
+// targetListID, which may be empty when there are none. Rows that cannot be
+// imported are listed in the report instead of failing the import, and with
+// dryRun set nothing is changed.
+func (a *App) ImportCSV(data []byte, format csvfile.Format, targetListID string, dryRun bool) (*csvfile.Report, error) {
+	a.logger.Info("Importing CSV", "list_id", targetListID, "dry_run", dryRun, "size", len(data))
+	v := validate.New()
+	if targetListID != "" {
+		targetListID = v.ID("list_id", targetListID)
+	}
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid CSV input", "list_id", targetListID, "error", err)
+		return nil, err
+	}
+	lists, err := a.store.GetAllLists(a.ctx)
+	if err != nil {
+		a.logger.Error("Failed to import CSV", "error", err)
+		return nil, err
+	}
+	doc, report, err := csvfile.Parse(data, csvfile.Options{Format: format, ListID: targetListID, Lists: lists, Location: time.Local})
+	if err != nil {
+		a.logger.Error("Failed to parse CSV", "list_id", targetListID, "error", err)
+		return nil, err
+	}
+	report.Import, err = a.importDocument("Import CSV", "csv", doc, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// exportLists returns the export of the list with listID, or of every list
+// when it is empty
+func (a *App) exportLists(listID string) (*server.Workspace, error) {
+	doc, err := a.store.ExportWorkspace(a.ctx)
+	if err != nil {
+		return nil, err
+	}
+	if listID != "" {
+		doc.Lists = slices.DeleteFunc(doc.Lists, func(list server.WorkspaceList) bool { return list.ID != listID })
+		if len(doc.Lists) == 0 {
+			return nil, server.NotFound("list", listID)
+		}
+	}
+	return doc, nil
+}
+
+// importDocument merges a document read by one of the text format importers,
+// or only reports what merging it would do when dryRun is set
+func (a *App) importDocument(label, field string, doc *server.Workspace, dryRun bool) (*server.ImportReport, error) {
+	v := validate.New()
+	v.Workspace(field, doc)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid import input", "format", field, "error", err)
+		return nil, err
+	}
+	var before *importSnapshot
+	if !dryRun {
+		snapshot, err := a.snapshotImport(a.ctx, doc)
+		if err != nil {
+			a.logger.Error("Failed to import", "format", field, "error", err)
+			return nil, err
+		}
+		before = snapshot
+	}
+	report, err := a.store.ImportWorkspace(a.ctx, doc, server.ImportMerge, dryRun)
+	if err != nil {
+		a.logger.Error("Failed to import", "format", field, "dry_run", dryRun, "error", err)
+		return nil, err
+	}
+	if !dryRun {
+		a.recordImport(label, before, report)
+	}
+	a.logger.Info("Imported successfully", "format", field, "dry_run", dryRun,
+		"lists", report.Created.Lists, "tasks", report.Created.Tasks, "subtasks", report.Created.SubTasks,
+		"updated_tasks", report.Updated.Tasks)
+	return report, nil
+}
+
+// Search
+
+// Search finds lists, tasks and subtasks whose names have a word starting with
+// every word of the query, best match first
+func (a *App) Search(query string) ([]server.SearchHit, error) {
+	a.logger.Info("Searching", "query", query)
+	hits, err := a.store.Search(a.ctx, query)
+	if err != nil {
+		a.logger.Error("Failed to search", "query", query, "error", err)
+		return nil, err
+	}
+	a.logger.Info("Search completed successfully", "query", query, "count", len(hits))
+	return hits, nil
+}
+
+// Activity
+
+// GetTaskActivity returns the changes made to a task and its subtasks, newest first
+func (a *App) GetTaskActivity(taskID string) ([]server.Activity, error) {
+	a.logger.Info("Getting task activity", "task_id", taskID)
+	v := validate.New()
+	taskID = v.ID("task_id", taskID)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid task input", "task_id", taskID, "error", err)
+		return nil, err
+	}
+	activity, err := a.store.GetTaskActivity(a.ctx, taskID)
+	if err != nil {
+		a.logger.Error("Failed to get task activity", "task_id", taskID, "error", err)
+		return nil, err
+	}
+	a.logger.Info("Task activity retrieved successfully", "task_id", taskID, "count", len(activity))
+	return activity, nil
+}
+
+// GetListActivity returns the changes made to a list and everything in it since
+// a time formatted as RFC 3339, newest first; an empty since returns all of them
+func (a *App) GetListActivity(listID string, since string) ([]server.Activity, error) {
+	a.logger.Info("Getting list activity", "list_id", listID, "since", since)
+	v := validate.New()
+	listID = v.ID("list_id", listID)
+	if err := v.Err(); err != nil {
+		a.logger.Error("Invalid list input", "list_id", listID, "error", err)
+		return nil, err
+	}
+	from, err := parseOptionalTime(since)
+	if err != nil {
+		a.logger.Error("Invalid activity start", "since", since, "error", err)
+		return nil, err
+	}
+	var start time.Time
+	if from != nil {
+		start = *from
+	}
+	activity, err := a.store.GetListActivity(a.ctx, listID, start)
+	if err != nil {
+		a.logger.Error("Failed to get list activity", "list_id", listID, "since", since, "error", err)
+		return nil, err
+	}
+	a.logger.Info("List activity retrieved successfully", "list_id", listID, "count", len(activity))
+	return activity, nil
+}
+
+// purgeTrashLoop removes items deleted more than days ago, once at startup and
+// then every trashPurgeInterval until ctx is cancelled
+func (a *App) purgeTrashLoop(ctx context.Context, days int) {
+	ticker := time.NewTicker(trashPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		before := time.Now().AddDate(0, 0, -days)
+		purged, err := a.store.PurgeTrash(ctx, before)
+		switch {
+		case ctx.Err() != nil:
+			return
+		case err != nil:
+			a.logger.Error("Failed to purge trash", "before", before, "error", err)
+		case purged > 0:
+			a.logger.Info("Trash purged", "before", before, "purged", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
 	}
-	a.logger.Info("Subtask deleted successfully", "subtask_id", id)
-	return nil
 }
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	server "github.com/HolySxn/To-Do/internal"
+	"github.com/HolySxn/To-Do/internal/config"
+	"github.com/HolySxn/To-Do/internal/memory"
+)
+
+// newTestApp returns an App over an empty in-memory store
+func newTestApp(t *testing.T) *App {
+	t.Helper()
+	store := memory.New()
+	t.Cleanup(store.Close)
+	cfg := &config.Config{App: config.AppConfig{HistoryLimit: 50}}
+	a := NewApp(store, cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	a.ctx = context.Background()
+	return a
+}
+
+func TestValidation(t *testing.T) {
+	a := newTestApp(t)
+
+	list, err := a.CreateList("  Groceries\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.Title != "Groceries" {
+		t.Errorf("Title = %q, want the cleaned title", list.Title)
+	}
+	task, err := a.CreateTask(strings.ToUpper(list.ID), "Milk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.ListID != list.ID {
+		t.Errorf("ListID = %q, want %q", task.ListID, list.ID)
+	}
+
+	var validation *server.ValidationError
+	if _, err := a.CreateList(" \t "); !errors.As(err, &validation) || validation.Fields[0].Field != "title" {
+		t.Errorf("CreateList() of a blank title: error = %v, want a title field error", err)
+	}
+	if _, err := a.CreateTask("not-an-id", ""); !errors.As(err, &validation) || len(validation.Fields) != 2 {
+		t.Errorf("CreateTask() = %v, want errors for both arguments", err)
+	}
+	notes := "    indented\n" + strings.Repeat("x", 20000)
+	if got, err := a.SetTaskNotes(task.ID, notes); err != nil || got.Notes != notes {
+		t.Errorf("SetTaskNotes() of long indented notes: error = %v, want them kept as they are", err)
+	}
+	if lists, err := a.GetAllLists(); err != nil || len(lists) != 1 {
+		t.Errorf("GetAllLists() = %d lists, %v; want only the valid one", len(lists), err)
+	}
+}
+
+func TestActivityActor(t *testing.T) {
+	a := newTestApp(t)
+	a.config.App.Actor = "alice"
+	a.startup(context.Background())
+
+	list, err := a.CreateList("Groceries")
+	if err != nil {
+		t.Fatal(err)
+	}
+	activity, err := a.GetListActivity(list.ID, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(activity) != 1 || activity[0].Actor != "alice" {
+		t.Errorf("GetListActivity() = %+v, want one create by alice", activity)
+	}
+}
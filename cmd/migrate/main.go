@@ -0,0 +1,132 @@
+// Command migrate inspects and applies database schema migrations outside of
+// app startup. It reads the same configuration as the application.
+//
+// Usage:
+//
+//	migrate status      list migrations and whether they are applied
+//	migrate up          apply all pending migrations
+//	migrate down [N]    revert the last N applied migrations (default 1)
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/HolySxn/To-Do/internal/config"
+	"github.com/HolySxn/To-Do/internal/db"
+	"github.com/HolySxn/To-Do/internal/migrate"
+	"github.com/HolySxn/To-Do/internal/sqlite"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	migrator, closeDB, err := openMigrator(cfg)
+	if err != nil {
+		log.Fatalf("Failed to open database: %v", err)
+	}
+	defer closeDB()
+
+	ctx := context.Background()
+	switch os.Args[1] {
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatalf("Failed to read migration status: %v", err)
+		}
+		printStatus(statuses)
+
+	case "up":
+		ran, err := migrator.Up(ctx)
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		printRan("Applied", ran)
+
+	case "down":
+		steps := 1
+		if len(os.Args) > 2 {
+			steps, err = strconv.Atoi(os.Args[2])
+			if err != nil || steps <= 0 {
+				log.Fatalf("Invalid number of steps %q", os.Args[2])
+			}
+		}
+		ran, err := migrator.Down(ctx, steps)
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		printRan("Reverted", ran)
+
+	default:
+		usage()
+	}
+}
+
+// openMigrator connects to the configured database without applying migrations
+func openMigrator(cfg *config.Config) (*migrate.Migrator, func(), error) {
+	switch cfg.Database.Driver {
+	case config.DriverSQLite:
+		database, err := sqlite.Open(cfg.Database.Path)
+		if err != nil {
+			return nil, nil, err
+		}
+		migrator, err := database.Migrator()
+		if err != nil {
+			database.Close()
+			return nil, nil, err
+		}
+		return migrator, database.Close, nil
+	default:
+		database, err := db.Connect(cfg.GetConnectionString())
+		if err != nil {
+			return nil, nil, err
+		}
+		migrator, err := database.Migrator()
+		if err != nil {
+			database.Close()
+			return nil, nil, err
+		}
+		return migrator, database.Close, nil
+	}
+}
+
+func printStatus(statuses []migrate.Status) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, status := range statuses {
+		state, appliedAt := "pending", ""
+		if status.Applied {
+			state = "applied"
+			appliedAt = status.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", status.Version, status.Name, state, appliedAt)
+	}
+	w.Flush()
+}
+
+func printRan(verb string, ran []migrate.Migration) {
+	if len(ran) == 0 {
+		fmt.Println("Nothing to do")
+		return
+	}
+	for _, m := range ran {
+		fmt.Printf("%s %d_%s\n", verb, m.Version, m.Name)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: migrate status | up | down [N]")
+	os.Exit(2)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	server "github.com/HolySxn/To-Do/internal"
+	"github.com/HolySxn/To-Do/internal/csvfile"
+)
+
+func TestCSV(t *testing.T) {
+	a := newTestApp(t)
+	home, _ := a.CreateList("Home")
+	a.CreateList("Empty")
+	laundry, _ := a.CreateTask(home.ID, `Laundry, "cold"`)
+	a.CreateSubTask(laundry.ID, "Fold")
+
+	text, err := a.ExportCSV("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSuffix(text, "\r\n"), "\r\n"); len(lines) != 4 || !strings.HasSuffix(lines[0], "list,task,subtask,completed,created,updated") {
+		t.Errorf("ExportCSV() = %q, want a header, Laundry, Fold and Empty", text)
+	}
+
+	// Importing the export adds copies, since rows have no ids
+	report, err := a.ImportCSV([]byte(text), csvfile.Format{}, "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Rows != 3 || len(report.Errors) != 0 || !report.Import.DryRun || report.Import.Created != (server.ImportCounts{Tasks: 1, SubTasks: 1}) {
+		t.Errorf("dry run report = %+v %+v, want a task and a subtask added", report, report.Import)
+	}
+	if tasks, _ := a.GetTasksByListID(home.ID); len(tasks) != 1 {
+		t.Errorf("GetTasksByListID() after the dry run = %+v, want only Laundry", tasks)
+	}
+
+	data := []byte("Name;Done;Project;Prio\r\nReport;x;Work;high\r\nBad;maybe;Work;\r\nLoose;no;;low\r\n")
+	format := csvfile.Format{Columns: map[string]string{"task": "Name", "list": "project", "priority": "4"}}
+	report, err = a.ImportCSV(data, format, home.ID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Rows != 3 || len(report.Errors) != 1 || report.Errors[0].Line != 3 || report.Import.Created != (server.ImportCounts{Lists: 1, Tasks: 2}) {
+		t.Errorf("report = %+v %+v, want Work, Report and Loose created and line 3 skipped", report, report.Import)
+	}
+	if tasks, _ := a.GetTasksByListID(home.ID); len(tasks) != 2 || tasks[1].TaskName != "Loose" || tasks[1].Priority != server.PriorityLow {
+		t.Errorf("GetTasksByListID() = %+v, want Loose added to Home", tasks)
+	}
+	if _, err := a.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if lists, _ := a.GetAllLists(); len(lists) != 2 {
+		t.Errorf("GetAllLists() after undo = %+v, want Work gone", lists)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		format csvfile.Format
+		listID string
+	}{
+		{"a missing column", csvfile.Format{Columns: map[string]string{"task": "Nope"}}, ""},
+		{"an unknown encoding", csvfile.Format{Encoding: "ebcdic"}, ""},
+		{"a bad list id", csvfile.Format{}, "home"},
+	} {
+		if _, err := a.ImportCSV(data, tt.format, tt.listID, false); !errors.Is(err, server.ErrInvalidInput) {
+			t.Errorf("ImportCSV() with %s: err = %v, want ErrInvalidInput", tt.name, err)
+		}
+	}
+}
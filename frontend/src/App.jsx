@@ -1,4 +1,4 @@
-import React, { useState } from 'react'
+import React, { useState, useEffect } from 'react'
 import './App.css'
 import Header from './components/Header'
 import Sidebar from './components/Sidebar'
@@ -6,12 +6,13 @@ import TaskList from './components/TaskList'
 import LoadingScreen from './components/LoadingScreen'
 import { useTodoData } from './hooks/useTodoData'
 import { useTaskActions } from './hooks/useTaskActions'
+import { MoveTask, Undo, Redo } from '../wailsjs/go/main/App'
 
 function App() {
   const [sidebarOpen, setSidebarOpen] = useState(false)
   const [listsCollapsed, setListsCollapsed] = useState(true)
   
-  const { lists, setLists, taskLists, setTaskLists, loading } = useTodoData()
+  const { lists, setLists, taskLists, setTaskLists, loading, loadData } = useTodoData()
   const { 
     sortStates, 
     setSortStates, 
@@ -27,6 +28,31 @@ function App() {
     reorderLists
   } = useTaskActions()
 
+  // Ctrl+Z undoes the last change; Ctrl+Shift+Z or Ctrl+Y redoes it
+  useEffect(() => {
+    const handleKeyDown = async (e) => {
+      if (!(e.ctrlKey || e.metaKey) || e.target.closest?.('input, textarea')) return
+      const key = e.key.toLowerCase()
+      const redo = key === 'y' || (key === 'z' && e.shiftKey)
+      if (key !== 'z' && !redo) return
+
+      e.preventDefault()
+      try {
+        await (redo ? Redo() : Undo())
+      } catch (error) {
+        // Nothing to undo or redo, or the change no longer applies
+        if (error && error.code !== 'not_found') alert(error.message || 'Failed to undo the change.')
+        // A conflict means the row was changed elsewhere; show how it is now
+        if (error && error.code === 'conflict') await loadData()
+        return
+      }
+      await loadData()
+    }
+
+    window.addEventListener('keydown', handleKeyDown)
+    return () => window.removeEventListener('keydown', handleKeyDown)
+  }, [])
+
   // UI state handlers
   const toggleSidebar = () => setSidebarOpen(!sidebarOpen)
   const toggleLists = () => setListsCollapsed(!listsCollapsed)
@@ -129,12 +155,21 @@ function App() {
     })))
   }
 
-  const moveTaskToList = (fromListId, taskId, toListId) => {
+  const moveTaskToList = async (fromListId, taskId, toListId) => {
     if (fromListId === toListId) return
 
     const sourceList = taskLists.find(list => list.id === fromListId)
     const taskToMove = sourceList.tasks.find(task => task.id === taskId)
 
+    let movedTask
+    try {
+      // Position 0 appends the task to the end of the target list
+      movedTask = await MoveTask(taskId, toListId, 0)
+    } catch (error) {
+      alert('Failed to move task. Please try again.')
+      return
+    }
+
     setTaskLists(lists => lists.map(list => {
       if (list.id === fromListId) {
         return {
@@ -146,7 +181,7 @@ function App() {
           ...list,
           tasks: [
             ...list.tasks,
-            { ...taskToMove, settingsOpen: false }
+            { ...taskToMove, ...movedTask, settingsOpen: false }
           ]
         }
       }
@@ -160,11 +195,21 @@ function App() {
   const handleDeleteList = (listId) => deleteList(listId, lists, taskLists, setLists, setTaskLists)
   const handleReorderLists = (listIds) => reorderLists(listIds, setLists, setTaskLists)
   const handleAddTask = (listId) => addTask(listId, setTaskLists, setLists)
-  const handleToggleTask = (listId, taskId) => toggleTask(listId, taskId, setTaskLists)
-  const handleDeleteTask = (listId, taskId) => deleteTask(listId, taskId, setTaskLists, setLists)
+  const handleToggleTask = (listId, taskId) => {
+    const task = taskLists.find(list => list.id === listId)?.tasks.find(task => task.id === taskId)
+    return toggleTask(listId, taskId, task?.completed, setTaskLists)
+  }
+  const handleDeleteTask = (listId, taskId) => {
+    const task = taskLists.find(list => list.id === listId)?.tasks.find(task => task.id === taskId)
+    return deleteTask(listId, taskId, task?.version, setTaskLists, setLists)
+  }
   const handleAddSubtask = (listId, taskId) => addSubtask(listId, taskId, setTaskLists)
   const handleToggleSubtask = (listId, taskId, subtaskId) => toggleSubtask(listId, taskId, subtaskId, setTaskLists)
-  const handleDeleteSubtask = (listId, taskId, subtaskId) => deleteSubtask(listId, taskId, subtaskId, setTaskLists)
+  const handleDeleteSubtask = (listId, taskId, subtaskId) => {
+    const task = taskLists.find(list => list.id === listId)?.tasks.find(task => task.id === taskId)
+    const subtask = task?.subtasks?.find(subtask => subtask.id === subtaskId)
+    return deleteSubtask(listId, taskId, subtaskId, subtask?.version, setTaskLists)
+  }
 
   if (loading) {
     return <LoadingScreen onToggleSidebar={toggleSidebar} />
@@ -40,7 +40,7 @@ function TaskItem({
                   Move to list
                 </div>
                 <div className="task-settings-submenu">
-                  {taskLists.filter(otherList => otherList.id !== listId).map(otherList => (
+                  {taskLists.filter(otherList => otherList.id !== listId && !otherList.smart).map(otherList => (
                     <div key={otherList.id} className="task-settings-subitem" onClick={() => onMoveTaskToList(listId, task.id, otherList.id)}>
                       {otherList.title}
                     </div>
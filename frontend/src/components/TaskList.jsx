@@ -74,13 +74,15 @@ function TaskList({
         </div>
       </div>
       
-      {/* Add Task Button */}
-      <div className="add-task-button" onClick={() => onAddTask(list.id)}>
-        <div className="add-task-radio">
-          <div className="add-task-circle">+</div>
+      {/* Add Task Button, hidden on smart lists which hold no tasks of their own */}
+      {!list.smart && (
+        <div className="add-task-button" onClick={() => onAddTask(list.id)}>
+          <div className="add-task-radio">
+            <div className="add-task-circle">+</div>
+          </div>
+          <span className="add-task-text">Add a task</span>
         </div>
-        <span className="add-task-text">Add a task</span>
-      </div>
+      )}
       
       <div className="task-list">
         {/* Active Tasks */}
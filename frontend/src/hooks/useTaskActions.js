@@ -3,17 +3,23 @@ import {
   CreateList, 
   UpdateList, 
   DeleteList,
+  GetAllLists,
   ReorderLists,
   CreateTask,
   UpdateTask,
   DeleteTask,
   ToggleTaskCompletion,
+  CompleteTask,
+  GetSubTasksByTaskID,
   CreateSubTask,
   UpdateSubTask,
   DeleteSubTask,
   ToggleSubTaskCompletion
 } from '../../wailsjs/go/main/App'
 
+// Bound methods reject with {code, message, details}; conflicts carry the row as it is now stored
+const conflictState = (error) => (error && error.code === 'conflict' && error.details && error.details.current) || null
+
 export function useTaskActions() {
   const [sortStates, setSortStates] = useState({})
 
@@ -56,22 +62,38 @@ export function useTaskActions() {
     if (!newName || !newName.trim()) return
 
     try {
-      const updatedList = await UpdateList(listId, newName.trim())
+      const updatedList = await UpdateList(listId, newName.trim(), list.version)
       
       if (updatedList) {
         setLists(lists => lists.map(l => 
           l.id === listId 
-            ? { ...l, title: updatedList.title }
+            ? { ...l, title: updatedList.title, version: updatedList.version }
             : l
         ))
         
         setTaskLists(lists => lists.map(l => 
           l.id === listId 
-            ? { ...l, title: updatedList.title, settingsOpen: false }
+            ? { ...l, title: updatedList.title, version: updatedList.version, settingsOpen: false }
             : l
         ))
       }
     } catch (error) {
+      const current = conflictState(error)
+      if (current) {
+        // Show the latest state so the rename can be retried against it
+        setLists(lists => lists.map(l => 
+          l.id === listId 
+            ? { ...l, title: current.title, version: current.version }
+            : l
+        ))
+        setTaskLists(lists => lists.map(l => 
+          l.id === listId 
+            ? { ...l, title: current.title, version: current.version }
+            : l
+        ))
+        alert('This list was changed elsewhere. Its latest version has been loaded, please try again.')
+        return
+      }
       alert('Failed to rename list. Please try again.')
     }
   }
@@ -84,11 +106,27 @@ export function useTaskActions() {
     if (!confirmDelete) return
 
     try {
-      await DeleteList(listId)
+      await DeleteList(listId, list.version)
       
       setLists(lists => lists.filter(l => l.id !== listId))
       setTaskLists(taskLists => taskLists.filter(l => l.id !== listId))
     } catch (error) {
+      const current = conflictState(error)
+      if (current) {
+        // Show the latest state so the delete can be confirmed against it
+        setLists(lists => lists.map(l => 
+          l.id === listId 
+            ? { ...l, title: current.title, version: current.version }
+            : l
+        ))
+        setTaskLists(lists => lists.map(l => 
+          l.id === listId 
+            ? { ...l, title: current.title, version: current.version }
+            : l
+        ))
+        alert('This list was changed elsewhere. Its latest version has been loaded, please try again.')
+        return
+      }
       alert('Failed to delete list. Please try again.')
     }
   }
@@ -96,16 +134,19 @@ export function useTaskActions() {
   const reorderLists = async (listIds, setLists, setTaskLists) => {
     try {
       await ReorderLists(listIds)
+      // Moving a list bumps its version, which later renames and deletes must send
+      const versions = new Map((await GetAllLists() || []).map(list => [list.id, list.version]))
+      const withVersion = list => versions.has(list.id) ? { ...list, version: versions.get(list.id) } : list
       
       // Update the order in both state arrays
       setLists(prevLists => {
         const reorderedLists = listIds.map(id => prevLists.find(list => list.id === id)).filter(Boolean)
-        return reorderedLists
+        return reorderedLists.map(withVersion)
       })
       
       setTaskLists(prevTaskLists => {
         const reorderedTaskLists = listIds.map(id => prevTaskLists.find(list => list.id === id)).filter(Boolean)
-        return reorderedTaskLists
+        return reorderedTaskLists.map(withVersion)
       })
     } catch (error) {
       alert('Failed to reorder lists. Please try again.')
@@ -165,19 +206,39 @@ export function useTaskActions() {
     }
   }
 
-  const toggleTask = async (listId, taskId, setTaskLists) => {
+  const toggleTask = async (listId, taskId, completed, setTaskLists) => {
     try {
-      const updatedTask = await ToggleTaskCompletion(taskId)
+      let updatedTask
+      let nextTask = null
+      if (!completed) {
+        // Completing a recurring task spawns its next occurrence
+        const completion = await CompleteTask(taskId)
+        updatedTask = completion.task
+        if (completion.next) {
+          const subtasks = await GetSubTasksByTaskID(completion.next.id) || []
+          nextTask = {
+            ...completion.next,
+            text: completion.next.task_name,
+            subtasks: subtasks.map(subtask => ({ ...subtask, text: subtask.subtask_name })),
+            settingsOpen: false
+          }
+        }
+      } else {
+        updatedTask = await ToggleTaskCompletion(taskId)
+      }
       
       setTaskLists(lists => lists.map(list => 
         list.id === listId 
           ? { 
               ...list, 
-              tasks: list.tasks.map(task => 
-                task.id === taskId 
-                  ? { ...task, completed: updatedTask.completed }
-                  : task
-              )
+              tasks: [
+                ...(nextTask ? [nextTask] : []),
+                ...list.tasks.map(task => 
+                  task.id === taskId 
+                    ? { ...task, completed: updatedTask.completed, version: updatedTask.version }
+                    : task
+                )
+              ]
             }
           : list
       ))
@@ -186,10 +247,10 @@ export function useTaskActions() {
     }
   }
 
-  const deleteTask = async (listId, taskId, setTaskLists, setLists) => {
+  const deleteTask = async (listId, taskId, version, setTaskLists, setLists) => {
     if (confirm('Are you sure you want to delete this task?')) {
       try {
-        await DeleteTask(taskId)
+        await DeleteTask(taskId, version)
         
         setTaskLists(lists => lists.map(list => 
           list.id === listId 
@@ -207,6 +268,24 @@ export function useTaskActions() {
             : list
         ))
       } catch (error) {
+        const current = conflictState(error)
+        if (current) {
+          // Show the latest state so the delete can be confirmed against it
+          setTaskLists(lists => lists.map(list => 
+            list.id === listId 
+              ? {
+                  ...list,
+                  tasks: list.tasks.map(task => 
+                    task.id === taskId 
+                      ? { ...task, ...current, text: current.task_name }
+                      : task
+                  )
+                }
+              : list
+          ))
+          alert('This task was changed elsewhere. Its latest version has been loaded, please try again.')
+          return
+        }
         alert('Failed to delete task')
       }
     }
@@ -260,7 +339,7 @@ export function useTaskActions() {
                       ...task,
                       subtasks: (task.subtasks || []).map(subtask => 
                         subtask.id === subtaskId 
-                          ? { ...subtask, completed: updatedSubtask.completed }
+                          ? { ...subtask, completed: updatedSubtask.completed, version: updatedSubtask.version }
                           : subtask
                       )
                     }
@@ -274,9 +353,9 @@ export function useTaskActions() {
     }
   }
 
-  const deleteSubtask = async (listId, taskId, subtaskId, setTaskLists) => {
+  const deleteSubtask = async (listId, taskId, subtaskId, version, setTaskLists) => {
     try {
-      await DeleteSubTask(subtaskId)
+      await DeleteSubTask(subtaskId, version)
       
       setTaskLists(lists => lists.map(list => 
         list.id === listId 
@@ -294,6 +373,31 @@ export function useTaskActions() {
           : list
       ))
     } catch (error) {
+      const current = conflictState(error)
+      if (current) {
+        // Show the latest state so the delete can be retried against it
+        setTaskLists(lists => lists.map(list => 
+          list.id === listId 
+            ? {
+                ...list,
+                tasks: list.tasks.map(task => 
+                  task.id === taskId 
+                    ? {
+                        ...task,
+                        subtasks: (task.subtasks || []).map(subtask => 
+                          subtask.id === subtaskId 
+                            ? { ...subtask, ...current, text: current.subtask_name }
+                            : subtask
+                        )
+                      }
+                    : task
+                )
+              }
+            : list
+        ))
+        alert('This subtask was changed elsewhere. Its latest version has been loaded, please try again.')
+        return
+      }
       alert('Failed to delete subtask')
     }
   }
@@ -1,43 +1,169 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 import {server} from '../models';
+import {history} from '../models';
+import {csvfile} from '../models';
+
+export function AddTagToTask(arg1:string,arg2:string):Promise<void>;
+
+export function CompleteTask(arg1:string):Promise<server.TaskCompletion>;
 
 export function CreateList(arg1:string):Promise<server.List>;
 
+export function CreateSmartList(arg1:string,arg2:string):Promise<server.List>;
+
 export function CreateSubTask(arg1:string,arg2:string):Promise<server.SubTask>;
 
+export function CreateTag(arg1:string,arg2:string):Promise<server.Tag>;
+
 export function CreateTask(arg1:string,arg2:string):Promise<server.Task>;
 
-export function DeleteList(arg1:string):Promise<void>;
+export function DeleteList(arg1:string,arg2:number):Promise<void>;
+
+export function DeleteSubTask(arg1:string,arg2:number):Promise<void>;
+
+export function DeleteTag(arg1:string):Promise<void>;
+
+export function DeleteTask(arg1:string,arg2:number):Promise<void>;
+
+export function DemoteTaskToSubTask(arg1:string,arg2:string):Promise<server.SubTask>;
 
-export function DeleteSubTask(arg1:string):Promise<void>;
+export function ExportCSV(arg1:string):Promise<string>;
 
-export function DeleteTask(arg1:string):Promise<void>;
+export function ExportICal(arg1:string):Promise<string>;
+
+export function ExportListMarkdown(arg1:string):Promise<string>;
+
+export function ExportTodoTxt():Promise<string>;
+
+export function ExportWorkspace():Promise<server.Workspace>;
 
 export function GetAllLists():Promise<Array<server.List>>;
 
 export function GetAllSubTasks():Promise<Array<server.SubTask>>;
 
+export function GetAllSubTasksPage(arg1:number,arg2:string):Promise<server.SubTaskPage>;
+
+export function GetAllTags():Promise<Array<server.Tag>>;
+
 export function GetAllTasks():Promise<Array<server.Task>>;
 
+export function GetAllTasksPage(arg1:number,arg2:string):Promise<server.TaskPage>;
+
+export function GetDueReminders():Promise<Array<server.Task>>;
+
+export function GetHistory():Promise<history.Snapshot>;
+
 export function GetList(arg1:string):Promise<server.List>;
 
+export function GetListActivity(arg1:string,arg2:string):Promise<Array<server.Activity>>;
+
+export function GetListTree(arg1:string):Promise<server.ListTree>;
+
+export function GetOverdueTasks():Promise<Array<server.Task>>;
+
+export function GetSmartListTasks(arg1:string):Promise<Array<server.Task>>;
+
+export function GetStarredTasks():Promise<Array<server.Task>>;
+
 export function GetSubTask(arg1:string):Promise<server.SubTask>;
 
 export function GetSubTasksByTaskID(arg1:string):Promise<Array<server.SubTask>>;
 
 export function GetTask(arg1:string):Promise<server.Task>;
 
+export function GetTaskActivity(arg1:string):Promise<Array<server.Activity>>;
+
+export function GetTaskTags(arg1:string):Promise<Array<server.Tag>>;
+
 export function GetTasksByListID(arg1:string):Promise<Array<server.Task>>;
 
+export function GetTasksByTag(arg1:string):Promise<Array<server.Task>>;
+
+export function GetTasksByTags(arg1:Array<string>,arg2:boolean):Promise<Array<server.Task>>;
+
+export function GetTasksDueBetween(arg1:string,arg2:string):Promise<Array<server.Task>>;
+
+export function GetTasksDueToday():Promise<Array<server.Task>>;
+
+export function GetTasksPage(arg1:string,arg2:number,arg3:string):Promise<server.TaskPage>;
+
+export function GetTrash():Promise<server.Trash>;
+
+export function GetWorkspaceTree():Promise<Array<server.ListTree>>;
+
+export function ImportCSV(arg1:Array<number>,arg2:csvfile.Format,arg3:string,arg4:boolean):Promise<csvfile.Report>;
+
+export function ImportICal(arg1:string,arg2:string):Promise<server.ImportReport>;
+
+export function ImportMarkdown(arg1:string,arg2:string):Promise<server.ImportReport>;
+
+export function ImportTodoTxt(arg1:string,arg2:string):Promise<server.ImportReport>;
+
+export function ImportWorkspace(arg1:server.Workspace,arg2:string,arg3:boolean):Promise<server.ImportReport>;
+
+export function MergeTags(arg1:string,arg2:string):Promise<server.Tag>;
+
+export function MoveSubTask(arg1:string,arg2:string,arg3:number):Promise<server.SubTask>;
+
+export function MoveTask(arg1:string,arg2:string,arg3:number):Promise<server.Task>;
+
+export function PatchList(arg1:string,arg2:server.ListPatch):Promise<server.List>;
+
+export function PatchSubTask(arg1:string,arg2:server.SubTaskPatch):Promise<server.SubTask>;
+
+export function PatchTask(arg1:string,arg2:server.TaskPatch):Promise<server.Task>;
+
+export function PromoteSubTaskToTask(arg1:string):Promise<server.Task>;
+
+export function QueryTasks(arg1:string):Promise<Array<server.Task>>;
+
+export function Redo():Promise<history.Entry>;
+
+export function RemoveTagFromTask(arg1:string,arg2:string):Promise<void>;
+
+export function RenameTag(arg1:string,arg2:string):Promise<server.Tag>;
+
 export function ReorderLists(arg1:Array<string>):Promise<void>;
 
+export function ReorderSubTasks(arg1:string,arg2:Array<string>):Promise<void>;
+
+export function ReorderTasks(arg1:string,arg2:Array<string>):Promise<void>;
+
+export function RestoreList(arg1:string):Promise<server.List>;
+
+export function RestoreSubTask(arg1:string):Promise<server.SubTask>;
+
+export function RestoreTask(arg1:string):Promise<server.Task>;
+
+export function Search(arg1:string):Promise<Array<server.SearchHit>>;
+
+export function SetListQuery(arg1:string,arg2:string):Promise<server.List>;
+
+export function SetTagColor(arg1:string,arg2:string):Promise<server.Tag>;
+
+export function SetTaskDueDate(arg1:string,arg2:string):Promise<server.Task>;
+
+export function SetTaskDueDateTime(arg1:string,arg2:string):Promise<server.Task>;
+
+export function SetTaskNotes(arg1:string,arg2:string):Promise<server.Task>;
+
+export function SetTaskPriority(arg1:string,arg2:string):Promise<server.Task>;
+
+export function SetTaskRecurrence(arg1:string,arg2:string):Promise<server.Task>;
+
+export function SetTaskReminder(arg1:string,arg2:string):Promise<server.Task>;
+
+export function StarTask(arg1:string,arg2:boolean):Promise<server.Task>;
+
 export function ToggleSubTaskCompletion(arg1:string):Promise<server.SubTask>;
 
 export function ToggleTaskCompletion(arg1:string):Promise<server.Task>;
 
-export function UpdateList(arg1:string,arg2:string):Promise<server.List>;
+export function Undo():Promise<history.Entry>;
+
+export function UpdateList(arg1:string,arg2:string,arg3:number):Promise<server.List>;
 
-export function UpdateSubTask(arg1:string,arg2:string,arg3:boolean):Promise<server.SubTask>;
+export function UpdateSubTask(arg1:string,arg2:string,arg3:boolean,arg4:number):Promise<server.SubTask>;
 
-export function UpdateTask(arg1:string,arg2:string,arg3:boolean):Promise<server.Task>;
+export function UpdateTask(arg1:string,arg2:string,arg3:boolean,arg4:number):Promise<server.Task>;
@@ -2,28 +2,72 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AddTagToTask(arg1, arg2) {
+  return window['go']['main']['App']['AddTagToTask'](arg1, arg2);
+}
+
+export function CompleteTask(arg1) {
+  return window['go']['main']['App']['CompleteTask'](arg1);
+}
+
 export function CreateList(arg1) {
   return window['go']['main']['App']['CreateList'](arg1);
 }
 
+export function CreateSmartList(arg1, arg2) {
+  return window['go']['main']['App']['CreateSmartList'](arg1, arg2);
+}
+
 export function CreateSubTask(arg1, arg2) {
   return window['go']['main']['App']['CreateSubTask'](arg1, arg2);
 }
 
+export function CreateTag(arg1, arg2) {
+  return window['go']['main']['App']['CreateTag'](arg1, arg2);
+}
+
 export function CreateTask(arg1, arg2) {
   return window['go']['main']['App']['CreateTask'](arg1, arg2);
 }
 
-export function DeleteList(arg1) {
-  return window['go']['main']['App']['DeleteList'](arg1);
+export function DeleteList(arg1, arg2) {
+  return window['go']['main']['App']['DeleteList'](arg1, arg2);
+}
+
+export function DeleteSubTask(arg1, arg2) {
+  return window['go']['main']['App']['DeleteSubTask'](arg1, arg2);
+}
+
+export function DeleteTag(arg1) {
+  return window['go']['main']['App']['DeleteTag'](arg1);
+}
+
+export function DeleteTask(arg1, arg2) {
+  return window['go']['main']['App']['DeleteTask'](arg1, arg2);
+}
+
+export function DemoteTaskToSubTask(arg1, arg2) {
+  return window['go']['main']['App']['DemoteTaskToSubTask'](arg1, arg2);
 }
 
-export function DeleteSubTask(arg1) {
-  return window['go']['main']['App']['DeleteSubTask'](arg1);
+export function ExportCSV(arg1) {
+  return window['go']['main']['App']['ExportCSV'](arg1);
 }
 
-export function DeleteTask(arg1) {
-  return window['go']['main']['App']['DeleteTask'](arg1);
+export function ExportICal(arg1) {
+  return window['go']['main']['App']['ExportICal'](arg1);
+}
+
+export function ExportListMarkdown(arg1) {
+  return window['go']['main']['App']['ExportListMarkdown'](arg1);
+}
+
+export function ExportTodoTxt() {
+  return window['go']['main']['App']['ExportTodoTxt']();
+}
+
+export function ExportWorkspace() {
+  return window['go']['main']['App']['ExportWorkspace']();
 }
 
 export function GetAllLists() {
@@ -34,14 +78,54 @@ export function GetAllSubTasks() {
   return window['go']['main']['App']['GetAllSubTasks']();
 }
 
+export function GetAllSubTasksPage(arg1, arg2) {
+  return window['go']['main']['App']['GetAllSubTasksPage'](arg1, arg2);
+}
+
+export function GetAllTags() {
+  return window['go']['main']['App']['GetAllTags']();
+}
+
 export function GetAllTasks() {
   return window['go']['main']['App']['GetAllTasks']();
 }
 
+export function GetAllTasksPage(arg1, arg2) {
+  return window['go']['main']['App']['GetAllTasksPage'](arg1, arg2);
+}
+
+export function GetDueReminders() {
+  return window['go']['main']['App']['GetDueReminders']();
+}
+
+export function GetHistory() {
+  return window['go']['main']['App']['GetHistory']();
+}
+
 export function GetList(arg1) {
   return window['go']['main']['App']['GetList'](arg1);
 }
 
+export function GetListActivity(arg1, arg2) {
+  return window['go']['main']['App']['GetListActivity'](arg1, arg2);
+}
+
+export function GetListTree(arg1) {
+  return window['go']['main']['App']['GetListTree'](arg1);
+}
+
+export function GetOverdueTasks() {
+  return window['go']['main']['App']['GetOverdueTasks']();
+}
+
+export function GetSmartListTasks(arg1) {
+  return window['go']['main']['App']['GetSmartListTasks'](arg1);
+}
+
+export function GetStarredTasks() {
+  return window['go']['main']['App']['GetStarredTasks']();
+}
+
 export function GetSubTask(arg1) {
   return window['go']['main']['App']['GetSubTask'](arg1);
 }
@@ -54,14 +138,174 @@ export function GetTask(arg1) {
   return window['go']['main']['App']['GetTask'](arg1);
 }
 
+export function GetTaskActivity(arg1) {
+  return window['go']['main']['App']['GetTaskActivity'](arg1);
+}
+
+export function GetTaskTags(arg1) {
+  return window['go']['main']['App']['GetTaskTags'](arg1);
+}
+
 export function GetTasksByListID(arg1) {
   return window['go']['main']['App']['GetTasksByListID'](arg1);
 }
 
+export function GetTasksByTag(arg1) {
+  return window['go']['main']['App']['GetTasksByTag'](arg1);
+}
+
+export function GetTasksByTags(arg1, arg2) {
+  return window['go']['main']['App']['GetTasksByTags'](arg1, arg2);
+}
+
+export function GetTasksDueBetween(arg1, arg2) {
+  return window['go']['main']['App']['GetTasksDueBetween'](arg1, arg2);
+}
+
+export function GetTasksDueToday() {
+  return window['go']['main']['App']['GetTasksDueToday']();
+}
+
+export function GetTasksPage(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetTasksPage'](arg1, arg2, arg3);
+}
+
+export function GetTrash() {
+  return window['go']['main']['App']['GetTrash']();
+}
+
+export function GetWorkspaceTree() {
+  return window['go']['main']['App']['GetWorkspaceTree']();
+}
+
+export function ImportCSV(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['ImportCSV'](arg1, arg2, arg3, arg4);
+}
+
+export function ImportICal(arg1, arg2) {
+  return window['go']['main']['App']['ImportICal'](arg1, arg2);
+}
+
+export function ImportMarkdown(arg1, arg2) {
+  return window['go']['main']['App']['ImportMarkdown'](arg1, arg2);
+}
+
+export function ImportTodoTxt(arg1, arg2) {
+  return window['go']['main']['App']['ImportTodoTxt'](arg1, arg2);
+}
+
+export function ImportWorkspace(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ImportWorkspace'](arg1, arg2, arg3);
+}
+
+export function MergeTags(arg1, arg2) {
+  return window['go']['main']['App']['MergeTags'](arg1, arg2);
+}
+
+export function MoveSubTask(arg1, arg2, arg3) {
+  return window['go']['main']['App']['MoveSubTask'](arg1, arg2, arg3);
+}
+
+export function MoveTask(arg1, arg2, arg3) {
+  return window['go']['main']['App']['MoveTask'](arg1, arg2, arg3);
+}
+
+export function PatchList(arg1, arg2) {
+  return window['go']['main']['App']['PatchList'](arg1, arg2);
+}
+
+export function PatchSubTask(arg1, arg2) {
+  return window['go']['main']['App']['PatchSubTask'](arg1, arg2);
+}
+
+export function PatchTask(arg1, arg2) {
+  return window['go']['main']['App']['PatchTask'](arg1, arg2);
+}
+
+export function PromoteSubTaskToTask(arg1) {
+  return window['go']['main']['App']['PromoteSubTaskToTask'](arg1);
+}
+
+export function QueryTasks(arg1) {
+  return window['go']['main']['App']['QueryTasks'](arg1);
+}
+
+export function Redo() {
+  return window['go']['main']['App']['Redo']();
+}
+
+export function RemoveTagFromTask(arg1, arg2) {
+  return window['go']['main']['App']['RemoveTagFromTask'](arg1, arg2);
+}
+
+export function RenameTag(arg1, arg2) {
+  return window['go']['main']['App']['RenameTag'](arg1, arg2);
+}
+
 export function ReorderLists(arg1) {
   return window['go']['main']['App']['ReorderLists'](arg1);
 }
 
+export function ReorderSubTasks(arg1, arg2) {
+  return window['go']['main']['App']['ReorderSubTasks'](arg1, arg2);
+}
+
+export function ReorderTasks(arg1, arg2) {
+  return window['go']['main']['App']['ReorderTasks'](arg1, arg2);
+}
+
+export function RestoreList(arg1) {
+  return window['go']['main']['App']['RestoreList'](arg1);
+}
+
+export function RestoreSubTask(arg1) {
+  return window['go']['main']['App']['RestoreSubTask'](arg1);
+}
+
+export function RestoreTask(arg1) {
+  return window['go']['main']['App']['RestoreTask'](arg1);
+}
+
+export function Search(arg1) {
+  return window['go']['main']['App']['Search'](arg1);
+}
+
+export function SetListQuery(arg1, arg2) {
+  return window['go']['main']['App']['SetListQuery'](arg1, arg2);
+}
+
+export function SetTagColor(arg1, arg2) {
+  return window['go']['main']['App']['SetTagColor'](arg1, arg2);
+}
+
+export function SetTaskDueDate(arg1, arg2) {
+  return window['go']['main']['App']['SetTaskDueDate'](arg1, arg2);
+}
+
+export function SetTaskDueDateTime(arg1, arg2) {
+  return window['go']['main']['App']['SetTaskDueDateTime'](arg1, arg2);
+}
+
+export function SetTaskNotes(arg1, arg2) {
+  return window['go']['main']['App']['SetTaskNotes'](arg1, arg2);
+}
+
+export function SetTaskPriority(arg1, arg2) {
+  return window['go']['main']['App']['SetTaskPriority'](arg1, arg2);
+}
+
+export function SetTaskRecurrence(arg1, arg2) {
+  return window['go']['main']['App']['SetTaskRecurrence'](arg1, arg2);
+}
+
+export function SetTaskReminder(arg1, arg2) {
+  return window['go']['main']['App']['SetTaskReminder'](arg1, arg2);
+}
+
+export function StarTask(arg1, arg2) {
+  return window['go']['main']['App']['StarTask'](arg1, arg2);
+}
+
 export function ToggleSubTaskCompletion(arg1) {
   return window['go']['main']['App']['ToggleSubTaskCompletion'](arg1);
 }
@@ -70,14 +314,18 @@ export function ToggleTaskCompletion(arg1) {
   return window['go']['main']['App']['ToggleTaskCompletion'](arg1);
 }
 
-export function UpdateList(arg1, arg2) {
-  return window['go']['main']['App']['UpdateList'](arg1, arg2);
+export function Undo() {
+  return window['go']['main']['App']['Undo']();
+}
+
+export function UpdateList(arg1, arg2, arg3) {
+  return window['go']['main']['App']['UpdateList'](arg1, arg2, arg3);
 }
 
-export function UpdateSubTask(arg1, arg2, arg3) {
-  return window['go']['main']['App']['UpdateSubTask'](arg1, arg2, arg3);
+export function UpdateSubTask(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['UpdateSubTask'](arg1, arg2, arg3, arg4);
 }
 
-export function UpdateTask(arg1, arg2, arg3) {
-  return window['go']['main']['App']['UpdateTask'](arg1, arg2, arg3);
+export function UpdateTask(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['UpdateTask'](arg1, arg2, arg3, arg4);
 }
@@ -1,13 +1,294 @@
+export namespace csvfile {
+	
+	export class Format {
+	    delimiter: string;
+	    encoding: string;
+	    header: string;
+	    columns: Record<string, string>;
+	
+	    static createFrom(source: any = {}) {
+	        return new Format(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.delimiter = source["delimiter"];
+	        this.encoding = source["encoding"];
+	        this.header = source["header"];
+	        this.columns = source["columns"];
+	    }
+	}
+	export class RowError {
+	    line: number;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RowError(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.line = source["line"];
+	        this.message = source["message"];
+	    }
+	}
+	export class Report {
+	    rows: number;
+	    import?: server.ImportReport;
+	    errors: RowError[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Report(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.rows = source["rows"];
+	        this.import = this.convertValues(source["import"], server.ImportReport);
+	        this.errors = this.convertValues(source["errors"], RowError);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace history {
+	
+	export class Entry {
+	    id: number;
+	    label: string;
+	    // Go type: time
+	    at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Entry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.label = source["label"];
+	        this.at = this.convertValues(source["at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Snapshot {
+	    undo: Entry[];
+	    redo: Entry[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Snapshot(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.undo = this.convertValues(source["undo"], Entry);
+	        this.redo = this.convertValues(source["redo"], Entry);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
 export namespace server {
 	
+	export class Activity {
+	    id: number;
+	    entity: string;
+	    entity_id: string;
+	    list_id: string;
+	    task_id?: string;
+	    action: string;
+	    name: string;
+	    actor: string;
+	    before: Record<string, any>;
+	    after: Record<string, any>;
+	    // Go type: time
+	    at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Activity(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.entity = source["entity"];
+	        this.entity_id = source["entity_id"];
+	        this.list_id = source["list_id"];
+	        this.task_id = source["task_id"];
+	        this.action = source["action"];
+	        this.name = source["name"];
+	        this.actor = source["actor"];
+	        this.before = source["before"];
+	        this.after = source["after"];
+	        this.at = this.convertValues(source["at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ImportCounts {
+	    lists: number;
+	    tasks: number;
+	    subtasks: number;
+	    tags: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportCounts(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.lists = source["lists"];
+	        this.tasks = source["tasks"];
+	        this.subtasks = source["subtasks"];
+	        this.tags = source["tags"];
+	    }
+	}
+	export class ImportRow {
+	    entity: string;
+	    id: string;
+	    source_id?: string;
+	    name: string;
+	    action: string;
+	    changed?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportRow(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.entity = source["entity"];
+	        this.id = source["id"];
+	        this.source_id = source["source_id"];
+	        this.name = source["name"];
+	        this.action = source["action"];
+	        this.changed = source["changed"];
+	    }
+	}
+	export class ImportReport {
+	    mode: string;
+	    dry_run: boolean;
+	    created: ImportCounts;
+	    updated: ImportCounts;
+	    deleted: ImportCounts;
+	    remapped: Record<string, string>;
+	    rows: ImportRow[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.mode = source["mode"];
+	        this.dry_run = source["dry_run"];
+	        this.created = this.convertValues(source["created"], ImportCounts);
+	        this.updated = this.convertValues(source["updated"], ImportCounts);
+	        this.deleted = this.convertValues(source["deleted"], ImportCounts);
+	        this.remapped = source["remapped"];
+	        this.rows = this.convertValues(source["rows"], ImportRow);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
 	export class List {
 	    id: string;
 	    title: string;
+	    query?: string;
 	    position: number;
+	    version: number;
 	    // Go type: time
 	    created_at: any;
 	    // Go type: time
 	    updated_at: any;
+	    // Go type: time
+	    deleted_at?: any;
 	
 	    static createFrom(source: any = {}) {
 	        return new List(source);
@@ -17,9 +298,12 @@ export namespace server {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.id = source["id"];
 	        this.title = source["title"];
+	        this.query = source["query"];
 	        this.position = source["position"];
+	        this.version = source["version"];
 	        this.created_at = this.convertValues(source["created_at"], null);
 	        this.updated_at = this.convertValues(source["updated_at"], null);
+	        this.deleted_at = this.convertValues(source["deleted_at"], null);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -40,15 +324,35 @@ export namespace server {
 		    return a;
 		}
 	}
+	export class ListPatch {
+	    title?: string;
+	    query?: string;
+	    version?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ListPatch(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.title = source["title"];
+	        this.query = source["query"];
+	        this.version = source["version"];
+	    }
+	}
 	export class SubTask {
 	    id: string;
 	    task_id: string;
 	    subtask_name: string;
 	    completed: boolean;
+	    position: number;
+	    version: number;
 	    // Go type: time
 	    created_at: any;
 	    // Go type: time
 	    updated_at: any;
+	    // Go type: time
+	    deleted_at?: any;
 	
 	    static createFrom(source: any = {}) {
 	        return new SubTask(source);
@@ -60,8 +364,11 @@ export namespace server {
 	        this.task_id = source["task_id"];
 	        this.subtask_name = source["subtask_name"];
 	        this.completed = source["completed"];
+	        this.position = source["position"];
+	        this.version = source["version"];
 	        this.created_at = this.convertValues(source["created_at"], null);
 	        this.updated_at = this.convertValues(source["updated_at"], null);
+	        this.deleted_at = this.convertValues(source["deleted_at"], null);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -86,11 +393,24 @@ export namespace server {
 	    id: string;
 	    list_id: string;
 	    task_name: string;
+	    notes: string;
 	    completed: boolean;
+	    priority: string;
+	    starred: boolean;
+	    position: number;
+	    due_date?: string;
+	    // Go type: time
+	    due_at?: any;
+	    // Go type: time
+	    remind_at?: any;
+	    recurrence?: string;
+	    version: number;
 	    // Go type: time
 	    created_at: any;
 	    // Go type: time
 	    updated_at: any;
+	    // Go type: time
+	    deleted_at?: any;
 	
 	    static createFrom(source: any = {}) {
 	        return new Task(source);
@@ -101,9 +421,55 @@ export namespace server {
 	        this.id = source["id"];
 	        this.list_id = source["list_id"];
 	        this.task_name = source["task_name"];
+	        this.notes = source["notes"];
 	        this.completed = source["completed"];
+	        this.priority = source["priority"];
+	        this.starred = source["starred"];
+	        this.position = source["position"];
+	        this.due_date = source["due_date"];
+	        this.due_at = this.convertValues(source["due_at"], null);
+	        this.remind_at = this.convertValues(source["remind_at"], null);
+	        this.recurrence = source["recurrence"];
+	        this.version = source["version"];
 	        this.created_at = this.convertValues(source["created_at"], null);
 	        this.updated_at = this.convertValues(source["updated_at"], null);
+	        this.deleted_at = this.convertValues(source["deleted_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TaskTree {
+	    task: Task;
+	    subtasks: SubTask[];
+	    completed_subtasks: number;
+	    total_subtasks: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TaskTree(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.task = this.convertValues(source["task"], Task);
+	        this.subtasks = this.convertValues(source["subtasks"], SubTask);
+	        this.completed_subtasks = source["completed_subtasks"];
+	        this.total_subtasks = source["total_subtasks"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -124,6 +490,485 @@ export namespace server {
 		    return a;
 		}
 	}
+	export class ListTree {
+	    list: List;
+	    smart: boolean;
+	    tasks: TaskTree[];
+	    completed_tasks: number;
+	    total_tasks: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ListTree(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.list = this.convertValues(source["list"], List);
+	        this.smart = source["smart"];
+	        this.tasks = this.convertValues(source["tasks"], TaskTree);
+	        this.completed_tasks = source["completed_tasks"];
+	        this.total_tasks = source["total_tasks"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SearchHit {
+	    entity: string;
+	    id: string;
+	    name: string;
+	    list_id: string;
+	    list_title: string;
+	    task_id?: string;
+	    task_name?: string;
+	    rank: number;
+	    snippet: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SearchHit(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.entity = source["entity"];
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.list_id = source["list_id"];
+	        this.list_title = source["list_title"];
+	        this.task_id = source["task_id"];
+	        this.task_name = source["task_name"];
+	        this.rank = source["rank"];
+	        this.snippet = source["snippet"];
+	    }
+	}
+	
+	export class SubTaskPage {
+	    subtasks: SubTask[];
+	    next_cursor: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SubTaskPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.subtasks = this.convertValues(source["subtasks"], SubTask);
+	        this.next_cursor = source["next_cursor"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SubTaskPatch {
+	    subtask_name?: string;
+	    completed?: boolean;
+	    version?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SubTaskPatch(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.subtask_name = source["subtask_name"];
+	        this.completed = source["completed"];
+	        this.version = source["version"];
+	    }
+	}
+	export class Tag {
+	    id: string;
+	    name: string;
+	    color: string;
+	    // Go type: time
+	    created_at: any;
+	    // Go type: time
+	    updated_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Tag(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.color = source["color"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.updated_at = this.convertValues(source["updated_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class TaskCompletion {
+	    task: Task;
+	    next?: Task;
+	
+	    static createFrom(source: any = {}) {
+	        return new TaskCompletion(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.task = this.convertValues(source["task"], Task);
+	        this.next = this.convertValues(source["next"], Task);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TaskPage {
+	    tasks: Task[];
+	    next_cursor: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TaskPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tasks = this.convertValues(source["tasks"], Task);
+	        this.next_cursor = source["next_cursor"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TaskPatch {
+	    task_name?: string;
+	    notes?: string;
+	    completed?: boolean;
+	    priority?: string;
+	    starred?: boolean;
+	    version?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TaskPatch(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.task_name = source["task_name"];
+	        this.notes = source["notes"];
+	        this.completed = source["completed"];
+	        this.priority = source["priority"];
+	        this.starred = source["starred"];
+	        this.version = source["version"];
+	    }
+	}
+	
+	export class Trash {
+	    lists: List[];
+	    tasks: Task[];
+	    subtasks: SubTask[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Trash(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.lists = this.convertValues(source["lists"], List);
+	        this.tasks = this.convertValues(source["tasks"], Task);
+	        this.subtasks = this.convertValues(source["subtasks"], SubTask);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class WorkspaceSubTask {
+	    id: string;
+	    subtask_name: string;
+	    completed: boolean;
+	    position: number;
+	    // Go type: time
+	    created_at: any;
+	    // Go type: time
+	    updated_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new WorkspaceSubTask(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.subtask_name = source["subtask_name"];
+	        this.completed = source["completed"];
+	        this.position = source["position"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.updated_at = this.convertValues(source["updated_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class WorkspaceTask {
+	    id: string;
+	    task_name: string;
+	    notes: string;
+	    completed: boolean;
+	    priority: string;
+	    starred: boolean;
+	    position: number;
+	    due_date?: string;
+	    // Go type: time
+	    due_at?: any;
+	    // Go type: time
+	    remind_at?: any;
+	    recurrence?: string;
+	    tags: string[];
+	    // Go type: time
+	    created_at: any;
+	    // Go type: time
+	    updated_at: any;
+	    subtasks: WorkspaceSubTask[];
+	
+	    static createFrom(source: any = {}) {
+	        return new WorkspaceTask(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.task_name = source["task_name"];
+	        this.notes = source["notes"];
+	        this.completed = source["completed"];
+	        this.priority = source["priority"];
+	        this.starred = source["starred"];
+	        this.position = source["position"];
+	        this.due_date = source["due_date"];
+	        this.due_at = this.convertValues(source["due_at"], null);
+	        this.remind_at = this.convertValues(source["remind_at"], null);
+	        this.recurrence = source["recurrence"];
+	        this.tags = source["tags"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.updated_at = this.convertValues(source["updated_at"], null);
+	        this.subtasks = this.convertValues(source["subtasks"], WorkspaceSubTask);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class WorkspaceList {
+	    id: string;
+	    title: string;
+	    query?: string;
+	    position: number;
+	    // Go type: time
+	    created_at: any;
+	    // Go type: time
+	    updated_at: any;
+	    tasks: WorkspaceTask[];
+	
+	    static createFrom(source: any = {}) {
+	        return new WorkspaceList(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.title = source["title"];
+	        this.query = source["query"];
+	        this.position = source["position"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.updated_at = this.convertValues(source["updated_at"], null);
+	        this.tasks = this.convertValues(source["tasks"], WorkspaceTask);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class WorkspaceTag {
+	    name: string;
+	    color: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new WorkspaceTag(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.color = source["color"];
+	    }
+	}
+	export class Workspace {
+	    version: number;
+	    // Go type: time
+	    exported_at: any;
+	    tags: WorkspaceTag[];
+	    lists: WorkspaceList[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Workspace(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.version = source["version"];
+	        this.exported_at = this.convertValues(source["exported_at"], null);
+	        this.tags = this.convertValues(source["tags"], WorkspaceTag);
+	        this.lists = this.convertValues(source["lists"], WorkspaceList);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	
 
 }
 
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+func TestICal(t *testing.T) {
+	a := newTestApp(t)
+	home, _ := a.CreateList("Home")
+	work, _ := a.CreateList("Work")
+	laundry, _ := a.CreateTask(home.ID, "Laundry")
+	a.CreateSubTask(laundry.ID, "Fold")
+	a.CreateTask(work.ID, "Report")
+
+	text, err := a.ExportICal("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(text, "BEGIN:VCALENDAR"); n != 1 || strings.Count(text, "BEGIN:VTODO") != 3 {
+		t.Errorf("ExportICal() =\n%s\nwant one calendar with three VTODOs", text)
+	}
+	single, err := a.ExportICal(home.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(single, "\r\nX-WR-CALNAME:Home\r\nBEGIN:VTODO") || strings.Contains(single, "Report") {
+		t.Errorf("ExportICal(Home) =\n%s\nwant a calendar named Home with its tasks only", single)
+	}
+
+	// Importing the export back finds nothing to add
+	report, err := a.ImportICal(text, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Created != (server.ImportCounts{}) || report.Updated != (server.ImportCounts{}) {
+		t.Errorf("report = %+v, want nothing created or updated", report)
+	}
+
+	other := "BEGIN:VCALENDAR\r\nX-WR-CALNAME:work\r\nBEGIN:VTODO\r\nUID:abc@example.com\r\nSUMMARY:Slides\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"
+	report, err = a.ImportICal(other, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Created != (server.ImportCounts{Tasks: 1}) {
+		t.Errorf("report = %+v, want Slides added to Work", report)
+	}
+	if tasks, _ := a.GetTasksByListID(work.ID); len(tasks) != 2 {
+		t.Errorf("GetTasksByListID(Work) = %+v, want Report and Slides", tasks)
+	}
+	if _, err := a.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if tasks, _ := a.GetTasksByListID(work.ID); len(tasks) != 1 {
+		t.Errorf("GetTasksByListID(Work) after undo = %+v, want only Report", tasks)
+	}
+
+	if _, err := a.ExportICal("work"); !errors.Is(err, server.ErrInvalidInput) {
+		t.Errorf("ExportICal() with a bad id: err = %v, want ErrInvalidInput", err)
+	}
+	if _, err := a.ExportICal("7f0c7c1e-6a51-4c8e-9b1e-2f8f5a1d9c00"); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("ExportICal() of a missing list: err = %v, want ErrNotFound", err)
+	}
+	if _, err := a.ImportICal("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n", ""); !errors.Is(err, server.ErrInvalidInput) {
+		t.Errorf("ImportICal() without VTODOs: err = %v, want ErrInvalidInput", err)
+	}
+}
@@ -4,11 +4,19 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"os/user"
+	"path/filepath"
 	"strconv"
 
 	"github.com/joho/godotenv"
 )
 
+// Supported values for DB_DRIVER
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
 // Config holds all configuration for the application
 type Config struct {
 	Database DatabaseConfig `json:"database"`
@@ -17,6 +25,8 @@ type Config struct {
 
 // DatabaseConfig holds database-specific configuration
 type DatabaseConfig struct {
+	Driver   string `json:"driver"`
+	Path     string `json:"path"`
 	Host     string `json:"host"`
 	Port     int    `json:"port"`
 	User     string `json:"user"`
@@ -28,6 +38,12 @@ type DatabaseConfig struct {
 // AppConfig holds application-specific configuration
 type AppConfig struct {
 	LogLevel string `json:"log_level"`
+	// TrashRetentionDays is how long deleted items stay in the trash; 0 keeps them forever
+	TrashRetentionDays int `json:"trash_retention_days"`
+	// HistoryLimit is how many changes can be undone; 0 turns undo off
+	HistoryLimit int `json:"history_limit"`
+	// Actor is the name logged as making each change in the activity log
+	Actor string `json:"actor"`
 }
 
 // LoadConfig loads configuration from environment variables and .env file
@@ -40,6 +56,8 @@ func LoadConfig() (*Config, error) {
 
 	config := &Config{
 		Database: DatabaseConfig{
+			Driver:   getEnv("DB_DRIVER", DriverPostgres),
+			Path:     getEnv("DB_PATH", defaultDBPath()),
 			Host:     getEnv("DB_HOST", "localhost"),
 			Port:     getEnvAsInt("DB_PORT", 5432),
 			User:     getEnv("DB_USER", "postgres"),
@@ -48,10 +66,29 @@ func LoadConfig() (*Config, error) {
 			SSLMode:  getEnv("DB_SSLMODE", "disable"),
 		},
 		App: AppConfig{
-			LogLevel: getEnv("LOG_LEVEL", "info"),
+			LogLevel:           getEnv("LOG_LEVEL", "info"),
+			TrashRetentionDays: getEnvAsInt("TRASH_RETENTION_DAYS", 30),
+			HistoryLimit:       getEnvAsInt("HISTORY_LIMIT", 100),
+			Actor:              getEnv("ACTOR", defaultActor()),
 		},
 	}
 
+	switch config.Database.Driver {
+	case DriverPostgres, DriverSQLite:
+	default:
+		return nil, fmt.Errorf("unsupported DB_DRIVER %q: expected %q or %q", config.Database.Driver, DriverPostgres, DriverSQLite)
+	}
+
+	if config.App.TrashRetentionDays < 0 {
+		return nil, fmt.Errorf("invalid TRASH_RETENTION_DAYS %d: must not be negative", config.App.TrashRetentionDays)
+	}
+	if config.App.HistoryLimit < 0 {
+		return nil, fmt.Errorf("invalid HISTORY_LIMIT %d: must not be negative", config.App.HistoryLimit)
+	}
+	if len(config.App.Actor) > 255 {
+		return nil, fmt.Errorf("invalid ACTOR %q: must be at most 255 bytes", config.App.Actor)
+	}
+
 	return config, nil
 }
 
@@ -67,6 +104,24 @@ func (c *Config) GetConnectionString() string {
 	)
 }
 
+// defaultDBPath returns the SQLite file location inside the user's config directory
+func defaultDBPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "todo.db"
+	}
+	return filepath.Join(dir, "to-do", "todo.db")
+}
+
+// defaultActor returns the name of the user running the app
+func defaultActor() string {
+	current, err := user.Current()
+	if err != nil {
+		return ""
+	}
+	return current.Username
+}
+
 // Helper functions for environment variable handling
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
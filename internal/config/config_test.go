@@ -0,0 +1,83 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigDriver(t *testing.T) {
+	tests := []struct {
+		name    string
+		driver  string
+		want    string
+		wantErr bool
+	}{
+		{"default", "", DriverPostgres, false},
+		{"postgres", "postgres", DriverPostgres, false},
+		{"sqlite", "sqlite", DriverSQLite, false},
+		{"unknown", "mysql", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DB_DRIVER", tt.driver)
+			cfg, err := LoadConfig()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("LoadConfig() accepted DB_DRIVER %q", tt.driver)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Database.Driver != tt.want {
+				t.Errorf("Driver = %q, want %q", cfg.Database.Driver, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigPath(t *testing.T) {
+	t.Setenv("DB_PATH", "")
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Database.Path != defaultDBPath() {
+		t.Errorf("Path = %q, want %q", cfg.Database.Path, defaultDBPath())
+	}
+	if filepath.Base(cfg.Database.Path) != "todo.db" {
+		t.Errorf("Path = %q, want a todo.db file", cfg.Database.Path)
+	}
+
+	t.Setenv("DB_PATH", "/tmp/other.db")
+	cfg, err = LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Database.Path != "/tmp/other.db" {
+		t.Errorf("Path = %q, want /tmp/other.db", cfg.Database.Path)
+	}
+}
+
+func TestLoadConfigActor(t *testing.T) {
+	t.Setenv("ACTOR", "")
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.App.Actor != defaultActor() {
+		t.Errorf("Actor = %q, want the OS user %q", cfg.App.Actor, defaultActor())
+	}
+
+	t.Setenv("ACTOR", "alice")
+	if cfg, err = LoadConfig(); err != nil || cfg.App.Actor != "alice" {
+		t.Errorf("LoadConfig() = %+v, %v; want actor alice", cfg, err)
+	}
+
+	t.Setenv("ACTOR", strings.Repeat("a", 256))
+	if _, err := LoadConfig(); err == nil {
+		t.Error("LoadConfig() accepted a 256-byte ACTOR")
+	}
+}
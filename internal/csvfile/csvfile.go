@@ -0,0 +1,645 @@
+// Package csvfile converts tasks to and from CSV files for spreadsheets.
+//
+// Render writes a row per task and per subtask under the header
+//
+//	list,task,subtask,completed,created,updated
+//
+// where subtask rows repeat the name of their task. Parse reads such files,
+// and others whose columns are mapped to those fields or to notes, priority,
+// due and tags, detecting the header row, the delimiter and the encoding
+// unless they are given. Rows that cannot be imported are reported with the
+// reason instead of failing the whole file.
+package csvfile
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	server "github.com/HolySxn/To-Do/internal"
+	"github.com/HolySxn/To-Do/internal/validate"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// Fields that columns are mapped to
+const (
+	FieldList      = "list"
+	FieldTask      = "task"
+	FieldSubTask   = "subtask"
+	FieldCompleted = "completed"
+	FieldCreated   = "created"
+	FieldUpdated   = "updated"
+	FieldNotes     = "notes"
+	FieldPriority  = "priority"
+	FieldDue       = "due"
+	FieldTags      = "tags"
+)
+
+// Header is the header row written by Render. Files without a header and
+// without a column mapping are read in this order.
+var Header = []string{FieldList, FieldTask, FieldSubTask, FieldCompleted, FieldCreated, FieldUpdated}
+
+var fields = []string{FieldList, FieldTask, FieldSubTask, FieldCompleted, FieldCreated, FieldUpdated, FieldNotes, FieldPriority, FieldDue, FieldTags}
+
+// aliases maps header cells, lower-cased without spaces, dashes and
+// underscores, to fields
+var aliases = map[string]string{
+	"listtitle":   FieldList,
+	"taskname":    FieldTask,
+	"subtaskname": FieldSubTask,
+	"done":        FieldCompleted,
+	"createdat":   FieldCreated,
+	"updatedat":   FieldUpdated,
+	"duedate":     FieldDue,
+}
+
+// TimeLayout is the layout of the times written by Render, which spreadsheets
+// read as date-times
+const TimeLayout = "2006-01-02 15:04:05"
+
+// timeLayouts are the layouts of created and updated times that Parse reads
+var timeLayouts = []string{time.RFC3339, TimeLayout, "2006-01-02T15:04:05", "2006-01-02 15:04", server.DateLayout}
+
+// bom starts the files written by Render, so that spreadsheets read them as
+// UTF-8
+const bom = "\uFEFF"
+
+// Render writes the tasks and subtasks of a workspace document with times in
+// loc. A list without tasks gets a row of its own, and smart lists are left
+// out.
+func Render(doc *server.Workspace, loc *time.Location) string {
+	var b bytes.Buffer
+	b.WriteString(bom)
+	w := csv.NewWriter(&b)
+	w.UseCRLF = true
+	w.Write(Header)
+	format := func(t time.Time) string { return t.In(loc).Format(TimeLayout) }
+	for _, list := range doc.Lists {
+		if list.Query != nil {
+			continue
+		}
+		if len(list.Tasks) == 0 {
+			w.Write([]string{list.Title, "", "", "", format(list.CreatedAt), format(list.UpdatedAt)})
+		}
+		for _, task := range list.Tasks {
+			w.Write([]string{list.Title, task.TaskName, "", strconv.FormatBool(task.Completed), format(task.CreatedAt), format(task.UpdatedAt)})
+			for _, subTask := range task.SubTasks {
+				w.Write([]string{list.Title, task.TaskName, subTask.SubTaskName, strconv.FormatBool(subTask.Completed), format(subTask.CreatedAt), format(subTask.UpdatedAt)})
+			}
+		}
+	}
+	// Writing to a bytes.Buffer does not fail
+	w.Flush()
+	return b.String()
+}
+
+// Header modes
+const (
+	HeaderDetect  = ""
+	HeaderPresent = "yes"
+	HeaderAbsent  = "no"
+)
+
+// Format describes how a file is laid out; the zero value detects everything
+type Format struct {
+	// Delimiter is the single character separating fields, such as "," or
+	// "\t"; empty detects it from the first line
+	Delimiter string `json:"delimiter"`
+	// Encoding is "utf-8", "utf-16le", "utf-16be", "windows-1252" or
+	// "iso-8859-1"; empty detects it from a byte order mark, falling back to
+	// windows-1252 for files that are not valid UTF-8
+	Encoding string `json:"encoding"`
+	// Header is "yes" or "no" for files with or without a header row; empty
+	// takes the first row as a header when most of its cells name a field or
+	// a column mapped by name, when some do and no other cell holds a
+	// completion, priority, date or time, or when it cannot be read as a row
+	// of data
+	Header string `json:"header"`
+	// Columns maps fields to the header cell or 1-based number of their
+	// column; fields missing from it are found by header cell
+	Columns map[string]string `json:"columns"`
+}
+
+// Options tell Parse how to read a file and where its tasks go
+type Options struct {
+	Format
+	// ListID is the existing list that rows without a list are appended to;
+	// without one such rows are rejected
+	ListID string
+	// Lists are the existing lists; the rows of a list with the title of one
+	// of them, regardless of case, are appended to it instead of to a new list
+	Lists []server.List
+	// Location is the time zone of times without an offset and of due dates
+	Location *time.Location
+}
+
+// RowError tells why a row was not imported
+type RowError struct {
+	// Line is the line the row starts on, counting from 1
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// Report tells what importing a file did or, for a dry run, would do
+type Report struct {
+	// Rows counts the rows read, without the header
+	Rows   int                  `json:"rows"`
+	Import *server.ImportReport `json:"import"`
+	Errors []RowError           `json:"errors"`
+}
+
+// Parse reads the rows of a file into a workspace document to import by
+// merging, and a report of the rows that were skipped. A row with a subtask
+// belongs to the latest task of the same list and name, which is created when
+// there is none. The error, which matches server.ErrInvalidInput, is only set
+// when the file cannot be read at all, such as for an unknown encoding or a
+// mapping to a column that does not exist.
+func Parse(data []byte, opts Options) (*server.Workspace, *Report, error) {
+	if opts.Location == nil {
+		opts.Location = time.Local
+	}
+	text, err := decode(data, opts.Encoding)
+	if err != nil {
+		return nil, nil, err
+	}
+	delimiter, err := delimiter(text, opts.Delimiter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r := csv.NewReader(strings.NewReader(text))
+	r.Comma = delimiter
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	r.TrimLeadingSpace = true
+
+	p := &parser{
+		opts:   opts,
+		doc:    &server.Workspace{Version: server.WorkspaceVersion},
+		report: &Report{Errors: []RowError{}},
+		lists:  make(map[string]string),
+		index:  make(map[string]int),
+		tasks:  make(map[[2]string]int),
+	}
+	for _, list := range opts.Lists {
+		if list.Query == nil {
+			p.lists[strings.ToLower(list.Title)] = list.ID
+		}
+	}
+
+	first := true
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			p.report.Rows++
+			p.fail(parseErr.StartLine, "%s", parseErr.Err)
+			continue
+		}
+		if err != nil {
+			return nil, nil, server.InvalidInput("failed to read CSV: %s", err)
+		}
+		if first {
+			first = false
+			if err := p.columns(record); err != nil {
+				return nil, nil, err
+			}
+			if p.header {
+				continue
+			}
+		}
+		p.report.Rows++
+		line, _ := r.FieldPos(0)
+		p.row(line, record)
+	}
+	if p.columnsOf == nil {
+		return nil, nil, server.InvalidInput("the file has no rows")
+	}
+	return p.doc, p.report, nil
+}
+
+type parser struct {
+	opts   Options
+	doc    *server.Workspace
+	report *Report
+	// columnsOf maps fields to 0-based column numbers
+	columnsOf map[string]int
+	header    bool
+	// lists maps lower-case titles of existing lists to ids
+	lists map[string]string
+	// index maps lower-case list titles to positions in doc.Lists
+	index map[string]int
+	// tasks maps lower-case list titles and task names to the position of
+	// the latest task in its list
+	tasks map[[2]string]int
+}
+
+func (p *parser) fail(line int, format string, args ...any) {
+	p.report.Errors = append(p.report.Errors, RowError{Line: line, Message: server.InvalidInput(format, args...).Error()})
+}
+
+// columns works out the columns of the fields from the first record, and
+// whether it is a header
+func (p *parser) columns(first []string) error {
+	byName := make(map[string]int)
+	for i, cell := range first {
+		byName[normalize(cell)] = i
+	}
+	named := make(map[string]int)
+	for _, field := range fields {
+		if i, ok := byName[field]; ok {
+			named[field] = i
+		}
+	}
+	for alias, field := range aliases {
+		if i, ok := byName[alias]; ok {
+			if _, ok := named[field]; !ok {
+				named[field] = i
+			}
+		}
+	}
+
+	// Cells naming a field, or a column mapped by name, are known names
+	mappedNames := make(map[string]bool)
+	for field, column := range p.opts.Columns {
+		if !slices.Contains(fields, field) {
+			return server.InvalidInput("cannot map a column to %q; the fields are %s", field, strings.Join(fields, ", "))
+		}
+		if _, err := strconv.Atoi(strings.TrimSpace(column)); err != nil {
+			mappedNames[normalize(column)] = true
+		}
+	}
+	known, cells, typed := 0, 0, false
+	for _, cell := range first {
+		name := normalize(cell)
+		if name == "" {
+			continue
+		}
+		cells++
+		if _, ok := aliases[name]; ok || slices.Contains(fields, name) || mappedNames[name] {
+			known++
+		} else if p.typed(strings.TrimSpace(cell)) {
+			typed = true
+		}
+	}
+	switch p.opts.Header {
+	case HeaderPresent:
+		p.header = true
+	case HeaderAbsent:
+	case HeaderDetect:
+		// A row of data may hold a word such as "notes" or "done"; it is only
+		// a header when most of its cells are names, when some are and no
+		// other cell holds a value such as a date or a priority, or when it
+		// cannot be data. Spreadsheet headers such as "Title,Description,Due
+		// Date" name only some fields but hold no values.
+		p.header = known*2 > cells || known > 0 && !typed || !p.parsesAsData(first)
+	default:
+		return server.InvalidInput("header must be %q, %q or empty to detect it, got %q", HeaderPresent, HeaderAbsent, p.opts.Header)
+	}
+
+	p.columnsOf = make(map[string]int)
+	switch {
+	case p.header:
+		p.columnsOf = named
+	case len(p.opts.Columns) == 0:
+		p.columnsOf = defaultColumns()
+	}
+	for field, column := range p.opts.Columns {
+		if n, err := strconv.Atoi(strings.TrimSpace(column)); err == nil {
+			if n < 1 {
+				return server.InvalidInput("column of %s must be 1 or more, got %d", field, n)
+			}
+			p.columnsOf[field] = n - 1
+			continue
+		}
+		i, ok := byName[normalize(column)]
+		if !p.header || !ok {
+			return server.InvalidInput("the file has no column %q for %s", column, field)
+		}
+		p.columnsOf[field] = i
+	}
+	if _, ok := p.columnsOf[FieldTask]; !ok {
+		return server.InvalidInput("no column holds the task; map one to %q", FieldTask)
+	}
+	return nil
+}
+
+// defaultColumns maps the fields of Header to their columns
+func defaultColumns() map[string]int {
+	columns := make(map[string]int, len(Header))
+	for i, field := range Header {
+		columns[field] = i
+	}
+	return columns
+}
+
+// typed reports whether a cell reads as a completion, a priority, a date or a
+// time rather than as a name
+func (p *parser) typed(value string) bool {
+	if _, err := parseBool(value); err == nil {
+		return true
+	}
+	if _, err := parsePriority(value); err == nil {
+		return true
+	}
+	_, err := p.parseTime("", value)
+	return err == nil
+}
+
+// parsesAsData reports whether the completion, times, priority and due date of
+// a record read as a row without a header are valid
+func (p *parser) parsesAsData(record []string) bool {
+	columns := make(map[string]int)
+	if len(p.opts.Columns) == 0 {
+		columns = defaultColumns()
+	}
+	for field, column := range p.opts.Columns {
+		if n, err := strconv.Atoi(strings.TrimSpace(column)); err == nil && n >= 1 {
+			columns[field] = n - 1
+		}
+	}
+	cell := func(field string) string {
+		if i, ok := columns[field]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	if _, err := parseBool(cell(FieldCompleted)); err != nil {
+		return false
+	}
+	if _, err := parsePriority(cell(FieldPriority)); err != nil {
+		return false
+	}
+	for _, field := range []string{FieldCreated, FieldUpdated} {
+		if _, err := p.parseTime(field, cell(field)); err != nil {
+			return false
+		}
+	}
+	if value := cell(FieldDue); value != "" {
+		if _, err := time.Parse(server.DateLayout, value); err != nil {
+			if _, err := p.parseTime(FieldDue, value); err != nil {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// row adds the list, task or subtask of a row
+func (p *parser) row(line int, record []string) {
+	cell := func(field string) string {
+		if i, ok := p.columnsOf[field]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	if strings.Join(record, "") == "" {
+		p.report.Rows--
+		return
+	}
+
+	v := validate.New()
+	listTitle := cell(FieldList)
+	if listTitle != "" {
+		listTitle = v.Name(FieldList, listTitle)
+	}
+	taskName, subTaskName := cell(FieldTask), cell(FieldSubTask)
+	if taskName != "" {
+		taskName = v.Name(FieldTask, taskName)
+	}
+	if subTaskName != "" {
+		subTaskName = v.Name(FieldSubTask, subTaskName)
+	}
+	notes := validate.CleanText(cell(FieldNotes))
+	var tags []string
+	if value := cell(FieldTags); value != "" {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				tags = append(tags, v.Name(FieldTags, name))
+			}
+		}
+	}
+	if err := v.Err(); err != nil {
+		var validation *server.ValidationError
+		errors.As(err, &validation)
+		messages := make([]string, len(validation.Fields))
+		for i, field := range validation.Fields {
+			messages[i] = field.Field + " " + field.Message
+		}
+		p.fail(line, "%s", strings.Join(messages, "; "))
+		return
+	}
+
+	completed, err := parseBool(cell(FieldCompleted))
+	if err != nil {
+		p.fail(line, "%s", err)
+		return
+	}
+	created, err := p.parseTime(FieldCreated, cell(FieldCreated))
+	if err != nil {
+		p.fail(line, "%s", err)
+		return
+	}
+	updated, err := p.parseTime(FieldUpdated, cell(FieldUpdated))
+	if err != nil {
+		p.fail(line, "%s", err)
+		return
+	}
+	priority, err := parsePriority(cell(FieldPriority))
+	if err != nil {
+		p.fail(line, "%s", err)
+		return
+	}
+	var dueDate *string
+	var dueAt *time.Time
+	if value := cell(FieldDue); value != "" {
+		if _, err := time.Parse(server.DateLayout, value); err == nil {
+			dueDate = &value
+		} else if t, err := p.parseTime(FieldDue, value); err == nil {
+			dueAt = &t
+		} else {
+			p.fail(line, "%s", err)
+			return
+		}
+	}
+
+	switch {
+	case taskName == "" && subTaskName != "":
+		p.fail(line, "subtask %q has no task", subTaskName)
+		return
+	case listTitle == "" && p.opts.ListID == "":
+		p.fail(line, "the row has no list, and no list was chosen for such rows")
+		return
+	}
+	list := p.list(listTitle, created, updated)
+	if taskName == "" {
+		return
+	}
+
+	key := [2]string{strings.ToLower(listTitle), strings.ToLower(taskName)}
+	at, ok := p.tasks[key]
+	if subTaskName == "" || !ok {
+		task := server.WorkspaceTask{TaskName: taskName, Tags: []string{}}
+		if subTaskName == "" {
+			task = server.WorkspaceTask{
+				TaskName:  taskName,
+				Notes:     notes,
+				Completed: completed,
+				Priority:  priority,
+				DueDate:   dueDate,
+				DueAt:     dueAt,
+				Tags:      tags,
+				CreatedAt: created,
+				UpdatedAt: updated,
+			}
+			if task.Tags == nil {
+				task.Tags = []string{}
+			}
+		}
+		at = len(list.Tasks)
+		p.tasks[key] = at
+		list.Tasks = append(list.Tasks, task)
+	}
+	if subTaskName != "" {
+		task := &list.Tasks[at]
+		task.SubTasks = append(task.SubTasks, server.WorkspaceSubTask{
+			SubTaskName: subTaskName,
+			Completed:   completed,
+			CreatedAt:   created,
+			UpdatedAt:   updated,
+		})
+	}
+}
+
+// list returns the list with a title, adding it to the document first if
+// needed; rows without a title belong to the list chosen for them
+func (p *parser) list(title string, created, updated time.Time) *server.WorkspaceList {
+	key := strings.ToLower(title)
+	if i, ok := p.index[key]; ok {
+		return &p.doc.Lists[i]
+	}
+	list := server.WorkspaceList{Title: title, CreatedAt: created, UpdatedAt: updated}
+	if id, ok := p.lists[key]; ok {
+		list = server.WorkspaceList{ID: id, Append: true}
+	} else if title == "" {
+		list = server.WorkspaceList{ID: p.opts.ListID, Append: true}
+	}
+	p.index[key] = len(p.doc.Lists)
+	p.doc.Lists = append(p.doc.Lists, list)
+	return &p.doc.Lists[len(p.doc.Lists)-1]
+}
+
+func (p *parser) parseTime(field, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, value, p.opts.Location); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, server.InvalidInput("%s %q is not a time such as 2024-05-01 09:30:00", field, value)
+}
+
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "", "false", "no", "n", "0", "todo":
+		return false, nil
+	case "true", "yes", "y", "1", "x", "done", "completed":
+		return true, nil
+	}
+	return false, server.InvalidInput("completed %q is not true or false", value)
+}
+
+func parsePriority(value string) (server.Priority, error) {
+	priority := server.Priority(strings.ToLower(value))
+	if priority == "" {
+		return server.PriorityNone, nil
+	}
+	if !priority.Valid() {
+		return "", server.InvalidInput("priority %q is not none, low, medium or high", value)
+	}
+	return priority, nil
+}
+
+// normalize lower-cases a header cell and drops spaces, dashes and
+// underscores
+func normalize(cell string) string {
+	return strings.NewReplacer(" ", "", "-", "", "_", "").Replace(strings.ToLower(strings.TrimSpace(cell)))
+}
+
+// decode returns the text of a file in an encoding, or the detected one
+func decode(data []byte, encoding string) (string, error) {
+	var decoded []byte
+	var err error
+	switch normalize(encoding) {
+	case "":
+		switch {
+		case bytes.HasPrefix(data, []byte{0xff, 0xfe}), bytes.HasPrefix(data, []byte{0xfe, 0xff}):
+			encoding = "utf-16"
+			decoded, err = unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewDecoder().Bytes(data)
+		case utf8.Valid(data):
+			decoded = data
+		default:
+			encoding = "windows-1252"
+			decoded, err = charmap.Windows1252.NewDecoder().Bytes(data)
+		}
+	case "utf8":
+		decoded = data
+	case "utf16le":
+		decoded, err = unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewDecoder().Bytes(data)
+	case "utf16be":
+		decoded, err = unicode.UTF16(unicode.BigEndian, unicode.UseBOM).NewDecoder().Bytes(data)
+	case "windows1252", "cp1252":
+		decoded, err = charmap.Windows1252.NewDecoder().Bytes(data)
+	case "iso88591", "latin1":
+		decoded, err = charmap.ISO8859_1.NewDecoder().Bytes(data)
+	default:
+		return "", server.InvalidInput("unsupported encoding %q; use utf-8, utf-16le, utf-16be, windows-1252 or iso-8859-1", encoding)
+	}
+	if err != nil {
+		return "", server.InvalidInput("the file is not valid %s: %s", encoding, err)
+	}
+	return strings.TrimPrefix(strings.ToValidUTF8(string(decoded), "\uFFFD"), bom), nil
+}
+
+// delimiter returns the given delimiter or, without one, the most frequent of
+// comma, semicolon, tab and pipe outside quotes on the first line
+func delimiter(text, given string) (rune, error) {
+	if given != "" {
+		r, size := utf8.DecodeRuneInString(given)
+		if size != len(given) || r == '"' || r == '\r' || r == '\n' || r == utf8.RuneError {
+			return 0, server.InvalidInput("delimiter must be a single character other than a quote or line break, got %q", given)
+		}
+		return r, nil
+	}
+
+	first, _, _ := strings.Cut(text, "\n")
+	counts := make(map[rune]int)
+	quoted := false
+	for _, r := range first {
+		if r == '"' {
+			quoted = !quoted
+		} else if !quoted {
+			counts[r]++
+		}
+	}
+	best := ','
+	for _, r := range []rune{';', '\t', '|'} {
+		if counts[r] > counts[best] {
+			best = r
+		}
+	}
+	return best, nil
+}
@@ -0,0 +1,167 @@
+package csvfile
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+func parse(t *testing.T, data string, opts Options) (*server.Workspace, *Report) {
+	t.Helper()
+	if opts.Location == nil {
+		opts.Location = time.UTC
+	}
+	doc, report, err := Parse([]byte(data), opts)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", data, err)
+	}
+	return doc, report
+}
+
+func TestRoundTrip(t *testing.T) {
+	now := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	query := "priority:high"
+	doc := &server.Workspace{Version: 1, Lists: []server.WorkspaceList{
+		{Title: "Home", CreatedAt: now, UpdatedAt: now, Tasks: []server.WorkspaceTask{
+			{TaskName: `Laundry, "cold"`, Completed: true, CreatedAt: now, UpdatedAt: now,
+				SubTasks: []server.WorkspaceSubTask{{SubTaskName: "Fold", CreatedAt: now, UpdatedAt: now}}},
+			{TaskName: "Dishes", CreatedAt: now, UpdatedAt: now},
+		}},
+		{Title: "Urgent", Query: &query},
+		{Title: "Empty", CreatedAt: now, UpdatedAt: now},
+	}}
+
+	text := Render(doc, time.UTC)
+	want := bom + "list,task,subtask,completed,created,updated\r\n" +
+		"Home,\"Laundry, \"\"cold\"\"\",,true,2024-05-01 09:30:00,2024-05-01 09:30:00\r\n" +
+		"Home,\"Laundry, \"\"cold\"\"\",Fold,false,2024-05-01 09:30:00,2024-05-01 09:30:00\r\n" +
+		"Home,Dishes,,false,2024-05-01 09:30:00,2024-05-01 09:30:00\r\n" +
+		"Empty,,,,2024-05-01 09:30:00,2024-05-01 09:30:00\r\n"
+	if text != want {
+		t.Fatalf("Render() =\n%q\nwant\n%q", text, want)
+	}
+
+	back, report := parse(t, text, Options{})
+	if report.Rows != 4 || len(report.Errors) != 0 {
+		t.Errorf("report = %+v, want 4 rows without errors", report)
+	}
+	if len(back.Lists) != 2 || back.Lists[0].Title != "Home" || back.Lists[1].Title != "Empty" {
+		t.Fatalf("lists = %+v, want Home and Empty", back.Lists)
+	}
+	tasks := back.Lists[0].Tasks
+	if len(tasks) != 2 || tasks[0].TaskName != `Laundry, "cold"` || !tasks[0].Completed || !tasks[0].CreatedAt.Equal(now) ||
+		len(tasks[0].SubTasks) != 1 || tasks[0].SubTasks[0].SubTaskName != "Fold" {
+		t.Errorf("tasks = %+v", tasks)
+	}
+}
+
+func TestHeaderDetection(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		data   string
+		opts   Options
+		header bool
+	}{
+		{"export header", "list,task,subtask,completed,created,updated\nHome,Laundry,,,,\n", Options{}, true},
+		{"aliases", "List Title;Task_Name;Done\nHome;Laundry;x\n", Options{}, true},
+		{"mapped names", "Project,Name,Prio\nHome,Laundry,high\n", Options{Format: Format{Columns: map[string]string{"list": "Project", "task": "Name"}}}, true},
+		{"data with a field name", "Work,Notes,,true,2024-05-01 09:30:00,\nHome,Laundry,,,,\n", Options{}, false},
+		{"data with a mapped name", "Notes,high\nLaundry,low\n", Options{Format: Format{Columns: map[string]string{"task": "1", "priority": "2"}}}, false},
+		{"spreadsheet header", "Title,Task Name,Description,Owner\nHome,Laundry,Warm wash,Sam\n", Options{}, true},
+		{"unknown names that are not data", "Where,What,Finished\nHome,Laundry,x\n", Options{Format: Format{Columns: map[string]string{"task": "2", "completed": "3"}}}, true},
+		{"forced header", "Home,Laundry\nWork,Report\n", Options{Format: Format{Header: HeaderPresent, Columns: map[string]string{"list": "1", "task": "2"}}}, true},
+		{"forced data", "list,task\nHome,Laundry\n", Options{Format: Format{Header: HeaderAbsent}}, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, report := parse(t, tt.data, tt.opts)
+			want := 2
+			if tt.header {
+				want = 1
+			}
+			if report.Rows != want {
+				t.Errorf("Rows = %d, want %d (errors %+v)", report.Rows, want, report.Errors)
+			}
+		})
+	}
+}
+
+func TestColumnsAndErrors(t *testing.T) {
+	data := "Name;Done;Project;Prio;Due;Tags\r\n" +
+		"Café;x;Work;high;2024-05-01;Deep Work, home\r\n" +
+		"Bad;maybe;Work;;;\r\n" +
+		";;;;;\r\n" +
+		"Loose;no;;low;;\r\n" +
+		"Urgent;1;Work;urgent;;\r\n" +
+		"Late;;Work;;tomorrow;\r\n" +
+		"\"Unterminated;1;Work\r\n"
+	opts := Options{Format: Format{Columns: map[string]string{"task": "Name", "list": "project", "priority": "4"}}}
+	doc, report := parse(t, data, opts)
+	if len(doc.Lists) != 1 || doc.Lists[0].Title != "Work" || len(doc.Lists[0].Tasks) != 1 {
+		t.Fatalf("lists = %+v, want Work with Café", doc.Lists)
+	}
+	task := doc.Lists[0].Tasks[0]
+	if task.TaskName != "Café" || !task.Completed || task.Priority != server.PriorityHigh || task.DueDate == nil || *task.DueDate != "2024-05-01" ||
+		strings.Join(task.Tags, "|") != "Deep Work|home" {
+		t.Errorf("task = %+v", task)
+	}
+	lines := make([]int, len(report.Errors))
+	for i, rowErr := range report.Errors {
+		lines[i] = rowErr.Line
+	}
+	if report.Rows != 6 || len(lines) != 5 || lines[0] != 3 || lines[1] != 5 || lines[2] != 6 || lines[3] != 7 || lines[4] != 8 {
+		t.Errorf("report = %+v, want 6 rows and errors on lines 3, 5, 6, 7 and 8", report)
+	}
+
+	// Rows without a list go to the one chosen for them
+	doc, _ = parse(t, "task\nLoose\n", Options{ListID: "inbox"})
+	if list := doc.Lists[0]; list.ID != "inbox" || !list.Append || len(list.Tasks) != 1 {
+		t.Errorf("list = %+v, want Loose added to the inbox", list)
+	}
+	existing := server.List{ID: "0b7f7f5e-9d0c-4f9a-8a8e-3f1c2d4e5f60", Title: "Work"}
+	doc, _ = parse(t, "list\ttask\nwork\tReport\n", Options{Lists: []server.List{existing}})
+	if list := doc.Lists[0]; list.ID != existing.ID || !list.Append {
+		t.Errorf("list = %+v, want Report added to the existing Work list", list)
+	}
+
+	for _, tt := range []struct {
+		name string
+		data string
+		opts Options
+	}{
+		{"no rows", "", Options{}},
+		{"unknown field", "a,b\n", Options{Format: Format{Columns: map[string]string{"title": "1"}}}},
+		{"missing column", "list,task\nHome,Laundry\n", Options{Format: Format{Columns: map[string]string{"notes": "Comments"}}}},
+		{"column 0", "list,task\n", Options{Format: Format{Columns: map[string]string{"task": "0"}}}},
+		{"no task column", "list,notes\nHome,Hi\n", Options{}},
+		// Rather than a list named Title with a task named Description
+		{"spreadsheet header without a task", "Title,Description,Due Date\nHome,Laundry,2024-05-01\n", Options{}},
+		{"bad header mode", "list,task\n", Options{Format: Format{Header: "maybe"}}},
+		{"bad delimiter", "list,task\n", Options{Format: Format{Delimiter: "ab"}}},
+		{"unknown encoding", "list,task\n", Options{Format: Format{Encoding: "ebcdic"}}},
+	} {
+		if _, _, err := Parse([]byte(tt.data), tt.opts); !errors.Is(err, server.ErrInvalidInput) {
+			t.Errorf("Parse() with %s: err = %v, want ErrInvalidInput", tt.name, err)
+		}
+	}
+}
+
+func TestEncodings(t *testing.T) {
+	// UTF-16 is detected from a byte order mark, and Windows-1252 is read for
+	// files that are not valid UTF-8
+	utf16 := []byte{0xff, 0xfe}
+	for _, r := range "task\nCafé\n" {
+		utf16 = append(utf16, byte(r), 0)
+	}
+	for _, data := range [][]byte{utf16, []byte("task\nCaf\xe9\n")} {
+		doc, _, err := Parse(data, Options{ListID: "inbox"})
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", data, err)
+		}
+		if name := doc.Lists[0].Tasks[0].TaskName; name != "Café" {
+			t.Errorf("Parse(%q) read %q, want Café", data, name)
+		}
+	}
+}
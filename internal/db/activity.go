@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	server "github.com/HolySxn/To-Do/internal"
+	"github.com/jackc/pgx/v5"
+)
+
+// Activity rows are written by the log_activity trigger from the 0008_activity
+// migration, and their actor comes from the todo.actor setting (0013_activity_actor)
+
+const activityColumns = `id, entity, entity_id, list_id, task_id, action, name, COALESCE(actor, ''), before, after, created_at`
+
+func scanActivity(row pgx.Row) (*server.Activity, error) {
+	var activity server.Activity
+	err := row.Scan(
+		&activity.ID,
+		&activity.Entity,
+		&activity.EntityID,
+		&activity.ListID,
+		&activity.TaskID,
+		&activity.Action,
+		&activity.Name,
+		&activity.Actor,
+		&activity.Before,
+		&activity.After,
+		&activity.At,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &activity, nil
+}
+
+func (d *DB) queryActivity(ctx context.Context, query string, args ...any) ([]server.Activity, error) {
+	rows, err := d.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get activity: %w", mapError(err))
+	}
+	defer rows.Close()
+
+	var activity []server.Activity
+	for rows.Next() {
+		entry, err := scanActivity(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan activity: %w", mapError(err))
+		}
+		activity = append(activity, *entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get activity: %w", mapError(err))
+	}
+
+	return activity, nil
+}
+
+func (d *DB) GetTaskActivity(ctx context.Context, taskID string) ([]server.Activity, error) {
+	// Subtasks moved to another task are logged under the new one
+	query := `SELECT ` + activityColumns + ` FROM activity
+		WHERE task_id = $1::uuid OR (entity = 'subtask' AND (before ->> 'task_id')::uuid = $1::uuid)
+		ORDER BY id DESC`
+	return d.queryActivity(ctx, query, taskID)
+}
+
+func (d *DB) GetListActivity(ctx context.Context, listID string, since time.Time) ([]server.Activity, error) {
+	// Tasks moved to another list are logged under the new one
+	query := `SELECT ` + activityColumns + ` FROM activity
+		WHERE (list_id = $1::uuid OR (entity = 'task' AND (before ->> 'list_id')::uuid = $1::uuid)) AND created_at >= $2
+		ORDER BY id DESC`
+	return d.queryActivity(ctx, query, listID, since)
+}
+
+// SetActor changes the todo.actor setting that the activity table takes its
+// actor from. The setting belongs to each connection, so the pool is reset
+// and every connection made after sets it again.
+func (d *DB) SetActor(ctx context.Context, actor string) error {
+	d.actor.Store(actor)
+	d.Pool.Reset()
+	if err := d.Pool.Ping(ctx); err != nil {
+		return fmt.Errorf("failed to set actor: %w", mapError(err))
+	}
+	return nil
+}
+
+// setSessionActor is the pool's AfterConnect hook
+func (d *DB) setSessionActor(ctx context.Context, conn *pgx.Conn) error {
+	actor, _ := d.actor.Load().(string)
+	if _, err := conn.Exec(ctx, `SELECT set_config('todo.actor', $1, false)`, actor); err != nil {
+		return fmt.Errorf("failed to set actor: %w", mapError(err))
+	}
+	return nil
+}
@@ -5,16 +5,43 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"sync/atomic"
 
+	server "github.com/HolySxn/To-Do/internal"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+var _ server.Store = (*DB)(nil)
+
 type DB struct {
 	Pool   *pgxpool.Pool
 	Logger *slog.Logger
+
+	// actor is set on every connection as todo.actor; see SetActor
+	actor atomic.Value
 }
 
+// NewDB connects to PostgreSQL and applies any pending schema migrations
 func NewDB(connectionString string) (*DB, error) {
+	db, err := Connect(connectionString)
+	if err != nil {
+		return nil, err
+	}
+
+	// Bring the schema up to date
+	db.Logger.Info("Applying database migrations")
+	if err := db.migrate(); err != nil {
+		db.Logger.Error("Failed to migrate database", "error", err)
+		db.Pool.Close()
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	db.Logger.Info("Database initialization completed successfully")
+	return db, nil
+}
+
+// Connect opens the connection pool without touching the schema
+func Connect(connectionString string) (*DB, error) {
 	// Initialize logger
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
 		Level: slog.LevelInfo,
@@ -33,6 +60,9 @@ func NewDB(connectionString string) (*DB, error) {
 	poolConfig.MaxConns = 10
 	poolConfig.MinConns = 1
 
+	db := &DB{Logger: logger}
+	poolConfig.AfterConnect = db.setSessionActor
+
 	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
 	if err != nil {
 		logger.Error("Failed to create connection pool", "error", err)
@@ -43,24 +73,12 @@ func NewDB(connectionString string) (*DB, error) {
 	logger.Info("Testing database connection")
 	if err := pool.Ping(context.Background()); err != nil {
 		logger.Error("Failed to ping database", "error", err)
-		return nil, fmt.Errorf("failed to ping database: %w", err)
+		return nil, fmt.Errorf("failed to ping database: %w", mapError(err))
 	}
 
 	logger.Info("Database connection established successfully")
 
-	db := &DB{
-		Pool:   pool,
-		Logger: logger,
-	}
-
-	// Initialize tables
-	logger.Info("Initializing database tables")
-	if err := db.initTables(); err != nil {
-		logger.Error("Failed to initialize tables", "error", err)
-		return nil, fmt.Errorf("failed to initialize tables: %w", err)
-	}
-
-	logger.Info("Database initialization completed successfully")
+	db.Pool = pool
 	return db, nil
 }
 
@@ -70,39 +88,12 @@ func (d *DB) Close() {
 	d.Logger.Info("Database connection closed")
 }
 
-func (d *DB) initTables() error {
-	queries := []string{
-		`CREATE EXTENSION IF NOT EXISTS "uuid-ossp"`,
-		`CREATE TABLE IF NOT EXISTS lists (
-			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-			title VARCHAR(255) NOT NULL,
-			position INTEGER DEFAULT 0,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE TABLE IF NOT EXISTS tasks (
-			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-			list_id UUID NOT NULL REFERENCES lists(id) ON DELETE CASCADE,
-			task_name VARCHAR(255) NOT NULL,
-			completed BOOLEAN DEFAULT FALSE,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE TABLE IF NOT EXISTS subtasks (
-			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-			task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
-			subtask_name VARCHAR(255) NOT NULL,
-			completed BOOLEAN DEFAULT FALSE,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)`,
-	}
-
-	for _, query := range queries {
-		if _, err := d.Pool.Exec(context.Background(), query); err != nil {
-			return fmt.Errorf("failed to execute query: %w", err)
-		}
+func (d *DB) migrate() error {
+	migrator, err := d.Migrator()
+	if err != nil {
+		return err
 	}
 
-	return nil
+	_, err = migrator.Up(context.Background())
+	return err
 }
@@ -0,0 +1,61 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	server "github.com/HolySxn/To-Do/internal"
+	"github.com/jackc/pgx/v5"
+)
+
+func (d *DB) SetTaskNotes(ctx context.Context, id, notes string) (*server.Task, error) {
+	query := `UPDATE tasks SET notes = $1, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $2 AND deleted_at IS NULL RETURNING ` + taskColumns
+
+	task, err := scanTask(d.Pool.QueryRow(ctx, query, notes, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, server.NotFound("task", id)
+		}
+		return nil, fmt.Errorf("failed to set task notes: %w", mapError(err))
+	}
+
+	return task, nil
+}
+
+func (d *DB) SetTaskPriority(ctx context.Context, id string, priority server.Priority) (*server.Task, error) {
+	if !priority.Valid() {
+		return nil, server.InvalidInput("invalid priority %q", priority)
+	}
+
+	query := `UPDATE tasks SET priority = $1, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $2 AND deleted_at IS NULL RETURNING ` + taskColumns
+
+	task, err := scanTask(d.Pool.QueryRow(ctx, query, string(priority), id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, server.NotFound("task", id)
+		}
+		return nil, fmt.Errorf("failed to set task priority: %w", mapError(err))
+	}
+
+	return task, nil
+}
+
+func (d *DB) StarTask(ctx context.Context, id string, starred bool) (*server.Task, error) {
+	query := `UPDATE tasks SET starred = $1, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $2 AND deleted_at IS NULL RETURNING ` + taskColumns
+
+	task, err := scanTask(d.Pool.QueryRow(ctx, query, starred, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, server.NotFound("task", id)
+		}
+		return nil, fmt.Errorf("failed to star task: %w", mapError(err))
+	}
+
+	return task, nil
+}
+
+func (d *DB) GetStarredTasks(ctx context.Context) ([]server.Task, error) {
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE starred AND deleted_at IS NULL ORDER BY created_at DESC`
+	return d.queryTasks(ctx, query)
+}
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	server "github.com/HolySxn/To-Do/internal"
+	"github.com/jackc/pgx/v5"
+)
+
+// parseDueDate converts a YYYY-MM-DD due date to the value stored in the DATE column
+func parseDueDate(dueDate *string) (*time.Time, error) {
+	if dueDate == nil {
+		return nil, nil
+	}
+	parsed, err := time.Parse(server.DateLayout, *dueDate)
+	if err != nil {
+		return nil, server.InvalidInput("invalid due date %q: %w", *dueDate, err)
+	}
+	return &parsed, nil
+}
+
+// SetTaskDue sets a whole-day due date or a due moment, clearing the other
+func (d *DB) SetTaskDue(ctx context.Context, id string, dueDate *string, dueAt *time.Time) (*server.Task, error) {
+	date, err := parseDueDate(dueDate)
+	if err != nil {
+		return nil, err
+	}
+	if date != nil {
+		dueAt = nil
+	}
+
+	query := `UPDATE tasks SET due_date = $1, due_at = $2, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $3 AND deleted_at IS NULL RETURNING ` + taskColumns
+
+	task, err := scanTask(d.Pool.QueryRow(ctx, query, date, dueAt, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, server.NotFound("task", id)
+		}
+		return nil, fmt.Errorf("failed to set task due date: %w", mapError(err))
+	}
+
+	return task, nil
+}
+
+func (d *DB) SetTaskReminder(ctx context.Context, id string, remindAt *time.Time) (*server.Task, error) {
+	query := `UPDATE tasks SET remind_at = $1, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $2 AND deleted_at IS NULL RETURNING ` + taskColumns
+
+	task, err := scanTask(d.Pool.QueryRow(ctx, query, remindAt, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, server.NotFound("task", id)
+		}
+		return nil, fmt.Errorf("failed to set task reminder: %w", mapError(err))
+	}
+
+	return task, nil
+}
+
+// GetTasksDueBetween returns tasks due in [from, to), ordered by due time
+func (d *DB) GetTasksDueBetween(ctx context.Context, from, to time.Time) ([]server.Task, error) {
+	first, last := server.DueDateRange(from, to)
+	query := `SELECT ` + taskColumns + ` FROM tasks
+		WHERE deleted_at IS NULL AND ((due_at >= $1 AND due_at < $2) OR (due_date >= $3::date AND due_date <= $4::date))`
+
+	tasks, err := d.queryTasks(ctx, query, from, to, first, last)
+	if err != nil {
+		return nil, err
+	}
+	server.SortByDue(tasks, from.Location())
+
+	return tasks, nil
+}
+
+// GetOverdueTasks returns incomplete tasks due before now, ordered by due time
+func (d *DB) GetOverdueTasks(ctx context.Context, now time.Time) ([]server.Task, error) {
+	query := `SELECT ` + taskColumns + ` FROM tasks
+		WHERE deleted_at IS NULL AND completed = FALSE AND (due_at < $1 OR due_date < $2::date)`
+
+	tasks, err := d.queryTasks(ctx, query, now, server.DateOf(now))
+	if err != nil {
+		return nil, err
+	}
+	server.SortByDue(tasks, now.Location())
+
+	return tasks, nil
+}
+
+// GetDueReminders returns incomplete tasks whose reminder time has passed
+func (d *DB) GetDueReminders(ctx context.Context, now time.Time) ([]server.Task, error) {
+	query := `SELECT ` + taskColumns + ` FROM tasks
+		WHERE deleted_at IS NULL AND completed = FALSE AND remind_at <= $1
+		ORDER BY remind_at ASC`
+
+	return d.queryTasks(ctx, query, now)
+}
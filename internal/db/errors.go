@@ -0,0 +1,48 @@
+package db
+
+import (
+	"errors"
+	"net"
+	"strings"
+
+	server "github.com/HolySxn/To-Do/internal"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// mapError marks PostgreSQL and connection errors with the server sentinel they
+// correspond to; any other error is returned unchanged
+func mapError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23503": // foreign_key_violation, e.g. a task for a missing list
+			return server.Mark(err, server.ErrNotFound)
+		case "22P02", // invalid_text_representation, e.g. a malformed UUID
+			"22001", // string_data_right_truncation
+			"23502", // not_null_violation
+			"23514": // check_violation
+			return server.Mark(err, server.ErrInvalidInput)
+		case "23505", // unique_violation
+			"40001", // serialization_failure
+			"40P01": // deadlock_detected
+			return server.Mark(err, server.ErrConflict)
+		case "53300", // too_many_connections
+			"57P01", // admin_shutdown
+			"57P02", // crash_shutdown
+			"57P03": // cannot_connect_now
+			return server.Mark(err, server.ErrUnavailable)
+		}
+		if strings.HasPrefix(pgErr.Code, "08") { // connection_exception class
+			return server.Mark(err, server.ErrUnavailable)
+		}
+		return err
+	}
+
+	var connectErr *pgconn.ConnectError
+	var netErr net.Error
+	if errors.As(err, &connectErr) || errors.As(err, &netErr) || pgconn.Timeout(err) || pgconn.SafeToRetry(err) {
+		return server.Mark(err, server.ErrUnavailable)
+	}
+
+	return err
+}
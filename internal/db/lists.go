@@ -2,12 +2,33 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	server "github.com/HolySxn/To-Do/internal"
 	"github.com/jackc/pgx/v5"
 )
 
+const listColumns = `id, title, query, position, version, created_at, updated_at, deleted_at`
+
+func scanList(row pgx.Row) (*server.List, error) {
+	var list server.List
+	err := row.Scan(
+		&list.ID,
+		&list.Title,
+		&list.Query,
+		&list.Position,
+		&list.Version,
+		&list.CreatedAt,
+		&list.UpdatedAt,
+		&list.DeletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
 // CRUD operations for Lists
 func (d *DB) CreateList(ctx context.Context, title string) (*server.List, error) {
 	// Get the next position for the new list
@@ -15,106 +36,98 @@ func (d *DB) CreateList(ctx context.Context, title string) (*server.List, error)
 	query := `SELECT COALESCE(MAX(position), 0) FROM lists`
 	err := d.Pool.QueryRow(ctx, query).Scan(&maxPosition)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get max position: %w", err)
+		return nil, fmt.Errorf("failed to get max position: %w", mapError(err))
 	}
 
-	query = `INSERT INTO lists (title, position) VALUES ($1, $2) RETURNING id, title, position, created_at, updated_at`
+	query = `INSERT INTO lists (title, position) VALUES ($1, $2) RETURNING ` + listColumns
 
-	var list server.List
-	err = d.Pool.QueryRow(ctx, query, title, maxPosition+1).Scan(
-		&list.ID,
-		&list.Title,
-		&list.Position,
-		&list.CreatedAt,
-		&list.UpdatedAt,
-	)
+	list, err := scanList(d.Pool.QueryRow(ctx, query, title, maxPosition+1))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create list: %w", err)
+		return nil, fmt.Errorf("failed to create list: %w", mapError(err))
 	}
 
-	return &list, nil
+	return list, nil
 }
 
 func (d *DB) GetList(ctx context.Context, id string) (*server.List, error) {
-	query := `SELECT id, title, position, created_at, updated_at FROM lists WHERE id = $1`
+	query := `SELECT ` + listColumns + ` FROM lists WHERE id = $1 AND deleted_at IS NULL`
 
-	var list server.List
-	err := d.Pool.QueryRow(ctx, query, id).Scan(
-		&list.ID,
-		&list.Title,
-		&list.Position,
-		&list.CreatedAt,
-		&list.UpdatedAt,
-	)
+	list, err := scanList(d.Pool.QueryRow(ctx, query, id))
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("list with id %s not found", id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, server.NotFound("list", id)
 		}
-		return nil, fmt.Errorf("failed to get list: %w", err)
+		return nil, fmt.Errorf("failed to get list: %w", mapError(err))
 	}
 
-	return &list, nil
+	return list, nil
 }
 
 func (d *DB) GetAllLists(ctx context.Context) ([]server.List, error) {
-	query := `SELECT id, title, position, created_at, updated_at FROM lists ORDER BY position ASC`
+	query := `SELECT ` + listColumns + ` FROM lists WHERE deleted_at IS NULL ORDER BY position ASC`
 
 	rows, err := d.Pool.Query(ctx, query)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get lists: %w", err)
+		return nil, fmt.Errorf("failed to get lists: %w", mapError(err))
 	}
 	defer rows.Close()
 
 	var lists []server.List
 	for rows.Next() {
-		var list server.List
-		err := rows.Scan(
-			&list.ID,
-			&list.Title,
-			&list.Position,
-			&list.CreatedAt,
-			&list.UpdatedAt,
-		)
+		list, err := scanList(rows)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan list: %w", err)
+			return nil, fmt.Errorf("failed to scan list: %w", mapError(err))
 		}
-		lists = append(lists, list)
+		lists = append(lists, *list)
 	}
 
 	return lists, nil
 }
 
-func (d *DB) UpdateList(ctx context.Context, id string, title string) (*server.List, error) {
-	query := `UPDATE lists SET title = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2 RETURNING id, title, position, created_at, updated_at`
+func (d *DB) UpdateList(ctx context.Context, id string, title string, version int64) (*server.List, error) {
+	query := `UPDATE lists SET title = $1, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $2 AND deleted_at IS NULL AND ($3::bigint = 0 OR version = $3) RETURNING ` + listColumns
 
-	var list server.List
-	err := d.Pool.QueryRow(ctx, query, title, id).Scan(
-		&list.ID,
-		&list.Title,
-		&list.Position,
-		&list.CreatedAt,
-		&list.UpdatedAt,
-	)
+	list, err := scanList(d.Pool.QueryRow(ctx, query, title, id, version))
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("list with id %s not found", id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, d.listConflict(ctx, id, version)
 		}
-		return nil, fmt.Errorf("failed to update list: %w", err)
+		return nil, fmt.Errorf("failed to update list: %w", mapError(err))
 	}
 
-	return &list, nil
+	return list, nil
 }
 
-func (d *DB) DeleteList(ctx context.Context, id string) error {
-	query := `DELETE FROM lists WHERE id = $1`
-
-	result, err := d.Pool.Exec(ctx, query, id)
+// DeleteList moves a list to the trash together with its tasks and subtasks.
+// CURRENT_TIMESTAMP is fixed for the transaction, so they share one deleted_at.
+func (d *DB) DeleteList(ctx context.Context, id string, version int64) error {
+	tx, err := d.Pool.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to delete list: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", mapError(err))
 	}
+	defer tx.Rollback(ctx)
 
+	query := `UPDATE lists SET deleted_at = CURRENT_TIMESTAMP, version = version + 1 WHERE id = $1 AND deleted_at IS NULL AND ($2::bigint = 0 OR version = $2)`
+	result, err := tx.Exec(ctx, query, id, version)
+	if err != nil {
+		return fmt.Errorf("failed to delete list: %w", mapError(err))
+	}
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("list with id %s not found", id)
+		return d.listConflict(ctx, id, version)
+	}
+
+	query = `UPDATE subtasks SET deleted_at = CURRENT_TIMESTAMP, version = version + 1
+		WHERE deleted_at IS NULL AND task_id IN (SELECT id FROM tasks WHERE list_id = $1 AND deleted_at IS NULL)`
+	if _, err := tx.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to delete subtasks: %w", mapError(err))
+	}
+	query = `UPDATE tasks SET deleted_at = CURRENT_TIMESTAMP, version = version + 1 WHERE list_id = $1 AND deleted_at IS NULL`
+	if _, err := tx.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to delete tasks: %w", mapError(err))
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", mapError(err))
 	}
 
 	return nil
@@ -129,22 +142,22 @@ func (d *DB) ReorderLists(ctx context.Context, listIDs []string) error {
 	// Start a transaction to ensure atomicity
 	tx, err := d.Pool.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", mapError(err))
 	}
 	defer tx.Rollback(ctx)
 
 	// Update positions for each list
 	for i, listID := range listIDs {
-		query := `UPDATE lists SET position = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
+		query := `UPDATE lists SET position = $1, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $2 AND deleted_at IS NULL`
 		_, err := tx.Exec(ctx, query, i+1, listID)
 		if err != nil {
-			return fmt.Errorf("failed to update list position: %w", err)
+			return fmt.Errorf("failed to update list position: %w", mapError(err))
 		}
 	}
 
 	// Commit the transaction
 	if err := tx.Commit(ctx); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return fmt.Errorf("failed to commit transaction: %w", mapError(err))
 	}
 
 	return nil
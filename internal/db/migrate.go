@@ -0,0 +1,137 @@
+package db
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+
+	"github.com/HolySxn/To-Do/internal/migrate"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockID is the pg_advisory_lock key that serializes schema migrations
+const migrationLockID int64 = 0x746f646f
+
+// Migrator returns a migrator for the embedded PostgreSQL migrations
+func (d *DB) Migrator() (*migrate.Migrator, error) {
+	files, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open migrations: %w", err)
+	}
+
+	migrations, err := migrate.Load(files)
+	if err != nil {
+		return nil, err
+	}
+
+	return migrate.New(&migrationDriver{pool: d.Pool}, migrations, d.Logger), nil
+}
+
+// migrationDriver runs migrations on a single pooled connection so that
+// the session-level advisory lock covers every statement
+type migrationDriver struct {
+	pool *pgxpool.Pool
+	conn *pgxpool.Conn
+}
+
+func (m *migrationDriver) Lock(ctx context.Context) error {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+		conn.Release()
+		return err
+	}
+
+	m.conn = conn
+	return nil
+}
+
+func (m *migrationDriver) Unlock(ctx context.Context) error {
+	if m.conn == nil {
+		return nil
+	}
+	defer func() {
+		m.conn.Release()
+		m.conn = nil
+	}()
+
+	_, err := m.conn.Exec(ctx, `SELECT pg_advisory_unlock($1)`, migrationLockID)
+	return err
+}
+
+func (m *migrationDriver) EnsureVersionTable(ctx context.Context) error {
+	query := `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`
+	_, err := m.conn.Exec(ctx, query)
+	return err
+}
+
+func (m *migrationDriver) Applied(ctx context.Context) ([]migrate.Record, error) {
+	query := `SELECT version, name, applied_at FROM schema_migrations ORDER BY version ASC`
+
+	rows, err := m.conn.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []migrate.Record
+	for rows.Next() {
+		var record migrate.Record
+		if err := rows.Scan(&record.Version, &record.Name, &record.AppliedAt); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+func (m *migrationDriver) Apply(ctx context.Context, migration migrate.Migration, dir migrate.Direction) error {
+	tx, err := m.conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var applied bool
+	query := `SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)`
+	if err := tx.QueryRow(ctx, query, migration.Version).Scan(&applied); err != nil {
+		return err
+	}
+	if applied == (dir == migrate.Up) {
+		return nil
+	}
+
+	body := migration.Up
+	if dir == migrate.Down {
+		body = migration.Down
+	}
+	// Exec without arguments uses the simple protocol, which allows multiple statements
+	if _, err := tx.Exec(ctx, body); err != nil {
+		return err
+	}
+
+	if dir == migrate.Up {
+		query = `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`
+		_, err = tx.Exec(ctx, query, migration.Version, migration.Name)
+	} else {
+		query = `DELETE FROM schema_migrations WHERE version = $1`
+		_, err = tx.Exec(ctx, query, migration.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record migration: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,27 @@
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+CREATE TABLE IF NOT EXISTS lists (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	title VARCHAR(255) NOT NULL,
+	position INTEGER DEFAULT 0,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS tasks (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	list_id UUID NOT NULL REFERENCES lists(id) ON DELETE CASCADE,
+	task_name VARCHAR(255) NOT NULL,
+	completed BOOLEAN DEFAULT FALSE,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS subtasks (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+	subtask_name VARCHAR(255) NOT NULL,
+	completed BOOLEAN DEFAULT FALSE,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS subtasks_task_id_position_idx;
+DROP INDEX IF EXISTS tasks_list_id_position_idx;
+
+ALTER TABLE subtasks DROP COLUMN IF EXISTS position;
+ALTER TABLE tasks DROP COLUMN IF EXISTS position;
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS tasks_remind_at_idx;
+DROP INDEX IF EXISTS tasks_due_at_idx;
+DROP INDEX IF EXISTS tasks_due_date_idx;
+
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_single_due_check;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS remind_at;
+ALTER TABLE tasks DROP COLUMN IF EXISTS due_at;
+ALTER TABLE tasks DROP COLUMN IF EXISTS due_date;
@@ -0,0 +1,9 @@
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS due_date DATE;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS due_at TIMESTAMPTZ;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS remind_at TIMESTAMPTZ;
+
+ALTER TABLE tasks ADD CONSTRAINT tasks_single_due_check CHECK (due_date IS NULL OR due_at IS NULL);
+
+CREATE INDEX IF NOT EXISTS tasks_due_date_idx ON tasks(due_date) WHERE due_date IS NOT NULL;
+CREATE INDEX IF NOT EXISTS tasks_due_at_idx ON tasks(due_at) WHERE due_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS tasks_remind_at_idx ON tasks(remind_at) WHERE remind_at IS NOT NULL;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS tasks_starred_idx;
+
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_priority_check;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS starred;
+ALTER TABLE tasks DROP COLUMN IF EXISTS priority;
+ALTER TABLE tasks DROP COLUMN IF EXISTS notes;
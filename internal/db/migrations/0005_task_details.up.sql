@@ -0,0 +1,7 @@
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS notes TEXT NOT NULL DEFAULT '';
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS priority VARCHAR(6) NOT NULL DEFAULT 'none';
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS starred BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE tasks ADD CONSTRAINT tasks_priority_check CHECK (priority IN ('none', 'low', 'medium', 'high'));
+
+CREATE INDEX IF NOT EXISTS tasks_starred_idx ON tasks(created_at) WHERE starred;
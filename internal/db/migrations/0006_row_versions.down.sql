@@ -0,0 +1,3 @@
+ALTER TABLE subtasks DROP COLUMN IF EXISTS version;
+ALTER TABLE tasks DROP COLUMN IF EXISTS version;
+ALTER TABLE lists DROP COLUMN IF EXISTS version;
@@ -0,0 +1,3 @@
+ALTER TABLE lists ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE subtasks ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
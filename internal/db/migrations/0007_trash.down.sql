@@ -0,0 +1,12 @@
+-- Trashed rows would reappear once the column is gone
+DELETE FROM subtasks WHERE deleted_at IS NOT NULL;
+DELETE FROM tasks WHERE deleted_at IS NOT NULL;
+DELETE FROM lists WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS subtasks_deleted_at_idx;
+DROP INDEX IF EXISTS tasks_deleted_at_idx;
+DROP INDEX IF EXISTS lists_deleted_at_idx;
+
+ALTER TABLE subtasks DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE tasks DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE lists DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS subtasks_activity ON subtasks;
+DROP TRIGGER IF EXISTS tasks_activity ON tasks;
+DROP TRIGGER IF EXISTS lists_activity ON lists;
+DROP FUNCTION IF EXISTS log_activity();
+
+DROP TABLE IF EXISTS activity;
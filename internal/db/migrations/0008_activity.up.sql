@@ -0,0 +1,76 @@
+-- Activity is written by triggers, so every change is logged in the same
+-- transaction that makes it. before and after hold the changed columns;
+-- position is left out because reordering renumbers whole lists.
+CREATE TABLE IF NOT EXISTS activity (
+	id BIGSERIAL PRIMARY KEY,
+	entity VARCHAR(7) NOT NULL CHECK (entity IN ('list', 'task', 'subtask')),
+	entity_id UUID NOT NULL,
+	list_id UUID NOT NULL,
+	task_id UUID,
+	action VARCHAR(7) NOT NULL CHECK (action IN ('create', 'update', 'toggle', 'move', 'delete', 'restore')),
+	name VARCHAR(255) NOT NULL,
+	before JSONB,
+	after JSONB,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS activity_list_id_idx ON activity(list_id, id);
+CREATE INDEX IF NOT EXISTS activity_task_id_idx ON activity(task_id, id);
+CREATE INDEX IF NOT EXISTS activity_entity_id_idx ON activity(entity_id);
+
+CREATE OR REPLACE FUNCTION log_activity() RETURNS TRIGGER AS $$
+DECLARE
+	new_row JSONB := to_jsonb(NEW) - 'id' - 'position' - 'version' - 'created_at' - 'updated_at';
+	old_row JSONB;
+	before_row JSONB;
+	after_row JSONB;
+	kind TEXT;
+	list UUID;
+	task UUID;
+BEGIN
+	IF TG_OP = 'INSERT' THEN
+		after_row := new_row - 'deleted_at';
+		kind := 'create';
+	ELSE
+		old_row := to_jsonb(OLD) - 'id' - 'position' - 'version' - 'created_at' - 'updated_at';
+		SELECT jsonb_object_agg(key, value) INTO before_row
+		FROM jsonb_each(old_row) WHERE new_row -> key IS DISTINCT FROM value;
+		IF before_row IS NULL THEN
+			RETURN NULL;
+		END IF;
+		SELECT jsonb_object_agg(key, new_row -> key) INTO after_row
+		FROM jsonb_object_keys(before_row) AS changed(key);
+
+		kind := CASE
+			WHEN OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN 'delete'
+			WHEN OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN 'restore'
+			WHEN before_row ? 'list_id' OR before_row ? 'task_id' THEN 'move'
+			WHEN before_row - 'completed' = '{}' THEN 'toggle'
+			ELSE 'update'
+		END;
+	END IF;
+
+	CASE TG_TABLE_NAME
+	WHEN 'lists' THEN
+		list := NEW.id;
+	WHEN 'tasks' THEN
+		list := (new_row ->> 'list_id')::uuid;
+		task := NEW.id;
+	ELSE
+		task := (new_row ->> 'task_id')::uuid;
+		SELECT tasks.list_id INTO list FROM tasks WHERE tasks.id = task;
+	END CASE;
+
+	INSERT INTO activity (entity, entity_id, list_id, task_id, action, name, before, after)
+	VALUES (
+		rtrim(TG_TABLE_NAME, 's'), NEW.id, list, task, kind,
+		COALESCE(new_row ->> 'title', new_row ->> 'task_name', new_row ->> 'subtask_name'),
+		before_row, after_row
+	);
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER lists_activity AFTER INSERT OR UPDATE ON lists FOR EACH ROW EXECUTE FUNCTION log_activity();
+CREATE TRIGGER tasks_activity AFTER INSERT OR UPDATE ON tasks FOR EACH ROW EXECUTE FUNCTION log_activity();
+CREATE TRIGGER subtasks_activity AFTER INSERT OR UPDATE ON subtasks FOR EACH ROW EXECUTE FUNCTION log_activity();
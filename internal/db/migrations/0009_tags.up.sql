@@ -0,0 +1,18 @@
+-- color is a #rrggbb hex value, or empty for the default color
+CREATE TABLE IF NOT EXISTS tags (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	name VARCHAR(255) NOT NULL,
+	color VARCHAR(7) NOT NULL DEFAULT '',
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS tags_name_idx ON tags(lower(name));
+
+CREATE TABLE IF NOT EXISTS task_tags (
+	task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+	tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+	PRIMARY KEY (task_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS task_tags_tag_id_idx ON task_tags(tag_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS subtasks_search_idx;
+DROP INDEX IF EXISTS tasks_search_idx;
+DROP INDEX IF EXISTS lists_search_idx;
@@ -0,0 +1,5 @@
+-- Search matches names through the 'simple' configuration, which lower-cases
+-- words without stemming them so that prefix queries behave predictably
+CREATE INDEX IF NOT EXISTS lists_search_idx ON lists USING GIN (to_tsvector('simple', title));
+CREATE INDEX IF NOT EXISTS tasks_search_idx ON tasks USING GIN (to_tsvector('simple', task_name));
+CREATE INDEX IF NOT EXISTS subtasks_search_idx ON subtasks USING GIN (to_tsvector('simple', subtask_name));
@@ -0,0 +1,2 @@
+DELETE FROM lists WHERE query IS NOT NULL;
+ALTER TABLE lists DROP COLUMN IF EXISTS query;
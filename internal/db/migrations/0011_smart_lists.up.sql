@@ -0,0 +1,2 @@
+-- A list with a query is a smart list, which shows the tasks matching it
+ALTER TABLE lists ADD COLUMN IF NOT EXISTS query TEXT;
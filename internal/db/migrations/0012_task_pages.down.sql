@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS subtasks_created_at_idx;
+DROP INDEX IF EXISTS tasks_created_at_idx;
+DROP INDEX IF EXISTS tasks_page_idx;
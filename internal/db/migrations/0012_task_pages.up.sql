@@ -0,0 +1,4 @@
+-- Keyset indexes for reading tasks and subtasks a page at a time
+CREATE INDEX IF NOT EXISTS tasks_page_idx ON tasks(list_id, position, created_at DESC, id DESC) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS tasks_created_at_idx ON tasks(created_at DESC, id DESC) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS subtasks_created_at_idx ON subtasks(created_at DESC, id DESC) WHERE deleted_at IS NULL;
@@ -0,0 +1,6 @@
+-- actor names who made a change. Rows logged before this migration stay NULL;
+-- new rows take the todo.actor setting of the session that writes them, which
+-- is set on every pool connection (see DB.SetActor). The default is added in a
+-- second step so existing rows are not filled in with the migrating session's.
+ALTER TABLE activity ADD COLUMN IF NOT EXISTS actor VARCHAR(255);
+ALTER TABLE activity ALTER COLUMN actor SET DEFAULT NULLIF(current_setting('todo.actor', true), '');
@@ -0,0 +1,134 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	server "github.com/HolySxn/To-Do/internal"
+	"github.com/jackc/pgx/v5"
+)
+
+// assignments collects the SET clause of a dynamically built UPDATE
+type assignments struct {
+	columns []string
+	args    []any
+}
+
+func (a *assignments) set(column string, value any) {
+	a.args = append(a.args, value)
+	a.columns = append(a.columns, fmt.Sprintf("%s = $%d", column, len(a.args)))
+}
+
+// update builds an UPDATE of the collected columns on the row with the given id,
+// guarded by the expected version unless it is 0
+func (a *assignments) update(table, id string, version int64, returning string) (string, []any) {
+	set := append(a.columns, "version = version + 1", "updated_at = CURRENT_TIMESTAMP")
+	args := append(a.args, id, version)
+	query := fmt.Sprintf(`UPDATE %s SET %s WHERE id = $%d AND deleted_at IS NULL AND ($%d::bigint = 0 OR version = $%d) RETURNING %s`,
+		table, strings.Join(set, ", "), len(args)-1, len(args), len(args), returning)
+	return query, args
+}
+
+func (d *DB) PatchList(ctx context.Context, id string, patch server.ListPatch) (*server.List, error) {
+	if patch.Empty() {
+		list, err := d.GetList(ctx, id)
+		if err == nil && patch.Version != 0 && list.Version != patch.Version {
+			return nil, &server.ConflictError{Entity: "list", ID: id, Version: patch.Version, Current: list}
+		}
+		return list, err
+	}
+
+	var a assignments
+	if patch.Title != nil {
+		a.set("title", *patch.Title)
+	}
+	if patch.Query != nil {
+		if err := d.checkListQuery(ctx, id, *patch.Query); err != nil {
+			return nil, err
+		}
+		a.set("query", *patch.Query)
+	}
+	query, args := a.update("lists", id, patch.Version, listColumns)
+
+	list, err := scanList(d.Pool.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, d.listConflict(ctx, id, patch.Version)
+		}
+		return nil, fmt.Errorf("failed to patch list: %w", mapError(err))
+	}
+
+	return list, nil
+}
+
+func (d *DB) PatchTask(ctx context.Context, id string, patch server.TaskPatch) (*server.Task, error) {
+	if err := patch.Validate(); err != nil {
+		return nil, err
+	}
+	if patch.Empty() {
+		task, err := d.GetTask(ctx, id)
+		if err == nil && patch.Version != 0 && task.Version != patch.Version {
+			return nil, &server.ConflictError{Entity: "task", ID: id, Version: patch.Version, Current: task}
+		}
+		return task, err
+	}
+
+	var a assignments
+	if patch.TaskName != nil {
+		a.set("task_name", *patch.TaskName)
+	}
+	if patch.Notes != nil {
+		a.set("notes", *patch.Notes)
+	}
+	if patch.Completed != nil {
+		a.set("completed", *patch.Completed)
+	}
+	if patch.Priority != nil {
+		a.set("priority", string(*patch.Priority))
+	}
+	if patch.Starred != nil {
+		a.set("starred", *patch.Starred)
+	}
+	query, args := a.update("tasks", id, patch.Version, taskColumns)
+
+	task, err := scanTask(d.Pool.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, d.taskConflict(ctx, id, patch.Version)
+		}
+		return nil, fmt.Errorf("failed to patch task: %w", mapError(err))
+	}
+
+	return task, nil
+}
+
+func (d *DB) PatchSubTask(ctx context.Context, id string, patch server.SubTaskPatch) (*server.SubTask, error) {
+	if patch.Empty() {
+		subTask, err := d.GetSubTask(ctx, id)
+		if err == nil && patch.Version != 0 && subTask.Version != patch.Version {
+			return nil, &server.ConflictError{Entity: "subtask", ID: id, Version: patch.Version, Current: subTask}
+		}
+		return subTask, err
+	}
+
+	var a assignments
+	if patch.SubTaskName != nil {
+		a.set("subtask_name", *patch.SubTaskName)
+	}
+	if patch.Completed != nil {
+		a.set("completed", *patch.Completed)
+	}
+	query, args := a.update("subtasks", id, patch.Version, subTaskColumns)
+
+	subTask, err := scanSubTask(d.Pool.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, d.subTaskConflict(ctx, id, patch.Version)
+		}
+		return nil, fmt.Errorf("failed to patch subtask: %w", mapError(err))
+	}
+
+	return subTask, nil
+}
@@ -0,0 +1,120 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	server "github.com/HolySxn/To-Do/internal"
+	"github.com/jackc/pgx/v5"
+)
+
+// CompleteTask marks a task completed and spawns its next occurrence if it recurs
+func (d *DB) CompleteTask(ctx context.Context, id string) (*server.TaskCompletion, error) {
+	tx, err := d.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", mapError(err))
+	}
+	defer tx.Rollback(ctx)
+
+	task, err := lockTask(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	completion := &server.TaskCompletion{Task: *task}
+	if !task.Completed {
+		completion, err = completeTask(ctx, tx, task)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", mapError(err))
+	}
+
+	return completion, nil
+}
+
+func (d *DB) SetTaskRecurrence(ctx context.Context, id string, rule *string) (*server.Task, error) {
+	var recurrence *string
+	if rule != nil {
+		parsed, err := server.ParseRecurrence(*rule)
+		if err != nil {
+			return nil, err
+		}
+		canonical := parsed.String()
+		recurrence = &canonical
+	}
+
+	query := `UPDATE tasks SET recurrence = $1, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $2 AND deleted_at IS NULL RETURNING ` + taskColumns
+
+	task, err := scanTask(d.Pool.QueryRow(ctx, query, recurrence, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, server.NotFound("task", id)
+		}
+		return nil, fmt.Errorf("failed to set task recurrence: %w", mapError(err))
+	}
+
+	return task, nil
+}
+
+// lockTask reads a task and locks its row until the transaction ends
+func lockTask(ctx context.Context, tx pgx.Tx, id string) (*server.Task, error) {
+	task, err := scanTask(tx.QueryRow(ctx, `SELECT `+taskColumns+` FROM tasks WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, server.NotFound("task", id)
+		}
+		return nil, fmt.Errorf("failed to get task: %w", mapError(err))
+	}
+	return task, nil
+}
+
+// completeTask marks an incomplete task completed. For a recurring task the next
+// occurrence takes the same position, which lists it above the completed one,
+// and receives copies of the subtasks reset to incomplete and of the tags.
+func completeTask(ctx context.Context, tx pgx.Tx, task *server.Task) (*server.TaskCompletion, error) {
+	query := `UPDATE tasks SET completed = TRUE, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND deleted_at IS NULL RETURNING ` + taskColumns
+	completed, err := scanTask(tx.QueryRow(ctx, query, task.ID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to complete task: %w", mapError(err))
+	}
+	completion := &server.TaskCompletion{Task: *completed}
+
+	next, err := server.NextOccurrence(*completed, time.Now(), time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute next occurrence: %w", mapError(err))
+	}
+	if next == nil {
+		return completion, nil
+	}
+
+	dueDate, err := parseDueDate(next.DueDate)
+	if err != nil {
+		return nil, err
+	}
+	query = `INSERT INTO tasks (list_id, task_name, notes, priority, starred, position, due_date, due_at, remind_at, recurrence)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING ` + taskColumns
+	completion.Next, err = scanTask(tx.QueryRow(ctx, query, next.ListID, next.TaskName, next.Notes, next.Priority, next.Starred,
+		completed.Position, dueDate, next.DueAt, next.RemindAt, next.Recurrence))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create next occurrence: %w", mapError(err))
+	}
+
+	query = `INSERT INTO subtasks (task_id, subtask_name, position)
+		SELECT $1, subtask_name, position FROM subtasks WHERE task_id = $2 AND deleted_at IS NULL`
+	if _, err := tx.Exec(ctx, query, completion.Next.ID, completed.ID); err != nil {
+		return nil, fmt.Errorf("failed to copy subtasks: %w", mapError(err))
+	}
+	query = `INSERT INTO task_tags (task_id, tag_id) SELECT $1, tag_id FROM task_tags WHERE task_id = $2`
+	if _, err := tx.Exec(ctx, query, completion.Next.ID, completed.ID); err != nil {
+		return nil, fmt.Errorf("failed to copy tags: %w", mapError(err))
+	}
+
+	return completion, nil
+}
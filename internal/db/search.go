@@ -0,0 +1,59 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+// The GIN indexes of the 0010_search migration find the rows that contain a
+// word starting with every term; server.RankSearchHits ranks them.
+
+const searchQuery = `
+	SELECT 'list', l.id, l.title, l.id, l.title, NULL::uuid, NULL::text
+	FROM lists l
+	WHERE to_tsvector('simple', l.title) @@ to_tsquery('simple', $1) AND l.deleted_at IS NULL
+	UNION ALL
+	SELECT 'task', t.id, t.task_name, l.id, l.title, NULL, NULL
+	FROM tasks t JOIN lists l ON l.id = t.list_id
+	WHERE to_tsvector('simple', t.task_name) @@ to_tsquery('simple', $1) AND t.deleted_at IS NULL
+	UNION ALL
+	SELECT 'subtask', s.id, s.subtask_name, l.id, l.title, t.id, t.task_name
+	FROM subtasks s JOIN tasks t ON t.id = s.task_id JOIN lists l ON l.id = t.list_id
+	WHERE to_tsvector('simple', s.subtask_name) @@ to_tsquery('simple', $1) AND s.deleted_at IS NULL`
+
+func (d *DB) Search(ctx context.Context, query string) ([]server.SearchHit, error) {
+	terms := server.SearchTerms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	// Terms hold only letters and digits, so quoting them is enough
+	lexemes := make([]string, len(terms))
+	for i, term := range terms {
+		lexemes[i] = `'` + term + `':*`
+	}
+
+	rows, err := d.Pool.Query(ctx, searchQuery, strings.Join(lexemes, " & "))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", mapError(err))
+	}
+	defer rows.Close()
+
+	var candidates []server.SearchHit
+	for rows.Next() {
+		var hit server.SearchHit
+		err := rows.Scan(&hit.Entity, &hit.ID, &hit.Name, &hit.ListID, &hit.ListTitle, &hit.TaskID, &hit.TaskName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan search hit: %w", mapError(err))
+		}
+		candidates = append(candidates, hit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to search: %w", mapError(err))
+	}
+
+	return server.RankSearchHits(candidates, terms), nil
+}
@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	server "github.com/HolySxn/To-Do/internal"
+	"github.com/HolySxn/To-Do/internal/query"
+	"github.com/jackc/pgx/v5"
+)
+
+var dialect = query.Dialect{
+	Param: func(n int) string { return fmt.Sprintf("$%d", n) },
+	Date:  func(param string) string { return param + "::date" },
+	// lower() folds all of Unicode under a UTF-8 locale; under the C locale
+	// it folds only ASCII letters, so create the database with a UTF-8 one
+	Lower: func(expr string) string { return "lower(" + expr + ")" },
+}
+
+func (d *DB) CreateSmartList(ctx context.Context, title, q string) (*server.List, error) {
+	if _, err := query.Parse(q, time.Now()); err != nil {
+		return nil, err
+	}
+
+	stmt := `INSERT INTO lists (title, query, position)
+		SELECT $1, $2, COALESCE(MAX(position), 0) + 1 FROM lists
+		RETURNING ` + listColumns
+
+	list, err := scanList(d.Pool.QueryRow(ctx, stmt, title, q))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create smart list: %w", mapError(err))
+	}
+
+	return list, nil
+}
+
+func (d *DB) GetSmartListTasks(ctx context.Context, listID string, now time.Time) ([]server.Task, error) {
+	var q *string
+	err := d.Pool.QueryRow(ctx, `SELECT query FROM lists WHERE id = $1 AND deleted_at IS NULL`, listID).Scan(&q)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, server.NotFound("list", listID)
+		}
+		return nil, fmt.Errorf("failed to get list: %w", mapError(err))
+	}
+	if q == nil {
+		return nil, server.NotSmartList(listID)
+	}
+
+	return d.QueryTasks(ctx, *q, now)
+}
+
+func (d *DB) QueryTasks(ctx context.Context, q string, now time.Time) ([]server.Task, error) {
+	parsed, err := query.Parse(q, now)
+	if err != nil {
+		return nil, err
+	}
+
+	where, args := parsed.SQL(dialect)
+	tasks, err := d.queryTasks(ctx, `SELECT `+taskColumns+` FROM tasks
+		WHERE deleted_at IS NULL AND `+where+`
+		ORDER BY created_at DESC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	parsed.Sort(tasks)
+
+	return tasks, nil
+}
+
+// checkListQuery checks that a list is a smart list and that q parses
+func (d *DB) checkListQuery(ctx context.Context, id, q string) error {
+	if _, err := query.Parse(q, time.Now()); err != nil {
+		return err
+	}
+	var smart bool
+	err := d.Pool.QueryRow(ctx, `SELECT query IS NOT NULL FROM lists WHERE id = $1 AND deleted_at IS NULL`, id).Scan(&smart)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return server.NotFound("list", id)
+		}
+		return fmt.Errorf("failed to get list: %w", mapError(err))
+	}
+	if !smart {
+		return server.NotSmartList(id)
+	}
+	return nil
+}
@@ -2,166 +2,168 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	server "github.com/HolySxn/To-Do/internal"
 	"github.com/jackc/pgx/v5"
 )
 
-// CRUD operations for SubTasks
-func (d *DB) CreateSubTask(ctx context.Context, taskID string, subTaskName string) (*server.SubTask, error) {
-	query := `INSERT INTO subtasks (task_id, subtask_name) VALUES ($1, $2) RETURNING id, task_id, subtask_name, completed, created_at, updated_at`
+const subTaskColumns = `id, task_id, subtask_name, completed, position, version, created_at, updated_at, deleted_at`
 
+func scanSubTask(row pgx.Row) (*server.SubTask, error) {
 	var subTask server.SubTask
-	err := d.Pool.QueryRow(ctx, query, taskID, subTaskName).Scan(
+	err := row.Scan(
 		&subTask.ID,
 		&subTask.TaskID,
 		&subTask.SubTaskName,
 		&subTask.Completed,
+		&subTask.Position,
+		&subTask.Version,
 		&subTask.CreatedAt,
 		&subTask.UpdatedAt,
+		&subTask.DeletedAt,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create subtask: %w", err)
-	}
-
-	return &subTask, nil
-}
-
-func (d *DB) GetSubTask(ctx context.Context, id string) (*server.SubTask, error) {
-	query := `SELECT id, task_id, subtask_name, completed, created_at, updated_at FROM subtasks WHERE id = $1`
-
-	var subTask server.SubTask
-	err := d.Pool.QueryRow(ctx, query, id).Scan(
-		&subTask.ID,
-		&subTask.TaskID,
-		&subTask.SubTaskName,
-		&subTask.Completed,
-		&subTask.CreatedAt,
-		&subTask.UpdatedAt,
-	)
-	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("subtask with id %s not found", id)
-		}
-		return nil, fmt.Errorf("failed to get subtask: %w", err)
+		return nil, err
 	}
-
 	return &subTask, nil
 }
 
-func (d *DB) GetSubTasksByTaskID(ctx context.Context, taskID string) ([]server.SubTask, error) {
-	query := `SELECT id, task_id, subtask_name, completed, created_at, updated_at FROM subtasks WHERE task_id = $1 ORDER BY created_at DESC`
-
-	rows, err := d.Pool.Query(ctx, query, taskID)
+func (d *DB) querySubTasks(ctx context.Context, query string, args ...any) ([]server.SubTask, error) {
+	rows, err := d.Pool.Query(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get subtasks: %w", err)
+		return nil, fmt.Errorf("failed to get subtasks: %w", mapError(err))
 	}
 	defer rows.Close()
 
 	var subTasks []server.SubTask
 	for rows.Next() {
-		var subTask server.SubTask
-		err := rows.Scan(
-			&subTask.ID,
-			&subTask.TaskID,
-			&subTask.SubTaskName,
-			&subTask.Completed,
-			&subTask.CreatedAt,
-			&subTask.UpdatedAt,
-		)
+		subTask, err := scanSubTask(rows)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan subtask: %w", err)
+			return nil, fmt.Errorf("failed to scan subtask: %w", mapError(err))
 		}
-		subTasks = append(subTasks, subTask)
+		subTasks = append(subTasks, *subTask)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get subtasks: %w", mapError(err))
 	}
 
 	return subTasks, nil
 }
 
-func (d *DB) GetAllSubTasks(ctx context.Context) ([]server.SubTask, error) {
-	query := `SELECT id, task_id, subtask_name, completed, created_at, updated_at FROM subtasks ORDER BY created_at DESC`
+// CRUD operations for SubTasks
+func (d *DB) CreateSubTask(ctx context.Context, taskID string, subTaskName string) (*server.SubTask, error) {
+	// New subtasks are placed above the existing ones
+	query := `INSERT INTO subtasks (task_id, subtask_name, position)
+		SELECT $1::uuid, $2, (SELECT COALESCE(MIN(position), 1) - 1 FROM subtasks WHERE task_id = $1::uuid)
+		WHERE EXISTS (SELECT 1 FROM tasks WHERE id = $1::uuid AND deleted_at IS NULL)
+		RETURNING ` + subTaskColumns
 
-	rows, err := d.Pool.Query(ctx, query)
+	subTask, err := scanSubTask(d.Pool.QueryRow(ctx, query, taskID, subTaskName))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get subtasks: %w", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("failed to create subtask: %w", server.NotFound("task", taskID))
+		}
+		return nil, fmt.Errorf("failed to create subtask: %w", mapError(err))
 	}
-	defer rows.Close()
 
-	var subTasks []server.SubTask
-	for rows.Next() {
-		var subTask server.SubTask
-		err := rows.Scan(
-			&subTask.ID,
-			&subTask.TaskID,
-			&subTask.SubTaskName,
-			&subTask.Completed,
-			&subTask.CreatedAt,
-			&subTask.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan subtask: %w", err)
+	return subTask, nil
+}
+
+func (d *DB) GetSubTask(ctx context.Context, id string) (*server.SubTask, error) {
+	query := `SELECT ` + subTaskColumns + ` FROM subtasks WHERE id = $1 AND deleted_at IS NULL`
+
+	subTask, err := scanSubTask(d.Pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, server.NotFound("subtask", id)
 		}
-		subTasks = append(subTasks, subTask)
+		return nil, fmt.Errorf("failed to get subtask: %w", mapError(err))
 	}
 
-	return subTasks, nil
+	return subTask, nil
 }
 
-func (d *DB) UpdateSubTask(ctx context.Context, id, subTaskName string, completed bool) (*server.SubTask, error) {
-	query := `UPDATE subtasks SET subtask_name = $1, completed = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $3 RETURNING id, task_id, subtask_name, completed, created_at, updated_at`
+func (d *DB) GetSubTasksByTaskID(ctx context.Context, taskID string) ([]server.SubTask, error) {
+	query := `SELECT ` + subTaskColumns + ` FROM subtasks WHERE task_id = $1 AND deleted_at IS NULL ORDER BY position ASC, created_at DESC`
+	return d.querySubTasks(ctx, query, taskID)
+}
 
-	var subTask server.SubTask
-	err := d.Pool.QueryRow(ctx, query, subTaskName, completed, id).Scan(
-		&subTask.ID,
-		&subTask.TaskID,
-		&subTask.SubTaskName,
-		&subTask.Completed,
-		&subTask.CreatedAt,
-		&subTask.UpdatedAt,
-	)
+func (d *DB) GetAllSubTasks(ctx context.Context) ([]server.SubTask, error) {
+	query := `SELECT ` + subTaskColumns + ` FROM subtasks WHERE deleted_at IS NULL ORDER BY created_at DESC`
+	return d.querySubTasks(ctx, query)
+}
+
+func (d *DB) UpdateSubTask(ctx context.Context, id, subTaskName string, completed bool, version int64) (*server.SubTask, error) {
+	query := `UPDATE subtasks SET subtask_name = $1, completed = $2, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $3 AND deleted_at IS NULL AND ($4::bigint = 0 OR version = $4) RETURNING ` + subTaskColumns
+
+	subTask, err := scanSubTask(d.Pool.QueryRow(ctx, query, subTaskName, completed, id, version))
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("subtask with id %s not found", id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, d.subTaskConflict(ctx, id, version)
 		}
-		return nil, fmt.Errorf("failed to update subtask: %w", err)
+		return nil, fmt.Errorf("failed to update subtask: %w", mapError(err))
 	}
 
-	return &subTask, nil
+	return subTask, nil
 }
 
 func (d *DB) ToggleSubTaskCompletion(ctx context.Context, id string) (*server.SubTask, error) {
-	query := `UPDATE subtasks SET completed = NOT completed, updated_at = CURRENT_TIMESTAMP WHERE id = $1 RETURNING id, task_id, subtask_name, completed, created_at, updated_at`
+	query := `UPDATE subtasks SET completed = NOT completed, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND deleted_at IS NULL RETURNING ` + subTaskColumns
 
-	var subTask server.SubTask
-	err := d.Pool.QueryRow(ctx, query, id).Scan(
-		&subTask.ID,
-		&subTask.TaskID,
-		&subTask.SubTaskName,
-		&subTask.Completed,
-		&subTask.CreatedAt,
-		&subTask.UpdatedAt,
-	)
+	subTask, err := scanSubTask(d.Pool.QueryRow(ctx, query, id))
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("subtask with id %s not found", id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, server.NotFound("subtask", id)
 		}
-		return nil, fmt.Errorf("failed to toggle subtask completion: %w", err)
+		return nil, fmt.Errorf("failed to toggle subtask completion: %w", mapError(err))
 	}
 
-	return &subTask, nil
+	return subTask, nil
 }
 
-func (d *DB) DeleteSubTask(ctx context.Context, id string) error {
-	query := `DELETE FROM subtasks WHERE id = $1`
+// DeleteSubTask moves a subtask to the trash
+func (d *DB) DeleteSubTask(ctx context.Context, id string, version int64) error {
+	query := `UPDATE subtasks SET deleted_at = CURRENT_TIMESTAMP, version = version + 1 WHERE id = $1 AND deleted_at IS NULL AND ($2::bigint = 0 OR version = $2)`
 
-	result, err := d.Pool.Exec(ctx, query, id)
+	result, err := d.Pool.Exec(ctx, query, id, version)
 	if err != nil {
-		return fmt.Errorf("failed to delete subtask: %w", err)
+		return fmt.Errorf("failed to delete subtask: %w", mapError(err))
 	}
 
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("subtask with id %s not found", id)
+		return d.subTaskConflict(ctx, id, version)
+	}
+
+	return nil
+}
+
+// ReorderSubTasks updates the positions of a task's subtasks based on the provided order
+func (d *DB) ReorderSubTasks(ctx context.Context, taskID string, subTaskIDs []string) error {
+	if len(subTaskIDs) == 0 {
+		return nil
+	}
+
+	// Start a transaction to ensure atomicity
+	tx, err := d.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", mapError(err))
+	}
+	defer tx.Rollback(ctx)
+
+	// Update positions for each subtask; ids from other tasks are ignored
+	for i, subTaskID := range subTaskIDs {
+		query := `UPDATE subtasks SET position = $1, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $2 AND deleted_at IS NULL AND task_id = $3`
+		_, err := tx.Exec(ctx, query, i+1, subTaskID, taskID)
+		if err != nil {
+			return fmt.Errorf("failed to update subtask position: %w", mapError(err))
+		}
+	}
+
+	// Commit the transaction
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", mapError(err))
 	}
 
 	return nil
@@ -2,166 +2,213 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	server "github.com/HolySxn/To-Do/internal"
 	"github.com/jackc/pgx/v5"
 )
 
-// CRUD operations for Tasks
-func (d *DB) CreateTask(ctx context.Context, listID, taskName string) (*server.Task, error) {
-	query := `INSERT INTO tasks (list_id, task_name) VALUES ($1, $2) RETURNING id, list_id, task_name, completed, created_at, updated_at`
+const taskColumns = `id, list_id, task_name, notes, completed, priority, starred, position, due_date, due_at, remind_at, recurrence, version, created_at, updated_at, deleted_at`
 
+func scanTask(row pgx.Row) (*server.Task, error) {
 	var task server.Task
-	err := d.Pool.QueryRow(ctx, query, listID, taskName).Scan(
+	var dueDate *time.Time
+	err := row.Scan(
 		&task.ID,
 		&task.ListID,
 		&task.TaskName,
+		&task.Notes,
 		&task.Completed,
+		&task.Priority,
+		&task.Starred,
+		&task.Position,
+		&dueDate,
+		&task.DueAt,
+		&task.RemindAt,
+		&task.Recurrence,
+		&task.Version,
 		&task.CreatedAt,
 		&task.UpdatedAt,
+		&task.DeletedAt,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create task: %w", err)
+		return nil, err
 	}
-
-	return &task, nil
-}
-
-func (d *DB) GetTask(ctx context.Context, id string) (*server.Task, error) {
-	query := `SELECT id, list_id, task_name, completed, created_at, updated_at FROM tasks WHERE id = $1`
-
-	var task server.Task
-	err := d.Pool.QueryRow(ctx, query, id).Scan(
-		&task.ID,
-		&task.ListID,
-		&task.TaskName,
-		&task.Completed,
-		&task.CreatedAt,
-		&task.UpdatedAt,
-	)
-	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("task with id %s not found", id)
-		}
-		return nil, fmt.Errorf("failed to get task: %w", err)
+	if dueDate != nil {
+		date := server.DateOf(*dueDate)
+		task.DueDate = &date
 	}
-
 	return &task, nil
 }
 
-func (d *DB) GetTasksByListID(ctx context.Context, listID string) ([]server.Task, error) {
-	query := `SELECT id, list_id, task_name, completed, created_at, updated_at FROM tasks WHERE list_id = $1 ORDER BY created_at DESC`
-
-	rows, err := d.Pool.Query(ctx, query, listID)
+func (d *DB) queryTasks(ctx context.Context, query string, args ...any) ([]server.Task, error) {
+	rows, err := d.Pool.Query(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get tasks: %w", err)
+		return nil, fmt.Errorf("failed to get tasks: %w", mapError(err))
 	}
 	defer rows.Close()
 
 	var tasks []server.Task
 	for rows.Next() {
-		var task server.Task
-		err := rows.Scan(
-			&task.ID,
-			&task.ListID,
-			&task.TaskName,
-			&task.Completed,
-			&task.CreatedAt,
-			&task.UpdatedAt,
-		)
+		task, err := scanTask(rows)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan task: %w", err)
+			return nil, fmt.Errorf("failed to scan task: %w", mapError(err))
 		}
-		tasks = append(tasks, task)
+		tasks = append(tasks, *task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get tasks: %w", mapError(err))
 	}
 
 	return tasks, nil
 }
 
-func (d *DB) GetAllTasks(ctx context.Context) ([]server.Task, error) {
-	query := `SELECT id, list_id, task_name, completed, created_at, updated_at FROM tasks ORDER BY created_at DESC`
+// CRUD operations for Tasks
+func (d *DB) CreateTask(ctx context.Context, listID, taskName string) (*server.Task, error) {
+	// New tasks are placed above the existing ones
+	query := `INSERT INTO tasks (list_id, task_name, position)
+		SELECT $1::uuid, $2, (SELECT COALESCE(MIN(position), 1) - 1 FROM tasks WHERE list_id = $1::uuid)
+		WHERE EXISTS (SELECT 1 FROM lists WHERE id = $1::uuid AND deleted_at IS NULL AND query IS NULL)
+		RETURNING ` + taskColumns
 
-	rows, err := d.Pool.Query(ctx, query)
+	task, err := scanTask(d.Pool.QueryRow(ctx, query, listID, taskName))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get tasks: %w", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("failed to create task: %w", taskListError(ctx, d.Pool, listID, err))
+		}
+		return nil, fmt.Errorf("failed to create task: %w", mapError(err))
 	}
-	defer rows.Close()
 
-	var tasks []server.Task
-	for rows.Next() {
-		var task server.Task
-		err := rows.Scan(
-			&task.ID,
-			&task.ListID,
-			&task.TaskName,
-			&task.Completed,
-			&task.CreatedAt,
-			&task.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan task: %w", err)
+	return task, nil
+}
+
+func (d *DB) GetTask(ctx context.Context, id string) (*server.Task, error) {
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE id = $1 AND deleted_at IS NULL`
+
+	task, err := scanTask(d.Pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, server.NotFound("task", id)
 		}
-		tasks = append(tasks, task)
+		return nil, fmt.Errorf("failed to get task: %w", mapError(err))
 	}
 
-	return tasks, nil
+	return task, nil
 }
 
-func (d *DB) UpdateTask(ctx context.Context, id, taskName string, completed bool) (*server.Task, error) {
-	query := `UPDATE tasks SET task_name = $1, completed = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $3 RETURNING id, list_id, task_name, completed, created_at, updated_at`
+func (d *DB) GetTasksByListID(ctx context.Context, listID string) ([]server.Task, error) {
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE list_id = $1 AND deleted_at IS NULL ORDER BY position ASC, created_at DESC`
+	return d.queryTasks(ctx, query, listID)
+}
 
-	var task server.Task
-	err := d.Pool.QueryRow(ctx, query, taskName, completed, id).Scan(
-		&task.ID,
-		&task.ListID,
-		&task.TaskName,
-		&task.Completed,
-		&task.CreatedAt,
-		&task.UpdatedAt,
-	)
+func (d *DB) GetAllTasks(ctx context.Context) ([]server.Task, error) {
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE deleted_at IS NULL ORDER BY created_at DESC`
+	return d.queryTasks(ctx, query)
+}
+
+func (d *DB) UpdateTask(ctx context.Context, id, taskName string, completed bool, version int64) (*server.Task, error) {
+	query := `UPDATE tasks SET task_name = $1, completed = $2, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $3 AND deleted_at IS NULL AND ($4::bigint = 0 OR version = $4) RETURNING ` + taskColumns
+
+	task, err := scanTask(d.Pool.QueryRow(ctx, query, taskName, completed, id, version))
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("task with id %s not found", id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, d.taskConflict(ctx, id, version)
 		}
-		return nil, fmt.Errorf("failed to update task: %w", err)
+		return nil, fmt.Errorf("failed to update task: %w", mapError(err))
 	}
 
-	return &task, nil
+	return task, nil
 }
 
 func (d *DB) ToggleTaskCompletion(ctx context.Context, id string) (*server.Task, error) {
-	query := `UPDATE tasks SET completed = NOT completed, updated_at = CURRENT_TIMESTAMP WHERE id = $1 RETURNING id, list_id, task_name, completed, created_at, updated_at`
+	tx, err := d.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", mapError(err))
+	}
+	defer tx.Rollback(ctx)
 
-	var task server.Task
-	err := d.Pool.QueryRow(ctx, query, id).Scan(
-		&task.ID,
-		&task.ListID,
-		&task.TaskName,
-		&task.Completed,
-		&task.CreatedAt,
-		&task.UpdatedAt,
-	)
+	task, err := lockTask(ctx, tx, id)
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("task with id %s not found", id)
+		return nil, err
+	}
+
+	if task.Completed {
+		query := `UPDATE tasks SET completed = FALSE, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND deleted_at IS NULL RETURNING ` + taskColumns
+		task, err = scanTask(tx.QueryRow(ctx, query, id))
+		if err != nil {
+			return nil, fmt.Errorf("failed to toggle task completion: %w", mapError(err))
+		}
+	} else {
+		completion, err := completeTask(ctx, tx, task)
+		if err != nil {
+			return nil, err
 		}
-		return nil, fmt.Errorf("failed to toggle task completion: %w", err)
+		task = &completion.Task
 	}
 
-	return &task, nil
-}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", mapError(err))
+	}
 
-func (d *DB) DeleteTask(ctx context.Context, id string) error {
-	query := `DELETE FROM tasks WHERE id = $1`
+	return task, nil
+}
 
-	result, err := d.Pool.Exec(ctx, query, id)
+// DeleteTask moves a task to the trash together with its subtasks
+func (d *DB) DeleteTask(ctx context.Context, id string, version int64) error {
+	tx, err := d.Pool.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to delete task: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", mapError(err))
 	}
+	defer tx.Rollback(ctx)
 
+	query := `UPDATE tasks SET deleted_at = CURRENT_TIMESTAMP, version = version + 1 WHERE id = $1 AND deleted_at IS NULL AND ($2::bigint = 0 OR version = $2)`
+	result, err := tx.Exec(ctx, query, id, version)
+	if err != nil {
+		return fmt.Errorf("failed to delete task: %w", mapError(err))
+	}
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("task with id %s not found", id)
+		return d.taskConflict(ctx, id, version)
+	}
+
+	query = `UPDATE subtasks SET deleted_at = CURRENT_TIMESTAMP, version = version + 1 WHERE task_id = $1 AND deleted_at IS NULL`
+	if _, err := tx.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to delete subtasks: %w", mapError(err))
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", mapError(err))
+	}
+
+	return nil
+}
+
+// ReorderTasks updates the positions of a list's tasks based on the provided order
+func (d *DB) ReorderTasks(ctx context.Context, listID string, taskIDs []string) error {
+	if len(taskIDs) == 0 {
+		return nil
+	}
+
+	// Start a transaction to ensure atomicity
+	tx, err := d.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", mapError(err))
+	}
+	defer tx.Rollback(ctx)
+
+	// Update positions for each task; ids from other lists are ignored
+	for i, taskID := range taskIDs {
+		query := `UPDATE tasks SET position = $1, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $2 AND deleted_at IS NULL AND list_id = $3`
+		_, err := tx.Exec(ctx, query, i+1, taskID, listID)
+		if err != nil {
+			return fmt.Errorf("failed to update task position: %w", mapError(err))
+		}
+	}
+
+	// Commit the transaction
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", mapError(err))
 	}
 
 	return nil
@@ -0,0 +1,213 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	server "github.com/HolySxn/To-Do/internal"
+	"github.com/jackc/pgx/v5"
+)
+
+// Rows trashed along with their parent share its deleted_at, which is how they
+// are told apart from rows deleted directly and found again on restore.
+
+func (d *DB) GetTrash(ctx context.Context) (*server.Trash, error) {
+	trash := &server.Trash{}
+
+	rows, err := d.Pool.Query(ctx, `SELECT `+listColumns+` FROM lists WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC, created_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trashed lists: %w", mapError(err))
+	}
+	defer rows.Close()
+	for rows.Next() {
+		list, err := scanList(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan list: %w", mapError(err))
+		}
+		trash.Lists = append(trash.Lists, *list)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get trashed lists: %w", mapError(err))
+	}
+
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE deleted_at IS NOT NULL
+		AND NOT EXISTS (SELECT 1 FROM lists WHERE lists.id = tasks.list_id AND lists.deleted_at = tasks.deleted_at)
+		ORDER BY deleted_at DESC, created_at DESC`
+	trash.Tasks, err = d.queryTasks(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	query = `SELECT ` + subTaskColumns + ` FROM subtasks WHERE deleted_at IS NOT NULL
+		AND NOT EXISTS (SELECT 1 FROM tasks WHERE tasks.id = subtasks.task_id AND tasks.deleted_at = subtasks.deleted_at)
+		ORDER BY deleted_at DESC, created_at DESC`
+	trash.SubTasks, err = d.querySubTasks(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return trash, nil
+}
+
+func (d *DB) RestoreList(ctx context.Context, id string) (*server.List, error) {
+	tx, err := d.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", mapError(err))
+	}
+	defer tx.Rollback(ctx)
+
+	if err := requireRow(ctx, tx, `SELECT EXISTS (SELECT 1 FROM lists WHERE id = $1 AND deleted_at IS NOT NULL)`, id); err != nil {
+		return nil, listNotFound(id, err)
+	}
+	if err := restoreListChildren(ctx, tx, id); err != nil {
+		return nil, err
+	}
+
+	query := `UPDATE lists SET deleted_at = NULL, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $1 RETURNING ` + listColumns
+	list, err := scanList(tx.QueryRow(ctx, query, id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore list: %w", mapError(err))
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", mapError(err))
+	}
+
+	return list, nil
+}
+
+func (d *DB) RestoreTask(ctx context.Context, id string) (*server.Task, error) {
+	tx, err := d.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", mapError(err))
+	}
+	defer tx.Rollback(ctx)
+
+	if err := requireRow(ctx, tx, `SELECT EXISTS (SELECT 1 FROM tasks WHERE id = $1 AND deleted_at IS NOT NULL)`, id); err != nil {
+		return nil, taskNotFound(id, err)
+	}
+	query := `UPDATE lists SET deleted_at = NULL, version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = (SELECT list_id FROM tasks WHERE id = $1) AND deleted_at IS NOT NULL`
+	if _, err := tx.Exec(ctx, query, id); err != nil {
+		return nil, fmt.Errorf("failed to restore list: %w", mapError(err))
+	}
+	if err := restoreTaskChildren(ctx, tx, id); err != nil {
+		return nil, err
+	}
+
+	query = `UPDATE tasks SET deleted_at = NULL, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $1 RETURNING ` + taskColumns
+	task, err := scanTask(tx.QueryRow(ctx, query, id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore task: %w", mapError(err))
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", mapError(err))
+	}
+
+	return task, nil
+}
+
+func (d *DB) RestoreSubTask(ctx context.Context, id string) (*server.SubTask, error) {
+	tx, err := d.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", mapError(err))
+	}
+	defer tx.Rollback(ctx)
+
+	err = requireRow(ctx, tx, `SELECT EXISTS (SELECT 1 FROM subtasks WHERE id = $1 AND deleted_at IS NOT NULL)`, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, server.NotFound("subtask", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subtask: %w", mapError(err))
+	}
+	query := `UPDATE lists SET deleted_at = NULL, version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = (SELECT list_id FROM tasks WHERE id = (SELECT task_id FROM subtasks WHERE id = $1)) AND deleted_at IS NOT NULL`
+	if _, err := tx.Exec(ctx, query, id); err != nil {
+		return nil, fmt.Errorf("failed to restore list: %w", mapError(err))
+	}
+	query = `UPDATE tasks SET deleted_at = NULL, version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = (SELECT task_id FROM subtasks WHERE id = $1) AND deleted_at IS NOT NULL`
+	if _, err := tx.Exec(ctx, query, id); err != nil {
+		return nil, fmt.Errorf("failed to restore task: %w", mapError(err))
+	}
+
+	query = `UPDATE subtasks SET deleted_at = NULL, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $1 RETURNING ` + subTaskColumns
+	subTask, err := scanSubTask(tx.QueryRow(ctx, query, id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore subtask: %w", mapError(err))
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", mapError(err))
+	}
+
+	return subTask, nil
+}
+
+func (d *DB) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	tx, err := d.Pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", mapError(err))
+	}
+	defer tx.Rollback(ctx)
+
+	query := `DELETE FROM activity WHERE entity_id IN (
+		SELECT id FROM subtasks WHERE deleted_at < $1
+		UNION ALL SELECT id FROM tasks WHERE deleted_at < $1
+		UNION ALL SELECT id FROM lists WHERE deleted_at < $1)`
+	if _, err := tx.Exec(ctx, query, before); err != nil {
+		return 0, fmt.Errorf("failed to purge activity: %w", mapError(err))
+	}
+
+	// Children first so that every removed row is counted
+	var purged int64
+	for _, table := range []string{"subtasks", "tasks", "lists"} {
+		result, err := tx.Exec(ctx, `DELETE FROM `+table+` WHERE deleted_at < $1`, before)
+		if err != nil {
+			return 0, fmt.Errorf("failed to purge %s: %w", table, mapError(err))
+		}
+		purged += result.RowsAffected()
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", mapError(err))
+	}
+
+	return purged, nil
+}
+
+// restoreListChildren restores the tasks, and their subtasks, trashed together
+// with a list that is still in the trash
+func restoreListChildren(ctx context.Context, tx pgx.Tx, listID string) error {
+	query := `UPDATE subtasks SET deleted_at = NULL, version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE deleted_at = (SELECT deleted_at FROM lists WHERE id = $1)
+		AND task_id IN (SELECT id FROM tasks WHERE list_id = $1 AND deleted_at = subtasks.deleted_at)`
+	if _, err := tx.Exec(ctx, query, listID); err != nil {
+		return fmt.Errorf("failed to restore subtasks: %w", mapError(err))
+	}
+
+	query = `UPDATE tasks SET deleted_at = NULL, version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE list_id = $1 AND deleted_at = (SELECT deleted_at FROM lists WHERE id = $1)`
+	if _, err := tx.Exec(ctx, query, listID); err != nil {
+		return fmt.Errorf("failed to restore tasks: %w", mapError(err))
+	}
+
+	return nil
+}
+
+// restoreTaskChildren restores the subtasks trashed together with a task that
+// is still in the trash
+func restoreTaskChildren(ctx context.Context, tx pgx.Tx, taskID string) error {
+	query := `UPDATE subtasks SET deleted_at = NULL, version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE task_id = $1 AND deleted_at = (SELECT deleted_at FROM tasks WHERE id = $1)`
+	if _, err := tx.Exec(ctx, query, taskID); err != nil {
+		return fmt.Errorf("failed to restore subtasks: %w", mapError(err))
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	server "github.com/HolySxn/To-Do/internal"
+	"github.com/jackc/pgx/v5"
+)
+
+// Trees are read with a batch, which sends the list, task and subtask queries
+// in one round trip.
+
+func (d *DB) GetListTree(ctx context.Context, listID string) (*server.ListTree, error) {
+	batch := &pgx.Batch{}
+	batch.Queue(`SELECT `+listColumns+` FROM lists WHERE id = $1 AND deleted_at IS NULL`, listID)
+	batch.Queue(`SELECT `+taskColumns+` FROM tasks WHERE list_id = $1 AND deleted_at IS NULL ORDER BY position ASC, created_at DESC`, listID)
+	batch.Queue(`SELECT `+subTaskColumns+` FROM subtasks
+		WHERE task_id IN (SELECT id FROM tasks WHERE list_id = $1) AND deleted_at IS NULL
+		ORDER BY position ASC, created_at DESC`, listID)
+	results := d.Pool.SendBatch(ctx, batch)
+	defer results.Close()
+
+	list, err := scanList(results.QueryRow())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, server.NotFound("list", listID)
+		}
+		return nil, fmt.Errorf("failed to get list: %w", mapError(err))
+	}
+	tasks, subTasks, err := collectTreeRows(results)
+	if err != nil {
+		return nil, err
+	}
+
+	return &server.BuildTrees([]server.List{*list}, tasks, subTasks)[0], nil
+}
+
+func (d *DB) GetWorkspaceTree(ctx context.Context) ([]server.ListTree, error) {
+	// Tasks and subtasks are trashed with their list, so the live ones all
+	// belong to live lists
+	batch := &pgx.Batch{}
+	batch.Queue(`SELECT ` + listColumns + ` FROM lists WHERE deleted_at IS NULL ORDER BY position ASC`)
+	batch.Queue(`SELECT ` + taskColumns + ` FROM tasks WHERE deleted_at IS NULL ORDER BY position ASC, created_at DESC`)
+	batch.Queue(`SELECT ` + subTaskColumns + ` FROM subtasks WHERE deleted_at IS NULL ORDER BY position ASC, created_at DESC`)
+	results := d.Pool.SendBatch(ctx, batch)
+	defer results.Close()
+
+	rows, err := results.Query()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lists: %w", mapError(err))
+	}
+	lists, err := pgx.CollectRows(rows, rowTo(scanList))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lists: %w", mapError(err))
+	}
+	tasks, subTasks, err := collectTreeRows(results)
+	if err != nil {
+		return nil, err
+	}
+
+	return server.BuildTrees(lists, tasks, subTasks), nil
+}
+
+// collectTreeRows reads the task and subtask queries of a tree batch
+func collectTreeRows(results pgx.BatchResults) ([]server.Task, []server.SubTask, error) {
+	rows, err := results.Query()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get tasks: %w", mapError(err))
+	}
+	tasks, err := pgx.CollectRows(rows, rowTo(scanTask))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get tasks: %w", mapError(err))
+	}
+
+	rows, err = results.Query()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get subtasks: %w", mapError(err))
+	}
+	subTasks, err := pgx.CollectRows(rows, rowTo(scanSubTask))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get subtasks: %w", mapError(err))
+	}
+
+	return tasks, subTasks, nil
+}
+
+// rowTo adapts a scan function to pgx.CollectRows
+func rowTo[T any](scan func(pgx.Row) (*T, error)) pgx.RowToFunc[T] {
+	return func(row pgx.CollectableRow) (T, error) {
+		item, err := scan(row)
+		if err != nil {
+			var zero T
+			return zero, err
+		}
+		return *item, nil
+	}
+}
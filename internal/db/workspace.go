@@ -0,0 +1,193 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	server "github.com/HolySxn/To-Do/internal"
+	"github.com/HolySxn/To-Do/internal/query"
+	"github.com/jackc/pgx/v5"
+)
+
+func (d *DB) ExportWorkspace(ctx context.Context) (*server.Workspace, error) {
+	// A repeatable read transaction sees every query at the same snapshot
+	tx, err := d.Pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", mapError(err))
+	}
+	defer tx.Rollback(ctx)
+
+	batch := &pgx.Batch{}
+	batch.Queue(`SELECT ` + listColumns + ` FROM lists WHERE deleted_at IS NULL ORDER BY position ASC`)
+	batch.Queue(`SELECT ` + taskColumns + ` FROM tasks WHERE deleted_at IS NULL ORDER BY position ASC, created_at DESC`)
+	batch.Queue(`SELECT ` + subTaskColumns + ` FROM subtasks WHERE deleted_at IS NULL ORDER BY position ASC, created_at DESC`)
+	batch.Queue(`SELECT ` + tagColumns + ` FROM tags ORDER BY lower(name)`)
+	batch.Queue(`SELECT task_id, tag_id FROM task_tags`)
+	results := tx.SendBatch(ctx, batch)
+	defer results.Close()
+
+	rows, err := results.Query()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lists: %w", mapError(err))
+	}
+	lists, err := pgx.CollectRows(rows, rowTo(scanList))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lists: %w", mapError(err))
+	}
+	tasks, subTasks, err := collectTreeRows(results)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = results.Query()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", mapError(err))
+	}
+	tags, err := pgx.CollectRows(rows, rowTo(scanTag))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", mapError(err))
+	}
+
+	rows, err = results.Query()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task tags: %w", mapError(err))
+	}
+	taskTags, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (server.TaskTag, error) {
+		var taskTag server.TaskTag
+		err := row.Scan(&taskTag.TaskID, &taskTag.TagID)
+		return taskTag, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task tags: %w", mapError(err))
+	}
+
+	return server.BuildWorkspace(lists, tasks, subTasks, tags, taskTags, time.Now()), nil
+}
+
+func (d *DB) ImportWorkspace(ctx context.Context, doc *server.Workspace, mode server.ImportMode, dryRun bool) (*server.ImportReport, error) {
+	tx, err := d.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", mapError(err))
+	}
+	defer tx.Rollback(ctx)
+
+	state, err := importState(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := server.PlanImport(doc, mode, *state, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if dryRun {
+		plan.Report.DryRun = true
+		return &plan.Report, nil
+	}
+
+	// The rows are written with a single batch, clearing the workspace first
+	// when replacing it. Children are removed first and inserted last, so that
+	// every foreign key still holds.
+	batch := &pgx.Batch{}
+	if mode == server.ImportReplace {
+		for _, table := range []string{"activity", "task_tags", "subtasks", "tasks", "lists", "tags"} {
+			batch.Queue(`DELETE FROM ` + table)
+		}
+	}
+	for _, list := range plan.Lists {
+		batch.Queue(`INSERT INTO lists (id, title, query, position, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6)`,
+			list.ID, list.Title, list.Query, list.Position, list.CreatedAt, list.UpdatedAt)
+	}
+	for _, list := range plan.UpdatedLists {
+		batch.Queue(`UPDATE lists SET title = $2, query = $3, version = $4, updated_at = $5 WHERE id = $1`,
+			list.ID, list.Title, list.Query, list.Version, list.UpdatedAt)
+	}
+	for _, task := range plan.Tasks {
+		batch.Queue(`INSERT INTO tasks (id, list_id, task_name, notes, completed, priority, starred, position, due_date, due_at, remind_at, recurrence, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`,
+			task.ID, task.ListID, task.TaskName, task.Notes, task.Completed, string(task.Priority), task.Starred,
+			task.Position, task.DueDate, task.DueAt, task.RemindAt, task.Recurrence, task.CreatedAt, task.UpdatedAt)
+	}
+	for _, task := range plan.UpdatedTasks {
+		batch.Queue(`UPDATE tasks SET task_name = $2, notes = $3, completed = $4, priority = $5, starred = $6, due_date = $7, due_at = $8,
+			remind_at = $9, recurrence = $10, version = $11, updated_at = $12 WHERE id = $1`,
+			task.ID, task.TaskName, task.Notes, task.Completed, string(task.Priority), task.Starred, task.DueDate, task.DueAt,
+			task.RemindAt, task.Recurrence, task.Version, task.UpdatedAt)
+	}
+	for _, subTask := range plan.SubTasks {
+		batch.Queue(`INSERT INTO subtasks (id, task_id, subtask_name, completed, position, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			subTask.ID, subTask.TaskID, subTask.SubTaskName, subTask.Completed, subTask.Position, subTask.CreatedAt, subTask.UpdatedAt)
+	}
+	for _, subTask := range plan.UpdatedSubTasks {
+		batch.Queue(`UPDATE subtasks SET subtask_name = $2, completed = $3, version = $4, updated_at = $5 WHERE id = $1`,
+			subTask.ID, subTask.SubTaskName, subTask.Completed, subTask.Version, subTask.UpdatedAt)
+	}
+	for _, tag := range plan.Tags {
+		batch.Queue(`INSERT INTO tags (id, name, color, created_at, updated_at) VALUES ($1, $2, $3, $4, $5)`,
+			tag.ID, tag.Name, tag.Color, tag.CreatedAt, tag.UpdatedAt)
+	}
+	for _, taskTag := range plan.TaskTags {
+		batch.Queue(`INSERT INTO task_tags (task_id, tag_id) VALUES ($1, $2)`, taskTag.TaskID, taskTag.TagID)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return nil, fmt.Errorf("failed to import workspace: %w", mapError(err))
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", mapError(err))
+	}
+
+	return &plan.Report, nil
+}
+
+// importState reads what planning an import needs to know, with a batch that
+// sends every query in one round trip
+func importState(ctx context.Context, tx pgx.Tx) (*server.ImportState, error) {
+	batch := &pgx.Batch{}
+	batch.Queue(`SELECT ` + listColumns + ` FROM lists`)
+	batch.Queue(`SELECT ` + taskColumns + ` FROM tasks`)
+	batch.Queue(`SELECT ` + subTaskColumns + ` FROM subtasks`)
+	batch.Queue(`SELECT ` + tagColumns + ` FROM tags`)
+	batch.Queue(`SELECT task_id, tag_id FROM task_tags`)
+	results := tx.SendBatch(ctx, batch)
+	defer results.Close()
+
+	state := &server.ImportState{CheckQuery: checkQuery}
+	var err error
+	if state.Lists, err = collectBatch(results, scanList); err != nil {
+		return nil, fmt.Errorf("failed to get lists: %w", mapError(err))
+	}
+	if state.Tasks, err = collectBatch(results, scanTask); err != nil {
+		return nil, fmt.Errorf("failed to get tasks: %w", mapError(err))
+	}
+	if state.SubTasks, err = collectBatch(results, scanSubTask); err != nil {
+		return nil, fmt.Errorf("failed to get subtasks: %w", mapError(err))
+	}
+	if state.Tags, err = collectBatch(results, scanTag); err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", mapError(err))
+	}
+	state.TaskTags, err = collectBatch(results, func(row pgx.Row) (*server.TaskTag, error) {
+		var taskTag server.TaskTag
+		err := row.Scan(&taskTag.TaskID, &taskTag.TagID)
+		return &taskTag, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task tags: %w", mapError(err))
+	}
+	return state, nil
+}
+
+// checkQuery is the ImportState.CheckQuery of the store
+func checkQuery(q string) error {
+	_, err := query.Parse(q, time.Now())
+	return err
+}
+
+// collectBatch scans every row of the next query of a batch
+func collectBatch[T any](results pgx.BatchResults, scan func(pgx.Row) (*T, error)) ([]T, error) {
+	rows, err := results.Query()
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, rowTo(scan))
+}
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"sort"
+	"time"
+)
+
+// DateLayout is the format of Task.DueDate
+const DateLayout = "2006-01-02"
+
+// DateOf returns the calendar date of t in its own location
+func DateOf(t time.Time) string {
+	return t.Format(DateLayout)
+}
+
+// DueDateRange returns the inclusive range of calendar dates that overlap [from, to),
+// evaluated in from's location
+func DueDateRange(from, to time.Time) (first, last string) {
+	return DateOf(from), DateOf(to.In(from.Location()).Add(-time.Nanosecond))
+}
+
+// DueTime returns the moment a task is due, treating a DueDate as midnight in loc.
+// ok is false for tasks without a due date.
+func (t Task) DueTime(loc *time.Location) (due time.Time, ok bool) {
+	if t.DueAt != nil {
+		return *t.DueAt, true
+	}
+	if t.DueDate != nil {
+		day, err := time.ParseInLocation(DateLayout, *t.DueDate, loc)
+		return day, err == nil
+	}
+	return time.Time{}, false
+}
+
+// SortByDue orders tasks by when they are due, then by position
+func SortByDue(tasks []Task, loc *time.Location) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		a, _ := tasks[i].DueTime(loc)
+		b, _ := tasks[j].DueTime(loc)
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		return tasks[i].Position < tasks[j].Position
+	})
+}
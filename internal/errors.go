@@ -0,0 +1,162 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Every store error that callers may want to act on matches one of these with errors.Is
+var (
+	// ErrNotFound means the requested row, or a row it refers to, does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidInput means the arguments were rejected before or by the database
+	ErrInvalidInput = errors.New("invalid input")
+	// ErrConflict is matched when a write was rejected because the row changed
+	// since the version the caller expected
+	ErrConflict = errors.New("conflict")
+	// ErrUnavailable means the database could not be reached or is busy; the
+	// call may succeed if retried
+	ErrUnavailable = errors.New("unavailable")
+)
+
+// Error codes sent to the frontend
+const (
+	CodeNotFound     = "not_found"
+	CodeInvalidInput = "invalid_input"
+	CodeConflict     = "conflict"
+	CodeUnavailable  = "unavailable"
+	CodeInternal     = "internal"
+)
+
+// NotFoundError reports a missing list, task or subtask
+type NotFoundError struct {
+	Entity string `json:"entity"`
+	ID     string `json:"id"`
+}
+
+// NotFound returns the error for a missing entity, such as NotFound("task", id)
+func NotFound(entity, id string) error {
+	return &NotFoundError{Entity: entity, ID: id}
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%s with id %s not found", e.Entity, e.ID)
+}
+
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// SmartListTasks returns the error for putting a task in a smart list, which
+// cannot hold tasks of its own
+func SmartListTasks(listID string) error {
+	return InvalidInput("list %s is a smart list and cannot hold tasks", listID)
+}
+
+// NotSmartList returns the error for setting the query of a list that is not a smart list
+func NotSmartList(listID string) error {
+	return InvalidInput("list %s is not a smart list", listID)
+}
+
+// ConflictError reports a version mismatch together with the row as currently stored
+type ConflictError struct {
+	Entity  string `json:"entity"`
+	ID      string `json:"id"`
+	Version int64  `json:"version"`
+	// Current is the stored *List, *Task or *SubTask
+	Current any `json:"current"`
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s with id %s has changed since version %d", e.Entity, e.ID, e.Version)
+}
+
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// FieldError describes why one argument was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError collects the rejected arguments of a call
+type ValidationError struct {
+	Fields []FieldError `json:"fields"`
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		parts[i] = f.Field + " " + f.Message
+	}
+	return "invalid input: " + strings.Join(parts, "; ")
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalidInput
+}
+
+// InvalidInput formats an error that matches ErrInvalidInput
+func InvalidInput(format string, args ...any) error {
+	return Mark(fmt.Errorf(format, args...), ErrInvalidInput)
+}
+
+// Mark makes err match kind, one of the sentinel errors, without changing its
+// message. Stores use it to classify driver errors.
+func Mark(err, kind error) error {
+	if err == nil {
+		return nil
+	}
+	return &markedError{err: err, kind: kind}
+}
+
+type markedError struct {
+	err  error
+	kind error
+}
+
+func (e *markedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *markedError) Unwrap() []error {
+	return []error{e.err, e.kind}
+}
+
+// ErrorInfo is the structured form of an error returned to the frontend
+type ErrorInfo struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details any    `json:"details,omitempty"`
+}
+
+// Describe classifies err by the sentinel it matches. Details hold the
+// *NotFoundError, *ConflictError or *ValidationError when there is one.
+func Describe(err error) ErrorInfo {
+	info := ErrorInfo{Code: CodeInternal, Message: err.Error()}
+
+	var notFound *NotFoundError
+	var conflict *ConflictError
+	var validation *ValidationError
+	switch {
+	case errors.As(err, &validation):
+		info.Code, info.Details = CodeInvalidInput, validation
+	case errors.As(err, &conflict):
+		info.Code, info.Details = CodeConflict, conflict
+	case errors.As(err, &notFound):
+		info.Code, info.Details = CodeNotFound, notFound
+	case errors.Is(err, ErrNotFound):
+		info.Code = CodeNotFound
+	case errors.Is(err, ErrInvalidInput):
+		info.Code = CodeInvalidInput
+	case errors.Is(err, ErrConflict):
+		info.Code = CodeConflict
+	case errors.Is(err, ErrUnavailable):
+		info.Code = CodeUnavailable
+	}
+
+	return info
+}
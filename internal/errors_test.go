@@ -0,0 +1,70 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestDescribe(t *testing.T) {
+	notFound := NotFound("task", "id")
+	conflict := &ConflictError{Entity: "list", ID: "id", Version: 2}
+	validation := &ValidationError{Fields: []FieldError{{Field: "title", Message: "must not be empty"}}}
+
+	tests := []struct {
+		name    string
+		err     error
+		code    string
+		details any
+	}{
+		{"not found", notFound, CodeNotFound, notFound},
+		{"wrapped not found", fmt.Errorf("failed to get task: %w", notFound), CodeNotFound, notFound},
+		{"conflict", conflict, CodeConflict, conflict},
+		{"validation", validation, CodeInvalidInput, validation},
+		{"invalid input", InvalidInput("bad %s", "rule"), CodeInvalidInput, nil},
+		{"marked", Mark(errors.New("busy"), ErrUnavailable), CodeUnavailable, nil},
+		{"marked conflict", Mark(errors.New("duplicate key"), ErrConflict), CodeConflict, nil},
+		{"plain", errors.New("boom"), CodeInternal, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := Describe(tt.err)
+			if info.Code != tt.code {
+				t.Errorf("Code = %q, want %q", info.Code, tt.code)
+			}
+			if info.Message != tt.err.Error() {
+				t.Errorf("Message = %q, want %q", info.Message, tt.err.Error())
+			}
+			if info.Details != tt.details {
+				t.Errorf("Details = %v, want %v", info.Details, tt.details)
+			}
+		})
+	}
+}
+
+func TestMark(t *testing.T) {
+	cause := errors.New("constraint failed")
+	err := Mark(cause, ErrInvalidInput)
+	if !errors.Is(err, ErrInvalidInput) || !errors.Is(err, cause) {
+		t.Errorf("Mark() = %v, want it to match both the cause and the kind", err)
+	}
+	if err.Error() != cause.Error() {
+		t.Errorf("Error() = %q, want %q", err.Error(), cause.Error())
+	}
+	if Mark(nil, ErrInvalidInput) != nil {
+		t.Error("Mark(nil) is not nil")
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	err := &ValidationError{Fields: []FieldError{
+		{Field: "title", Message: "must not be empty"},
+		{Field: "id", Message: "must be a UUID"},
+	}}
+	if want := "invalid input: title must not be empty; id must be a UUID"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+	if !errors.Is(err, ErrInvalidInput) || errors.Is(err, ErrNotFound) {
+		t.Error("ValidationError matches the wrong sentinel")
+	}
+}
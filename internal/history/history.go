@@ -0,0 +1,152 @@
+// Package history keeps a bounded undo/redo stack of changes made during a
+// session.
+//
+// Every change is recorded as a pair of operations, one reverting it and one
+// applying it again:
+//
+//	h.Record("Rename list", func(ctx context.Context) error {
+//		_, err := store.UpdateList(ctx, id, oldTitle, 0)
+//		return err
+//	}, func(ctx context.Context) error {
+//		_, err := store.UpdateList(ctx, id, newTitle, 0)
+//		return err
+//	})
+package history
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+// Undo and Redo fail with these when there is nothing to undo or redo
+var (
+	ErrNothingToUndo = server.Mark(errors.New("nothing to undo"), server.ErrNotFound)
+	ErrNothingToRedo = server.Mark(errors.New("nothing to redo"), server.ErrNotFound)
+)
+
+// Op applies one direction of a recorded change
+type Op func(ctx context.Context) error
+
+// Entry describes a recorded change
+type Entry struct {
+	ID    int64     `json:"id"`
+	Label string    `json:"label"`
+	At    time.Time `json:"at"`
+}
+
+// Snapshot lists the changes that can be undone and redone, most recent first
+type Snapshot struct {
+	Undo []Entry `json:"undo"`
+	Redo []Entry `json:"redo"`
+}
+
+type command struct {
+	Entry
+	undo Op
+	redo Op
+}
+
+// History is safe for concurrent use
+type History struct {
+	mu     sync.Mutex
+	limit  int
+	nextID int64
+	done   []command
+	undone []command
+}
+
+// New returns an empty history that keeps at most limit changes
+func New(limit int) *History {
+	return &History{limit: limit}
+}
+
+// Record adds a change that has just been made. It clears the redo stack and,
+// once the history is full, forgets the oldest change.
+func (h *History) Record(label string, undo, redo Op) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.limit <= 0 {
+		return
+	}
+	h.nextID++
+	h.done = append(h.done, command{
+		Entry: Entry{ID: h.nextID, Label: label, At: time.Now()},
+		undo:  undo,
+		redo:  redo,
+	})
+	if len(h.done) > h.limit {
+		h.done = h.done[len(h.done)-h.limit:]
+	}
+	h.undone = nil
+}
+
+// Undo reverts the most recent change and moves it to the redo stack. A change
+// that cannot be reverted, for example because a row it touches is gone, is
+// dropped so that the next call moves on to the one before it.
+func (h *History) Undo(ctx context.Context) (*Entry, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.done) == 0 {
+		return nil, ErrNothingToUndo
+	}
+	cmd := h.done[len(h.done)-1]
+	h.done = h.done[:len(h.done)-1]
+	if err := cmd.undo(ctx); err != nil {
+		return nil, fmt.Errorf("failed to undo %q: %w", cmd.Label, err)
+	}
+	h.undone = append(h.undone, cmd)
+
+	return &cmd.Entry, nil
+}
+
+// Redo applies the most recently undone change again. Like Undo, it drops a
+// change that cannot be applied.
+func (h *History) Redo(ctx context.Context) (*Entry, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.undone) == 0 {
+		return nil, ErrNothingToRedo
+	}
+	cmd := h.undone[len(h.undone)-1]
+	h.undone = h.undone[:len(h.undone)-1]
+	if err := cmd.redo(ctx); err != nil {
+		return nil, fmt.Errorf("failed to redo %q: %w", cmd.Label, err)
+	}
+	h.done = append(h.done, cmd)
+
+	return &cmd.Entry, nil
+}
+
+// Clear forgets every change, for when the rows they touch have been replaced
+func (h *History) Clear() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.done = nil
+	h.undone = nil
+}
+
+// Snapshot returns the current undo and redo stacks
+func (h *History) Snapshot() Snapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return Snapshot{Undo: entries(h.done), Redo: entries(h.undone)}
+}
+
+// entries returns the entries of a stack, top first
+func entries(stack []command) []Entry {
+	result := make([]Entry, 0, len(stack))
+	for i := len(stack) - 1; i >= 0; i-- {
+		result = append(result, stack[i].Entry)
+	}
+	return result
+}
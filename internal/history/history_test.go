@@ -0,0 +1,125 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+// counter records changes to a single value
+type counter struct {
+	value int
+}
+
+func (c *counter) add(h *History, label string, n int) {
+	c.value += n
+	h.Record(label, func(ctx context.Context) error {
+		c.value -= n
+		return nil
+	}, func(ctx context.Context) error {
+		c.value += n
+		return nil
+	})
+}
+
+func labels(entries []Entry) []string {
+	result := make([]string, len(entries))
+	for i, entry := range entries {
+		result[i] = entry.Label
+	}
+	return result
+}
+
+func TestUndoRedo(t *testing.T) {
+	ctx := context.Background()
+	h := New(10)
+	var c counter
+	c.add(h, "one", 1)
+	c.add(h, "two", 2)
+
+	entry, err := h.Undo(ctx)
+	if err != nil || entry.Label != "two" || c.value != 1 {
+		t.Fatalf("Undo() = %v, %v with value %d; want two undone", entry, err, c.value)
+	}
+	entry, err = h.Redo(ctx)
+	if err != nil || entry.Label != "two" || c.value != 3 {
+		t.Fatalf("Redo() = %v, %v with value %d; want two redone", entry, err, c.value)
+	}
+	if _, err := h.Redo(ctx); !errors.Is(err, ErrNothingToRedo) || !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("Redo() with nothing undone: error = %v", err)
+	}
+
+	// A new change clears what could be redone
+	h.Undo(ctx)
+	c.add(h, "three", 3)
+	snapshot := h.Snapshot()
+	if got := labels(snapshot.Undo); len(got) != 2 || got[0] != "three" || got[1] != "one" {
+		t.Errorf("Undo stack = %q, want [three one]", got)
+	}
+	if len(snapshot.Redo) != 0 {
+		t.Errorf("Redo stack = %q, want it empty", labels(snapshot.Redo))
+	}
+
+	h.Undo(ctx)
+	h.Undo(ctx)
+	if _, err := h.Undo(ctx); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("Undo() with nothing done: error = %v", err)
+	}
+	if c.value != 0 {
+		t.Errorf("value = %d after undoing everything, want 0", c.value)
+	}
+}
+
+func TestLimit(t *testing.T) {
+	h := New(2)
+	var c counter
+	for _, label := range []string{"one", "two", "three"} {
+		c.add(h, label, 1)
+	}
+	if got := labels(h.Snapshot().Undo); len(got) != 2 || got[1] != "two" {
+		t.Errorf("Undo stack = %q, want the last two changes", got)
+	}
+
+	off := New(0)
+	c.add(off, "one", 1)
+	if got := off.Snapshot().Undo; len(got) != 0 {
+		t.Errorf("history with limit 0 recorded %q", labels(got))
+	}
+}
+
+func TestFailedUndoIsDropped(t *testing.T) {
+	ctx := context.Background()
+	h := New(10)
+	var c counter
+	c.add(h, "one", 1)
+	conflict := &server.ConflictError{Entity: "list", ID: "id", Version: 3}
+	h.Record("broken", func(ctx context.Context) error { return conflict }, func(ctx context.Context) error { return nil })
+
+	_, err := h.Undo(ctx)
+	var got *server.ConflictError
+	if !errors.As(err, &got) || got != conflict {
+		t.Fatalf("Undo() error = %v, want the conflict", err)
+	}
+	entry, err := h.Undo(ctx)
+	if err != nil || entry.Label != "one" {
+		t.Errorf("Undo() after a failure = %v, %v; want the change before it", entry, err)
+	}
+	if redo := labels(h.Snapshot().Redo); len(redo) != 1 || redo[0] != "one" {
+		t.Errorf("Redo stack = %q, want only one", redo)
+	}
+}
+
+func TestClear(t *testing.T) {
+	ctx := context.Background()
+	h := New(10)
+	var c counter
+	c.add(h, "one", 1)
+	c.add(h, "two", 1)
+	h.Undo(ctx)
+	h.Clear()
+	if snapshot := h.Snapshot(); len(snapshot.Undo) != 0 || len(snapshot.Redo) != 0 {
+		t.Errorf("Snapshot() after Clear() = %+v, want it empty", snapshot)
+	}
+}
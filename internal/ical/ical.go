@@ -0,0 +1,628 @@
+// Package ical converts tasks to and from iCalendar (RFC 5545) VTODO
+// components.
+//
+// Render writes a single VCALENDAR holding a VTODO for each task and subtask.
+// A VTODO's UID is the id of its task, tasks name their list with
+// X-WR-CALNAME and subtasks refer to their task with RELATED-TO. Parse reads
+// the VTODOs of any number of calendars, including those written by other
+// clients; other components, such as events and time zones, are skipped.
+package ical
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	server "github.com/HolySxn/To-Do/internal"
+	"github.com/google/uuid"
+)
+
+// ProdID identifies this application as the producer of written calendars
+const ProdID = "-//HolySxn//To-Do//EN"
+
+// Date and date-time value layouts
+const (
+	dateLayout     = "20060102"
+	dateTimeLayout = "20060102T150405"
+	utcLayout      = "20060102T150405Z"
+)
+
+// maxLineLength is the length in octets after which content lines are folded
+const maxLineLength = 75
+
+// Render writes the lists of a workspace document as one calendar, named
+// after the list with X-WR-CALNAME when there is only one. Smart lists, which
+// have no tasks of their own, are left out. DTSTAMP is the time of the export.
+func Render(doc *server.Workspace) string {
+	w := &writer{}
+	stamp := doc.ExportedAt.UTC().Format(utcLayout)
+	var lists []server.WorkspaceList
+	for _, list := range doc.Lists {
+		if list.Query == nil {
+			lists = append(lists, list)
+		}
+	}
+
+	w.line("BEGIN:VCALENDAR")
+	w.line("VERSION:2.0")
+	w.line("PRODID:" + ProdID)
+	if len(lists) == 1 {
+		w.line("X-WR-CALNAME:" + escape(lists[0].Title))
+	}
+	for _, list := range lists {
+		for _, task := range list.Tasks {
+			w.task(list.Title, task, stamp)
+			for _, subTask := range task.SubTasks {
+				w.line("BEGIN:VTODO")
+				w.line("UID:" + subTask.ID)
+				w.line("DTSTAMP:" + stamp)
+				w.times(subTask.CreatedAt, subTask.UpdatedAt)
+				w.line("SUMMARY:" + escape(subTask.SubTaskName))
+				w.status(subTask.Completed, subTask.UpdatedAt)
+				w.line("RELATED-TO;RELTYPE=PARENT:" + task.ID)
+				w.line("END:VTODO")
+			}
+		}
+	}
+	w.line("END:VCALENDAR")
+	return w.String()
+}
+
+// priorities maps priorities to the PRIORITY values that stand for them
+var priorities = map[server.Priority]int{
+	server.PriorityHigh:   1,
+	server.PriorityMedium: 5,
+	server.PriorityLow:    9,
+}
+
+type writer struct {
+	strings.Builder
+}
+
+// line writes a content line, folding it after maxLineLength octets without
+// splitting a character
+func (w *writer) line(s string) {
+	limit := maxLineLength
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.WriteString(s[:cut])
+		w.WriteString("\r\n ")
+		s = s[cut:]
+		// The space that starts a continuation line counts towards its length
+		limit = maxLineLength - 1
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
+
+// task writes the VTODO of a task, with the title of its list as an
+// X-WR-CALNAME of its own
+func (w *writer) task(list string, task server.WorkspaceTask, stamp string) {
+	w.line("BEGIN:VTODO")
+	w.line("UID:" + task.ID)
+	w.line("DTSTAMP:" + stamp)
+	w.times(task.CreatedAt, task.UpdatedAt)
+	w.line("SUMMARY:" + escape(task.TaskName))
+	w.line("X-WR-CALNAME:" + escape(list))
+	if task.Notes != "" {
+		w.line("DESCRIPTION:" + escape(task.Notes))
+	}
+	w.status(task.Completed, task.UpdatedAt)
+	if priority, ok := priorities[task.Priority]; ok {
+		w.line("PRIORITY:" + strconv.Itoa(priority))
+	}
+
+	due := ""
+	switch {
+	case task.DueDate != nil:
+		due = ";VALUE=DATE:" + strings.ReplaceAll(*task.DueDate, "-", "")
+	case task.DueAt != nil:
+		due = ":" + task.DueAt.UTC().Format(utcLayout)
+	}
+	if due != "" {
+		w.line("DUE" + due)
+		// A recurrence rule counts from DTSTART, so it is only written for
+		// tasks with a due date to start from
+		if task.Recurrence != nil {
+			w.line("DTSTART" + due)
+			w.line("RRULE:" + *task.Recurrence)
+		}
+	}
+
+	if len(task.Tags) > 0 {
+		categories := make([]string, len(task.Tags))
+		for i, tag := range task.Tags {
+			categories[i] = escape(tag)
+		}
+		w.line("CATEGORIES:" + strings.Join(categories, ","))
+	}
+	if task.RemindAt != nil {
+		w.line("BEGIN:VALARM")
+		w.line("ACTION:DISPLAY")
+		w.line("DESCRIPTION:" + escape(task.TaskName))
+		w.line("TRIGGER;VALUE=DATE-TIME:" + task.RemindAt.UTC().Format(utcLayout))
+		w.line("END:VALARM")
+	}
+	w.line("END:VTODO")
+}
+
+func (w *writer) times(created, updated time.Time) {
+	w.line("CREATED:" + created.UTC().Format(utcLayout))
+	w.line("LAST-MODIFIED:" + updated.UTC().Format(utcLayout))
+}
+
+func (w *writer) status(completed bool, updated time.Time) {
+	if completed {
+		w.line("STATUS:COMPLETED")
+		w.line("COMPLETED:" + updated.UTC().Format(utcLayout))
+	} else {
+		w.line("STATUS:NEEDS-ACTION")
+	}
+}
+
+// escape escapes a TEXT value
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// unescape reverses escape
+func unescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// splitList splits a TEXT list such as CATEGORIES at unescaped commas
+func splitList(s string) []string {
+	var values []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case ',':
+			values = append(values, unescape(s[start:i]))
+			start = i + 1
+		}
+	}
+	return append(values, unescape(s[start:]))
+}
+
+// Options tell Parse where tasks go
+type Options struct {
+	// ListID is the existing list that tasks without a list name, of their
+	// own or of their calendar, are appended to; without one such tasks are
+	// rejected
+	ListID string
+	// Lists are the existing lists; tasks with the name of one of them,
+	// regardless of case, are appended to it instead of to a new list
+	Lists []server.List
+	// Location is the time zone of floating times, which have no time zone of
+	// their own
+	Location *time.Location
+}
+
+// property is a content line
+type property struct {
+	number int
+	name   string
+	params map[string]string
+	value  string
+}
+
+func (p *property) param(name string) string {
+	return p.params[name]
+}
+
+// component is a VCALENDAR, VTODO or VALARM with the properties Parse reads
+// and its VTODOs or VALARMs
+type component struct {
+	name       string
+	number     int
+	properties map[string][]*property
+	children   []*component
+}
+
+func (c *component) get(name string) *property {
+	if values := c.properties[name]; len(values) > 0 {
+		return values[0]
+	}
+	return nil
+}
+
+// todo is a parsed VTODO before subtasks are attached to their parents;
+// calendar is the name of the list it goes to
+type todo struct {
+	number   int
+	uid      string
+	parent   string
+	calendar string
+	task     server.WorkspaceTask
+}
+
+// Parse reads the VTODOs of an iCalendar stream into a workspace document to
+// import by merging. Tasks go to lists named after their own X-WR-CALNAME or,
+// without one, that of their calendar; a VTODO related to another one as its child becomes a subtask
+// of it, and children of subtasks become subtasks of the same task. Properties
+// without a counterpart, such as recurrence rules beyond what tasks support,
+// are dropped. Errors match server.ErrInvalidInput and give the number of the
+// offending line.
+func Parse(text string, opts Options) (*server.Workspace, error) {
+	if opts.Location == nil {
+		opts.Location = time.Local
+	}
+	calendars, err := parseComponents(text)
+	if err != nil {
+		return nil, err
+	}
+
+	var todos []*todo
+	uids := make(map[string]*todo)
+	for _, calendar := range calendars {
+		name := ""
+		if p := calendar.get("X-WR-CALNAME"); p != nil {
+			name = strings.TrimSpace(unescape(p.value))
+		}
+		for _, c := range calendar.children {
+			parsed, err := parseTodo(c, opts.Location)
+			if err != nil {
+				return nil, err
+			}
+			if parsed.calendar == "" {
+				parsed.calendar = name
+			}
+			if parsed.uid != "" {
+				if _, ok := uids[parsed.uid]; ok {
+					return nil, server.InvalidInput("line %d: UID %s is used by an earlier VTODO", parsed.number, parsed.uid)
+				}
+				uids[parsed.uid] = parsed
+			}
+			todos = append(todos, parsed)
+		}
+	}
+	if len(todos) == 0 {
+		return nil, server.InvalidInput("no VTODO components found")
+	}
+
+	// Children whose parent is not in the stream are imported as tasks
+	top := make(map[*todo]*todo)
+	for _, parsed := range todos {
+		root := parsed
+		seen := map[*todo]bool{parsed: true}
+		for root.parent != "" && uids[root.parent] != nil {
+			root = uids[root.parent]
+			if seen[root] {
+				return nil, server.InvalidInput("line %d: the RELATED-TO parents of this VTODO form a cycle", parsed.number)
+			}
+			seen[root] = true
+		}
+		top[parsed] = root
+	}
+
+	lists := make(map[string]string)
+	for _, list := range opts.Lists {
+		if list.Query == nil {
+			lists[strings.ToLower(list.Title)] = list.ID
+		}
+	}
+	doc := &server.Workspace{Version: server.WorkspaceVersion}
+	listIndex := make(map[string]int)
+	taskIndex := make(map[*todo][2]int)
+	for _, parsed := range todos {
+		if top[parsed] != parsed {
+			continue
+		}
+		key := strings.ToLower(parsed.calendar)
+		if _, ok := listIndex[key]; !ok {
+			list := server.WorkspaceList{Title: parsed.calendar}
+			switch id, ok := lists[key]; {
+			case parsed.calendar == "" && opts.ListID == "":
+				return nil, server.InvalidInput("line %d: tasks without an X-WR-CALNAME need a list to be added to", parsed.number)
+			case parsed.calendar == "":
+				list = server.WorkspaceList{ID: opts.ListID, Append: true}
+			case ok:
+				list = server.WorkspaceList{ID: id, Append: true}
+			}
+			listIndex[key] = len(doc.Lists)
+			doc.Lists = append(doc.Lists, list)
+		}
+		list := &doc.Lists[listIndex[key]]
+		taskIndex[parsed] = [2]int{listIndex[key], len(list.Tasks)}
+		list.Tasks = append(list.Tasks, parsed.task)
+	}
+	for _, parsed := range todos {
+		root := top[parsed]
+		if root == parsed {
+			continue
+		}
+		at := taskIndex[root]
+		task := &doc.Lists[at[0]].Tasks[at[1]]
+		task.SubTasks = append(task.SubTasks, server.WorkspaceSubTask{
+			ID:          parsed.task.ID,
+			SubTaskName: parsed.task.TaskName,
+			Completed:   parsed.task.Completed,
+			CreatedAt:   parsed.task.CreatedAt,
+			UpdatedAt:   parsed.task.UpdatedAt,
+		})
+	}
+	return doc, nil
+}
+
+// parseComponents returns the calendars of a stream
+func parseComponents(text string) ([]*component, error) {
+	var calendars []*component
+	var stack []*component
+	// skipped holds the names of the components being skipped, such as a
+	// VEVENT and the VALARMs inside it
+	var skipped []string
+
+	for _, p := range unfold(text) {
+		if len(skipped) > 0 {
+			switch p.name {
+			case "BEGIN":
+				skipped = append(skipped, strings.ToUpper(p.value))
+			case "END":
+				if !strings.EqualFold(p.value, skipped[len(skipped)-1]) {
+					return nil, server.InvalidInput("line %d: END:%s does not close BEGIN:%s", p.number, p.value, skipped[len(skipped)-1])
+				}
+				skipped = skipped[:len(skipped)-1]
+			}
+			continue
+		}
+
+		switch p.name {
+		case "BEGIN":
+			name := strings.ToUpper(p.value)
+			c := &component{name: name, number: p.number, properties: make(map[string][]*property)}
+			switch {
+			case len(stack) == 0 && name == "VCALENDAR":
+				calendars = append(calendars, c)
+			case len(stack) == 1 && name == "VTODO", len(stack) == 2 && name == "VALARM":
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, c)
+			case len(stack) == 0:
+				return nil, server.InvalidInput("line %d: BEGIN:%s outside of a VCALENDAR", p.number, p.value)
+			default:
+				skipped = append(skipped, name)
+				continue
+			}
+			stack = append(stack, c)
+		case "END":
+			if len(stack) == 0 || !strings.EqualFold(p.value, stack[len(stack)-1].name) {
+				return nil, server.InvalidInput("line %d: END:%s without a matching BEGIN", p.number, p.value)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) == 0 {
+				return nil, server.InvalidInput("line %d: %s outside of a VCALENDAR", p.number, p.name)
+			}
+			c := stack[len(stack)-1]
+			c.properties[p.name] = append(c.properties[p.name], p)
+		}
+	}
+	if len(stack) > 0 {
+		c := stack[len(stack)-1]
+		return nil, server.InvalidInput("line %d: BEGIN:%s is never closed", c.number, c.name)
+	}
+	if len(calendars) == 0 {
+		return nil, server.InvalidInput("no VCALENDAR found")
+	}
+	return calendars, nil
+}
+
+// unfold joins folded lines and parses them into properties numbered after
+// the line they start on. Blank lines are skipped.
+func unfold(text string) []*property {
+	var properties []*property
+	var current *strings.Builder
+	number := 0
+	flush := func() {
+		if current != nil {
+			properties = append(properties, parseProperty(current.String(), number))
+			current = nil
+		}
+	}
+	for i, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if current != nil && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			current.WriteString(line[1:])
+			continue
+		}
+		flush()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		current, number = &strings.Builder{}, i+1
+		current.WriteString(line)
+	}
+	flush()
+	return properties
+}
+
+// parseProperty parses a content line, name *(";" param) ":" value. A line
+// without a colon is read as a name without a value.
+func parseProperty(line string, number int) *property {
+	p := &property{number: number, params: make(map[string]string)}
+	quoted := false
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		if line[i] == '"' {
+			quoted = !quoted
+		} else if line[i] == ':' && !quoted {
+			end = i
+			break
+		}
+	}
+	if end < len(line) {
+		p.value = line[end+1:]
+	}
+
+	parts := strings.Split(line[:end], ";")
+	p.name = strings.ToUpper(strings.TrimSpace(parts[0]))
+	for _, param := range parts[1:] {
+		name, value, _ := strings.Cut(param, "=")
+		p.params[strings.ToUpper(name)] = strings.Trim(value, `"`)
+	}
+	return p
+}
+
+// parseTodo reads the task of a VTODO; its parent is the UID of the VTODO it
+// is the child of, if any
+func parseTodo(c *component, loc *time.Location) (*todo, error) {
+	parsed := &todo{number: c.number}
+	task := &parsed.task
+
+	if p := c.get("UID"); p != nil {
+		parsed.uid = strings.TrimSpace(p.value)
+		// UIDs from other clients are rarely UUIDs; those tasks get new ids
+		if id, err := uuid.Parse(parsed.uid); err == nil {
+			task.ID = id.String()
+		}
+	}
+	if p := c.get("SUMMARY"); p != nil {
+		task.TaskName = strings.TrimSpace(unescape(p.value))
+	}
+	if task.TaskName == "" {
+		return nil, server.InvalidInput("line %d: VTODO has no SUMMARY", c.number)
+	}
+	if p := c.get("DESCRIPTION"); p != nil {
+		task.Notes = unescape(p.value)
+	}
+	if p := c.get("X-WR-CALNAME"); p != nil {
+		parsed.calendar = strings.TrimSpace(unescape(p.value))
+	}
+	for _, p := range c.properties["RELATED-TO"] {
+		if reltype := strings.ToUpper(p.param("RELTYPE")); reltype == "" || reltype == "PARENT" {
+			parsed.parent = strings.TrimSpace(p.value)
+		}
+	}
+
+	if p := c.get("STATUS"); p != nil && strings.EqualFold(p.value, "COMPLETED") {
+		task.Completed = true
+	}
+	if p := c.get("PERCENT-COMPLETE"); p != nil && strings.TrimSpace(p.value) == "100" {
+		task.Completed = true
+	}
+	if c.get("COMPLETED") != nil {
+		task.Completed = true
+	}
+
+	if p := c.get("PRIORITY"); p != nil {
+		priority, err := strconv.Atoi(strings.TrimSpace(p.value))
+		if err != nil || priority < 0 || priority > 9 {
+			return nil, server.InvalidInput("line %d: PRIORITY must be a number from 0 to 9, got %q", p.number, p.value)
+		}
+		switch {
+		case priority == 0:
+		case priority < 5:
+			task.Priority = server.PriorityHigh
+		case priority == 5:
+			task.Priority = server.PriorityMedium
+		default:
+			task.Priority = server.PriorityLow
+		}
+	}
+
+	var err error
+	if task.CreatedAt, _, err = dateTime(c.get("CREATED"), loc); err != nil {
+		return nil, err
+	}
+	for _, name := range []string{"LAST-MODIFIED", "COMPLETED"} {
+		if !task.UpdatedAt.IsZero() {
+			break
+		}
+		if task.UpdatedAt, _, err = dateTime(c.get(name), loc); err != nil {
+			return nil, err
+		}
+	}
+
+	due, dateOnly, err := dateTime(c.get("DUE"), loc)
+	if err != nil {
+		return nil, err
+	}
+	if dateOnly {
+		date := due.Format(server.DateLayout)
+		task.DueDate = &date
+	} else if !due.IsZero() {
+		task.DueAt = &due
+	}
+	if p := c.get("RRULE"); p != nil {
+		if rule, err := server.ParseRecurrence(p.value); err == nil {
+			canonical := rule.String()
+			task.Recurrence = &canonical
+		}
+	}
+
+	for _, p := range c.properties["CATEGORIES"] {
+		for _, name := range splitList(p.value) {
+			if name = strings.TrimSpace(name); name != "" {
+				task.Tags = append(task.Tags, name)
+			}
+		}
+	}
+	if task.Tags == nil {
+		task.Tags = []string{}
+	}
+
+	// Only reminders at a fixed time are kept; the first one wins
+	for _, alarm := range c.children {
+		p := alarm.get("TRIGGER")
+		if p == nil || !strings.EqualFold(p.param("VALUE"), "DATE-TIME") {
+			continue
+		}
+		remind, _, err := dateTime(p, loc)
+		if err != nil {
+			return nil, err
+		}
+		task.RemindAt = &remind
+		break
+	}
+	return parsed, nil
+}
+
+// dateTime parses a DATE or DATE-TIME value, reporting whether it is a date.
+// A missing property gives the zero time.
+func dateTime(p *property, loc *time.Location) (time.Time, bool, error) {
+	if p == nil {
+		return time.Time{}, false, nil
+	}
+	value := strings.TrimSpace(p.value)
+	if tzid := p.param("TZID"); tzid != "" {
+		if zone, err := time.LoadLocation(tzid); err == nil {
+			loc = zone
+		}
+	}
+
+	var t time.Time
+	var err error
+	switch {
+	case strings.EqualFold(p.param("VALUE"), "DATE") || len(value) == len(dateLayout):
+		t, err = time.ParseInLocation(dateLayout, value, time.UTC)
+		if err == nil {
+			return t, true, nil
+		}
+	case strings.HasSuffix(value, "Z"):
+		t, err = time.Parse(utcLayout, value)
+	default:
+		t, err = time.ParseInLocation(dateTimeLayout, value, loc)
+	}
+	if err != nil {
+		return time.Time{}, false, server.InvalidInput("line %d: %s must be a date or date-time such as 20240501T090000Z, got %q", p.number, p.name, value)
+	}
+	return t, false, nil
+}
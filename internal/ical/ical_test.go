@@ -0,0 +1,147 @@
+package ical
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+func TestRender(t *testing.T) {
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	query := "priority:high"
+	doc := &server.Workspace{Version: 1, ExportedAt: now, Lists: []server.WorkspaceList{
+		{Title: "Home", Tasks: []server.WorkspaceTask{
+			{ID: "4f2d3e5f-6071-4f0a-9a9e-1c8f8f6e0d1c", TaskName: "Laundry", CreatedAt: now, UpdatedAt: now,
+				SubTasks: []server.WorkspaceSubTask{{ID: "5f2d3e5f-6071-4f0a-9a9e-1c8f8f6e0d1c", SubTaskName: "Fold", Completed: true, CreatedAt: now, UpdatedAt: now}}},
+		}},
+		{Title: "Urgent", Query: &query},
+		{Title: "Work", Tasks: []server.WorkspaceTask{
+			{ID: "6f2d3e5f-6071-4f0a-9a9e-1c8f8f6e0d1c", TaskName: "Report", CreatedAt: now, UpdatedAt: now},
+		}},
+	}}
+
+	out := Render(doc)
+	if n := strings.Count(out, "BEGIN:VCALENDAR"); n != 1 {
+		t.Errorf("Render() wrote %d calendars, want 1:\n%s", n, out)
+	}
+	if !strings.HasPrefix(out, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:"+ProdID+"\r\nBEGIN:VTODO\r\n") || !strings.HasSuffix(out, "END:VTODO\r\nEND:VCALENDAR\r\n") {
+		t.Errorf("Render() =\n%s\nwant a calendar without a name wrapping the VTODOs", out)
+	}
+	for _, line := range []string{"X-WR-CALNAME:Home", "X-WR-CALNAME:Work", "RELATED-TO;RELTYPE=PARENT:4f2d3e5f-6071-4f0a-9a9e-1c8f8f6e0d1c"} {
+		if !strings.Contains(out, line+"\r\n") {
+			t.Errorf("Render() =\n%s\nwant a line %s", out, line)
+		}
+	}
+	if strings.Contains(out, "Urgent") {
+		t.Errorf("Render() =\n%s\nwant the smart list left out", out)
+	}
+
+	// A single list names the calendar
+	single := &server.Workspace{Version: 1, ExportedAt: now, Lists: doc.Lists[2:]}
+	if out := Render(single); !strings.Contains(out, "PRODID:"+ProdID+"\r\nX-WR-CALNAME:Work\r\n") {
+		t.Errorf("Render() of one list =\n%s\nwant the calendar named after it", out)
+	}
+
+	// The calendar is written without tasks too
+	empty := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:" + ProdID + "\r\nEND:VCALENDAR\r\n"
+	if out := Render(&server.Workspace{Version: 1, ExportedAt: now}); out != empty {
+		t.Errorf("Render() of no lists = %q, want %q", out, empty)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	due := "2024-05-10"
+	rule := "FREQ=WEEKLY"
+	remind := time.Date(2024, 5, 9, 8, 0, 0, 0, time.UTC)
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	name := strings.Repeat("Long ünïcode name ", 8)
+	doc := &server.Workspace{Version: 1, ExportedAt: now, Lists: []server.WorkspaceList{
+		{Title: "Home, sweet; home", Tasks: []server.WorkspaceTask{
+			{ID: "4f2d3e5f-6071-4f0a-9a9e-1c8f8f6e0d1c", TaskName: name, Notes: "line1\nline2, with; stuff\\", Priority: server.PriorityMedium,
+				DueDate: &due, Recurrence: &rule, RemindAt: &remind, Tags: []string{"a,b", "c"}, CreatedAt: now, UpdatedAt: now,
+				SubTasks: []server.WorkspaceSubTask{{ID: "5f2d3e5f-6071-4f0a-9a9e-1c8f8f6e0d1c", SubTaskName: "Fold", Completed: true, CreatedAt: now, UpdatedAt: now}}},
+		}},
+		{Title: "Work", Tasks: []server.WorkspaceTask{
+			{ID: "6f2d3e5f-6071-4f0a-9a9e-1c8f8f6e0d1c", TaskName: "Report", Completed: true, CreatedAt: now, UpdatedAt: now},
+		}},
+	}}
+
+	out := Render(doc)
+	for _, line := range strings.Split(out, "\r\n") {
+		if len(line) > maxLineLength {
+			t.Errorf("line of %d octets, want at most %d: %q", len(line), maxLineLength, line)
+		}
+	}
+	back, err := Parse(out, Options{Location: time.UTC})
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(back.Lists) != 2 || back.Lists[0].Title != "Home, sweet; home" || back.Lists[1].Title != "Work" {
+		t.Fatalf("lists = %+v, want Home, sweet; home and Work", back.Lists)
+	}
+	task := back.Lists[0].Tasks[0]
+	if task.ID != doc.Lists[0].Tasks[0].ID || task.TaskName != strings.TrimSpace(name) || task.Notes != doc.Lists[0].Tasks[0].Notes ||
+		task.Priority != server.PriorityMedium || task.DueDate == nil || *task.DueDate != due || task.Recurrence == nil || *task.Recurrence != rule ||
+		task.RemindAt == nil || !task.RemindAt.Equal(remind) || strings.Join(task.Tags, "|") != "a,b|c" || !task.CreatedAt.Equal(now) {
+		t.Errorf("task = %+v", task)
+	}
+	if subTasks := task.SubTasks; len(subTasks) != 1 || subTasks[0].SubTaskName != "Fold" || !subTasks[0].Completed {
+		t.Errorf("subtasks = %+v, want Fold completed", subTasks)
+	}
+	if report := back.Lists[1].Tasks; len(report) != 1 || !report[0].Completed {
+		t.Errorf("tasks of Work = %+v, want Report completed", report)
+	}
+}
+
+func TestParseOtherClients(t *testing.T) {
+	text := "BEGIN:VCALENDAR\n" +
+		"VERSION:2.0\n" +
+		"PRODID:-//Apple Inc.//macOS//EN\n" +
+		"X-WR-CALNAME:Errands\n" +
+		"BEGIN:VTIMEZONE\nTZID:Europe/Berlin\nBEGIN:STANDARD\nDTSTART:19701025T030000\nEND:STANDARD\nEND:VTIMEZONE\n" +
+		"BEGIN:VEVENT\nUID:ev1\nSUMMARY:Meeting\nEND:VEVENT\n" +
+		"BEGIN:VTODO\nUID:ABC-123@example.com\nSUMMARY:Buy\n  milk\nDUE;TZID=Europe/Berlin:20240502T100000\nPRIORITY:3\nPERCENT-COMPLETE:100\nRRULE:FREQ=DAILY;BYHOUR=9\nEND:VTODO\n" +
+		"BEGIN:VTODO\nUID:child\nRELATED-TO:ABC-123@example.com\nSUMMARY:Oat\nEND:VTODO\n" +
+		"BEGIN:VTODO\nUID:orphan\nRELATED-TO:missing\nSUMMARY:Orphan\nX-FOO;X-BAR=\"a:b\":c\nEND:VTODO\n" +
+		"END:VCALENDAR\n" +
+		"BEGIN:VCALENDAR\nBEGIN:VTODO\nSUMMARY:Loose end\nEND:VTODO\nEND:VCALENDAR\n"
+	existing := server.List{ID: "0b7f7f5e-9d0c-4f9a-8a8e-3f1c2d4e5f60", Title: "errands"}
+	doc, err := Parse(text, Options{ListID: "inbox", Lists: []server.List{existing}, Location: time.UTC})
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(doc.Lists) != 2 || doc.Lists[0].ID != existing.ID || !doc.Lists[0].Append || doc.Lists[1].ID != "inbox" || !doc.Lists[1].Append {
+		t.Fatalf("lists = %+v, want Errands appended to the existing list and the unnamed calendar to the inbox", doc.Lists)
+	}
+	tasks := doc.Lists[0].Tasks
+	if len(tasks) != 2 || tasks[0].TaskName != "Buy milk" || tasks[0].ID != "" || !tasks[0].Completed || tasks[0].Priority != server.PriorityHigh ||
+		tasks[0].Recurrence != nil || len(tasks[0].SubTasks) != 1 || tasks[1].TaskName != "Orphan" {
+		t.Errorf("tasks = %+v", tasks)
+	}
+	if due := tasks[0].DueAt; due == nil || !due.Equal(time.Date(2024, 5, 2, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("DueAt = %v, want 10:00 in Berlin", due)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"BEGIN:VCALENDAR\n",
+		"BEGIN:VCALENDAR\nEND:VCALENDAR",
+		"BEGIN:VTODO\nEND:VTODO",
+		"BEGIN:VCALENDAR\nBEGIN:VTODO\nEND:VTODO\nEND:VCALENDAR",
+		"BEGIN:VCALENDAR\nBEGIN:VTODO\nSUMMARY:x\nDUE:2024\nEND:VTODO\nEND:VCALENDAR",
+		"BEGIN:VCALENDAR\nBEGIN:VTODO\nSUMMARY:x\nPRIORITY:high\nEND:VTODO\nEND:VCALENDAR",
+		"BEGIN:VCALENDAR\nBEGIN:VTODO\nUID:a\nRELATED-TO:b\nSUMMARY:x\nEND:VTODO\nBEGIN:VTODO\nUID:b\nRELATED-TO:a\nSUMMARY:y\nEND:VTODO\nEND:VCALENDAR",
+		"BEGIN:VCALENDAR\nBEGIN:VTODO\nUID:a\nSUMMARY:x\nEND:VTODO\nBEGIN:VTODO\nUID:a\nSUMMARY:y\nEND:VTODO\nEND:VCALENDAR",
+		// Without a list name or a list to add them to
+		"BEGIN:VCALENDAR\nBEGIN:VTODO\nSUMMARY:x\nEND:VTODO\nEND:VCALENDAR",
+	} {
+		if _, err := Parse(text, Options{Location: time.UTC}); !errors.Is(err, server.ErrInvalidInput) {
+			t.Errorf("Parse(%q): err = %v, want ErrInvalidInput", text, err)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+// Package markdown converts lists to and from GitHub-flavored Markdown
+// checklists, such as
+//
+//	# Groceries
+//
+//	- [ ] Milk
+//	- [x] Bread
+//	  - [x] Sourdough
+//	  - [ ] Rye
+//
+// A heading starts a list, a top-level item is a task and a nested item is a
+// subtask of the task above it; deeper items are flattened into subtasks of
+// the same task. Items without a checkbox are open tasks. Notes, priorities,
+// due dates and tags are not written, and other lines, including fenced
+// code blocks, are ignored when parsing.
+package markdown
+
+import (
+	"regexp"
+	"strings"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+// Render writes a list and its tasks as a checklist headed by the list title
+func Render(tree *server.ListTree) string {
+	var b strings.Builder
+	b.WriteString("# ")
+	b.WriteString(escapeHeading(tree.List.Title))
+	b.WriteString("\n")
+	if len(tree.Tasks) > 0 {
+		b.WriteString("\n")
+	}
+	for _, task := range tree.Tasks {
+		writeItem(&b, "", task.Task.Completed, task.Task.TaskName)
+		for _, subTask := range task.SubTasks {
+			writeItem(&b, "  ", subTask.Completed, subTask.SubTaskName)
+		}
+	}
+	return b.String()
+}
+
+func writeItem(b *strings.Builder, indent string, completed bool, name string) {
+	b.WriteString(indent)
+	if completed {
+		b.WriteString("- [x] ")
+	} else {
+		b.WriteString("- [ ] ")
+	}
+	b.WriteString(name)
+	b.WriteString("\n")
+}
+
+// escapeHeading keeps a title ending in # from being read as the closing
+// sequence of its heading
+func escapeHeading(title string) string {
+	if strings.HasSuffix(title, "#") {
+		return title[:len(title)-1] + `\#`
+	}
+	return title
+}
+
+var (
+	headingPattern  = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?[ \t]*$`)
+	closingPattern  = regexp.MustCompile(`(?:^|[ \t]+)#+$`)
+	itemPattern     = regexp.MustCompile(`^([ \t]*)(?:[-*+]|[0-9]{1,9}[.)])(?:[ \t]+(.*?))?[ \t]*$`)
+	checkboxPattern = regexp.MustCompile(`^\[([ xX])\](?:[ \t]+(.*))?$`)
+)
+
+// Parse reads the lists and tasks of a checklist into a workspace document to
+// import by merging. Headings become new lists. Items before the first
+// heading are appended to the existing list with id listID; without one they
+// are rejected. Errors match server.ErrInvalidInput.
+func Parse(text, listID string) (*server.Workspace, error) {
+	doc := &server.Workspace{Version: server.WorkspaceVersion}
+	var list *server.WorkspaceList
+	var task *server.WorkspaceTask
+	taskIndent := 0
+	fence := ""
+
+	for i, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		number := i + 1
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+		if thematicBreak(trimmed) {
+			continue
+		}
+
+		if m := headingPattern.FindStringSubmatch(line); m != nil {
+			title := closingPattern.ReplaceAllString(m[2], "")
+			if strings.HasSuffix(title, `\#`) {
+				title = strings.TrimSuffix(title, `\#`) + "#"
+			}
+			if strings.TrimSpace(title) == "" {
+				return nil, server.InvalidInput("line %d: heading has no title", number)
+			}
+			doc.Lists = append(doc.Lists, server.WorkspaceList{Title: title})
+			list, task = &doc.Lists[len(doc.Lists)-1], nil
+			continue
+		}
+
+		m := itemPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		completed, name := false, m[2]
+		if box := checkboxPattern.FindStringSubmatch(name); box != nil {
+			completed, name = box[1] != " ", box[2]
+		}
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if list == nil {
+			if listID == "" {
+				return nil, server.InvalidInput("line %d: tasks before the first heading need a list to be added to", number)
+			}
+			doc.Lists = append(doc.Lists, server.WorkspaceList{ID: listID, Append: true})
+			list = &doc.Lists[len(doc.Lists)-1]
+		}
+
+		indent := width(m[1])
+		if task != nil && indent > taskIndent {
+			task.SubTasks = append(task.SubTasks, server.WorkspaceSubTask{SubTaskName: name, Completed: completed})
+			continue
+		}
+		list.Tasks = append(list.Tasks, server.WorkspaceTask{TaskName: name, Completed: completed})
+		task, taskIndent = &list.Tasks[len(list.Tasks)-1], indent
+	}
+
+	if len(doc.Lists) == 0 {
+		return nil, server.InvalidInput("no headings or checklist items found")
+	}
+	return doc, nil
+}
+
+// thematicBreak reports whether a trimmed line is a break such as --- or * * *,
+// which would otherwise be read as an item
+func thematicBreak(line string) bool {
+	if line == "" || !strings.ContainsRune("-*_", rune(line[0])) {
+		return false
+	}
+	count := 0
+	for _, r := range line {
+		switch r {
+		case rune(line[0]):
+			count++
+		case ' ', '\t':
+		default:
+			return false
+		}
+	}
+	return count >= 3
+}
+
+// width returns the columns taken by leading white space, with tab stops
+// every 4 columns
+func width(space string) int {
+	n := 0
+	for _, r := range space {
+		if r == '\t' {
+			n += 4 - n%4
+		} else {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,95 @@
+package markdown
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+func TestRender(t *testing.T) {
+	tree := &server.ListTree{
+		List: server.List{Title: "C#"},
+		Tasks: []server.TaskTree{
+			{
+				Task:     server.Task{TaskName: "Milk", Completed: true},
+				SubTasks: []server.SubTask{{SubTaskName: "Oat"}, {SubTaskName: "Cow", Completed: true}},
+			},
+			{Task: server.Task{TaskName: "Bread"}},
+		},
+	}
+	want := "# C\\#\n\n- [x] Milk\n  - [ ] Oat\n  - [x] Cow\n- [ ] Bread\n"
+	if got := Render(tree); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+	if got := Render(&server.ListTree{List: server.List{Title: "Empty"}}); got != "# Empty\n" {
+		t.Errorf("Render() of an empty list = %q, want only the heading", got)
+	}
+}
+
+func TestParse(t *testing.T) {
+	text := "Intro\n\n" +
+		"- [ ] Vacuum\n" +
+		"\t- [x] Stairs\n" +
+		"    * Hall\n" +
+		"1. Dust\n" +
+		"---\n" +
+		"```\n- [ ] Not a task\n```\n" +
+		"# Garden ##\n" +
+		"- [X] Weed\n" +
+		"  - Water\n" +
+		"## C\\#\n" +
+		"+ [ ]\n"
+	doc, err := Parse(text, "home")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := []server.WorkspaceList{
+		{ID: "home", Append: true, Tasks: []server.WorkspaceTask{
+			{TaskName: "Vacuum", SubTasks: []server.WorkspaceSubTask{{SubTaskName: "Stairs", Completed: true}, {SubTaskName: "Hall"}}},
+			{TaskName: "Dust"},
+		}},
+		{Title: "Garden", Tasks: []server.WorkspaceTask{
+			{TaskName: "Weed", Completed: true, SubTasks: []server.WorkspaceSubTask{{SubTaskName: "Water"}}},
+		}},
+		{Title: "C#"},
+	}
+	if doc.Version != server.WorkspaceVersion || !reflect.DeepEqual(doc.Lists, want) {
+		t.Errorf("Parse() = %+v, want %+v", doc.Lists, want)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	tree := &server.ListTree{
+		List: server.List{Title: "Groceries"},
+		Tasks: []server.TaskTree{
+			{Task: server.Task{TaskName: "Milk"}, SubTasks: []server.SubTask{{SubTaskName: "Oat", Completed: true}}},
+			{Task: server.Task{TaskName: "Bread", Completed: true}},
+		},
+	}
+	doc, err := Parse(Render(tree), "")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := []server.WorkspaceList{{Title: "Groceries", Tasks: []server.WorkspaceTask{
+		{TaskName: "Milk", SubTasks: []server.WorkspaceSubTask{{SubTaskName: "Oat", Completed: true}}},
+		{TaskName: "Bread", Completed: true},
+	}}}
+	if !reflect.DeepEqual(doc.Lists, want) {
+		t.Errorf("Parse(Render()) = %+v, want %+v", doc.Lists, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, text := range []string{
+		"- [ ] Milk",
+		"Just some text",
+		"",
+		"#\n- [ ] Milk",
+	} {
+		if _, err := Parse(text, ""); !errors.Is(err, server.ErrInvalidInput) {
+			t.Errorf("Parse(%q): err = %v, want ErrInvalidInput", text, err)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+func (s *Store) GetTaskActivity(ctx context.Context, taskID string) ([]server.Activity, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// Subtasks moved to another task are logged under the new one
+	var activity []server.Activity
+	for i := len(s.activity) - 1; i >= 0; i-- {
+		entry := s.activity[i]
+		if (entry.TaskID != nil && *entry.TaskID == taskID) || (entry.Entity == "subtask" && entry.Before["task_id"] == taskID) {
+			activity = append(activity, entry)
+		}
+	}
+
+	return activity, nil
+}
+
+func (s *Store) GetListActivity(ctx context.Context, listID string, since time.Time) ([]server.Activity, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// Tasks moved to another list are logged under the new one
+	var activity []server.Activity
+	for i := len(s.activity) - 1; i >= 0; i-- {
+		entry := s.activity[i]
+		if entry.At.Before(since) {
+			continue
+		}
+		if entry.ListID == listID || (entry.Entity == "task" && entry.Before["list_id"] == listID) {
+			activity = append(activity, entry)
+		}
+	}
+
+	return activity, nil
+}
+
+func (s *Store) SetActor(ctx context.Context, actor string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.actor = actor
+	return nil
+}
+
+// logLocked records a row that has just been created or changed. The fields
+// are compared with the ones logged last time, and nothing is recorded when
+// only the position or version moved. The caller must hold s.mu.
+func (s *Store) logLocked(row any) {
+	entry := server.Activity{Actor: s.actor, At: time.Now().UTC()}
+	switch row := row.(type) {
+	case *server.List:
+		entry.Entity, entry.EntityID, entry.ListID, entry.Name = "list", row.ID, row.ID, row.Title
+	case *server.Task:
+		entry.Entity, entry.EntityID, entry.ListID, entry.Name = "task", row.ID, row.ListID, row.TaskName
+		taskID := row.ID
+		entry.TaskID = &taskID
+	case *server.SubTask:
+		entry.Entity, entry.EntityID, entry.Name = "subtask", row.ID, row.SubTaskName
+		taskID := row.TaskID
+		entry.TaskID = &taskID
+		if task, ok := s.tasks[row.TaskID]; ok {
+			entry.ListID = task.ListID
+		} else if task, ok := s.trashedTasks[row.TaskID]; ok {
+			entry.ListID = task.ListID
+		}
+	}
+
+	key := entry.Entity + ":" + entry.EntityID
+	fields := activityFields(row)
+	previous, ok := s.logged[key]
+	s.logged[key] = fields
+
+	if !ok {
+		entry.Action = server.ActionCreate
+		entry.After = make(map[string]any, len(fields))
+		for name, value := range fields {
+			if name != "deleted_at" {
+				entry.After[name] = value
+			}
+		}
+	} else {
+		entry.Before, entry.After = map[string]any{}, map[string]any{}
+		for name, value := range fields {
+			if previous[name] != value {
+				entry.Before[name], entry.After[name] = previous[name], value
+			}
+		}
+		if len(entry.After) == 0 {
+			return
+		}
+		entry.Action = activityAction(entry.Before, entry.After)
+	}
+
+	s.nextActivityID++
+	entry.ID = s.nextActivityID
+	s.activity = append(s.activity, entry)
+}
+
+// forgetLocked drops the fields logged for a row that no longer exists as the
+// given entity, so that a row created again with its id is logged as new.
+// The caller must hold s.mu.
+func (s *Store) forgetLocked(entity, id string) {
+	delete(s.logged, entity+":"+id)
+}
+
+// purgeActivityLocked removes the activity of rows removed for good. The caller must hold s.mu.
+func (s *Store) purgeActivityLocked(ids map[string]bool) {
+	kept := s.activity[:0]
+	for _, entry := range s.activity {
+		if ids[entry.EntityID] {
+			s.forgetLocked(entry.Entity, entry.EntityID)
+		} else {
+			kept = append(kept, entry)
+		}
+	}
+	s.activity = kept
+}
+
+// activityFields returns the logged fields of a row keyed by their JSON names,
+// leaving out the ones the database triggers leave out
+func activityFields(row any) map[string]any {
+	data, err := json.Marshal(row)
+	if err != nil {
+		panic(err)
+	}
+	fields := map[string]any{"deleted_at": nil}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		panic(err)
+	}
+	for _, name := range []string{"id", "position", "version", "created_at", "updated_at"} {
+		delete(fields, name)
+	}
+	return fields
+}
+
+// activityAction names a change from the fields it changed
+func activityAction(before, after map[string]any) string {
+	_, deleted := after["deleted_at"]
+	_, moved := after["list_id"]
+	if _, ok := after["task_id"]; ok {
+		moved = true
+	}
+	_, completed := after["completed"]
+	switch {
+	case deleted && before["deleted_at"] == nil:
+		return server.ActionDelete
+	case deleted:
+		return server.ActionRestore
+	case moved:
+		return server.ActionMove
+	case completed && len(after) == 1:
+		return server.ActionToggle
+	default:
+		return server.ActionUpdate
+	}
+}
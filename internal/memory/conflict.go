@@ -0,0 +1,32 @@
+package memory
+
+import (
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+// The conflict helpers reject a write whose expected version no longer matches;
+// a version of 0 skips the check. The caller must hold s.mu.
+
+func listConflict(list *server.List, version int64) error {
+	if version == 0 || list.Version == version {
+		return nil
+	}
+	current := *list
+	return &server.ConflictError{Entity: "list", ID: list.ID, Version: version, Current: &current}
+}
+
+func taskConflict(task *server.Task, version int64) error {
+	if version == 0 || task.Version == version {
+		return nil
+	}
+	current := *task
+	return &server.ConflictError{Entity: "task", ID: task.ID, Version: version, Current: &current}
+}
+
+func subTaskConflict(subTask *server.SubTask, version int64) error {
+	if version == 0 || subTask.Version == version {
+		return nil
+	}
+	current := *subTask
+	return &server.ConflictError{Entity: "subtask", ID: subTask.ID, Version: version, Current: &current}
+}
@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+func (s *Store) SetTaskNotes(ctx context.Context, id, notes string) (*server.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[id]
+	if !ok {
+		return nil, server.NotFound("task", id)
+	}
+	task.Notes = notes
+	task.UpdatedAt = time.Now().UTC()
+	task.Version++
+	s.logLocked(task)
+
+	result := *task
+	return &result, nil
+}
+
+func (s *Store) SetTaskPriority(ctx context.Context, id string, priority server.Priority) (*server.Task, error) {
+	if !priority.Valid() {
+		return nil, server.InvalidInput("invalid priority %q", priority)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[id]
+	if !ok {
+		return nil, server.NotFound("task", id)
+	}
+	task.Priority = priority
+	task.UpdatedAt = time.Now().UTC()
+	task.Version++
+	s.logLocked(task)
+
+	result := *task
+	return &result, nil
+}
+
+func (s *Store) StarTask(ctx context.Context, id string, starred bool) (*server.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[id]
+	if !ok {
+		return nil, server.NotFound("task", id)
+	}
+	task.Starred = starred
+	task.UpdatedAt = time.Now().UTC()
+	task.Version++
+	s.logLocked(task)
+
+	result := *task
+	return &result, nil
+}
+
+func (s *Store) GetStarredTasks(ctx context.Context) ([]server.Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var tasks []server.Task
+	for _, task := range s.tasks {
+		if task.Starred {
+			tasks = append(tasks, *task)
+		}
+	}
+	s.sortTasks(tasks)
+
+	return tasks, nil
+}
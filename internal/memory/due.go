@@ -0,0 +1,139 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+// SetTaskDue sets a whole-day due date or a due moment, clearing the other
+func (s *Store) SetTaskDue(ctx context.Context, id string, dueDate *string, dueAt *time.Time) (*server.Task, error) {
+	var date *string
+	if dueDate != nil {
+		parsed, err := time.Parse(server.DateLayout, *dueDate)
+		if err != nil {
+			return nil, server.InvalidInput("invalid due date %q: %w", *dueDate, err)
+		}
+		formatted := server.DateOf(parsed)
+		date = &formatted
+		dueAt = nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[id]
+	if !ok {
+		return nil, server.NotFound("task", id)
+	}
+	task.DueDate = date
+	task.DueAt = utcPtr(dueAt)
+	task.UpdatedAt = time.Now().UTC()
+	task.Version++
+	s.logLocked(task)
+
+	result := *task
+	return &result, nil
+}
+
+func (s *Store) SetTaskReminder(ctx context.Context, id string, remindAt *time.Time) (*server.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[id]
+	if !ok {
+		return nil, server.NotFound("task", id)
+	}
+	task.RemindAt = utcPtr(remindAt)
+	task.UpdatedAt = time.Now().UTC()
+	task.Version++
+	s.logLocked(task)
+
+	result := *task
+	return &result, nil
+}
+
+// GetTasksDueBetween returns tasks due in [from, to), ordered by due time
+func (s *Store) GetTasksDueBetween(ctx context.Context, from, to time.Time) ([]server.Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	first, last := server.DueDateRange(from, to)
+	var tasks []server.Task
+	for _, task := range s.tasks {
+		switch {
+		case task.DueAt != nil:
+			if !task.DueAt.Before(from) && task.DueAt.Before(to) {
+				tasks = append(tasks, *task)
+			}
+		case task.DueDate != nil:
+			if *task.DueDate >= first && *task.DueDate <= last {
+				tasks = append(tasks, *task)
+			}
+		}
+	}
+	s.sortByDue(tasks, from.Location())
+
+	return tasks, nil
+}
+
+// GetOverdueTasks returns incomplete tasks due before now, ordered by due time
+func (s *Store) GetOverdueTasks(ctx context.Context, now time.Time) ([]server.Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	today := server.DateOf(now)
+	var tasks []server.Task
+	for _, task := range s.tasks {
+		if task.Completed {
+			continue
+		}
+		if (task.DueAt != nil && task.DueAt.Before(now)) || (task.DueDate != nil && *task.DueDate < today) {
+			tasks = append(tasks, *task)
+		}
+	}
+	s.sortByDue(tasks, now.Location())
+
+	return tasks, nil
+}
+
+// GetDueReminders returns incomplete tasks whose reminder time has passed
+func (s *Store) GetDueReminders(ctx context.Context, now time.Time) ([]server.Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var tasks []server.Task
+	for _, task := range s.tasks {
+		if !task.Completed && task.RemindAt != nil && !task.RemindAt.After(now) {
+			tasks = append(tasks, *task)
+		}
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if !tasks[i].RemindAt.Equal(*tasks[j].RemindAt) {
+			return tasks[i].RemindAt.Before(*tasks[j].RemindAt)
+		}
+		return s.seq[tasks[i].ID] < s.seq[tasks[j].ID]
+	})
+
+	return tasks, nil
+}
+
+// sortByDue orders tasks by due time and breaks remaining ties by insertion order,
+// since map iteration order is random
+func (s *Store) sortByDue(tasks []server.Task, loc *time.Location) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return s.seq[tasks[i].ID] < s.seq[tasks[j].ID]
+	})
+	server.SortByDue(tasks, loc)
+}
+
+// utcPtr returns a copy of t in UTC so callers cannot mutate stored values
+func utcPtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
@@ -0,0 +1,153 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	server "github.com/HolySxn/To-Do/internal"
+	"github.com/google/uuid"
+)
+
+// CRUD operations for Lists
+func (s *Store) CreateList(ctx context.Context, title string) (*server.List, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.createListLocked(title, nil), nil
+}
+
+// createListLocked adds a list, or a smart list if query is set, after the
+// existing lists. The caller must hold s.mu.
+func (s *Store) createListLocked(title string, query *string) *server.List {
+	// Get the next position for the new list
+	maxPosition := 0
+	for _, list := range s.lists {
+		if list.Position > maxPosition {
+			maxPosition = list.Position
+		}
+	}
+
+	id := uuid.NewString()
+	now := s.track(id)
+	list := &server.List{
+		ID:        id,
+		Title:     title,
+		Query:     query,
+		Position:  maxPosition + 1,
+		Version:   1,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.lists[id] = list
+	s.logLocked(list)
+
+	result := *list
+	return &result
+}
+
+func (s *Store) GetList(ctx context.Context, id string) (*server.List, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list, ok := s.lists[id]
+	if !ok {
+		return nil, server.NotFound("list", id)
+	}
+
+	result := *list
+	return &result, nil
+}
+
+func (s *Store) GetAllLists(ctx context.Context) ([]server.List, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.listsLocked(), nil
+}
+
+// listsLocked returns the lists ordered by position
+func (s *Store) listsLocked() []server.List {
+	var lists []server.List
+	for _, list := range s.lists {
+		lists = append(lists, *list)
+	}
+	sort.SliceStable(lists, func(i, j int) bool {
+		if lists[i].Position != lists[j].Position {
+			return lists[i].Position < lists[j].Position
+		}
+		return s.seq[lists[i].ID] < s.seq[lists[j].ID]
+	})
+
+	return lists
+}
+
+func (s *Store) UpdateList(ctx context.Context, id string, title string, version int64) (*server.List, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, ok := s.lists[id]
+	if !ok {
+		return nil, server.NotFound("list", id)
+	}
+	if err := listConflict(list, version); err != nil {
+		return nil, err
+	}
+	list.Title = title
+	list.UpdatedAt = time.Now().UTC()
+	list.Version++
+	s.logLocked(list)
+
+	result := *list
+	return &result, nil
+}
+
+func (s *Store) DeleteList(ctx context.Context, id string, version int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, ok := s.lists[id]
+	if !ok {
+		return server.NotFound("list", id)
+	}
+	if err := listConflict(list, version); err != nil {
+		return err
+	}
+
+	// Cascade to tasks and their subtasks
+	now := time.Now().UTC()
+	for taskID, task := range s.tasks {
+		if task.ListID == id {
+			s.trashTaskLocked(taskID, now)
+		}
+	}
+	delete(s.lists, id)
+	list.DeletedAt = &now
+	list.Version++
+	s.trashedLists[id] = list
+	s.logLocked(list)
+
+	return nil
+}
+
+// ReorderLists updates the positions of lists based on the provided order
+func (s *Store) ReorderLists(ctx context.Context, listIDs []string) error {
+	if len(listIDs) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	for i, listID := range listIDs {
+		// Unknown ids are skipped, matching an UPDATE that affects no rows
+		if list, ok := s.lists[listID]; ok {
+			list.Position = i + 1
+			list.UpdatedAt = now
+			list.Version++
+		}
+	}
+
+	return nil
+}
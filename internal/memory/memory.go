@@ -0,0 +1,75 @@
+package memory
+
+import (
+	"sync"
+	"time"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+var _ server.Store = (*Store)(nil)
+
+// Store is an in-memory implementation of server.Store.
+// It keeps all data in process memory and is intended for tests and offline use.
+type Store struct {
+	mu       sync.RWMutex
+	lists    map[string]*server.List
+	tasks    map[string]*server.Task
+	subTasks map[string]*server.SubTask
+
+	// Trashed rows are kept apart so lookups in the maps above never see them
+	trashedLists    map[string]*server.List
+	trashedTasks    map[string]*server.Task
+	trashedSubTasks map[string]*server.SubTask
+
+	// taskTags holds the tag ids of each tagged task, trashed or not
+	tags     map[string]*server.Tag
+	taskTags map[string]map[string]bool
+
+	// seq records insertion order so rows created within the same clock tick
+	// still sort deterministically
+	seq     map[string]int64
+	nextSeq int64
+
+	// activity is the change log, oldest first. logged holds the fields of
+	// every row as last logged, keyed by entity and id, to diff changes against.
+	activity       []server.Activity
+	nextActivityID int64
+	logged         map[string]map[string]any
+	actor          string
+}
+
+func New() *Store {
+	return &Store{
+		lists:    make(map[string]*server.List),
+		tasks:    make(map[string]*server.Task),
+		subTasks: make(map[string]*server.SubTask),
+
+		trashedLists:    make(map[string]*server.List),
+		trashedTasks:    make(map[string]*server.Task),
+		trashedSubTasks: make(map[string]*server.SubTask),
+
+		tags:     make(map[string]*server.Tag),
+		taskTags: make(map[string]map[string]bool),
+
+		seq:    make(map[string]int64),
+		logged: make(map[string]map[string]any),
+	}
+}
+
+func (s *Store) Close() {}
+
+// track registers a new row id and returns the creation timestamp to use for it
+func (s *Store) track(id string) time.Time {
+	s.nextSeq++
+	s.seq[id] = s.nextSeq
+	return time.Now().UTC()
+}
+
+// newer reports whether row a should be listed before row b in created_at DESC order
+func (s *Store) newer(aID string, aCreated time.Time, bID string, bCreated time.Time) bool {
+	if !aCreated.Equal(bCreated) {
+		return aCreated.After(bCreated)
+	}
+	return s.seq[aID] > s.seq[bID]
+}
@@ -0,0 +1,12 @@
+package memory
+
+import (
+	"testing"
+
+	server "github.com/HolySxn/To-Do/internal"
+	"github.com/HolySxn/To-Do/internal/storetest"
+)
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) server.Store { return New() })
+}
@@ -0,0 +1,197 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+// MoveTask moves a task to another list (or within its list) at the given 1-based position
+func (s *Store) MoveTask(ctx context.Context, taskID, targetListID string, position int) (*server.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkTaskListLocked(targetListID); err != nil {
+		return nil, err
+	}
+	task, ok := s.tasks[taskID]
+	if !ok {
+		return nil, server.NotFound("task", taskID)
+	}
+
+	task.ListID = targetListID
+	task.UpdatedAt = time.Now().UTC()
+	task.Version++
+	s.logLocked(task)
+	s.renumberTasksLocked(server.InsertAt(s.taskOrderLocked(targetListID, taskID), taskID, position))
+
+	result := *task
+	return &result, nil
+}
+
+// MoveSubTask moves a subtask to another task (or within its task) at the given 1-based position
+func (s *Store) MoveSubTask(ctx context.Context, subTaskID, targetTaskID string, position int) (*server.SubTask, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.tasks[targetTaskID]; !ok {
+		return nil, server.NotFound("task", targetTaskID)
+	}
+	subTask, ok := s.subTasks[subTaskID]
+	if !ok {
+		return nil, server.NotFound("subtask", subTaskID)
+	}
+
+	subTask.TaskID = targetTaskID
+	subTask.UpdatedAt = time.Now().UTC()
+	subTask.Version++
+	s.logLocked(subTask)
+	s.renumberSubTasksLocked(server.InsertAt(s.subTaskOrderLocked(targetTaskID, subTaskID), subTaskID, position))
+
+	result := *subTask
+	return &result, nil
+}
+
+// PromoteSubTaskToTask replaces a subtask with a task placed right after its former parent
+func (s *Store) PromoteSubTaskToTask(ctx context.Context, subTaskID string) (*server.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subTask, ok := s.subTasks[subTaskID]
+	if !ok {
+		return nil, server.NotFound("subtask", subTaskID)
+	}
+	parent := s.tasks[subTask.TaskID]
+
+	task := &server.Task{
+		ID:        subTask.ID,
+		ListID:    parent.ListID,
+		TaskName:  subTask.SubTaskName,
+		Completed: subTask.Completed,
+		Priority:  server.PriorityNone,
+		Version:   1,
+		CreatedAt: subTask.CreatedAt,
+		UpdatedAt: subTask.UpdatedAt,
+	}
+	delete(s.subTasks, subTaskID)
+	s.forgetLocked("subtask", subTaskID)
+	s.tasks[task.ID] = task
+	s.logLocked(task)
+
+	ids := s.taskOrderLocked(parent.ListID, task.ID)
+	s.renumberTasksLocked(server.InsertAt(ids, task.ID, server.IndexOf(ids, parent.ID)+1))
+
+	result := *task
+	return &result, nil
+}
+
+// DemoteTaskToSubTask replaces a task with a subtask appended to targetTaskID
+func (s *Store) DemoteTaskToSubTask(ctx context.Context, taskID, targetTaskID string) (*server.SubTask, error) {
+	if taskID == targetTaskID {
+		return nil, server.InvalidInput("task %s cannot become a subtask of itself", taskID)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.tasks[targetTaskID]; !ok {
+		return nil, server.NotFound("task", targetTaskID)
+	}
+	// Subtasks cannot be nested, so a task that has its own must not be demoted.
+	// Trashed subtasks count too, as they could otherwise never be restored.
+	for _, subTasks := range []map[string]*server.SubTask{s.subTasks, s.trashedSubTasks} {
+		for _, subTask := range subTasks {
+			if subTask.TaskID == taskID {
+				return nil, server.InvalidInput("task with id %s has subtasks and cannot be demoted", taskID)
+			}
+		}
+	}
+	task, ok := s.tasks[taskID]
+	if !ok {
+		return nil, server.NotFound("task", taskID)
+	}
+
+	subTask := &server.SubTask{
+		ID:          task.ID,
+		TaskID:      targetTaskID,
+		SubTaskName: task.TaskName,
+		Completed:   task.Completed,
+		Version:     1,
+		CreatedAt:   task.CreatedAt,
+		UpdatedAt:   task.UpdatedAt,
+	}
+	delete(s.tasks, taskID)
+	delete(s.taskTags, taskID)
+	s.forgetLocked("task", taskID)
+	s.subTasks[subTask.ID] = subTask
+	s.logLocked(subTask)
+
+	s.renumberSubTasksLocked(append(s.subTaskOrderLocked(targetTaskID, subTask.ID), subTask.ID))
+
+	result := *subTask
+	return &result, nil
+}
+
+// taskOrderLocked returns the ids of a list's tasks in display order, leaving out excludeID
+func (s *Store) taskOrderLocked(listID, excludeID string) []string {
+	var tasks []*server.Task
+	for _, task := range s.tasks {
+		if task.ListID == listID && task.ID != excludeID {
+			tasks = append(tasks, task)
+		}
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if tasks[i].Position != tasks[j].Position {
+			return tasks[i].Position < tasks[j].Position
+		}
+		return s.newer(tasks[i].ID, tasks[i].CreatedAt, tasks[j].ID, tasks[j].CreatedAt)
+	})
+
+	ids := make([]string, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	return ids
+}
+
+// subTaskOrderLocked returns the ids of a task's subtasks in display order, leaving out excludeID
+func (s *Store) subTaskOrderLocked(taskID, excludeID string) []string {
+	var subTasks []*server.SubTask
+	for _, subTask := range s.subTasks {
+		if subTask.TaskID == taskID && subTask.ID != excludeID {
+			subTasks = append(subTasks, subTask)
+		}
+	}
+	sort.SliceStable(subTasks, func(i, j int) bool {
+		if subTasks[i].Position != subTasks[j].Position {
+			return subTasks[i].Position < subTasks[j].Position
+		}
+		return s.newer(subTasks[i].ID, subTasks[i].CreatedAt, subTasks[j].ID, subTasks[j].CreatedAt)
+	})
+
+	ids := make([]string, len(subTasks))
+	for i, subTask := range subTasks {
+		ids[i] = subTask.ID
+	}
+	return ids
+}
+
+func (s *Store) renumberTasksLocked(ids []string) {
+	for i, id := range ids {
+		if task := s.tasks[id]; task.Position != i+1 {
+			task.Position = i + 1
+			task.Version++
+		}
+	}
+}
+
+func (s *Store) renumberSubTasksLocked(ids []string) {
+	for i, id := range ids {
+		if subTask := s.subTasks[id]; subTask.Position != i+1 {
+			subTask.Position = i + 1
+			subTask.Version++
+		}
+	}
+}
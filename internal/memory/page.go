@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+// Pages follow the keyset order of the SQL stores, so ties in created_at are
+// broken by id rather than by insertion order.
+
+func (s *Store) GetTasksPageByListID(ctx context.Context, listID string, size int, cursor string) (*server.TaskPage, error) {
+	after, err := server.ParsePage(size, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var tasks []server.Task
+	for _, task := range s.tasks {
+		if task.ListID == listID && (after == nil || sortsBefore(*after, taskCursor(*task), true)) {
+			tasks = append(tasks, *task)
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		return sortsBefore(taskCursor(tasks[i]), taskCursor(tasks[j]), true)
+	})
+
+	return server.NewTaskPage(tasks[:min(len(tasks), size+1)], size), nil
+}
+
+func (s *Store) GetAllTasksPage(ctx context.Context, size int, cursor string) (*server.TaskPage, error) {
+	after, err := server.ParsePage(size, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var tasks []server.Task
+	for _, task := range s.tasks {
+		if after == nil || sortsBefore(*after, taskCursor(*task), false) {
+			tasks = append(tasks, *task)
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		return sortsBefore(taskCursor(tasks[i]), taskCursor(tasks[j]), false)
+	})
+
+	return server.NewTaskPage(tasks[:min(len(tasks), size+1)], size), nil
+}
+
+func (s *Store) GetAllSubTasksPage(ctx context.Context, size int, cursor string) (*server.SubTaskPage, error) {
+	after, err := server.ParsePage(size, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var subTasks []server.SubTask
+	for _, subTask := range s.subTasks {
+		if after == nil || sortsBefore(*after, subTaskCursor(*subTask), false) {
+			subTasks = append(subTasks, *subTask)
+		}
+	}
+	sort.Slice(subTasks, func(i, j int) bool {
+		return sortsBefore(subTaskCursor(subTasks[i]), subTaskCursor(subTasks[j]), false)
+	})
+
+	return server.NewSubTaskPage(subTasks[:min(len(subTasks), size+1)], size), nil
+}
+
+func taskCursor(task server.Task) server.Cursor {
+	return server.Cursor{Position: task.Position, CreatedAt: task.CreatedAt, ID: task.ID}
+}
+
+func subTaskCursor(subTask server.SubTask) server.Cursor {
+	return server.Cursor{Position: subTask.Position, CreatedAt: subTask.CreatedAt, ID: subTask.ID}
+}
+
+// sortsBefore reports whether a comes before b in page order: by position when
+// byPosition is set, then newest first, then by descending id
+func sortsBefore(a, b server.Cursor, byPosition bool) bool {
+	if byPosition && a.Position != b.Position {
+		return a.Position < b.Position
+	}
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.After(b.CreatedAt)
+	}
+	return a.ID > b.ID
+}
@@ -0,0 +1,108 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+func (s *Store) PatchList(ctx context.Context, id string, patch server.ListPatch) (*server.List, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, ok := s.lists[id]
+	if !ok {
+		return nil, server.NotFound("list", id)
+	}
+	if patch.Query != nil {
+		if err := checkListQuery(list, *patch.Query); err != nil {
+			return nil, err
+		}
+	}
+	if err := listConflict(list, patch.Version); err != nil {
+		return nil, err
+	}
+	if !patch.Empty() {
+		if patch.Title != nil {
+			list.Title = *patch.Title
+		}
+		if patch.Query != nil {
+			query := *patch.Query
+			list.Query = &query
+		}
+		list.UpdatedAt = time.Now().UTC()
+		list.Version++
+		s.logLocked(list)
+	}
+
+	result := *list
+	return &result, nil
+}
+
+func (s *Store) PatchTask(ctx context.Context, id string, patch server.TaskPatch) (*server.Task, error) {
+	if err := patch.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[id]
+	if !ok {
+		return nil, server.NotFound("task", id)
+	}
+	if err := taskConflict(task, patch.Version); err != nil {
+		return nil, err
+	}
+	if !patch.Empty() {
+		if patch.TaskName != nil {
+			task.TaskName = *patch.TaskName
+		}
+		if patch.Notes != nil {
+			task.Notes = *patch.Notes
+		}
+		if patch.Completed != nil {
+			task.Completed = *patch.Completed
+		}
+		if patch.Priority != nil {
+			task.Priority = *patch.Priority
+		}
+		if patch.Starred != nil {
+			task.Starred = *patch.Starred
+		}
+		task.UpdatedAt = time.Now().UTC()
+		task.Version++
+		s.logLocked(task)
+	}
+
+	result := *task
+	return &result, nil
+}
+
+func (s *Store) PatchSubTask(ctx context.Context, id string, patch server.SubTaskPatch) (*server.SubTask, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subTask, ok := s.subTasks[id]
+	if !ok {
+		return nil, server.NotFound("subtask", id)
+	}
+	if err := subTaskConflict(subTask, patch.Version); err != nil {
+		return nil, err
+	}
+	if !patch.Empty() {
+		if patch.SubTaskName != nil {
+			subTask.SubTaskName = *patch.SubTaskName
+		}
+		if patch.Completed != nil {
+			subTask.Completed = *patch.Completed
+		}
+		subTask.UpdatedAt = time.Now().UTC()
+		subTask.Version++
+		s.logLocked(subTask)
+	}
+
+	result := *subTask
+	return &result, nil
+}
@@ -0,0 +1,106 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"time"
+
+	server "github.com/HolySxn/To-Do/internal"
+	"github.com/google/uuid"
+)
+
+// CompleteTask marks a task completed and spawns its next occurrence if it recurs
+func (s *Store) CompleteTask(ctx context.Context, id string) (*server.TaskCompletion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[id]
+	if !ok {
+		return nil, server.NotFound("task", id)
+	}
+	if task.Completed {
+		return &server.TaskCompletion{Task: *task}, nil
+	}
+
+	return s.completeTaskLocked(task)
+}
+
+func (s *Store) SetTaskRecurrence(ctx context.Context, id string, rule *string) (*server.Task, error) {
+	var recurrence *string
+	if rule != nil {
+		parsed, err := server.ParseRecurrence(*rule)
+		if err != nil {
+			return nil, err
+		}
+		canonical := parsed.String()
+		recurrence = &canonical
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[id]
+	if !ok {
+		return nil, server.NotFound("task", id)
+	}
+	task.Recurrence = recurrence
+	task.UpdatedAt = time.Now().UTC()
+	task.Version++
+	s.logLocked(task)
+
+	result := *task
+	return &result, nil
+}
+
+// completeTaskLocked marks an incomplete task completed. For a recurring task the
+// next occurrence takes the same position, which lists it above the completed one,
+// and receives copies of the subtasks reset to incomplete and of the tags.
+func (s *Store) completeTaskLocked(task *server.Task) (*server.TaskCompletion, error) {
+	next, err := server.NextOccurrence(*task, time.Now(), time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute next occurrence: %w", err)
+	}
+
+	task.Completed = true
+	task.UpdatedAt = time.Now().UTC()
+	task.Version++
+	s.logLocked(task)
+	completion := &server.TaskCompletion{Task: *task}
+	if next == nil {
+		return completion, nil
+	}
+
+	next.ID = uuid.NewString()
+	next.Position = task.Position
+	next.Version = 1
+	next.CreatedAt = s.track(next.ID)
+	next.UpdatedAt = next.CreatedAt
+	s.tasks[next.ID] = next
+	s.logLocked(next)
+	if tagIDs := s.taskTags[task.ID]; len(tagIDs) > 0 {
+		s.taskTags[next.ID] = maps.Clone(tagIDs)
+	}
+
+	// Copy last to first so the copies keep their order when positions tie
+	ids := s.subTaskOrderLocked(task.ID, "")
+	for i := len(ids) - 1; i >= 0; i-- {
+		subTask := s.subTasks[ids[i]]
+		id := uuid.NewString()
+		now := s.track(id)
+		s.subTasks[id] = &server.SubTask{
+			ID:          id,
+			TaskID:      next.ID,
+			SubTaskName: subTask.SubTaskName,
+			Position:    subTask.Position,
+			Version:     1,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		s.logLocked(s.subTasks[id])
+	}
+
+	result := *next
+	completion.Next = &result
+	return completion, nil
+}
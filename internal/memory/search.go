@@ -0,0 +1,40 @@
+package memory
+
+import (
+	"context"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+func (s *Store) Search(ctx context.Context, query string) ([]server.SearchHit, error) {
+	terms := server.SearchTerms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// Every row is a candidate; RankSearchHits drops the ones that do not match
+	var candidates []server.SearchHit
+	for _, list := range s.lists {
+		candidates = append(candidates, server.SearchHit{
+			Entity: "list", ID: list.ID, Name: list.Title, ListID: list.ID, ListTitle: list.Title,
+		})
+	}
+	for _, task := range s.tasks {
+		candidates = append(candidates, server.SearchHit{
+			Entity: "task", ID: task.ID, Name: task.TaskName, ListID: task.ListID, ListTitle: s.lists[task.ListID].Title,
+		})
+	}
+	for _, subTask := range s.subTasks {
+		task := s.tasks[subTask.TaskID]
+		taskID, taskName := task.ID, task.TaskName
+		candidates = append(candidates, server.SearchHit{
+			Entity: "subtask", ID: subTask.ID, Name: subTask.SubTaskName, ListID: task.ListID, ListTitle: s.lists[task.ListID].Title,
+			TaskID: &taskID, TaskName: &taskName,
+		})
+	}
+
+	return server.RankSearchHits(candidates, terms), nil
+}
@@ -0,0 +1,85 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	server "github.com/HolySxn/To-Do/internal"
+	"github.com/HolySxn/To-Do/internal/query"
+)
+
+func (s *Store) CreateSmartList(ctx context.Context, title, q string) (*server.List, error) {
+	if _, err := query.Parse(q, time.Now()); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.createListLocked(title, &q), nil
+}
+
+func (s *Store) GetSmartListTasks(ctx context.Context, listID string, now time.Time) ([]server.Task, error) {
+	s.mu.RLock()
+	list, ok := s.lists[listID]
+	if !ok {
+		s.mu.RUnlock()
+		return nil, server.NotFound("list", listID)
+	}
+	q := list.Query
+	s.mu.RUnlock()
+
+	if q == nil {
+		return nil, server.NotSmartList(listID)
+	}
+	return s.QueryTasks(ctx, *q, now)
+}
+
+func (s *Store) QueryTasks(ctx context.Context, q string, now time.Time) ([]server.Task, error) {
+	parsed, err := query.Parse(q, now)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var tasks []server.Task
+	for _, task := range s.tasks {
+		candidate := query.Candidate{Task: *task, ListTitle: s.lists[task.ListID].Title}
+		for tagID := range s.taskTags[task.ID] {
+			candidate.TagNames = append(candidate.TagNames, s.tags[tagID].Name)
+		}
+		if parsed.Match(candidate) {
+			tasks = append(tasks, *task)
+		}
+	}
+	s.sortTasks(tasks)
+	parsed.Sort(tasks)
+
+	return tasks, nil
+}
+
+// checkTaskListLocked fails unless tasks can be put in a list: it must exist
+// and not be a smart list. The caller must hold s.mu.
+func (s *Store) checkTaskListLocked(listID string) error {
+	list, ok := s.lists[listID]
+	if !ok {
+		return server.NotFound("list", listID)
+	}
+	if list.Query != nil {
+		return server.SmartListTasks(listID)
+	}
+	return nil
+}
+
+// checkListQuery checks that a list is a smart list and that q parses
+func checkListQuery(list *server.List, q string) error {
+	if _, err := query.Parse(q, time.Now()); err != nil {
+		return err
+	}
+	if list.Query == nil {
+		return server.NotSmartList(list.ID)
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	server "github.com/HolySxn/To-Do/internal"
+	"github.com/google/uuid"
+)
+
+// CRUD operations for SubTasks
+func (s *Store) CreateSubTask(ctx context.Context, taskID string, subTaskName string) (*server.SubTask, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.tasks[taskID]; !ok {
+		return nil, fmt.Errorf("failed to create subtask: %w", server.NotFound("task", taskID))
+	}
+
+	// New subtasks are placed above the existing ones
+	minPosition := 1
+	for _, subTask := range s.subTasks {
+		if subTask.TaskID == taskID && subTask.Position < minPosition {
+			minPosition = subTask.Position
+		}
+	}
+
+	id := uuid.NewString()
+	now := s.track(id)
+	subTask := &server.SubTask{
+		ID:          id,
+		TaskID:      taskID,
+		SubTaskName: subTaskName,
+		Position:    minPosition - 1,
+		Version:     1,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	s.subTasks[id] = subTask
+	s.logLocked(subTask)
+
+	result := *subTask
+	return &result, nil
+}
+
+func (s *Store) GetSubTask(ctx context.Context, id string) (*server.SubTask, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	subTask, ok := s.subTasks[id]
+	if !ok {
+		return nil, server.NotFound("subtask", id)
+	}
+
+	result := *subTask
+	return &result, nil
+}
+
+func (s *Store) GetSubTasksByTaskID(ctx context.Context, taskID string) ([]server.SubTask, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var subTasks []server.SubTask
+	for _, subTask := range s.subTasks {
+		if subTask.TaskID == taskID {
+			subTasks = append(subTasks, *subTask)
+		}
+	}
+	sort.SliceStable(subTasks, func(i, j int) bool {
+		if subTasks[i].Position != subTasks[j].Position {
+			return subTasks[i].Position < subTasks[j].Position
+		}
+		return s.newer(subTasks[i].ID, subTasks[i].CreatedAt, subTasks[j].ID, subTasks[j].CreatedAt)
+	})
+
+	return subTasks, nil
+}
+
+func (s *Store) GetAllSubTasks(ctx context.Context) ([]server.SubTask, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var subTasks []server.SubTask
+	for _, subTask := range s.subTasks {
+		subTasks = append(subTasks, *subTask)
+	}
+	s.sortSubTasks(subTasks)
+
+	return subTasks, nil
+}
+
+func (s *Store) UpdateSubTask(ctx context.Context, id, subTaskName string, completed bool, version int64) (*server.SubTask, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subTask, ok := s.subTasks[id]
+	if !ok {
+		return nil, server.NotFound("subtask", id)
+	}
+	if err := subTaskConflict(subTask, version); err != nil {
+		return nil, err
+	}
+	subTask.SubTaskName = subTaskName
+	subTask.Completed = completed
+	subTask.UpdatedAt = time.Now().UTC()
+	subTask.Version++
+	s.logLocked(subTask)
+
+	result := *subTask
+	return &result, nil
+}
+
+func (s *Store) ToggleSubTaskCompletion(ctx context.Context, id string) (*server.SubTask, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subTask, ok := s.subTasks[id]
+	if !ok {
+		return nil, server.NotFound("subtask", id)
+	}
+	subTask.Completed = !subTask.Completed
+	subTask.UpdatedAt = time.Now().UTC()
+	subTask.Version++
+	s.logLocked(subTask)
+
+	result := *subTask
+	return &result, nil
+}
+
+func (s *Store) DeleteSubTask(ctx context.Context, id string, version int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subTask, ok := s.subTasks[id]
+	if !ok {
+		return server.NotFound("subtask", id)
+	}
+	if err := subTaskConflict(subTask, version); err != nil {
+		return err
+	}
+	s.trashSubTaskLocked(id, time.Now().UTC())
+
+	return nil
+}
+
+// ReorderSubTasks updates the positions of a task's subtasks based on the provided order
+func (s *Store) ReorderSubTasks(ctx context.Context, taskID string, subTaskIDs []string) error {
+	if len(subTaskIDs) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	for i, subTaskID := range subTaskIDs {
+		// Ids from other tasks are skipped, matching an UPDATE that affects no rows
+		if subTask, ok := s.subTasks[subTaskID]; ok && subTask.TaskID == taskID {
+			subTask.Position = i + 1
+			subTask.UpdatedAt = now
+			subTask.Version++
+		}
+	}
+
+	return nil
+}
+
+// sortSubTasks orders subtasks by created_at DESC
+func (s *Store) sortSubTasks(subTasks []server.SubTask) {
+	sort.SliceStable(subTasks, func(i, j int) bool {
+		return s.newer(subTasks[i].ID, subTasks[i].CreatedAt, subTasks[j].ID, subTasks[j].CreatedAt)
+	})
+}
+
+// trashSubTaskLocked moves a subtask to the trash. The caller must hold s.mu.
+func (s *Store) trashSubTaskLocked(id string, now time.Time) {
+	subTask := s.subTasks[id]
+	delete(s.subTasks, id)
+	subTask.DeletedAt = &now
+	subTask.Version++
+	s.trashedSubTasks[id] = subTask
+	s.logLocked(subTask)
+}
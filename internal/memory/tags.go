@@ -0,0 +1,230 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	server "github.com/HolySxn/To-Do/internal"
+	"github.com/google/uuid"
+)
+
+func (s *Store) CreateTag(ctx context.Context, name, color string) (*server.Tag, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkTagNameLocked("", name); err != nil {
+		return nil, fmt.Errorf("failed to create tag: %w", err)
+	}
+
+	id := uuid.NewString()
+	now := s.track(id)
+	tag := &server.Tag{
+		ID:        id,
+		Name:      name,
+		Color:     color,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.tags[id] = tag
+
+	result := *tag
+	return &result, nil
+}
+
+func (s *Store) GetTag(ctx context.Context, id string) (*server.Tag, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tag, ok := s.tags[id]
+	if !ok {
+		return nil, server.NotFound("tag", id)
+	}
+
+	result := *tag
+	return &result, nil
+}
+
+func (s *Store) GetAllTags(ctx context.Context) ([]server.Tag, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var tags []server.Tag
+	for _, tag := range s.tags {
+		tags = append(tags, *tag)
+	}
+	sortTags(tags)
+
+	return tags, nil
+}
+
+func (s *Store) RenameTag(ctx context.Context, id, name string) (*server.Tag, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tag, ok := s.tags[id]
+	if !ok {
+		return nil, server.NotFound("tag", id)
+	}
+	if err := s.checkTagNameLocked(id, name); err != nil {
+		return nil, fmt.Errorf("failed to rename tag: %w", err)
+	}
+	tag.Name = name
+	tag.UpdatedAt = time.Now().UTC()
+
+	result := *tag
+	return &result, nil
+}
+
+func (s *Store) SetTagColor(ctx context.Context, id, color string) (*server.Tag, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tag, ok := s.tags[id]
+	if !ok {
+		return nil, server.NotFound("tag", id)
+	}
+	tag.Color = color
+	tag.UpdatedAt = time.Now().UTC()
+
+	result := *tag
+	return &result, nil
+}
+
+func (s *Store) DeleteTag(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.tags[id]; !ok {
+		return server.NotFound("tag", id)
+	}
+	s.deleteTagLocked(id)
+
+	return nil
+}
+
+func (s *Store) MergeTags(ctx context.Context, sourceID, targetID string) (*server.Tag, error) {
+	if sourceID == targetID {
+		return nil, server.InvalidInput("tag %s cannot be merged into itself", sourceID)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.tags[sourceID]; !ok {
+		return nil, server.NotFound("tag", sourceID)
+	}
+	target, ok := s.tags[targetID]
+	if !ok {
+		return nil, server.NotFound("tag", targetID)
+	}
+	for _, tagIDs := range s.taskTags {
+		if tagIDs[sourceID] {
+			tagIDs[targetID] = true
+		}
+	}
+	s.deleteTagLocked(sourceID)
+
+	result := *target
+	return &result, nil
+}
+
+func (s *Store) AddTagToTask(ctx context.Context, taskID, tagID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.tasks[taskID]; !ok {
+		return server.NotFound("task", taskID)
+	}
+	if _, ok := s.tags[tagID]; !ok {
+		return server.NotFound("tag", tagID)
+	}
+	if s.taskTags[taskID] == nil {
+		s.taskTags[taskID] = make(map[string]bool)
+	}
+	s.taskTags[taskID][tagID] = true
+
+	return nil
+}
+
+func (s *Store) RemoveTagFromTask(ctx context.Context, taskID, tagID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.tasks[taskID]; !ok {
+		return server.NotFound("task", taskID)
+	}
+	if _, ok := s.tags[tagID]; !ok {
+		return server.NotFound("tag", tagID)
+	}
+	delete(s.taskTags[taskID], tagID)
+
+	return nil
+}
+
+func (s *Store) GetTaskTags(ctx context.Context, taskID string) ([]server.Tag, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var tags []server.Tag
+	for tagID := range s.taskTags[taskID] {
+		tags = append(tags, *s.tags[tagID])
+	}
+	sortTags(tags)
+
+	return tags, nil
+}
+
+func (s *Store) GetTasksByTags(ctx context.Context, tagIDs []string, matchAll bool) ([]server.Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var tasks []server.Task
+	for _, task := range s.tasks {
+		matched := 0
+		for _, tagID := range tagIDs {
+			if s.taskTags[task.ID][tagID] {
+				matched++
+			}
+		}
+		if (matchAll && matched == len(tagIDs) && matched > 0) || (!matchAll && matched > 0) {
+			tasks = append(tasks, *task)
+		}
+	}
+	s.sortTasks(tasks)
+
+	return tasks, nil
+}
+
+// checkTagNameLocked fails with ErrConflict when a tag other than id has name,
+// regardless of case. The caller must hold s.mu.
+func (s *Store) checkTagNameLocked(id, name string) error {
+	for _, tag := range s.tags {
+		if tag.ID != id && strings.ToLower(tag.Name) == strings.ToLower(name) {
+			return server.Mark(fmt.Errorf("tag %q already exists", tag.Name), server.ErrConflict)
+		}
+	}
+	return nil
+}
+
+// deleteTagLocked removes a tag and takes it off every task. The caller must hold s.mu.
+func (s *Store) deleteTagLocked(id string) {
+	delete(s.tags, id)
+	delete(s.seq, id)
+	for _, tagIDs := range s.taskTags {
+		delete(tagIDs, id)
+	}
+}
+
+// sortTags orders tags by name regardless of case
+func sortTags(tags []server.Tag) {
+	sort.SliceStable(tags, func(i, j int) bool {
+		a, b := strings.ToLower(tags[i].Name), strings.ToLower(tags[j].Name)
+		if a != b {
+			return a < b
+		}
+		return tags[i].Name < tags[j].Name
+	})
+}
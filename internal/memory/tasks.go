@@ -0,0 +1,198 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	server "github.com/HolySxn/To-Do/internal"
+	"github.com/google/uuid"
+)
+
+// CRUD operations for Tasks
+func (s *Store) CreateTask(ctx context.Context, listID, taskName string) (*server.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkTaskListLocked(listID); err != nil {
+		return nil, fmt.Errorf("failed to create task: %w", err)
+	}
+
+	// New tasks are placed above the existing ones
+	minPosition := 1
+	for _, task := range s.tasks {
+		if task.ListID == listID && task.Position < minPosition {
+			minPosition = task.Position
+		}
+	}
+
+	id := uuid.NewString()
+	now := s.track(id)
+	task := &server.Task{
+		ID:        id,
+		ListID:    listID,
+		TaskName:  taskName,
+		Priority:  server.PriorityNone,
+		Position:  minPosition - 1,
+		Version:   1,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.tasks[id] = task
+	s.logLocked(task)
+
+	result := *task
+	return &result, nil
+}
+
+func (s *Store) GetTask(ctx context.Context, id string) (*server.Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	task, ok := s.tasks[id]
+	if !ok {
+		return nil, server.NotFound("task", id)
+	}
+
+	result := *task
+	return &result, nil
+}
+
+func (s *Store) GetTasksByListID(ctx context.Context, listID string) ([]server.Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var tasks []server.Task
+	for _, task := range s.tasks {
+		if task.ListID == listID {
+			tasks = append(tasks, *task)
+		}
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if tasks[i].Position != tasks[j].Position {
+			return tasks[i].Position < tasks[j].Position
+		}
+		return s.newer(tasks[i].ID, tasks[i].CreatedAt, tasks[j].ID, tasks[j].CreatedAt)
+	})
+
+	return tasks, nil
+}
+
+func (s *Store) GetAllTasks(ctx context.Context) ([]server.Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var tasks []server.Task
+	for _, task := range s.tasks {
+		tasks = append(tasks, *task)
+	}
+	s.sortTasks(tasks)
+
+	return tasks, nil
+}
+
+func (s *Store) UpdateTask(ctx context.Context, id, taskName string, completed bool, version int64) (*server.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[id]
+	if !ok {
+		return nil, server.NotFound("task", id)
+	}
+	if err := taskConflict(task, version); err != nil {
+		return nil, err
+	}
+	task.TaskName = taskName
+	task.Completed = completed
+	task.UpdatedAt = time.Now().UTC()
+	task.Version++
+	s.logLocked(task)
+
+	result := *task
+	return &result, nil
+}
+
+func (s *Store) ToggleTaskCompletion(ctx context.Context, id string) (*server.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[id]
+	if !ok {
+		return nil, server.NotFound("task", id)
+	}
+	if !task.Completed {
+		completion, err := s.completeTaskLocked(task)
+		if err != nil {
+			return nil, err
+		}
+		return &completion.Task, nil
+	}
+	task.Completed = false
+	task.UpdatedAt = time.Now().UTC()
+	task.Version++
+	s.logLocked(task)
+
+	result := *task
+	return &result, nil
+}
+
+func (s *Store) DeleteTask(ctx context.Context, id string, version int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[id]
+	if !ok {
+		return server.NotFound("task", id)
+	}
+	if err := taskConflict(task, version); err != nil {
+		return err
+	}
+	s.trashTaskLocked(id, time.Now().UTC())
+
+	return nil
+}
+
+// ReorderTasks updates the positions of a list's tasks based on the provided order
+func (s *Store) ReorderTasks(ctx context.Context, listID string, taskIDs []string) error {
+	if len(taskIDs) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	for i, taskID := range taskIDs {
+		// Ids from other lists are skipped, matching an UPDATE that affects no rows
+		if task, ok := s.tasks[taskID]; ok && task.ListID == listID {
+			task.Position = i + 1
+			task.UpdatedAt = now
+			task.Version++
+		}
+	}
+
+	return nil
+}
+
+// trashTaskLocked moves a task and its subtasks to the trash. The caller must hold s.mu.
+func (s *Store) trashTaskLocked(id string, now time.Time) {
+	for subTaskID, subTask := range s.subTasks {
+		if subTask.TaskID == id {
+			s.trashSubTaskLocked(subTaskID, now)
+		}
+	}
+	task := s.tasks[id]
+	delete(s.tasks, id)
+	task.DeletedAt = &now
+	task.Version++
+	s.trashedTasks[id] = task
+	s.logLocked(task)
+}
+
+// sortTasks orders tasks by created_at DESC
+func (s *Store) sortTasks(tasks []server.Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return s.newer(tasks[i].ID, tasks[i].CreatedAt, tasks[j].ID, tasks[j].CreatedAt)
+	})
+}
@@ -0,0 +1,175 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+func (s *Store) GetTrash(ctx context.Context) (*server.Trash, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	trash := &server.Trash{}
+	for _, list := range s.trashedLists {
+		trash.Lists = append(trash.Lists, *list)
+	}
+	// Rows trashed along with their parent are listed through it
+	for _, task := range s.trashedTasks {
+		if list, ok := s.trashedLists[task.ListID]; !ok || !list.DeletedAt.Equal(*task.DeletedAt) {
+			trash.Tasks = append(trash.Tasks, *task)
+		}
+	}
+	for _, subTask := range s.trashedSubTasks {
+		if task, ok := s.trashedTasks[subTask.TaskID]; !ok || !task.DeletedAt.Equal(*subTask.DeletedAt) {
+			trash.SubTasks = append(trash.SubTasks, *subTask)
+		}
+	}
+
+	// Most recently deleted first
+	sort.SliceStable(trash.Lists, func(i, j int) bool {
+		return s.newer(trash.Lists[i].ID, *trash.Lists[i].DeletedAt, trash.Lists[j].ID, *trash.Lists[j].DeletedAt)
+	})
+	sort.SliceStable(trash.Tasks, func(i, j int) bool {
+		return s.newer(trash.Tasks[i].ID, *trash.Tasks[i].DeletedAt, trash.Tasks[j].ID, *trash.Tasks[j].DeletedAt)
+	})
+	sort.SliceStable(trash.SubTasks, func(i, j int) bool {
+		return s.newer(trash.SubTasks[i].ID, *trash.SubTasks[i].DeletedAt, trash.SubTasks[j].ID, *trash.SubTasks[j].DeletedAt)
+	})
+
+	return trash, nil
+}
+
+func (s *Store) RestoreList(ctx context.Context, id string) (*server.List, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, ok := s.trashedLists[id]
+	if !ok {
+		return nil, server.NotFound("list", id)
+	}
+	s.restoreListLocked(list, time.Now().UTC())
+
+	result := *list
+	return &result, nil
+}
+
+func (s *Store) RestoreTask(ctx context.Context, id string) (*server.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.trashedTasks[id]
+	if !ok {
+		return nil, server.NotFound("task", id)
+	}
+	now := time.Now().UTC()
+	if list, ok := s.trashedLists[task.ListID]; ok {
+		s.restoreRowLocked(list, now)
+	}
+	s.restoreTaskLocked(task, now)
+
+	result := *task
+	return &result, nil
+}
+
+func (s *Store) RestoreSubTask(ctx context.Context, id string) (*server.SubTask, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subTask, ok := s.trashedSubTasks[id]
+	if !ok {
+		return nil, server.NotFound("subtask", id)
+	}
+	now := time.Now().UTC()
+	if task, ok := s.trashedTasks[subTask.TaskID]; ok {
+		if list, ok := s.trashedLists[task.ListID]; ok {
+			s.restoreRowLocked(list, now)
+		}
+		s.restoreRowLocked(task, now)
+	}
+	s.restoreRowLocked(subTask, now)
+
+	result := *subTask
+	return &result, nil
+}
+
+func (s *Store) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	purgedIDs := make(map[string]bool)
+	for id, subTask := range s.trashedSubTasks {
+		if subTask.DeletedAt.Before(before) {
+			delete(s.trashedSubTasks, id)
+			delete(s.seq, id)
+			purgedIDs[id] = true
+		}
+	}
+	for id, task := range s.trashedTasks {
+		if task.DeletedAt.Before(before) {
+			delete(s.trashedTasks, id)
+			delete(s.taskTags, id)
+			delete(s.seq, id)
+			purgedIDs[id] = true
+		}
+	}
+	for id, list := range s.trashedLists {
+		if list.DeletedAt.Before(before) {
+			delete(s.trashedLists, id)
+			delete(s.seq, id)
+			purgedIDs[id] = true
+		}
+	}
+	s.purgeActivityLocked(purgedIDs)
+
+	return int64(len(purgedIDs)), nil
+}
+
+// restoreListLocked restores a list with the tasks trashed along with it.
+// The caller must hold s.mu.
+func (s *Store) restoreListLocked(list *server.List, now time.Time) {
+	for _, task := range s.trashedTasks {
+		if task.ListID == list.ID && task.DeletedAt.Equal(*list.DeletedAt) {
+			s.restoreTaskLocked(task, now)
+		}
+	}
+	s.restoreRowLocked(list, now)
+}
+
+// restoreTaskLocked restores a task with the subtasks trashed along with it.
+// The caller must hold s.mu.
+func (s *Store) restoreTaskLocked(task *server.Task, now time.Time) {
+	for _, subTask := range s.trashedSubTasks {
+		if subTask.TaskID == task.ID && subTask.DeletedAt.Equal(*task.DeletedAt) {
+			s.restoreRowLocked(subTask, now)
+		}
+	}
+	s.restoreRowLocked(task, now)
+}
+
+// restoreRowLocked moves a single trashed row back. The caller must hold s.mu.
+func (s *Store) restoreRowLocked(row any, now time.Time) {
+	switch row := row.(type) {
+	case *server.List:
+		delete(s.trashedLists, row.ID)
+		row.DeletedAt = nil
+		row.UpdatedAt = now
+		row.Version++
+		s.lists[row.ID] = row
+	case *server.Task:
+		delete(s.trashedTasks, row.ID)
+		row.DeletedAt = nil
+		row.UpdatedAt = now
+		row.Version++
+		s.tasks[row.ID] = row
+	case *server.SubTask:
+		delete(s.trashedSubTasks, row.ID)
+		row.DeletedAt = nil
+		row.UpdatedAt = now
+		row.Version++
+		s.subTasks[row.ID] = row
+	}
+	s.logLocked(row)
+}
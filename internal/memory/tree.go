@@ -0,0 +1,61 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+func (s *Store) GetListTree(ctx context.Context, listID string) (*server.ListTree, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list, ok := s.lists[listID]
+	if !ok {
+		return nil, server.NotFound("list", listID)
+	}
+
+	tasks, subTasks := s.treeRowsLocked(func(task *server.Task) bool { return task.ListID == listID })
+	return &server.BuildTrees([]server.List{*list}, tasks, subTasks)[0], nil
+}
+
+func (s *Store) GetWorkspaceTree(ctx context.Context) ([]server.ListTree, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tasks, subTasks := s.treeRowsLocked(func(task *server.Task) bool { return true })
+	return server.BuildTrees(s.listsLocked(), tasks, subTasks), nil
+}
+
+// treeRowsLocked returns the tasks that pass keep and their subtasks, ordered
+// by position
+func (s *Store) treeRowsLocked(keep func(task *server.Task) bool) ([]server.Task, []server.SubTask) {
+	var tasks []server.Task
+	for _, task := range s.tasks {
+		if keep(task) {
+			tasks = append(tasks, *task)
+		}
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if tasks[i].Position != tasks[j].Position {
+			return tasks[i].Position < tasks[j].Position
+		}
+		return s.newer(tasks[i].ID, tasks[i].CreatedAt, tasks[j].ID, tasks[j].CreatedAt)
+	})
+
+	var subTasks []server.SubTask
+	for _, subTask := range s.subTasks {
+		if task, ok := s.tasks[subTask.TaskID]; ok && keep(task) {
+			subTasks = append(subTasks, *subTask)
+		}
+	}
+	sort.SliceStable(subTasks, func(i, j int) bool {
+		if subTasks[i].Position != subTasks[j].Position {
+			return subTasks[i].Position < subTasks[j].Position
+		}
+		return s.newer(subTasks[i].ID, subTasks[i].CreatedAt, subTasks[j].ID, subTasks[j].CreatedAt)
+	})
+
+	return tasks, subTasks
+}
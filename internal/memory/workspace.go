@@ -0,0 +1,126 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	server "github.com/HolySxn/To-Do/internal"
+	"github.com/HolySxn/To-Do/internal/query"
+)
+
+// checkQuery is the ImportState.CheckQuery of the store
+func checkQuery(q string) error {
+	_, err := query.Parse(q, time.Now())
+	return err
+}
+
+func (s *Store) ExportWorkspace(ctx context.Context) (*server.Workspace, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tasks, subTasks := s.treeRowsLocked(func(task *server.Task) bool { return true })
+	var tags []server.Tag
+	for _, tag := range s.tags {
+		tags = append(tags, *tag)
+	}
+	sortTags(tags)
+	var taskTags []server.TaskTag
+	for taskID, tagIDs := range s.taskTags {
+		for tagID := range tagIDs {
+			taskTags = append(taskTags, server.TaskTag{TaskID: taskID, TagID: tagID})
+		}
+	}
+
+	return server.BuildWorkspace(s.listsLocked(), tasks, subTasks, tags, taskTags, time.Now()), nil
+}
+
+func (s *Store) ImportWorkspace(ctx context.Context, doc *server.Workspace, mode server.ImportMode, dryRun bool) (*server.ImportReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := server.ImportState{CheckQuery: checkQuery}
+	for _, lists := range []map[string]*server.List{s.lists, s.trashedLists} {
+		for _, list := range lists {
+			state.Lists = append(state.Lists, *list)
+		}
+	}
+	for _, tasks := range []map[string]*server.Task{s.tasks, s.trashedTasks} {
+		for _, task := range tasks {
+			state.Tasks = append(state.Tasks, *task)
+		}
+	}
+	for _, subTasks := range []map[string]*server.SubTask{s.subTasks, s.trashedSubTasks} {
+		for _, subTask := range subTasks {
+			state.SubTasks = append(state.SubTasks, *subTask)
+		}
+	}
+	for _, tag := range s.tags {
+		state.Tags = append(state.Tags, *tag)
+	}
+	for taskID, tagIDs := range s.taskTags {
+		for tagID := range tagIDs {
+			state.TaskTags = append(state.TaskTags, server.TaskTag{TaskID: taskID, TagID: tagID})
+		}
+	}
+
+	plan, err := server.PlanImport(doc, mode, state, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if dryRun {
+		plan.Report.DryRun = true
+		return &plan.Report, nil
+	}
+
+	if mode == server.ImportReplace {
+		s.lists = make(map[string]*server.List)
+		s.tasks = make(map[string]*server.Task)
+		s.subTasks = make(map[string]*server.SubTask)
+		s.trashedLists = make(map[string]*server.List)
+		s.trashedTasks = make(map[string]*server.Task)
+		s.trashedSubTasks = make(map[string]*server.SubTask)
+		s.tags = make(map[string]*server.Tag)
+		s.taskTags = make(map[string]map[string]bool)
+		s.seq = make(map[string]int64)
+		s.activity = nil
+		s.logged = make(map[string]map[string]any)
+	}
+	for _, list := range plan.Lists {
+		s.track(list.ID)
+		s.lists[list.ID] = &list
+		s.logLocked(&list)
+	}
+	for _, task := range plan.Tasks {
+		s.track(task.ID)
+		s.tasks[task.ID] = &task
+		s.logLocked(&task)
+	}
+	for _, subTask := range plan.SubTasks {
+		s.track(subTask.ID)
+		s.subTasks[subTask.ID] = &subTask
+		s.logLocked(&subTask)
+	}
+	for _, list := range plan.UpdatedLists {
+		s.lists[list.ID] = &list
+		s.logLocked(&list)
+	}
+	for _, task := range plan.UpdatedTasks {
+		s.tasks[task.ID] = &task
+		s.logLocked(&task)
+	}
+	for _, subTask := range plan.UpdatedSubTasks {
+		s.subTasks[subTask.ID] = &subTask
+		s.logLocked(&subTask)
+	}
+	for _, tag := range plan.Tags {
+		s.tags[tag.ID] = &tag
+	}
+	for _, taskTag := range plan.TaskTags {
+		if s.taskTags[taskTag.TaskID] == nil {
+			s.taskTags[taskTag.TaskID] = make(map[string]bool)
+		}
+		s.taskTags[taskTag.TaskID][taskTag.TagID] = true
+	}
+
+	return &plan.Report, nil
+}
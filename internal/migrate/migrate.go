@@ -0,0 +1,242 @@
+// Package migrate applies versioned, embedded SQL migrations.
+//
+// Migrations are read from files named <version>_<name>.up.sql and
+// <version>_<name>.down.sql. Applied versions are recorded in a
+// schema_migrations table maintained by the database-specific Driver.
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Direction selects which half of a migration is run
+type Direction int
+
+const (
+	Up Direction = iota
+	Down
+)
+
+func (d Direction) String() string {
+	if d == Down {
+		return "down"
+	}
+	return "up"
+}
+
+// Migration is a single schema change
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Record is a row of the schema_migrations table
+type Record struct {
+	Version   int64
+	Name      string
+	AppliedAt time.Time
+}
+
+// Status describes whether a known migration has been applied
+type Status struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+// Driver runs migrations against a specific database engine
+type Driver interface {
+	// Lock blocks until this process holds the migration lock, so that
+	// several app instances never migrate the same database concurrently
+	Lock(ctx context.Context) error
+	Unlock(ctx context.Context) error
+	// EnsureVersionTable creates schema_migrations if it does not exist
+	EnsureVersionTable(ctx context.Context) error
+	// Applied returns the recorded migrations in ascending version order
+	Applied(ctx context.Context) ([]Record, error)
+	// Apply runs one direction of a migration and updates schema_migrations
+	// in the same transaction. It is a no-op if the migration is already in
+	// the requested state.
+	Apply(ctx context.Context, m Migration, dir Direction) error
+}
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Load reads migrations from the root of fsys sorted by version
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("unexpected migration file name %q", entry.Name())
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %q: %w", entry.Name(), err)
+		}
+		body, err := fs.ReadFile(fsys, path.Clean(entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %q: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Migrator applies a set of migrations through a Driver
+type Migrator struct {
+	driver     Driver
+	migrations []Migration
+	logger     *slog.Logger
+}
+
+func New(driver Driver, migrations []Migration, logger *slog.Logger) *Migrator {
+	return &Migrator{
+		driver:     driver,
+		migrations: migrations,
+		logger:     logger,
+	}
+}
+
+// Status reports every known migration and whether it has been applied
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+	err := m.locked(ctx, func(applied map[int64]Record) error {
+		for _, migration := range m.migrations {
+			status := Status{Version: migration.Version, Name: migration.Name}
+			if record, ok := applied[migration.Version]; ok {
+				appliedAt := record.AppliedAt
+				status.Applied = true
+				status.AppliedAt = &appliedAt
+			}
+			statuses = append(statuses, status)
+		}
+		return nil
+	})
+	return statuses, err
+}
+
+// Up applies all pending migrations in version order and returns the ones it ran
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var ran []Migration
+	err := m.locked(ctx, func(applied map[int64]Record) error {
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			if err := m.apply(ctx, migration, Up); err != nil {
+				return err
+			}
+			ran = append(ran, migration)
+		}
+		return nil
+	})
+	return ran, err
+}
+
+// Down reverts the given number of most recently applied migrations
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps <= 0 {
+		return nil, fmt.Errorf("steps must be positive, got %d", steps)
+	}
+
+	var ran []Migration
+	err := m.locked(ctx, func(applied map[int64]Record) error {
+		for i := len(m.migrations) - 1; i >= 0 && len(ran) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			if migration.Down == "" {
+				return fmt.Errorf("migration %d_%s cannot be reverted: no down file", migration.Version, migration.Name)
+			}
+			if err := m.apply(ctx, migration, Down); err != nil {
+				return err
+			}
+			ran = append(ran, migration)
+		}
+		return nil
+	})
+	return ran, err
+}
+
+func (m *Migrator) apply(ctx context.Context, migration Migration, dir Direction) error {
+	m.logger.Info("Applying migration", "version", migration.Version, "name", migration.Name, "direction", dir.String())
+	if err := m.driver.Apply(ctx, migration, dir); err != nil {
+		m.logger.Error("Failed to apply migration", "version", migration.Version, "name", migration.Name, "direction", dir.String(), "error", err)
+		return fmt.Errorf("failed to apply migration %d_%s (%s): %w", migration.Version, migration.Name, dir, err)
+	}
+	return nil
+}
+
+// locked runs fn while holding the migration lock, passing the applied migrations by version
+func (m *Migrator) locked(ctx context.Context, fn func(applied map[int64]Record) error) (err error) {
+	if err := m.driver.Lock(ctx); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer func() {
+		if unlockErr := m.driver.Unlock(ctx); unlockErr != nil && err == nil {
+			err = fmt.Errorf("failed to release migration lock: %w", unlockErr)
+		}
+	}()
+
+	if err := m.driver.EnsureVersionTable(ctx); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	records, err := m.driver.Applied(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	applied := make(map[int64]Record, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+
+	return fn(applied)
+}
@@ -0,0 +1,141 @@
+package migrate
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// fakeDriver records applied migrations in memory
+type fakeDriver struct {
+	applied map[int64]Record
+	locked  bool
+	ran     []string
+}
+
+func (d *fakeDriver) Lock(ctx context.Context) error {
+	d.locked = true
+	return nil
+}
+
+func (d *fakeDriver) Unlock(ctx context.Context) error {
+	d.locked = false
+	return nil
+}
+
+func (d *fakeDriver) EnsureVersionTable(ctx context.Context) error {
+	if d.applied == nil {
+		d.applied = make(map[int64]Record)
+	}
+	return nil
+}
+
+func (d *fakeDriver) Applied(ctx context.Context) ([]Record, error) {
+	var records []Record
+	for _, record := range d.applied {
+		records = append(records, record)
+	}
+	slices.SortFunc(records, func(a, b Record) int { return int(a.Version - b.Version) })
+	return records, nil
+}
+
+func (d *fakeDriver) Apply(ctx context.Context, m Migration, dir Direction) error {
+	if !d.locked {
+		panic("migration applied without the lock")
+	}
+	if dir == Up {
+		d.ran = append(d.ran, m.Up)
+		d.applied[m.Version] = Record{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}
+	} else {
+		d.ran = append(d.ran, m.Down)
+		delete(d.applied, m.Version)
+	}
+	return nil
+}
+
+func TestLoad(t *testing.T) {
+	migrations, err := Load(fstest.MapFS{
+		"0002_tasks.up.sql":   {Data: []byte("up 2")},
+		"0002_tasks.down.sql": {Data: []byte("down 2")},
+		"0001_lists.up.sql":   {Data: []byte("up 1")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Migration{
+		{Version: 1, Name: "lists", Up: "up 1"},
+		{Version: 2, Name: "tasks", Up: "up 2", Down: "down 2"},
+	}
+	if !slices.Equal(migrations, want) {
+		t.Errorf("Load() = %+v, want %+v", migrations, want)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := map[string]fstest.MapFS{
+		"bad name":          {"lists.sql": {Data: []byte("x")}},
+		"no up file":        {"0001_lists.down.sql": {Data: []byte("x")}},
+		"conflicting names": {"0001_lists.up.sql": {Data: []byte("x")}, "0001_tasks.down.sql": {Data: []byte("x")}},
+	}
+	for name, fsys := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Load(fsys); err == nil {
+				t.Error("Load() accepted the migrations")
+			}
+		})
+	}
+}
+
+func TestMigrator(t *testing.T) {
+	ctx := context.Background()
+	driver := &fakeDriver{}
+	m := New(driver, []Migration{
+		{Version: 1, Name: "lists", Up: "up 1", Down: "down 1"},
+		{Version: 2, Name: "tasks", Up: "up 2", Down: "down 2"},
+		{Version: 3, Name: "tags", Up: "up 3"},
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	ran, err := m.Up(ctx)
+	if err != nil || len(ran) != 3 {
+		t.Fatalf("Up() = %d migrations, %v; want 3", len(ran), err)
+	}
+	if ran, err := m.Up(ctx); err != nil || len(ran) != 0 {
+		t.Fatalf("second Up() = %d migrations, %v; want none", len(ran), err)
+	}
+
+	// Migration 3 has no down file
+	if _, err := m.Down(ctx, 1); err == nil {
+		t.Fatal("Down() reverted a migration without a down file")
+	}
+	delete(driver.applied, 3)
+	if _, err := m.Down(ctx, 0); err == nil {
+		t.Fatal("Down(0) succeeded")
+	}
+	if ran, err := m.Down(ctx, 1); err != nil || len(ran) != 1 || ran[0].Version != 2 {
+		t.Fatalf("Down(1) = %+v, %v; want migration 2", ran, err)
+	}
+
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var applied []int64
+	for _, status := range statuses {
+		if status.Applied {
+			applied = append(applied, status.Version)
+		}
+	}
+	if len(statuses) != 3 || !slices.Equal(applied, []int64{1}) {
+		t.Errorf("Status() = %+v, want 3 migrations with only 1 applied", statuses)
+	}
+	if want := []string{"up 1", "up 2", "up 3", "down 2"}; !slices.Equal(driver.ran, want) {
+		t.Errorf("ran %q, want %q", driver.ran, want)
+	}
+	if driver.locked {
+		t.Error("lock still held")
+	}
+}
@@ -4,28 +4,298 @@ import (
 	"time"
 )
 
+// Version starts at 1 and is incremented by every change to a row; update and
+// delete calls may pass the version they expect to detect concurrent edits.
+// DeletedAt is set only on rows returned from the trash.
 type List struct {
-	ID        string    `json:"id"`
-	Title     string    `json:"title"`
-	Position  int       `json:"position"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	// Query is set on smart lists, which hold no tasks of their own and show
+	// the tasks across all lists that match it; see package query
+	Query     *string    `json:"query"`
+	Position  int        `json:"position"`
+	Version   int64      `json:"version"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 type Task struct {
+	ID       string `json:"id"`
+	ListID   string `json:"list_id"`
+	TaskName string `json:"task_name"`
+	// Notes is free-form markdown
+	Notes     string   `json:"notes"`
+	Completed bool     `json:"completed"`
+	Priority  Priority `json:"priority"`
+	Starred   bool     `json:"starred"`
+	Position  int      `json:"position"`
+	// DueDate is set for tasks due on a whole day, formatted as YYYY-MM-DD.
+	// DueAt is set for tasks due at a specific moment. At most one of them is set.
+	DueDate  *string    `json:"due_date"`
+	DueAt    *time.Time `json:"due_at"`
+	RemindAt *time.Time `json:"remind_at"`
+	// Recurrence is an RRULE such as "FREQ=WEEKLY;BYDAY=MO"; see ParseRecurrence
+	Recurrence *string    `json:"recurrence"`
+	Version    int64      `json:"version"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
+}
+
+// TaskCompletion is the result of completing a task. Next is the occurrence
+// spawned when a recurring task is completed.
+type TaskCompletion struct {
+	Task Task  `json:"task"`
+	Next *Task `json:"next"`
+}
+
+// Priority is a task's priority level
+type Priority string
+
+const (
+	PriorityNone   Priority = "none"
+	PriorityLow    Priority = "low"
+	PriorityMedium Priority = "medium"
+	PriorityHigh   Priority = "high"
+)
+
+// Valid reports whether p is one of the known priority levels
+func (p Priority) Valid() bool {
+	switch p {
+	case PriorityNone, PriorityLow, PriorityMedium, PriorityHigh:
+		return true
+	}
+	return false
+}
+
+type SubTask struct {
+	ID          string     `json:"id"`
+	TaskID      string     `json:"task_id"`
+	SubTaskName string     `json:"subtask_name"`
+	Completed   bool       `json:"completed"`
+	Position    int        `json:"position"`
+	Version     int64      `json:"version"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+}
+
+// Trash holds the rows that were deleted directly, most recently deleted first.
+// Tasks and subtasks deleted along with their list or task are restored with
+// it and are not listed on their own.
+type Trash struct {
+	Lists    []List    `json:"lists"`
+	Tasks    []Task    `json:"tasks"`
+	SubTasks []SubTask `json:"subtasks"`
+}
+
+// TaskPage is one page of tasks. NextCursor reads the following page and is
+// empty on the last one.
+type TaskPage struct {
+	Tasks      []Task `json:"tasks"`
+	NextCursor string `json:"next_cursor"`
+}
+
+// SubTaskPage is one page of subtasks; see TaskPage
+type SubTaskPage struct {
+	SubTasks   []SubTask `json:"subtasks"`
+	NextCursor string    `json:"next_cursor"`
+}
+
+// ListTree is a list with its tasks and their subtasks, each in display order,
+// and how many of its tasks are completed. Smart is set for smart lists, which
+// have no tasks of their own and can't be added to; their tasks are read with
+// GetSmartListTasks.
+type ListTree struct {
+	List           List       `json:"list"`
+	Smart          bool       `json:"smart"`
+	Tasks          []TaskTree `json:"tasks"`
+	CompletedTasks int        `json:"completed_tasks"`
+	TotalTasks     int        `json:"total_tasks"`
+}
+
+// TaskTree is a task with its subtasks in display order and how many of them
+// are completed
+type TaskTree struct {
+	Task              Task      `json:"task"`
+	SubTasks          []SubTask `json:"subtasks"`
+	CompletedSubTasks int       `json:"completed_subtasks"`
+	TotalSubTasks     int       `json:"total_subtasks"`
+}
+
+// Tag labels tasks across lists. Names are unique regardless of case; Color is
+// a #rrggbb hex value, or empty for the default color.
+type Tag struct {
 	ID        string    `json:"id"`
-	ListID    string    `json:"list_id"`
-	TaskName  string    `json:"task_name"`
-	Completed bool      `json:"completed"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
-type SubTask struct {
+// SearchHit is a list, task or subtask whose name matches a search. ListID and
+// ListTitle name the hit's list, or the list itself; TaskID and TaskName are
+// set only for subtasks. Rank is between 0 and 1, higher for better matches,
+// and Snippet is the name as HTML with the matched words wrapped in <mark>.
+type SearchHit struct {
+	Entity    string  `json:"entity"`
+	ID        string  `json:"id"`
+	Name      string  `json:"name"`
+	ListID    string  `json:"list_id"`
+	ListTitle string  `json:"list_title"`
+	TaskID    *string `json:"task_id"`
+	TaskName  *string `json:"task_name"`
+	Rank      float64 `json:"rank"`
+	Snippet   string  `json:"snippet"`
+}
+
+// Activity records one change to a list, task or subtask. Before and After hold
+// only the fields that changed, keyed by their JSON names; a create has no
+// Before. TaskID is set for tasks and subtasks, and ListID is the list the row
+// belonged to after the change. Actor is who made the change, as passed to
+// SetActor; it is empty when none was set.
+type Activity struct {
+	ID       int64          `json:"id"`
+	Entity   string         `json:"entity"`
+	EntityID string         `json:"entity_id"`
+	ListID   string         `json:"list_id"`
+	TaskID   *string        `json:"task_id"`
+	Action   string         `json:"action"`
+	Name     string         `json:"name"`
+	Actor    string         `json:"actor"`
+	Before   map[string]any `json:"before"`
+	After    map[string]any `json:"after"`
+	At       time.Time      `json:"at"`
+}
+
+// Activity actions. A toggle changes only the completion; a move changes the
+// list of a task or the task of a subtask.
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionToggle  = "toggle"
+	ActionMove    = "move"
+	ActionDelete  = "delete"
+	ActionRestore = "restore"
+)
+
+// Workspace is a document holding every list, task, subtask and tag outside
+// the trash, used to back up and move a workspace. Tasks and subtasks are
+// nested under their list and task, and tasks refer to tags by name.
+type Workspace struct {
+	Version    int             `json:"version"`
+	ExportedAt time.Time       `json:"exported_at"`
+	Tags       []WorkspaceTag  `json:"tags"`
+	Lists      []WorkspaceList `json:"lists"`
+}
+
+// WorkspaceTag is a tag of a Workspace
+type WorkspaceTag struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// WorkspaceList is a list of a Workspace with its tasks
+type WorkspaceList struct {
+	ID        string          `json:"id"`
+	Title     string          `json:"title"`
+	Query     *string         `json:"query,omitempty"`
+	Position  int             `json:"position"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	Tasks     []WorkspaceTask `json:"tasks"`
+	// Append adds the tasks to the existing list with ID, after its own tasks,
+	// instead of creating a list. It is set by the text format importers and
+	// only allowed when merging.
+	Append bool `json:"-"`
+}
+
+// WorkspaceTask is a task of a Workspace with its subtasks and tag names
+type WorkspaceTask struct {
+	ID         string             `json:"id"`
+	TaskName   string             `json:"task_name"`
+	Notes      string             `json:"notes"`
+	Completed  bool               `json:"completed"`
+	Priority   Priority           `json:"priority"`
+	Starred    bool               `json:"starred"`
+	Position   int                `json:"position"`
+	DueDate    *string            `json:"due_date,omitempty"`
+	DueAt      *time.Time         `json:"due_at,omitempty"`
+	RemindAt   *time.Time         `json:"remind_at,omitempty"`
+	Recurrence *string            `json:"recurrence,omitempty"`
+	Tags       []string           `json:"tags"`
+	CreatedAt  time.Time          `json:"created_at"`
+	UpdatedAt  time.Time          `json:"updated_at"`
+	SubTasks   []WorkspaceSubTask `json:"subtasks"`
+}
+
+// WorkspaceSubTask is a subtask of a Workspace
+type WorkspaceSubTask struct {
 	ID          string    `json:"id"`
-	TaskID      string    `json:"task_id"`
 	SubTaskName string    `json:"subtask_name"`
 	Completed   bool      `json:"completed"`
+	Position    int       `json:"position"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
+
+// ImportMode says what an import does with the existing workspace
+type ImportMode string
+
+const (
+	// ImportMerge updates the rows whose ids exist and adds the other lists
+	// after the existing ones
+	ImportMerge ImportMode = "merge"
+	// ImportReplace permanently deletes every list, task, subtask and tag,
+	// including the trash and the activity log, before importing
+	ImportReplace ImportMode = "replace"
+)
+
+// Valid reports whether m is a known mode
+func (m ImportMode) Valid() bool {
+	return m == ImportMerge || m == ImportReplace
+}
+
+// ImportReport tells what an import changed or, for a dry run, would change.
+// Updated counts existing rows matched by id whose fields the import changes.
+// Remapped maps the ids of imported rows that were already taken to the new
+// ids they were given. Rows describes every imported list, task, subtask and
+// created tag in document order.
+type ImportReport struct {
+	Mode     ImportMode        `json:"mode"`
+	DryRun   bool              `json:"dry_run"`
+	Created  ImportCounts      `json:"created"`
+	Updated  ImportCounts      `json:"updated"`
+	Deleted  ImportCounts      `json:"deleted"`
+	Remapped map[string]string `json:"remapped"`
+	Rows     []ImportRow       `json:"rows"`
+}
+
+// ImportRow is what an import does with one row of the document. ID is the
+// id the row has after the import, and SourceID the id in the document when
+// it was remapped. Changed names the fields an update changes, keyed like the
+// JSON models, with "tags" for tags added to a task.
+type ImportRow struct {
+	Entity   string   `json:"entity"`
+	ID       string   `json:"id"`
+	SourceID string   `json:"source_id,omitempty"`
+	Name     string   `json:"name"`
+	Action   string   `json:"action"`
+	Changed  []string `json:"changed,omitempty"`
+}
+
+// Actions of an ImportRow
+const (
+	ImportCreate    = "create"
+	ImportUpdate    = "update"
+	ImportUnchanged = "unchanged"
+)
+
+// ImportCounts counts rows of each kind
+type ImportCounts struct {
+	Lists    int `json:"lists"`
+	Tasks    int `json:"tasks"`
+	SubTasks int `json:"subtasks"`
+	Tags     int `json:"tags"`
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+)
+
+// Cursor is the sort key of the last row of a page, from which the next page
+// is read. Clients see it only as the opaque string returned by Encode.
+type Cursor struct {
+	Position  int       `json:"p,omitempty"`
+	CreatedAt time.Time `json:"c"`
+	ID        string    `json:"i"`
+}
+
+// Encode returns the cursor as an opaque URL-safe string
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a cursor returned by Encode. An empty string is the
+// start of the first page and decodes to nil.
+func DecodeCursor(s string) (*Cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, InvalidInput("invalid cursor %q", s)
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == "" {
+		return nil, InvalidInput("invalid cursor %q", s)
+	}
+	c.CreatedAt = c.CreatedAt.UTC()
+	return &c, nil
+}
+
+// ParsePage checks the size and cursor of a page request
+func ParsePage(size int, cursor string) (*Cursor, error) {
+	if size < 1 {
+		return nil, InvalidInput("page size must be positive, got %d", size)
+	}
+	return DecodeCursor(cursor)
+}
+
+// NewTaskPage returns the first size of rows as a page. Stores read size+1
+// rows so that an extra row tells there is a next page.
+func NewTaskPage(rows []Task, size int) *TaskPage {
+	page := &TaskPage{Tasks: rows}
+	if len(rows) > size {
+		page.Tasks = rows[:size]
+		last := page.Tasks[size-1]
+		page.NextCursor = Cursor{Position: last.Position, CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+	if page.Tasks == nil {
+		page.Tasks = []Task{}
+	}
+	return page
+}
+
+// NewSubTaskPage is NewTaskPage for subtasks
+func NewSubTaskPage(rows []SubTask, size int) *SubTaskPage {
+	page := &SubTaskPage{SubTasks: rows}
+	if len(rows) > size {
+		page.SubTasks = rows[:size]
+		last := page.SubTasks[size-1]
+		page.NextCursor = Cursor{Position: last.Position, CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+	if page.SubTasks == nil {
+		page.SubTasks = []SubTask{}
+	}
+	return page
+}
@@ -0,0 +1,53 @@
+package server
+
+// ListPatch lists the list fields to change; nil fields are left untouched
+type ListPatch struct {
+	Title *string `json:"title,omitempty"`
+	// Query can only be changed on smart lists
+	Query *string `json:"query,omitempty"`
+	// Version is the expected current version; 0 skips the check
+	Version int64 `json:"version,omitempty"`
+}
+
+// Empty reports whether the patch changes nothing
+func (p ListPatch) Empty() bool {
+	return p.Title == nil && p.Query == nil
+}
+
+// TaskPatch lists the task fields to change; nil fields are left untouched.
+// Setting Completed does not spawn recurring occurrences; use CompleteTask for that.
+type TaskPatch struct {
+	TaskName  *string   `json:"task_name,omitempty"`
+	Notes     *string   `json:"notes,omitempty"`
+	Completed *bool     `json:"completed,omitempty"`
+	Priority  *Priority `json:"priority,omitempty"`
+	Starred   *bool     `json:"starred,omitempty"`
+	// Version is the expected current version; 0 skips the check
+	Version int64 `json:"version,omitempty"`
+}
+
+// Empty reports whether the patch changes nothing
+func (p TaskPatch) Empty() bool {
+	return p.TaskName == nil && p.Notes == nil && p.Completed == nil && p.Priority == nil && p.Starred == nil
+}
+
+// Validate checks the fields that have a restricted set of values
+func (p TaskPatch) Validate() error {
+	if p.Priority != nil && !p.Priority.Valid() {
+		return InvalidInput("invalid priority %q", *p.Priority)
+	}
+	return nil
+}
+
+// SubTaskPatch lists the subtask fields to change; nil fields are left untouched
+type SubTaskPatch struct {
+	SubTaskName *string `json:"subtask_name,omitempty"`
+	Completed   *bool   `json:"completed,omitempty"`
+	// Version is the expected current version; 0 skips the check
+	Version int64 `json:"version,omitempty"`
+}
+
+// Empty reports whether the patch changes nothing
+func (p SubTaskPatch) Empty() bool {
+	return p.SubTaskName == nil && p.Completed == nil
+}
@@ -0,0 +1,24 @@
+package server
+
+// InsertAt returns ids with id inserted at the given 1-based position.
+// Positions below 1 or past the end append id to the end.
+func InsertAt(ids []string, id string, position int) []string {
+	if position < 1 || position > len(ids) {
+		return append(ids, id)
+	}
+
+	result := make([]string, 0, len(ids)+1)
+	result = append(result, ids[:position-1]...)
+	result = append(result, id)
+	return append(result, ids[position-1:]...)
+}
+
+// IndexOf returns the 1-based position of id in ids, or 0 if it is absent
+func IndexOf(ids []string, id string) int {
+	for i, candidate := range ids {
+		if candidate == id {
+			return i + 1
+		}
+	}
+	return 0
+}
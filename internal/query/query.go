@@ -0,0 +1,348 @@
+// Package query parses the task query language used by smart lists, such as
+//
+//	completed:false due:<7d tag:work list:"Inbox" sort:due
+//
+// A query is a sequence of terms separated by spaces, all of which a task must
+// match. A term is a field:value filter, or a word or "quoted phrase" that
+// must appear in the task name. Prefixing a term with - negates it. Values
+// holding spaces are quoted.
+//
+//	completed:true|false   starred:true|false
+//	priority:high          priority:>=medium (also <, <=, >)
+//	due:2024-05-01         due:<7d, due:>=today, due:<=tomorrow, due:>-1w
+//	due:none, due:any      due:overdue (due before now)
+//	tag:work               list:"Inbox"
+//	sort:created|due|priority|name
+//
+// Relative days count from today in the location of the time the query is
+// parsed at. Tag names and list titles are matched regardless of case.
+//
+// A parsed query is run by a store either as the parameterized condition
+// returned by SQL, or by calling Match on every task.
+package query
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+// Sort orders
+const (
+	SortCreated  = "created"
+	SortDue      = "due"
+	SortPriority = "priority"
+	SortName     = "name"
+)
+
+// Query is a parsed query
+type Query struct {
+	terms []term
+	sort  string
+	loc   *time.Location
+}
+
+// Candidate is a task together with what a query looks up about it
+type Candidate struct {
+	Task      server.Task
+	ListTitle string
+	TagNames  []string
+}
+
+// Parse parses a query. Relative dates are resolved against now. Errors are
+// *SyntaxError.
+func Parse(input string, now time.Time) (*Query, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &Query{sort: SortCreated, loc: now.Location()}
+	sorted := false
+	for _, tok := range tokens {
+		if tok.field == "sort" {
+			if tok.negated {
+				return nil, invalid("sort cannot be negated")
+			}
+			if sorted {
+				return nil, invalid("sort is given more than once")
+			}
+			switch tok.value {
+			case SortCreated, SortDue, SortPriority, SortName:
+				q.sort, sorted = tok.value, true
+			default:
+				return nil, invalid("unknown sort %q; use created, due, priority or name", tok.value)
+			}
+			continue
+		}
+
+		t, err := parseTerm(tok, now)
+		if err != nil {
+			return nil, err
+		}
+		if tok.negated {
+			t = notTerm{t}
+		}
+		q.terms = append(q.terms, t)
+	}
+
+	return q, nil
+}
+
+// Match reports whether a task matches every term of the query
+func (q *Query) Match(c Candidate) bool {
+	for _, t := range q.terms {
+		if !t.match(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// Sort orders tasks that are listed newest first by the query's sort order,
+// keeping the newest first among tasks that sort the same
+func (q *Query) Sort(tasks []server.Task) {
+	switch q.sort {
+	case SortDue:
+		slices.SortStableFunc(tasks, func(a, b server.Task) int {
+			aDue, aOK := a.DueTime(q.loc)
+			bDue, bOK := b.DueTime(q.loc)
+			switch {
+			case aOK != bOK:
+				// Tasks without a due date come last
+				if aOK {
+					return -1
+				}
+				return 1
+			case !aOK:
+				return 0
+			}
+			return aDue.Compare(bDue)
+		})
+	case SortPriority:
+		slices.SortStableFunc(tasks, func(a, b server.Task) int {
+			return priorityRank(b.Priority) - priorityRank(a.Priority)
+		})
+	case SortName:
+		slices.SortStableFunc(tasks, func(a, b server.Task) int {
+			return strings.Compare(strings.ToLower(a.TaskName), strings.ToLower(b.TaskName))
+		})
+	}
+}
+
+// token is a term as written, before its value is interpreted
+type token struct {
+	negated bool
+	field   string // empty for words and phrases
+	value   string
+}
+
+func tokenize(input string) ([]token, error) {
+	var tokens []token
+	rest := []rune(input)
+	for {
+		for len(rest) > 0 && unicode.IsSpace(rest[0]) {
+			rest = rest[1:]
+		}
+		if len(rest) == 0 {
+			return tokens, nil
+		}
+
+		var tok token
+		if rest[0] == '-' && len(rest) > 1 && !unicode.IsSpace(rest[1]) {
+			tok.negated = true
+			rest = rest[1:]
+		}
+		if rest[0] != '"' {
+			// A field name runs up to the colon
+			for i, r := range rest {
+				if r == ':' {
+					tok.field = strings.ToLower(string(rest[:i]))
+					rest = rest[i+1:]
+					break
+				}
+				if unicode.IsSpace(r) || r == '"' {
+					break
+				}
+			}
+		}
+
+		var value string
+		if len(rest) > 0 && rest[0] == '"' {
+			end := slices.Index(rest[1:], '"')
+			if end < 0 {
+				return nil, invalid("missing closing quote in %q", string(rest))
+			}
+			value, rest = string(rest[1:end+1]), rest[end+2:]
+		} else {
+			end := slices.IndexFunc(rest, unicode.IsSpace)
+			if end < 0 {
+				end = len(rest)
+			}
+			value, rest = string(rest[:end]), rest[end:]
+		}
+		if tok.field != "" && tok.field != "list" && tok.field != "tag" {
+			value = strings.ToLower(value)
+		}
+		if value == "" {
+			if tok.field != "" {
+				return nil, invalid("%s has no value", tok.field)
+			}
+			continue
+		}
+		tok.value = value
+		tokens = append(tokens, tok)
+	}
+}
+
+func parseTerm(tok token, now time.Time) (term, error) {
+	switch tok.field {
+	case "":
+		return textTerm{strings.ToLower(tok.value)}, nil
+	case "completed", "starred":
+		value, err := strconv.ParseBool(tok.value)
+		if err != nil {
+			return nil, invalid("%s must be true or false, got %q", tok.field, tok.value)
+		}
+		return boolTerm{column: tok.field, value: value}, nil
+	case "priority":
+		return parsePriority(tok.value)
+	case "due":
+		return parseDue(tok.value, now)
+	case "tag":
+		return tagTerm{tok.value}, nil
+	case "list":
+		return listTerm{tok.value}, nil
+	}
+	return nil, invalid("unknown field %q; use completed, starred, priority, due, tag, list or sort", tok.field)
+}
+
+// splitOperator splits a comparison operator off the front of a value
+func splitOperator(value string) (op, rest string) {
+	for _, op := range []string{"<=", ">=", "<", ">", "="} {
+		if strings.HasPrefix(value, op) {
+			return op, value[len(op):]
+		}
+	}
+	return "=", value
+}
+
+var priorities = []server.Priority{server.PriorityNone, server.PriorityLow, server.PriorityMedium, server.PriorityHigh}
+
+func priorityRank(p server.Priority) int {
+	return slices.Index(priorities, p)
+}
+
+func parsePriority(value string) (term, error) {
+	op, level := splitOperator(value)
+	rank := priorityRank(server.Priority(level))
+	if rank < 0 {
+		return nil, invalid("priority must be none, low, medium or high, got %q", level)
+	}
+
+	var levels []server.Priority
+	for i, p := range priorities {
+		if op == "=" && i == rank || op == "<" && i < rank || op == "<=" && i <= rank ||
+			op == ">" && i > rank || op == ">=" && i >= rank {
+			levels = append(levels, p)
+		}
+	}
+	return priorityTerm{levels}, nil
+}
+
+func parseDue(value string, now time.Time) (term, error) {
+	switch value {
+	case "none":
+		return dueTerm{none: true}, nil
+	case "any":
+		return notTerm{dueTerm{none: true}}, nil
+	case "overdue":
+		today := server.DateOf(now)
+		before := addDays(today, -1)
+		return dueTerm{before: &now, lastDate: &before}, nil
+	}
+
+	op, day := splitOperator(value)
+	date, err := parseDay(day, now)
+	if err != nil {
+		return nil, err
+	}
+	start, err := time.ParseInLocation(server.DateLayout, date, now.Location())
+	if err != nil {
+		return nil, invalid("invalid due date %q", day)
+	}
+	end := start.AddDate(0, 0, 1)
+	previous, next := addDays(date, -1), addDays(date, 1)
+
+	// Timed tasks are compared by moment and whole-day tasks by date
+	switch op {
+	case "<":
+		return dueTerm{before: &start, lastDate: &previous}, nil
+	case "<=":
+		return dueTerm{before: &end, lastDate: &date}, nil
+	case ">":
+		return dueTerm{from: &end, firstDate: &next}, nil
+	case ">=":
+		return dueTerm{from: &start, firstDate: &date}, nil
+	}
+	return dueTerm{from: &start, before: &end, firstDate: &date, lastDate: &date}, nil
+}
+
+// parseDay resolves a date given as YYYY-MM-DD, today, tomorrow, yesterday or
+// a number of days or weeks from today such as 7d, -1w or +2d
+func parseDay(value string, now time.Time) (string, error) {
+	today := server.DateOf(now)
+	switch value {
+	case "today":
+		return today, nil
+	case "tomorrow":
+		return addDays(today, 1), nil
+	case "yesterday":
+		return addDays(today, -1), nil
+	}
+	if _, err := time.Parse(server.DateLayout, value); err == nil {
+		return value, nil
+	}
+
+	if len(value) > 1 {
+		unit := 0
+		switch value[len(value)-1] {
+		case 'd':
+			unit = 1
+		case 'w':
+			unit = 7
+		}
+		if n, err := strconv.Atoi(value[:len(value)-1]); err == nil && unit > 0 {
+			return addDays(today, n*unit), nil
+		}
+	}
+	return "", invalid("invalid due date %q; use YYYY-MM-DD, today, tomorrow, yesterday or a number of days or weeks such as 7d or -1w", value)
+}
+
+// addDays moves a YYYY-MM-DD date by n calendar days
+func addDays(date string, n int) string {
+	day, _ := time.Parse(server.DateLayout, date)
+	return day.AddDate(0, 0, n).Format(server.DateLayout)
+}
+
+// SyntaxError reports a query that does not parse; it matches server.ErrInvalidInput
+type SyntaxError struct {
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return "invalid query: " + e.Message
+}
+
+func (e *SyntaxError) Is(target error) bool {
+	return target == server.ErrInvalidInput
+}
+
+func invalid(format string, args ...any) error {
+	return &SyntaxError{Message: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,145 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+var now = time.Date(2024, 5, 10, 15, 0, 0, 0, time.UTC)
+
+func dueOn(date string) *string { return &date }
+
+func TestMatch(t *testing.T) {
+	report := Candidate{
+		Task:      server.Task{TaskName: "Write Report", Priority: server.PriorityHigh, DueDate: dueOn("2024-05-12")},
+		ListTitle: "Work",
+		TagNames:  []string{"Job"},
+	}
+	milk := Candidate{
+		Task:      server.Task{TaskName: "Buy milk", Completed: true, Starred: true, Priority: server.PriorityNone},
+		ListTitle: "Épicerie",
+	}
+	tests := []struct {
+		query string
+		want  []bool // whether report and milk match
+	}{
+		{"", []bool{true, true}},
+		{"report", []bool{true, false}},
+		{"REPORT -milk", []bool{true, false}},
+		{`"buy milk"`, []bool{false, true}},
+		{"completed:true", []bool{false, true}},
+		{"starred:false", []bool{true, false}},
+		{"priority:>=medium", []bool{true, false}},
+		{"priority:<low", []bool{false, true}},
+		{"due:none", []bool{false, true}},
+		{"due:<7d", []bool{true, false}},
+		{"due:2024-05-12", []bool{true, false}},
+		{"due:>tomorrow", []bool{true, false}},
+		{"due:overdue", []bool{false, false}},
+		{"tag:job", []bool{true, false}},
+		{"-tag:job", []bool{false, true}},
+		{"list:ÉPICERIE", []bool{false, true}},
+	}
+	for _, tt := range tests {
+		q, err := Parse(tt.query, now)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.query, err)
+			continue
+		}
+		for i, c := range []Candidate{report, milk} {
+			if got := q.Match(c); got != tt.want[i] {
+				t.Errorf("Parse(%q).Match(%q) = %v, want %v", tt.query, c.Task.TaskName, got, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, input := range []string{
+		"due:someday",
+		"color:red",
+		`list:"Inbox`,
+		"sort:due sort:name",
+		"-sort:due",
+		"sort:size",
+		"completed:maybe",
+		"priority:urgent",
+	} {
+		_, err := Parse(input, now)
+		var syntax *SyntaxError
+		if !errors.As(err, &syntax) || !errors.Is(err, server.ErrInvalidInput) {
+			t.Errorf("Parse(%q) error = %v, want a SyntaxError", input, err)
+		}
+	}
+}
+
+func TestSort(t *testing.T) {
+	tasks := func() []server.Task {
+		return []server.Task{
+			{ID: "c", TaskName: "cherry", Priority: server.PriorityLow},
+			{ID: "b", TaskName: "Banana", Priority: server.PriorityHigh, DueDate: dueOn("2024-06-01")},
+			{ID: "a", TaskName: "apple", Priority: server.PriorityNone, DueDate: dueOn("2024-05-01")},
+		}
+	}
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", "c,b,a"},
+		{"sort:name", "a,b,c"},
+		{"sort:priority", "b,c,a"},
+		{"sort:due", "a,b,c"},
+	}
+	for _, tt := range tests {
+		q, err := Parse(tt.query, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sorted := tasks()
+		q.Sort(sorted)
+		ids := make([]string, len(sorted))
+		for i, task := range sorted {
+			ids[i] = task.ID
+		}
+		if got := strings.Join(ids, ","); got != tt.want {
+			t.Errorf("Sort with %q = %s, want %s", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSQL(t *testing.T) {
+	dialect := Dialect{
+		Param: func(n int) string { return fmt.Sprintf("$%d", n) },
+		Date:  func(param string) string { return param + "::date" },
+		Lower: func(expr string) string { return "fold(" + expr + ")" },
+	}
+
+	q, err := Parse("", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if where, args := q.SQL(dialect); where != "TRUE" || len(args) != 0 {
+		t.Errorf("SQL() of an empty query = %q, %v", where, args)
+	}
+
+	// Values are folded in Go and columns with the dialect's Lower
+	q, err = Parse(`ÉCOLE tag:Job list:"Work" -starred:true`, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	where, args := q.SQL(dialect)
+	for _, want := range []string{"fold(tasks.task_name) LIKE $1", "fold(tags.name) = $2", "fold(title) = $3", "NOT COALESCE(tasks.starred = $4, FALSE)"} {
+		if !strings.Contains(where, want) {
+			t.Errorf("SQL() = %q, want it to contain %q", where, want)
+		}
+	}
+	want := []any{"%école%", "job", "work", true}
+	if fmt.Sprint(args) != fmt.Sprint(want) {
+		t.Errorf("SQL() args = %v, want %v", args, want)
+	}
+}
@@ -0,0 +1,49 @@
+package query
+
+import "strings"
+
+// Dialect adapts the generated SQL to a database
+type Dialect struct {
+	// Param returns the placeholder of the nth argument, counting from 1
+	Param func(n int) string
+	// Date converts the placeholder of a YYYY-MM-DD argument into a value
+	// that compares with the due_date column
+	Date func(param string) string
+	// Lower folds the case of a text expression the way strings.ToLower does,
+	// which is how Match compares names
+	Lower func(expr string) string
+}
+
+// SQL returns the query as a condition over the tasks table together with its
+// arguments, which are numbered from 1. Every value is passed as an argument.
+func (q *Query) SQL(d Dialect) (where string, args []any) {
+	if len(q.terms) == 0 {
+		return "TRUE", nil
+	}
+	b := &builder{dialect: d}
+	conditions := make([]string, len(q.terms))
+	for i, t := range q.terms {
+		conditions[i] = t.sql(b)
+	}
+	return strings.Join(conditions, " AND "), b.args
+}
+
+// builder collects the arguments of a condition
+type builder struct {
+	dialect Dialect
+	args    []any
+}
+
+func (b *builder) arg(value any) string {
+	b.args = append(b.args, value)
+	return b.dialect.Param(len(b.args))
+}
+
+// lower folds the case of a column; values are folded before they are added
+func (b *builder) lower(expr string) string {
+	return b.dialect.Lower(expr)
+}
+
+func (b *builder) date(value string) string {
+	return b.dialect.Date(b.arg(value))
+}
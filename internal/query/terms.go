@@ -0,0 +1,138 @@
+package query
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+// term is one condition of a query
+type term interface {
+	match(c Candidate) bool
+	// sql returns the condition over the tasks table, adding its arguments to b
+	sql(b *builder) string
+}
+
+type notTerm struct{ term term }
+
+func (t notTerm) match(c Candidate) bool { return !t.term.match(c) }
+
+func (t notTerm) sql(b *builder) string {
+	// A condition involving NULL is unknown rather than false
+	return "NOT COALESCE(" + t.term.sql(b) + ", FALSE)"
+}
+
+// textTerm matches tasks whose name contains text, which is in lower case
+type textTerm struct{ text string }
+
+func (t textTerm) match(c Candidate) bool {
+	return strings.Contains(strings.ToLower(c.Task.TaskName), t.text)
+}
+
+func (t textTerm) sql(b *builder) string {
+	pattern := "%" + strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(t.text) + "%"
+	return b.lower("tasks.task_name") + " LIKE " + b.arg(pattern) + ` ESCAPE '\'`
+}
+
+// boolTerm matches the completed or starred flag
+type boolTerm struct {
+	column string
+	value  bool
+}
+
+func (t boolTerm) match(c Candidate) bool {
+	if t.column == "completed" {
+		return c.Task.Completed == t.value
+	}
+	return c.Task.Starred == t.value
+}
+
+func (t boolTerm) sql(b *builder) string {
+	return "tasks." + t.column + " = " + b.arg(t.value)
+}
+
+// priorityTerm matches tasks with any of the priority levels
+type priorityTerm struct{ levels []server.Priority }
+
+func (t priorityTerm) match(c Candidate) bool {
+	return slices.Contains(t.levels, c.Task.Priority)
+}
+
+func (t priorityTerm) sql(b *builder) string {
+	if len(t.levels) == 0 {
+		return "FALSE"
+	}
+	params := make([]string, len(t.levels))
+	for i, level := range t.levels {
+		params[i] = b.arg(string(level))
+	}
+	return "tasks.priority IN (" + strings.Join(params, ", ") + ")"
+}
+
+// dueTerm matches tasks without a due date when none is set. Otherwise it
+// matches timed tasks due in [from, before) and whole-day tasks due on a date
+// in [firstDate, lastDate]; nil bounds are open.
+type dueTerm struct {
+	none                bool
+	from, before        *time.Time
+	firstDate, lastDate *string
+}
+
+func (t dueTerm) match(c Candidate) bool {
+	task := c.Task
+	switch {
+	case t.none:
+		return task.DueAt == nil && task.DueDate == nil
+	case task.DueAt != nil:
+		return (t.from == nil || !task.DueAt.Before(*t.from)) && (t.before == nil || task.DueAt.Before(*t.before))
+	case task.DueDate != nil:
+		return (t.firstDate == nil || *task.DueDate >= *t.firstDate) && (t.lastDate == nil || *task.DueDate <= *t.lastDate)
+	}
+	return false
+}
+
+func (t dueTerm) sql(b *builder) string {
+	if t.none {
+		return "(tasks.due_at IS NULL AND tasks.due_date IS NULL)"
+	}
+	timed := []string{"tasks.due_at IS NOT NULL"}
+	if t.from != nil {
+		timed = append(timed, "tasks.due_at >= "+b.arg(t.from.UTC()))
+	}
+	if t.before != nil {
+		timed = append(timed, "tasks.due_at < "+b.arg(t.before.UTC()))
+	}
+	dated := []string{"tasks.due_date IS NOT NULL"}
+	if t.firstDate != nil {
+		dated = append(dated, "tasks.due_date >= "+b.date(*t.firstDate))
+	}
+	if t.lastDate != nil {
+		dated = append(dated, "tasks.due_date <= "+b.date(*t.lastDate))
+	}
+	return "((" + strings.Join(timed, " AND ") + ") OR (" + strings.Join(dated, " AND ") + "))"
+}
+
+// tagTerm matches tasks with a tag of the given name
+type tagTerm struct{ name string }
+
+func (t tagTerm) match(c Candidate) bool {
+	return slices.ContainsFunc(c.TagNames, func(name string) bool { return strings.ToLower(name) == strings.ToLower(t.name) })
+}
+
+func (t tagTerm) sql(b *builder) string {
+	return `EXISTS (SELECT 1 FROM task_tags JOIN tags ON tags.id = task_tags.tag_id
+		WHERE task_tags.task_id = tasks.id AND ` + b.lower("tags.name") + ` = ` + b.arg(strings.ToLower(t.name)) + `)`
+}
+
+// listTerm matches tasks in a list with the given title
+type listTerm struct{ title string }
+
+func (t listTerm) match(c Candidate) bool {
+	return strings.ToLower(c.ListTitle) == strings.ToLower(t.title)
+}
+
+func (t listTerm) sql(b *builder) string {
+	return "tasks.list_id IN (SELECT id FROM lists WHERE " + b.lower("title") + " = " + b.arg(strings.ToLower(t.title)) + ")"
+}
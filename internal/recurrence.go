@@ -0,0 +1,310 @@
+package server
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is the FREQ part of a recurrence rule
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// UNTIL may be given as a date or as a UTC date-time
+const (
+	untilDateLayout     = "20060102"
+	untilDateTimeLayout = "20060102T150405Z"
+)
+
+var weekdayCodes = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// Recurrence is the subset of an RFC 5545 RRULE supported for tasks:
+// FREQ (DAILY, WEEKLY, MONTHLY, YEARLY), INTERVAL, BYDAY (DAILY and WEEKLY only)
+// and either UNTIL or COUNT. Weeks start on Monday.
+type Recurrence struct {
+	Freq     Frequency
+	Interval int
+	ByDay    []time.Weekday
+	// Until is the last moment an occurrence may fall on. When UntilDate is set
+	// the rule was given a date and only the calendar day is compared.
+	Until     time.Time
+	UntilDate bool
+	// Count is the number of occurrences left, including the current one; 0 means unlimited
+	Count int
+}
+
+// ParseRecurrence parses a rule such as "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH;COUNT=10".
+// An optional "RRULE:" prefix is accepted.
+func ParseRecurrence(rule string) (*Recurrence, error) {
+	rule = strings.TrimSpace(rule)
+	if len(rule) >= len("RRULE:") && strings.EqualFold(rule[:len("RRULE:")], "RRULE:") {
+		rule = rule[len("RRULE:"):]
+	}
+	if rule == "" {
+		return nil, InvalidInput("invalid recurrence rule: empty rule")
+	}
+
+	r := &Recurrence{Interval: 1}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(rule, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		name = strings.ToUpper(strings.TrimSpace(name))
+		value = strings.ToUpper(strings.TrimSpace(value))
+		if !ok || value == "" {
+			return nil, InvalidInput("invalid recurrence rule part %q", part)
+		}
+		if seen[name] {
+			return nil, InvalidInput("invalid recurrence rule: %s given more than once", name)
+		}
+		seen[name] = true
+
+		switch name {
+		case "FREQ":
+			switch freq := Frequency(value); freq {
+			case Daily, Weekly, Monthly, Yearly:
+				r.Freq = freq
+			default:
+				return nil, InvalidInput("unsupported recurrence frequency %q", value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, InvalidInput("invalid recurrence interval %q", value)
+			}
+			r.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, InvalidInput("invalid recurrence count %q", value)
+			}
+			r.Count = n
+		case "UNTIL":
+			if t, err := time.Parse(untilDateLayout, value); err == nil {
+				r.Until, r.UntilDate = t, true
+			} else if t, err := time.Parse(untilDateTimeLayout, value); err == nil {
+				r.Until = t
+			} else {
+				return nil, InvalidInput("invalid recurrence until %q", value)
+			}
+		case "BYDAY":
+			days := make(map[time.Weekday]bool)
+			for _, code := range strings.Split(value, ",") {
+				day, ok := weekdayCodes[strings.TrimSpace(code)]
+				if !ok {
+					return nil, InvalidInput("invalid recurrence weekday %q", code)
+				}
+				days[day] = true
+			}
+			// Keep the days in week order, Monday first
+			for _, day := range []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday} {
+				if days[day] {
+					r.ByDay = append(r.ByDay, day)
+				}
+			}
+		default:
+			return nil, InvalidInput("unsupported recurrence rule part %q", name)
+		}
+	}
+
+	if r.Freq == "" {
+		return nil, InvalidInput("invalid recurrence rule: FREQ is required")
+	}
+	if seen["COUNT"] && seen["UNTIL"] {
+		return nil, InvalidInput("invalid recurrence rule: COUNT and UNTIL cannot be combined")
+	}
+	if len(r.ByDay) > 0 && r.Freq != Daily && r.Freq != Weekly {
+		return nil, InvalidInput("invalid recurrence rule: BYDAY is only supported with FREQ=DAILY or FREQ=WEEKLY")
+	}
+
+	return r, nil
+}
+
+// String formats the rule in its canonical form, without the "RRULE:" prefix
+func (r Recurrence) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			codes[i] = strings.ToUpper(day.String()[:2])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if !r.Until.IsZero() {
+		if r.UntilDate {
+			parts = append(parts, "UNTIL="+r.Until.Format(untilDateLayout))
+		} else {
+			parts = append(parts, "UNTIL="+r.Until.UTC().Format(untilDateTimeLayout))
+		}
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Next returns the first occurrence after prev, keeping prev's wall-clock time
+// in its location. ok is false once the rule has no further occurrences.
+func (r Recurrence) Next(prev time.Time) (next time.Time, ok bool) {
+	if r.Count == 1 {
+		return time.Time{}, false
+	}
+
+	switch r.Freq {
+	case Daily:
+		// Seven steps visit every weekday the interval can reach
+		found := false
+		for k := 1; k <= 7 && !found; k++ {
+			next = prev.AddDate(0, 0, k*r.Interval)
+			found = r.onDay(next)
+		}
+		if !found {
+			return time.Time{}, false
+		}
+	case Weekly:
+		if len(r.ByDay) == 0 {
+			next = prev.AddDate(0, 0, 7*r.Interval)
+			break
+		}
+		// Walk forward day by day, skipping weeks outside the interval
+		start := startOfWeek(prev)
+		for next = prev.AddDate(0, 0, 1); ; next = next.AddDate(0, 0, 1) {
+			weeks := daysBetween(start, startOfWeek(next)) / 7
+			if weeks%r.Interval == 0 && r.onDay(next) {
+				break
+			}
+		}
+	case Monthly:
+		// Months without prev's day of month are skipped, as RFC 5545 requires
+		for k := 1; ; k++ {
+			next = time.Date(prev.Year(), prev.Month()+time.Month(k*r.Interval), 1, prev.Hour(), prev.Minute(), prev.Second(), prev.Nanosecond(), prev.Location())
+			if prev.Day() <= daysIn(next.Year(), next.Month()) {
+				next = next.AddDate(0, 0, prev.Day()-1)
+				break
+			}
+		}
+	case Yearly:
+		// February 29 only recurs in leap years
+		for k := 1; ; k++ {
+			year := prev.Year() + k*r.Interval
+			if prev.Day() <= daysIn(year, prev.Month()) {
+				next = time.Date(year, prev.Month(), prev.Day(), prev.Hour(), prev.Minute(), prev.Second(), prev.Nanosecond(), prev.Location())
+				break
+			}
+		}
+	default:
+		return time.Time{}, false
+	}
+
+	if !r.Until.IsZero() {
+		if r.UntilDate {
+			if DateOf(next) > DateOf(r.Until) {
+				return time.Time{}, false
+			}
+		} else if next.After(r.Until) {
+			return time.Time{}, false
+		}
+	}
+
+	return next, true
+}
+
+func (r Recurrence) onDay(t time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, day := range r.ByDay {
+		if t.Weekday() == day {
+			return true
+		}
+	}
+	return false
+}
+
+// startOfWeek returns midnight on the Monday of t's week
+func startOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+}
+
+// daysBetween counts calendar days from a to b, ignoring DST changes
+func daysBetween(a, b time.Time) int {
+	ua := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	ub := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(ub.Sub(ua).Hours() / 24)
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// NextOccurrence builds the task that follows a completed recurring task.
+// The next due date is computed from the task's due date in loc, or from now
+// when it has none; the reminder keeps its offset from the due date. It returns
+// nil when the task does not recur or its rule is exhausted. Notes, priority and
+// the starred flag carry over. The result has no id, position or timestamps.
+func NextOccurrence(task Task, now time.Time, loc *time.Location) (*Task, error) {
+	if task.Recurrence == nil {
+		return nil, nil
+	}
+	rule, err := ParseRecurrence(*task.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+
+	prev, ok := task.DueTime(loc)
+	if !ok {
+		n := now.In(loc)
+		prev = time.Date(n.Year(), n.Month(), n.Day(), 0, 0, 0, 0, loc)
+	}
+	if task.DueAt != nil {
+		prev = prev.In(loc)
+	}
+
+	due, ok := rule.Next(prev)
+	if !ok {
+		return nil, nil
+	}
+	if rule.Count > 0 {
+		rule.Count--
+	}
+	recurrence := rule.String()
+
+	next := &Task{
+		ListID:     task.ListID,
+		TaskName:   task.TaskName,
+		Notes:      task.Notes,
+		Priority:   task.Priority,
+		Starred:    task.Starred,
+		Recurrence: &recurrence,
+	}
+	if task.DueAt != nil {
+		dueAt := due.UTC()
+		next.DueAt = &dueAt
+	} else {
+		date := DateOf(due)
+		next.DueDate = &date
+	}
+	if task.RemindAt != nil {
+		remindAt := task.RemindAt.Add(due.Sub(prev)).UTC()
+		next.RemindAt = &remindAt
+	}
+
+	return next, nil
+}
@@ -0,0 +1,145 @@
+package server
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	tests := []struct {
+		rule string
+		want string
+	}{
+		{"FREQ=DAILY", "FREQ=DAILY"},
+		{"RRULE:freq=weekly;byday=th,mo;interval=2", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH"},
+		{"FREQ=MONTHLY;INTERVAL=1;COUNT=3", "FREQ=MONTHLY;COUNT=3"},
+		{"FREQ=YEARLY;UNTIL=20301231", "FREQ=YEARLY;UNTIL=20301231"},
+		{"FREQ=DAILY;UNTIL=20300101T120000Z", "FREQ=DAILY;UNTIL=20300101T120000Z"},
+	}
+	for _, tt := range tests {
+		r, err := ParseRecurrence(tt.rule)
+		if err != nil {
+			t.Errorf("ParseRecurrence(%q) failed: %v", tt.rule, err)
+			continue
+		}
+		if got := r.String(); got != tt.want {
+			t.Errorf("ParseRecurrence(%q) = %q, want %q", tt.rule, got, tt.want)
+		}
+	}
+}
+
+func TestParseRecurrenceErrors(t *testing.T) {
+	for _, rule := range []string{
+		"",
+		"RRULE:",
+		"INTERVAL=2",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;COUNT=x",
+		"FREQ=DAILY;FREQ=WEEKLY",
+		"FREQ=DAILY;COUNT=2;UNTIL=20300101",
+		"FREQ=MONTHLY;BYDAY=MO",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=DAILY;BYMONTH=1",
+		"FREQ",
+	} {
+		if _, err := ParseRecurrence(rule); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("ParseRecurrence(%q) = %v, want an invalid input error", rule, err)
+		}
+	}
+}
+
+func TestRecurrenceNext(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 9, 30, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		rule string
+		prev time.Time
+		want []time.Time
+		// last is set when the rule ends after want
+		last bool
+	}{
+		{"FREQ=DAILY;INTERVAL=2", date(2024, 1, 30), []time.Time{date(2024, 2, 1), date(2024, 2, 3)}, false},
+		// Thursday, then the next Monday and Thursday
+		{"FREQ=DAILY;BYDAY=MO,TH", date(2024, 5, 2), []time.Time{date(2024, 5, 6), date(2024, 5, 9)}, false},
+		{"FREQ=WEEKLY", date(2024, 5, 1), []time.Time{date(2024, 5, 8)}, false},
+		// Every other week on Monday and Friday, from a Monday
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR", date(2024, 5, 6), []time.Time{date(2024, 5, 10), date(2024, 5, 20)}, false},
+		// Months without a 31st are skipped
+		{"FREQ=MONTHLY", date(2024, 1, 31), []time.Time{date(2024, 3, 31), date(2024, 5, 31)}, false},
+		{"FREQ=YEARLY", date(2024, 2, 29), []time.Time{date(2028, 2, 29)}, false},
+		{"FREQ=DAILY;UNTIL=20240102", date(2024, 1, 1), []time.Time{date(2024, 1, 2)}, true},
+		{"FREQ=DAILY;COUNT=2", date(2024, 1, 1), []time.Time{date(2024, 1, 2)}, true},
+	}
+	for _, tt := range tests {
+		r, err := ParseRecurrence(tt.rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		prev := tt.prev
+		for _, want := range tt.want {
+			next, ok := r.Next(prev)
+			if !ok || !next.Equal(want) {
+				t.Errorf("%s: Next(%s) = %s, %v; want %s", tt.rule, prev, next, ok, want)
+				break
+			}
+			if r.Count > 0 {
+				r.Count--
+			}
+			prev = next
+		}
+		if tt.last {
+			if next, ok := r.Next(prev); ok {
+				t.Errorf("%s: Next(%s) = %s, want no further occurrence", tt.rule, prev, next)
+			}
+		}
+	}
+}
+
+func TestNextOccurrence(t *testing.T) {
+	loc := time.FixedZone("UTC+5", 5*60*60)
+	rule := "FREQ=WEEKLY;COUNT=3"
+	dueDate := "2024-05-01"
+	remindAt := time.Date(2024, 4, 30, 20, 0, 0, 0, time.UTC)
+	task := Task{
+		ListID:     "list",
+		TaskName:   "Water plants",
+		Notes:      "Both rooms",
+		Priority:   PriorityHigh,
+		Starred:    true,
+		DueDate:    &dueDate,
+		RemindAt:   &remindAt,
+		Recurrence: &rule,
+	}
+	next, err := NextOccurrence(task, time.Now(), loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next == nil {
+		t.Fatal("NextOccurrence() = nil, want the next task")
+	}
+	if next.DueDate == nil || *next.DueDate != "2024-05-08" {
+		t.Errorf("DueDate = %v, want 2024-05-08", next.DueDate)
+	}
+	if want := remindAt.AddDate(0, 0, 7); next.RemindAt == nil || !next.RemindAt.Equal(want) {
+		t.Errorf("RemindAt = %v, want %s", next.RemindAt, want)
+	}
+	if next.Recurrence == nil || *next.Recurrence != "FREQ=WEEKLY;COUNT=2" {
+		t.Errorf("Recurrence = %v, want FREQ=WEEKLY;COUNT=2", next.Recurrence)
+	}
+	if next.TaskName != task.TaskName || next.Notes != task.Notes || next.Priority != task.Priority || !next.Starred {
+		t.Errorf("NextOccurrence() = %+v, want the name and details carried over", next)
+	}
+
+	last := "FREQ=WEEKLY;COUNT=1"
+	task.Recurrence = &last
+	if next, err := NextOccurrence(task, time.Now(), loc); err != nil || next != nil {
+		t.Errorf("NextOccurrence() of the last occurrence = %+v, %v; want nil", next, err)
+	}
+	task.Recurrence = nil
+	if next, err := NextOccurrence(task, time.Now(), loc); err != nil || next != nil {
+		t.Errorf("NextOccurrence() of a task that does not recur = %+v, %v; want nil", next, err)
+	}
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A search query is split into terms, which are runs of letters and digits.
+// A name matches when every term is the prefix of one of its words, regardless
+// of case. The stores use their indexes to find candidate rows and MatchName
+// to rank them, so every backend ranks and highlights hits the same way.
+
+// SearchTerms splits a search query into lower-case terms
+func SearchTerms(query string) []string {
+	return strings.FieldsFunc(strings.ToLower(query), notWordRune)
+}
+
+func notWordRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+}
+
+// MatchName reports whether name matches all terms. The rank is the share of
+// the name's words matched by a term, where a word matched only by a prefix
+// counts for the part of it the prefix covers. The snippet is the name as HTML
+// with the matched words wrapped in <mark>.
+func MatchName(name string, terms []string) (rank float64, snippet string, ok bool) {
+	if len(terms) == 0 {
+		return 0, "", false
+	}
+
+	matchedTerms := make([]bool, len(terms))
+	var words int
+	var score float64
+	var b strings.Builder
+	rest := name
+	for rest != "" {
+		// Copy the separators up to the next word
+		start := strings.IndexFunc(rest, func(r rune) bool { return !notWordRune(r) })
+		if start < 0 {
+			b.WriteString(html.EscapeString(rest))
+			break
+		}
+		b.WriteString(html.EscapeString(rest[:start]))
+		rest = rest[start:]
+		end := strings.IndexFunc(rest, notWordRune)
+		if end < 0 {
+			end = len(rest)
+		}
+		word := rest[:end]
+		rest = rest[end:]
+		words++
+
+		lower := strings.ToLower(word)
+		best := 0.0
+		for i, term := range terms {
+			if strings.HasPrefix(lower, term) {
+				matchedTerms[i] = true
+				best = max(best, float64(utf8.RuneCountInString(term))/float64(utf8.RuneCountInString(lower)))
+			}
+		}
+		if best == 0 {
+			b.WriteString(html.EscapeString(word))
+			continue
+		}
+		score += best
+		b.WriteString("<mark>" + html.EscapeString(word) + "</mark>")
+	}
+
+	for _, matched := range matchedTerms {
+		if !matched {
+			return 0, "", false
+		}
+	}
+	return score / float64(words), b.String(), true
+}
+
+// sortSearchHits orders hits by rank, highest first, then by name and id
+func sortSearchHits(hits []SearchHit) {
+	sort.SliceStable(hits, func(i, j int) bool {
+		a, b := hits[i], hits[j]
+		if a.Rank != b.Rank {
+			return a.Rank > b.Rank
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.ID < b.ID
+	})
+}
+
+// RankSearchHits fills in the rank and snippet of candidate hits, drops the
+// ones that do not match all terms and sorts the rest
+func RankSearchHits(candidates []SearchHit, terms []string) []SearchHit {
+	var hits []SearchHit
+	for _, hit := range candidates {
+		rank, snippet, ok := MatchName(hit.Name, terms)
+		if !ok {
+			continue
+		}
+		hit.Rank, hit.Snippet = rank, snippet
+		hits = append(hits, hit)
+	}
+	sortSearchHits(hits)
+	return hits
+}
@@ -0,0 +1,130 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+// Activity rows are written by the triggers in the 0008_activity migration, and
+// their actor by the temporary trigger created in SetActor
+
+const activityColumns = `id, entity, entity_id, list_id, task_id, action, name, COALESCE(actor, ''), before, after, created_at`
+
+func scanActivity(row scanner) (*server.Activity, error) {
+	var activity server.Activity
+	var before, after *string
+	err := row.Scan(
+		&activity.ID,
+		&activity.Entity,
+		&activity.EntityID,
+		&activity.ListID,
+		&activity.TaskID,
+		&activity.Action,
+		&activity.Name,
+		&activity.Actor,
+		&before,
+		&after,
+		&activity.At,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if activity.Before, err = decodeFields(before); err != nil {
+		return nil, err
+	}
+	if activity.After, err = decodeFields(after); err != nil {
+		return nil, err
+	}
+	return &activity, nil
+}
+
+// decodeFields decodes a JSON object of changed fields, which is NULL for the
+// before side of a create
+func decodeFields(data *string) (map[string]any, error) {
+	if data == nil {
+		return nil, nil
+	}
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(*data), &fields); err != nil {
+		return nil, fmt.Errorf("invalid activity fields: %w", err)
+	}
+	return fields, nil
+}
+
+func (d *DB) queryActivity(ctx context.Context, query string, args ...any) ([]server.Activity, error) {
+	rows, err := d.SQL.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get activity: %w", mapError(err))
+	}
+	defer rows.Close()
+
+	var activity []server.Activity
+	for rows.Next() {
+		entry, err := scanActivity(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan activity: %w", mapError(err))
+		}
+		activity = append(activity, *entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get activity: %w", mapError(err))
+	}
+
+	return activity, nil
+}
+
+func (d *DB) GetTaskActivity(ctx context.Context, taskID string) ([]server.Activity, error) {
+	// Subtasks moved to another task are logged under the new one
+	query := `SELECT ` + activityColumns + ` FROM activity
+		WHERE task_id = ?1 OR (entity = 'subtask' AND json_extract(before, '$.task_id') = ?1)
+		ORDER BY id DESC`
+	return d.queryActivity(ctx, query, taskID)
+}
+
+func (d *DB) GetListActivity(ctx context.Context, listID string, since time.Time) ([]server.Activity, error) {
+	// Tasks moved to another list are logged under the new one
+	query := `SELECT ` + activityColumns + ` FROM activity
+		WHERE (list_id = ?1 OR (entity = 'task' AND json_extract(before, '$.list_id') = ?1)) AND created_at >= ?2
+		ORDER BY id DESC`
+	return d.queryActivity(ctx, query, listID, since.UTC())
+}
+
+// SetActor keeps the actor in a temporary table of the connection, which a
+// temporary trigger copies into every activity row written after it. SQLite
+// has no session settings, and triggers in the main schema can't read
+// temporary tables, so this relies on the store using a single connection.
+func (d *DB) SetActor(ctx context.Context, actor string) error {
+	tx, err := d.SQL.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", mapError(err))
+	}
+	defer tx.Rollback()
+
+	statements := []struct {
+		query string
+		args  []any
+	}{
+		{query: `CREATE TEMP TABLE IF NOT EXISTS session (actor TEXT)`},
+		{query: `DELETE FROM session`},
+		{query: `INSERT INTO session (actor) VALUES (NULLIF(?, ''))`, args: []any{actor}},
+		{query: `CREATE TEMP TRIGGER IF NOT EXISTS activity_actor AFTER INSERT ON main.activity
+		WHEN NEW.actor IS NULL
+		BEGIN
+			UPDATE activity SET actor = (SELECT actor FROM session) WHERE id = NEW.id;
+		END`},
+	}
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement.query, statement.args...); err != nil {
+			return fmt.Errorf("failed to set actor: %w", mapError(err))
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", mapError(err))
+	}
+	return nil
+}
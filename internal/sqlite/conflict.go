@@ -0,0 +1,34 @@
+package sqlite
+
+import (
+	"context"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+// The conflict helpers explain a versioned write that matched no row: the row is
+// either gone, which reports not found, or has moved past the expected version.
+
+func (d *DB) listConflict(ctx context.Context, id string, version int64) error {
+	list, err := d.GetList(ctx, id)
+	if err != nil {
+		return err
+	}
+	return &server.ConflictError{Entity: "list", ID: id, Version: version, Current: list}
+}
+
+func (d *DB) taskConflict(ctx context.Context, id string, version int64) error {
+	task, err := d.GetTask(ctx, id)
+	if err != nil {
+		return err
+	}
+	return &server.ConflictError{Entity: "task", ID: id, Version: version, Current: task}
+}
+
+func (d *DB) subTaskConflict(ctx context.Context, id string, version int64) error {
+	subTask, err := d.GetSubTask(ctx, id)
+	if err != nil {
+		return err
+	}
+	return &server.ConflictError{Entity: "subtask", ID: id, Version: version, Current: subTask}
+}
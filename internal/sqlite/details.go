@@ -0,0 +1,61 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+func (d *DB) SetTaskNotes(ctx context.Context, id, notes string) (*server.Task, error) {
+	query := `UPDATE tasks SET notes = ?, version = version + 1, updated_at = ? WHERE id = ? AND deleted_at IS NULL RETURNING ` + taskColumns
+
+	task, err := scanTask(d.SQL.QueryRowContext(ctx, query, notes, now(), id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, server.NotFound("task", id)
+		}
+		return nil, fmt.Errorf("failed to set task notes: %w", mapError(err))
+	}
+
+	return task, nil
+}
+
+func (d *DB) SetTaskPriority(ctx context.Context, id string, priority server.Priority) (*server.Task, error) {
+	if !priority.Valid() {
+		return nil, server.InvalidInput("invalid priority %q", priority)
+	}
+
+	query := `UPDATE tasks SET priority = ?, version = version + 1, updated_at = ? WHERE id = ? AND deleted_at IS NULL RETURNING ` + taskColumns
+
+	task, err := scanTask(d.SQL.QueryRowContext(ctx, query, string(priority), now(), id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, server.NotFound("task", id)
+		}
+		return nil, fmt.Errorf("failed to set task priority: %w", mapError(err))
+	}
+
+	return task, nil
+}
+
+func (d *DB) StarTask(ctx context.Context, id string, starred bool) (*server.Task, error) {
+	query := `UPDATE tasks SET starred = ?, version = version + 1, updated_at = ? WHERE id = ? AND deleted_at IS NULL RETURNING ` + taskColumns
+
+	task, err := scanTask(d.SQL.QueryRowContext(ctx, query, starred, now(), id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, server.NotFound("task", id)
+		}
+		return nil, fmt.Errorf("failed to star task: %w", mapError(err))
+	}
+
+	return task, nil
+}
+
+func (d *DB) GetStarredTasks(ctx context.Context) ([]server.Task, error) {
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE starred AND deleted_at IS NULL ORDER BY created_at DESC, rowid DESC`
+	return d.queryTasks(ctx, query)
+}
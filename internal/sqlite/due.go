@@ -0,0 +1,96 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+// utc converts an optional timestamp to UTC so stored values compare correctly as text
+func utc(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
+
+// SetTaskDue sets a whole-day due date or a due moment, clearing the other
+func (d *DB) SetTaskDue(ctx context.Context, id string, dueDate *string, dueAt *time.Time) (*server.Task, error) {
+	var date any
+	if dueDate != nil {
+		parsed, err := time.Parse(server.DateLayout, *dueDate)
+		if err != nil {
+			return nil, server.InvalidInput("invalid due date %q: %w", *dueDate, err)
+		}
+		date = server.DateOf(parsed)
+		dueAt = nil
+	}
+
+	query := `UPDATE tasks SET due_date = ?, due_at = ?, version = version + 1, updated_at = ? WHERE id = ? AND deleted_at IS NULL RETURNING ` + taskColumns
+
+	task, err := scanTask(d.SQL.QueryRowContext(ctx, query, date, utc(dueAt), now(), id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, server.NotFound("task", id)
+		}
+		return nil, fmt.Errorf("failed to set task due date: %w", mapError(err))
+	}
+
+	return task, nil
+}
+
+func (d *DB) SetTaskReminder(ctx context.Context, id string, remindAt *time.Time) (*server.Task, error) {
+	query := `UPDATE tasks SET remind_at = ?, version = version + 1, updated_at = ? WHERE id = ? AND deleted_at IS NULL RETURNING ` + taskColumns
+
+	task, err := scanTask(d.SQL.QueryRowContext(ctx, query, utc(remindAt), now(), id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, server.NotFound("task", id)
+		}
+		return nil, fmt.Errorf("failed to set task reminder: %w", mapError(err))
+	}
+
+	return task, nil
+}
+
+// GetTasksDueBetween returns tasks due in [from, to), ordered by due time
+func (d *DB) GetTasksDueBetween(ctx context.Context, from, to time.Time) ([]server.Task, error) {
+	first, last := server.DueDateRange(from, to)
+	query := `SELECT ` + taskColumns + ` FROM tasks
+		WHERE deleted_at IS NULL AND ((due_at >= ? AND due_at < ?) OR (due_date >= ? AND due_date <= ?))`
+
+	tasks, err := d.queryTasks(ctx, query, from.UTC(), to.UTC(), first, last)
+	if err != nil {
+		return nil, err
+	}
+	server.SortByDue(tasks, from.Location())
+
+	return tasks, nil
+}
+
+// GetOverdueTasks returns incomplete tasks due before now, ordered by due time
+func (d *DB) GetOverdueTasks(ctx context.Context, now time.Time) ([]server.Task, error) {
+	query := `SELECT ` + taskColumns + ` FROM tasks
+		WHERE deleted_at IS NULL AND completed = FALSE AND (due_at < ? OR due_date < ?)`
+
+	tasks, err := d.queryTasks(ctx, query, now.UTC(), server.DateOf(now))
+	if err != nil {
+		return nil, err
+	}
+	server.SortByDue(tasks, now.Location())
+
+	return tasks, nil
+}
+
+// GetDueReminders returns incomplete tasks whose reminder time has passed
+func (d *DB) GetDueReminders(ctx context.Context, now time.Time) ([]server.Task, error) {
+	query := `SELECT ` + taskColumns + ` FROM tasks
+		WHERE deleted_at IS NULL AND completed = FALSE AND remind_at <= ?
+		ORDER BY remind_at ASC, rowid ASC`
+
+	return d.queryTasks(ctx, query, now.UTC())
+}
@@ -0,0 +1,34 @@
+package sqlite
+
+import (
+	"errors"
+
+	server "github.com/HolySxn/To-Do/internal"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// mapError marks SQLite errors with the server sentinel they correspond to; any
+// other error is returned unchanged. The driver reports extended result codes.
+func mapError(err error) error {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return err
+	}
+
+	switch code := sqliteErr.Code(); code {
+	case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+		return server.Mark(err, server.ErrNotFound)
+	case sqlite3.SQLITE_CONSTRAINT_CHECK, sqlite3.SQLITE_CONSTRAINT_NOTNULL:
+		return server.Mark(err, server.ErrInvalidInput)
+	case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+		return server.Mark(err, server.ErrConflict)
+	default:
+		switch code & 0xff {
+		case sqlite3.SQLITE_BUSY, sqlite3.SQLITE_LOCKED, sqlite3.SQLITE_CANTOPEN, sqlite3.SQLITE_FULL:
+			return server.Mark(err, server.ErrUnavailable)
+		}
+	}
+
+	return err
+}
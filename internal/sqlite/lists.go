@@ -0,0 +1,172 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	server "github.com/HolySxn/To-Do/internal"
+	"github.com/google/uuid"
+)
+
+const listColumns = `id, title, query, position, version, created_at, updated_at, deleted_at`
+
+func scanList(row scanner) (*server.List, error) {
+	var list server.List
+	err := row.Scan(
+		&list.ID,
+		&list.Title,
+		&list.Query,
+		&list.Position,
+		&list.Version,
+		&list.CreatedAt,
+		&list.UpdatedAt,
+		&list.DeletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// CRUD operations for Lists
+func (d *DB) CreateList(ctx context.Context, title string) (*server.List, error) {
+	// Get the next position for the new list
+	var maxPosition int
+	query := `SELECT COALESCE(MAX(position), 0) FROM lists`
+	err := d.SQL.QueryRowContext(ctx, query).Scan(&maxPosition)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get max position: %w", mapError(err))
+	}
+
+	query = `INSERT INTO lists (id, title, position, created_at, updated_at) VALUES (?, ?, ?, ?, ?) RETURNING ` + listColumns
+
+	ts := now()
+	list, err := scanList(d.SQL.QueryRowContext(ctx, query, uuid.NewString(), title, maxPosition+1, ts, ts))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create list: %w", mapError(err))
+	}
+
+	return list, nil
+}
+
+func (d *DB) GetList(ctx context.Context, id string) (*server.List, error) {
+	query := `SELECT ` + listColumns + ` FROM lists WHERE id = ? AND deleted_at IS NULL`
+
+	list, err := scanList(d.SQL.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, server.NotFound("list", id)
+		}
+		return nil, fmt.Errorf("failed to get list: %w", mapError(err))
+	}
+
+	return list, nil
+}
+
+func (d *DB) GetAllLists(ctx context.Context) ([]server.List, error) {
+	query := `SELECT ` + listColumns + ` FROM lists WHERE deleted_at IS NULL ORDER BY position ASC`
+
+	rows, err := d.SQL.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lists: %w", mapError(err))
+	}
+	defer rows.Close()
+
+	var lists []server.List
+	for rows.Next() {
+		list, err := scanList(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan list: %w", mapError(err))
+		}
+		lists = append(lists, *list)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get lists: %w", mapError(err))
+	}
+
+	return lists, nil
+}
+
+func (d *DB) UpdateList(ctx context.Context, id string, title string, version int64) (*server.List, error) {
+	query := `UPDATE lists SET title = ?1, version = version + 1, updated_at = ?2 WHERE id = ?3 AND deleted_at IS NULL AND (?4 = 0 OR version = ?4) RETURNING ` + listColumns
+
+	list, err := scanList(d.SQL.QueryRowContext(ctx, query, title, now(), id, version))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, d.listConflict(ctx, id, version)
+		}
+		return nil, fmt.Errorf("failed to update list: %w", mapError(err))
+	}
+
+	return list, nil
+}
+
+// DeleteList moves a list to the trash together with its tasks and subtasks
+func (d *DB) DeleteList(ctx context.Context, id string, version int64) error {
+	tx, err := d.SQL.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", mapError(err))
+	}
+	defer tx.Rollback()
+
+	deletedAt := now()
+	query := `UPDATE lists SET deleted_at = ?1, version = version + 1 WHERE id = ?2 AND deleted_at IS NULL AND (?3 = 0 OR version = ?3)`
+	result, err := tx.ExecContext(ctx, query, deletedAt, id, version)
+	if err != nil {
+		return fmt.Errorf("failed to delete list: %w", mapError(err))
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		// Release the only connection before looking the list up again
+		tx.Rollback()
+		return d.listConflict(ctx, id, version)
+	}
+
+	query = `UPDATE subtasks SET deleted_at = ?1, version = version + 1
+		WHERE deleted_at IS NULL AND task_id IN (SELECT id FROM tasks WHERE list_id = ?2 AND deleted_at IS NULL)`
+	if _, err := tx.ExecContext(ctx, query, deletedAt, id); err != nil {
+		return fmt.Errorf("failed to delete subtasks: %w", mapError(err))
+	}
+	query = `UPDATE tasks SET deleted_at = ?1, version = version + 1 WHERE list_id = ?2 AND deleted_at IS NULL`
+	if _, err := tx.ExecContext(ctx, query, deletedAt, id); err != nil {
+		return fmt.Errorf("failed to delete tasks: %w", mapError(err))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", mapError(err))
+	}
+
+	return nil
+}
+
+// ReorderLists updates the positions of lists based on the provided order
+func (d *DB) ReorderLists(ctx context.Context, listIDs []string) error {
+	if len(listIDs) == 0 {
+		return nil
+	}
+
+	// Start a transaction to ensure atomicity
+	tx, err := d.SQL.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", mapError(err))
+	}
+	defer tx.Rollback()
+
+	// Update positions for each list
+	ts := now()
+	for i, listID := range listIDs {
+		query := `UPDATE lists SET position = ?, version = version + 1, updated_at = ? WHERE id = ? AND deleted_at IS NULL`
+		_, err := tx.ExecContext(ctx, query, i+1, ts, listID)
+		if err != nil {
+			return fmt.Errorf("failed to update list position: %w", mapError(err))
+		}
+	}
+
+	// Commit the transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", mapError(err))
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+
+	"github.com/HolySxn/To-Do/internal/migrate"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migrator returns a migrator for the embedded SQLite migrations
+func (d *DB) Migrator() (*migrate.Migrator, error) {
+	files, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open migrations: %w", err)
+	}
+
+	migrations, err := migrate.Load(files)
+	if err != nil {
+		return nil, err
+	}
+
+	return migrate.New(&migrationDriver{db: d.SQL}, migrations, d.Logger), nil
+}
+
+// migrationDriver relies on SQLite's database-wide write lock instead of an
+// advisory lock: every migration runs in a BEGIN IMMEDIATE transaction that
+// re-checks schema_migrations, so a second process waits and then skips it.
+type migrationDriver struct {
+	db *sql.DB
+}
+
+func (m *migrationDriver) Lock(ctx context.Context) error {
+	return nil
+}
+
+func (m *migrationDriver) Unlock(ctx context.Context) error {
+	return nil
+}
+
+func (m *migrationDriver) EnsureVersionTable(ctx context.Context) error {
+	query := `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		applied_at TIMESTAMP NOT NULL
+	)`
+	_, err := m.db.ExecContext(ctx, query)
+	return err
+}
+
+func (m *migrationDriver) Applied(ctx context.Context) ([]migrate.Record, error) {
+	query := `SELECT version, name, applied_at FROM schema_migrations ORDER BY version ASC`
+
+	rows, err := m.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []migrate.Record
+	for rows.Next() {
+		var record migrate.Record
+		if err := rows.Scan(&record.Version, &record.Name, &record.AppliedAt); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+func (m *migrationDriver) Apply(ctx context.Context, migration migrate.Migration, dir migrate.Direction) error {
+	// The connection is opened with _txlock=immediate, so this takes the write lock up front
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var applied bool
+	query := `SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = ?)`
+	if err := tx.QueryRowContext(ctx, query, migration.Version).Scan(&applied); err != nil {
+		return err
+	}
+	if applied == (dir == migrate.Up) {
+		return nil
+	}
+
+	body := migration.Up
+	if dir == migrate.Down {
+		body = migration.Down
+	}
+	if _, err := tx.ExecContext(ctx, body); err != nil {
+		return err
+	}
+
+	if dir == migrate.Up {
+		query = `INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`
+		_, err = tx.ExecContext(ctx, query, migration.Version, migration.Name, now())
+	} else {
+		query = `DELETE FROM schema_migrations WHERE version = ?`
+		_, err = tx.ExecContext(ctx, query, migration.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record migration: %w", err)
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,68 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+// TestMigrations reverts every migration and applies them again over rows
+// written by the first schema
+func TestMigrations(t *testing.T) {
+	ctx := context.Background()
+	d, err := NewDB(filepath.Join(t.TempDir(), "todo.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	m, err := d.Migrator()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Down(ctx, len(statuses)-1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.SQL.Exec(`INSERT INTO lists (id, title, position, created_at, updated_at) VALUES ('list', 'List', 1, ?, ?)`, now(), now()); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a", "b", "c"} {
+		if _, err := d.SQL.Exec(`INSERT INTO tasks (id, list_id, task_name, created_at, updated_at) VALUES (?, 'list', ?, ?, ?)`, name, name, now(), now()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ran, err := m.Up(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ran) != len(statuses)-1 {
+		t.Errorf("Up() ran %d migrations, want %d", len(ran), len(statuses)-1)
+	}
+
+	tasks, err := d.GetTasksByListID(ctx, "list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 3 {
+		t.Fatalf("got %d tasks, want 3", len(tasks))
+	}
+	seen := make(map[int]bool)
+	for _, task := range tasks {
+		if task.Position < 1 || seen[task.Position] {
+			t.Errorf("task %s has position %d, want distinct positions from 1", task.ID, task.Position)
+		}
+		seen[task.Position] = true
+	}
+	hits, err := d.Search(ctx, "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) != 1 || hits[0].Name != "b" {
+		t.Errorf("Search(b) = %+v, want task b", hits)
+	}
+}
@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS lists (
+	id TEXT PRIMARY KEY,
+	title VARCHAR(255) NOT NULL,
+	position INTEGER DEFAULT 0,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS tasks (
+	id TEXT PRIMARY KEY,
+	list_id TEXT NOT NULL REFERENCES lists(id) ON DELETE CASCADE,
+	task_name VARCHAR(255) NOT NULL,
+	completed BOOLEAN DEFAULT FALSE,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS tasks_list_id_idx ON tasks(list_id);
+
+CREATE TABLE IF NOT EXISTS subtasks (
+	id TEXT PRIMARY KEY,
+	task_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+	subtask_name VARCHAR(255) NOT NULL,
+	completed BOOLEAN DEFAULT FALSE,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS subtasks_task_id_idx ON subtasks(task_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS subtasks_task_id_position_idx;
+DROP INDEX IF EXISTS tasks_list_id_position_idx;
+
+ALTER TABLE subtasks DROP COLUMN position;
+ALTER TABLE tasks DROP COLUMN position;
@@ -0,0 +1,20 @@
+ALTER TABLE tasks ADD COLUMN position INTEGER DEFAULT 0;
+ALTER TABLE subtasks ADD COLUMN position INTEGER DEFAULT 0;
+
+-- Keep the previous newest-first order for existing rows
+UPDATE tasks SET position = ordered.position
+FROM (
+	SELECT id, ROW_NUMBER() OVER (PARTITION BY list_id ORDER BY created_at DESC, rowid DESC) AS position
+	FROM tasks
+) ordered
+WHERE tasks.id = ordered.id;
+
+UPDATE subtasks SET position = ordered.position
+FROM (
+	SELECT id, ROW_NUMBER() OVER (PARTITION BY task_id ORDER BY created_at DESC, rowid DESC) AS position
+	FROM subtasks
+) ordered
+WHERE subtasks.id = ordered.id;
+
+CREATE INDEX IF NOT EXISTS tasks_list_id_position_idx ON tasks(list_id, position);
+CREATE INDEX IF NOT EXISTS subtasks_task_id_position_idx ON subtasks(task_id, position);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS tasks_remind_at_idx;
+DROP INDEX IF EXISTS tasks_due_at_idx;
+DROP INDEX IF EXISTS tasks_due_date_idx;
+
+ALTER TABLE tasks DROP COLUMN remind_at;
+ALTER TABLE tasks DROP COLUMN due_at;
+ALTER TABLE tasks DROP COLUMN due_date;
@@ -0,0 +1,9 @@
+-- due_date holds YYYY-MM-DD text; TEXT keeps the driver from parsing it as a timestamp.
+-- due_at and remind_at are stored in UTC so they compare correctly as text.
+ALTER TABLE tasks ADD COLUMN due_date TEXT;
+ALTER TABLE tasks ADD COLUMN due_at TIMESTAMP;
+ALTER TABLE tasks ADD COLUMN remind_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS tasks_due_date_idx ON tasks(due_date) WHERE due_date IS NOT NULL;
+CREATE INDEX IF NOT EXISTS tasks_due_at_idx ON tasks(due_at) WHERE due_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS tasks_remind_at_idx ON tasks(remind_at) WHERE remind_at IS NOT NULL;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS tasks_starred_idx;
+
+ALTER TABLE tasks DROP COLUMN starred;
+ALTER TABLE tasks DROP COLUMN priority;
+ALTER TABLE tasks DROP COLUMN notes;
@@ -0,0 +1,5 @@
+ALTER TABLE tasks ADD COLUMN notes TEXT NOT NULL DEFAULT '';
+ALTER TABLE tasks ADD COLUMN priority TEXT NOT NULL DEFAULT 'none' CHECK (priority IN ('none', 'low', 'medium', 'high'));
+ALTER TABLE tasks ADD COLUMN starred BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS tasks_starred_idx ON tasks(created_at) WHERE starred;
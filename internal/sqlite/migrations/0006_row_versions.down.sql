@@ -0,0 +1,3 @@
+ALTER TABLE subtasks DROP COLUMN version;
+ALTER TABLE tasks DROP COLUMN version;
+ALTER TABLE lists DROP COLUMN version;
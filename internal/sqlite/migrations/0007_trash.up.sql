@@ -0,0 +1,8 @@
+-- deleted_at is stored in UTC so it compares correctly as text
+ALTER TABLE lists ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE tasks ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE subtasks ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS lists_deleted_at_idx ON lists(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS tasks_deleted_at_idx ON tasks(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS subtasks_deleted_at_idx ON subtasks(deleted_at) WHERE deleted_at IS NOT NULL;
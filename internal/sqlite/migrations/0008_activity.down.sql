@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS subtasks_activity_update;
+DROP TRIGGER IF EXISTS subtasks_activity_insert;
+DROP TRIGGER IF EXISTS tasks_activity_update;
+DROP TRIGGER IF EXISTS tasks_activity_insert;
+DROP TRIGGER IF EXISTS lists_activity_update;
+DROP TRIGGER IF EXISTS lists_activity_insert;
+
+DROP TABLE IF EXISTS activity;
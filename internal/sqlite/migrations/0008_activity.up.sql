@@ -0,0 +1,177 @@
+-- Activity is written by triggers, so every change is logged in the same
+-- transaction that makes it. before and after hold the changed columns as JSON;
+-- position is left out because reordering renumbers whole lists.
+CREATE TABLE IF NOT EXISTS activity (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	entity TEXT NOT NULL CHECK (entity IN ('list', 'task', 'subtask')),
+	entity_id TEXT NOT NULL,
+	list_id TEXT NOT NULL,
+	task_id TEXT,
+	action TEXT NOT NULL CHECK (action IN ('create', 'update', 'toggle', 'move', 'delete', 'restore')),
+	name TEXT NOT NULL,
+	before TEXT,
+	after TEXT,
+	created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS activity_list_id_idx ON activity(list_id, id);
+CREATE INDEX IF NOT EXISTS activity_task_id_idx ON activity(task_id, id);
+CREATE INDEX IF NOT EXISTS activity_entity_id_idx ON activity(entity_id);
+
+CREATE TRIGGER IF NOT EXISTS lists_activity_insert AFTER INSERT ON lists
+BEGIN
+	INSERT INTO activity (entity, entity_id, list_id, task_id, action, name, after, created_at)
+	VALUES ('list', NEW.id, NEW.id, NULL, 'create', NEW.title,
+		json_object(
+			'title', NEW.title),
+		strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'));
+END;
+
+CREATE TRIGGER IF NOT EXISTS lists_activity_update AFTER UPDATE ON lists
+WHEN OLD.title IS NOT NEW.title OR OLD.deleted_at IS NOT NEW.deleted_at
+BEGIN
+	INSERT INTO activity (entity, entity_id, list_id, task_id, action, name, before, after, created_at)
+	VALUES ('list', NEW.id, NEW.id, NULL,
+		CASE
+			WHEN OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN 'delete'
+			WHEN OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN 'restore'
+			ELSE 'update'
+		END,
+		NEW.title,
+		json_remove(json_object(
+				'title', OLD.title,
+				'deleted_at', strftime('%Y-%m-%dT%H:%M:%fZ', OLD.deleted_at)),
+			CASE WHEN OLD.title IS NEW.title THEN '$.title' ELSE '$._' END,
+			CASE WHEN OLD.deleted_at IS NEW.deleted_at THEN '$.deleted_at' ELSE '$._' END),
+		json_remove(json_object(
+				'title', NEW.title,
+				'deleted_at', strftime('%Y-%m-%dT%H:%M:%fZ', NEW.deleted_at)),
+			CASE WHEN OLD.title IS NEW.title THEN '$.title' ELSE '$._' END,
+			CASE WHEN OLD.deleted_at IS NEW.deleted_at THEN '$.deleted_at' ELSE '$._' END),
+		strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'));
+END;
+
+CREATE TRIGGER IF NOT EXISTS tasks_activity_insert AFTER INSERT ON tasks
+BEGIN
+	INSERT INTO activity (entity, entity_id, list_id, task_id, action, name, after, created_at)
+	VALUES ('task', NEW.id, NEW.list_id, NEW.id, 'create', NEW.task_name,
+		json_object(
+			'list_id', NEW.list_id,
+			'task_name', NEW.task_name,
+			'notes', NEW.notes,
+			'completed', json(CASE WHEN NEW.completed THEN 'true' ELSE 'false' END),
+			'priority', NEW.priority,
+			'starred', json(CASE WHEN NEW.starred THEN 'true' ELSE 'false' END),
+			'due_date', NEW.due_date,
+			'due_at', strftime('%Y-%m-%dT%H:%M:%fZ', NEW.due_at),
+			'remind_at', strftime('%Y-%m-%dT%H:%M:%fZ', NEW.remind_at),
+			'recurrence', NEW.recurrence),
+		strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'));
+END;
+
+CREATE TRIGGER IF NOT EXISTS tasks_activity_update AFTER UPDATE ON tasks
+WHEN OLD.list_id IS NOT NEW.list_id OR OLD.task_name IS NOT NEW.task_name OR OLD.notes IS NOT NEW.notes OR OLD.completed IS NOT NEW.completed OR OLD.priority IS NOT NEW.priority OR OLD.starred IS NOT NEW.starred OR OLD.due_date IS NOT NEW.due_date OR OLD.due_at IS NOT NEW.due_at OR OLD.remind_at IS NOT NEW.remind_at OR OLD.recurrence IS NOT NEW.recurrence OR OLD.deleted_at IS NOT NEW.deleted_at
+BEGIN
+	INSERT INTO activity (entity, entity_id, list_id, task_id, action, name, before, after, created_at)
+	VALUES ('task', NEW.id, NEW.list_id, NEW.id,
+		CASE
+			WHEN OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN 'delete'
+			WHEN OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN 'restore'
+			WHEN OLD.list_id IS NOT NEW.list_id THEN 'move'
+			WHEN OLD.list_id IS NEW.list_id AND OLD.task_name IS NEW.task_name AND OLD.notes IS NEW.notes AND OLD.priority IS NEW.priority AND OLD.starred IS NEW.starred AND OLD.due_date IS NEW.due_date AND OLD.due_at IS NEW.due_at AND OLD.remind_at IS NEW.remind_at AND OLD.recurrence IS NEW.recurrence THEN 'toggle'
+			ELSE 'update'
+		END,
+		NEW.task_name,
+		json_remove(json_object(
+				'list_id', OLD.list_id,
+				'task_name', OLD.task_name,
+				'notes', OLD.notes,
+				'completed', json(CASE WHEN OLD.completed THEN 'true' ELSE 'false' END),
+				'priority', OLD.priority,
+				'starred', json(CASE WHEN OLD.starred THEN 'true' ELSE 'false' END),
+				'due_date', OLD.due_date,
+				'due_at', strftime('%Y-%m-%dT%H:%M:%fZ', OLD.due_at),
+				'remind_at', strftime('%Y-%m-%dT%H:%M:%fZ', OLD.remind_at),
+				'recurrence', OLD.recurrence,
+				'deleted_at', strftime('%Y-%m-%dT%H:%M:%fZ', OLD.deleted_at)),
+			CASE WHEN OLD.list_id IS NEW.list_id THEN '$.list_id' ELSE '$._' END,
+			CASE WHEN OLD.task_name IS NEW.task_name THEN '$.task_name' ELSE '$._' END,
+			CASE WHEN OLD.notes IS NEW.notes THEN '$.notes' ELSE '$._' END,
+			CASE WHEN OLD.completed IS NEW.completed THEN '$.completed' ELSE '$._' END,
+			CASE WHEN OLD.priority IS NEW.priority THEN '$.priority' ELSE '$._' END,
+			CASE WHEN OLD.starred IS NEW.starred THEN '$.starred' ELSE '$._' END,
+			CASE WHEN OLD.due_date IS NEW.due_date THEN '$.due_date' ELSE '$._' END,
+			CASE WHEN OLD.due_at IS NEW.due_at THEN '$.due_at' ELSE '$._' END,
+			CASE WHEN OLD.remind_at IS NEW.remind_at THEN '$.remind_at' ELSE '$._' END,
+			CASE WHEN OLD.recurrence IS NEW.recurrence THEN '$.recurrence' ELSE '$._' END,
+			CASE WHEN OLD.deleted_at IS NEW.deleted_at THEN '$.deleted_at' ELSE '$._' END),
+		json_remove(json_object(
+				'list_id', NEW.list_id,
+				'task_name', NEW.task_name,
+				'notes', NEW.notes,
+				'completed', json(CASE WHEN NEW.completed THEN 'true' ELSE 'false' END),
+				'priority', NEW.priority,
+				'starred', json(CASE WHEN NEW.starred THEN 'true' ELSE 'false' END),
+				'due_date', NEW.due_date,
+				'due_at', strftime('%Y-%m-%dT%H:%M:%fZ', NEW.due_at),
+				'remind_at', strftime('%Y-%m-%dT%H:%M:%fZ', NEW.remind_at),
+				'recurrence', NEW.recurrence,
+				'deleted_at', strftime('%Y-%m-%dT%H:%M:%fZ', NEW.deleted_at)),
+			CASE WHEN OLD.list_id IS NEW.list_id THEN '$.list_id' ELSE '$._' END,
+			CASE WHEN OLD.task_name IS NEW.task_name THEN '$.task_name' ELSE '$._' END,
+			CASE WHEN OLD.notes IS NEW.notes THEN '$.notes' ELSE '$._' END,
+			CASE WHEN OLD.completed IS NEW.completed THEN '$.completed' ELSE '$._' END,
+			CASE WHEN OLD.priority IS NEW.priority THEN '$.priority' ELSE '$._' END,
+			CASE WHEN OLD.starred IS NEW.starred THEN '$.starred' ELSE '$._' END,
+			CASE WHEN OLD.due_date IS NEW.due_date THEN '$.due_date' ELSE '$._' END,
+			CASE WHEN OLD.due_at IS NEW.due_at THEN '$.due_at' ELSE '$._' END,
+			CASE WHEN OLD.remind_at IS NEW.remind_at THEN '$.remind_at' ELSE '$._' END,
+			CASE WHEN OLD.recurrence IS NEW.recurrence THEN '$.recurrence' ELSE '$._' END,
+			CASE WHEN OLD.deleted_at IS NEW.deleted_at THEN '$.deleted_at' ELSE '$._' END),
+		strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'));
+END;
+
+CREATE TRIGGER IF NOT EXISTS subtasks_activity_insert AFTER INSERT ON subtasks
+BEGIN
+	INSERT INTO activity (entity, entity_id, list_id, task_id, action, name, after, created_at)
+	VALUES ('subtask', NEW.id, (SELECT list_id FROM tasks WHERE id = NEW.task_id), NEW.task_id, 'create', NEW.subtask_name,
+		json_object(
+			'task_id', NEW.task_id,
+			'subtask_name', NEW.subtask_name,
+			'completed', json(CASE WHEN NEW.completed THEN 'true' ELSE 'false' END)),
+		strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'));
+END;
+
+CREATE TRIGGER IF NOT EXISTS subtasks_activity_update AFTER UPDATE ON subtasks
+WHEN OLD.task_id IS NOT NEW.task_id OR OLD.subtask_name IS NOT NEW.subtask_name OR OLD.completed IS NOT NEW.completed OR OLD.deleted_at IS NOT NEW.deleted_at
+BEGIN
+	INSERT INTO activity (entity, entity_id, list_id, task_id, action, name, before, after, created_at)
+	VALUES ('subtask', NEW.id, (SELECT list_id FROM tasks WHERE id = NEW.task_id), NEW.task_id,
+		CASE
+			WHEN OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN 'delete'
+			WHEN OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN 'restore'
+			WHEN OLD.task_id IS NOT NEW.task_id THEN 'move'
+			WHEN OLD.task_id IS NEW.task_id AND OLD.subtask_name IS NEW.subtask_name THEN 'toggle'
+			ELSE 'update'
+		END,
+		NEW.subtask_name,
+		json_remove(json_object(
+				'task_id', OLD.task_id,
+				'subtask_name', OLD.subtask_name,
+				'completed', json(CASE WHEN OLD.completed THEN 'true' ELSE 'false' END),
+				'deleted_at', strftime('%Y-%m-%dT%H:%M:%fZ', OLD.deleted_at)),
+			CASE WHEN OLD.task_id IS NEW.task_id THEN '$.task_id' ELSE '$._' END,
+			CASE WHEN OLD.subtask_name IS NEW.subtask_name THEN '$.subtask_name' ELSE '$._' END,
+			CASE WHEN OLD.completed IS NEW.completed THEN '$.completed' ELSE '$._' END,
+			CASE WHEN OLD.deleted_at IS NEW.deleted_at THEN '$.deleted_at' ELSE '$._' END),
+		json_remove(json_object(
+				'task_id', NEW.task_id,
+				'subtask_name', NEW.subtask_name,
+				'completed', json(CASE WHEN NEW.completed THEN 'true' ELSE 'false' END),
+				'deleted_at', strftime('%Y-%m-%dT%H:%M:%fZ', NEW.deleted_at)),
+			CASE WHEN OLD.task_id IS NEW.task_id THEN '$.task_id' ELSE '$._' END,
+			CASE WHEN OLD.subtask_name IS NEW.subtask_name THEN '$.subtask_name' ELSE '$._' END,
+			CASE WHEN OLD.completed IS NEW.completed THEN '$.completed' ELSE '$._' END,
+			CASE WHEN OLD.deleted_at IS NEW.deleted_at THEN '$.deleted_at' ELSE '$._' END),
+		strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'));
+END;
@@ -0,0 +1,13 @@
+DROP TRIGGER IF EXISTS subtasks_search_delete;
+DROP TRIGGER IF EXISTS subtasks_search_update;
+DROP TRIGGER IF EXISTS subtasks_search_insert;
+DROP TRIGGER IF EXISTS tasks_search_delete;
+DROP TRIGGER IF EXISTS tasks_search_update;
+DROP TRIGGER IF EXISTS tasks_search_insert;
+DROP TRIGGER IF EXISTS lists_search_delete;
+DROP TRIGGER IF EXISTS lists_search_update;
+DROP TRIGGER IF EXISTS lists_search_insert;
+
+DROP TABLE IF EXISTS subtasks_search;
+DROP TABLE IF EXISTS tasks_search;
+DROP TABLE IF EXISTS lists_search;
@@ -0,0 +1,48 @@
+-- Full-text indexes over the names, kept in sync by triggers. Diacritics are
+-- kept so that words match the same way as in the other stores.
+CREATE VIRTUAL TABLE lists_search USING fts5(
+	title, content = 'lists', content_rowid = 'rowid', tokenize = 'unicode61 remove_diacritics 0'
+);
+CREATE VIRTUAL TABLE tasks_search USING fts5(
+	task_name, content = 'tasks', content_rowid = 'rowid', tokenize = 'unicode61 remove_diacritics 0'
+);
+CREATE VIRTUAL TABLE subtasks_search USING fts5(
+	subtask_name, content = 'subtasks', content_rowid = 'rowid', tokenize = 'unicode61 remove_diacritics 0'
+);
+
+INSERT INTO lists_search (lists_search) VALUES ('rebuild');
+INSERT INTO tasks_search (tasks_search) VALUES ('rebuild');
+INSERT INTO subtasks_search (subtasks_search) VALUES ('rebuild');
+
+CREATE TRIGGER lists_search_insert AFTER INSERT ON lists BEGIN
+	INSERT INTO lists_search (rowid, title) VALUES (NEW.rowid, NEW.title);
+END;
+CREATE TRIGGER lists_search_update AFTER UPDATE OF title ON lists BEGIN
+	INSERT INTO lists_search (lists_search, rowid, title) VALUES ('delete', OLD.rowid, OLD.title);
+	INSERT INTO lists_search (rowid, title) VALUES (NEW.rowid, NEW.title);
+END;
+CREATE TRIGGER lists_search_delete AFTER DELETE ON lists BEGIN
+	INSERT INTO lists_search (lists_search, rowid, title) VALUES ('delete', OLD.rowid, OLD.title);
+END;
+
+CREATE TRIGGER tasks_search_insert AFTER INSERT ON tasks BEGIN
+	INSERT INTO tasks_search (rowid, task_name) VALUES (NEW.rowid, NEW.task_name);
+END;
+CREATE TRIGGER tasks_search_update AFTER UPDATE OF task_name ON tasks BEGIN
+	INSERT INTO tasks_search (tasks_search, rowid, task_name) VALUES ('delete', OLD.rowid, OLD.task_name);
+	INSERT INTO tasks_search (rowid, task_name) VALUES (NEW.rowid, NEW.task_name);
+END;
+CREATE TRIGGER tasks_search_delete AFTER DELETE ON tasks BEGIN
+	INSERT INTO tasks_search (tasks_search, rowid, task_name) VALUES ('delete', OLD.rowid, OLD.task_name);
+END;
+
+CREATE TRIGGER subtasks_search_insert AFTER INSERT ON subtasks BEGIN
+	INSERT INTO subtasks_search (rowid, subtask_name) VALUES (NEW.rowid, NEW.subtask_name);
+END;
+CREATE TRIGGER subtasks_search_update AFTER UPDATE OF subtask_name ON subtasks BEGIN
+	INSERT INTO subtasks_search (subtasks_search, rowid, subtask_name) VALUES ('delete', OLD.rowid, OLD.subtask_name);
+	INSERT INTO subtasks_search (rowid, subtask_name) VALUES (NEW.rowid, NEW.subtask_name);
+END;
+CREATE TRIGGER subtasks_search_delete AFTER DELETE ON subtasks BEGIN
+	INSERT INTO subtasks_search (subtasks_search, rowid, subtask_name) VALUES ('delete', OLD.rowid, OLD.subtask_name);
+END;
@@ -0,0 +1,44 @@
+-- A list with a query is a smart list, which shows the tasks matching it.
+-- The list activity triggers are replaced to log changes of the query.
+ALTER TABLE lists ADD COLUMN query TEXT;
+
+DROP TRIGGER IF EXISTS lists_activity_insert;
+DROP TRIGGER IF EXISTS lists_activity_update;
+
+CREATE TRIGGER IF NOT EXISTS lists_activity_insert AFTER INSERT ON lists
+BEGIN
+	INSERT INTO activity (entity, entity_id, list_id, task_id, action, name, after, created_at)
+	VALUES ('list', NEW.id, NEW.id, NULL, 'create', NEW.title,
+		json_object(
+			'title', NEW.title,
+			'query', NEW.query),
+		strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'));
+END;
+
+CREATE TRIGGER IF NOT EXISTS lists_activity_update AFTER UPDATE ON lists
+WHEN OLD.title IS NOT NEW.title OR OLD.query IS NOT NEW.query OR OLD.deleted_at IS NOT NEW.deleted_at
+BEGIN
+	INSERT INTO activity (entity, entity_id, list_id, task_id, action, name, before, after, created_at)
+	VALUES ('list', NEW.id, NEW.id, NULL,
+		CASE
+			WHEN OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN 'delete'
+			WHEN OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN 'restore'
+			ELSE 'update'
+		END,
+		NEW.title,
+		json_remove(json_object(
+				'title', OLD.title,
+				'query', OLD.query,
+				'deleted_at', strftime('%Y-%m-%dT%H:%M:%fZ', OLD.deleted_at)),
+			CASE WHEN OLD.title IS NEW.title THEN '$.title' ELSE '$._' END,
+			CASE WHEN OLD.query IS NEW.query THEN '$.query' ELSE '$._' END,
+			CASE WHEN OLD.deleted_at IS NEW.deleted_at THEN '$.deleted_at' ELSE '$._' END),
+		json_remove(json_object(
+				'title', NEW.title,
+				'query', NEW.query,
+				'deleted_at', strftime('%Y-%m-%dT%H:%M:%fZ', NEW.deleted_at)),
+			CASE WHEN OLD.title IS NEW.title THEN '$.title' ELSE '$._' END,
+			CASE WHEN OLD.query IS NEW.query THEN '$.query' ELSE '$._' END,
+			CASE WHEN OLD.deleted_at IS NEW.deleted_at THEN '$.deleted_at' ELSE '$._' END),
+		strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'));
+END;
@@ -0,0 +1 @@
+ALTER TABLE activity DROP COLUMN actor;
@@ -0,0 +1,4 @@
+-- actor names who made a change. SQLite has no session settings, so the
+-- triggers from 0008_activity leave it NULL and a temporary trigger created by
+-- DB.SetActor on the connection fills it in.
+ALTER TABLE activity ADD COLUMN actor TEXT;
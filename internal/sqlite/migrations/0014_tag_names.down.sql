@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS tags_name_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS tags_name_idx ON tags(lower(name));
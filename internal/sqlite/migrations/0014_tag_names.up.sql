@@ -0,0 +1,5 @@
+-- lower() folds only ASCII letters, so 0009_tags let "Été" and "été" both be
+-- created. The index now folds names with unicode_lower, which package sqlite
+-- registers on every connection, the same way the other stores compare them.
+DROP INDEX IF EXISTS tags_name_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS tags_name_idx ON tags(unicode_lower(name));
@@ -0,0 +1,315 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+// MoveTask moves a task to another list (or within its list) at the given 1-based position
+func (d *DB) MoveTask(ctx context.Context, taskID, targetListID string, position int) (*server.Task, error) {
+	tx, err := d.SQL.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", mapError(err))
+	}
+	defer tx.Rollback()
+
+	if err := requireRow(ctx, tx, `SELECT EXISTS (SELECT 1 FROM lists WHERE id = ? AND deleted_at IS NULL AND query IS NULL)`, targetListID); err != nil {
+		return nil, taskListError(ctx, tx, targetListID, err)
+	}
+
+	query := `UPDATE tasks SET list_id = ?, version = version + 1, updated_at = ? WHERE id = ? AND deleted_at IS NULL`
+	result, err := tx.ExecContext(ctx, query, targetListID, now(), taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to move task: %w", mapError(err))
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil, server.NotFound("task", taskID)
+	}
+
+	ids, err := taskOrder(ctx, tx, targetListID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if err := renumberTasks(ctx, tx, server.InsertAt(ids, taskID, position)); err != nil {
+		return nil, err
+	}
+
+	task, err := scanTask(tx.QueryRowContext(ctx, `SELECT `+taskColumns+` FROM tasks WHERE id = ? AND deleted_at IS NULL`, taskID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task: %w", mapError(err))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", mapError(err))
+	}
+
+	return task, nil
+}
+
+// MoveSubTask moves a subtask to another task (or within its task) at the given 1-based position
+func (d *DB) MoveSubTask(ctx context.Context, subTaskID, targetTaskID string, position int) (*server.SubTask, error) {
+	tx, err := d.SQL.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", mapError(err))
+	}
+	defer tx.Rollback()
+
+	if err := requireRow(ctx, tx, `SELECT EXISTS (SELECT 1 FROM tasks WHERE id = ? AND deleted_at IS NULL)`, targetTaskID); err != nil {
+		return nil, taskNotFound(targetTaskID, err)
+	}
+
+	query := `UPDATE subtasks SET task_id = ?, version = version + 1, updated_at = ? WHERE id = ? AND deleted_at IS NULL`
+	result, err := tx.ExecContext(ctx, query, targetTaskID, now(), subTaskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to move subtask: %w", mapError(err))
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil, server.NotFound("subtask", subTaskID)
+	}
+
+	ids, err := subTaskOrder(ctx, tx, targetTaskID, subTaskID)
+	if err != nil {
+		return nil, err
+	}
+	if err := renumberSubTasks(ctx, tx, server.InsertAt(ids, subTaskID, position)); err != nil {
+		return nil, err
+	}
+
+	subTask, err := scanSubTask(tx.QueryRowContext(ctx, `SELECT `+subTaskColumns+` FROM subtasks WHERE id = ? AND deleted_at IS NULL`, subTaskID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subtask: %w", mapError(err))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", mapError(err))
+	}
+
+	return subTask, nil
+}
+
+// PromoteSubTaskToTask replaces a subtask with a task placed right after its former parent
+func (d *DB) PromoteSubTaskToTask(ctx context.Context, subTaskID string) (*server.Task, error) {
+	tx, err := d.SQL.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", mapError(err))
+	}
+	defer tx.Rollback()
+
+	query := `DELETE FROM subtasks WHERE id = ? AND deleted_at IS NULL RETURNING ` + subTaskColumns
+	subTask, err := scanSubTask(tx.QueryRowContext(ctx, query, subTaskID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, server.NotFound("subtask", subTaskID)
+		}
+		return nil, fmt.Errorf("failed to promote subtask: %w", mapError(err))
+	}
+
+	var listID string
+	query = `SELECT list_id FROM tasks WHERE id = ? AND deleted_at IS NULL`
+	if err := tx.QueryRowContext(ctx, query, subTask.TaskID).Scan(&listID); err != nil {
+		return nil, fmt.Errorf("failed to get parent task: %w", mapError(err))
+	}
+
+	query = `INSERT INTO tasks (id, list_id, task_name, completed, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)`
+	_, err = tx.ExecContext(ctx, query, subTask.ID, listID, subTask.SubTaskName, subTask.Completed, subTask.CreatedAt, subTask.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to promote subtask: %w", mapError(err))
+	}
+
+	ids, err := taskOrder(ctx, tx, listID, subTask.ID)
+	if err != nil {
+		return nil, err
+	}
+	position := server.IndexOf(ids, subTask.TaskID) + 1
+	if err := renumberTasks(ctx, tx, server.InsertAt(ids, subTask.ID, position)); err != nil {
+		return nil, err
+	}
+
+	task, err := scanTask(tx.QueryRowContext(ctx, `SELECT `+taskColumns+` FROM tasks WHERE id = ? AND deleted_at IS NULL`, subTask.ID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task: %w", mapError(err))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", mapError(err))
+	}
+
+	return task, nil
+}
+
+// DemoteTaskToSubTask replaces a task with a subtask appended to targetTaskID
+func (d *DB) DemoteTaskToSubTask(ctx context.Context, taskID, targetTaskID string) (*server.SubTask, error) {
+	if taskID == targetTaskID {
+		return nil, server.InvalidInput("task %s cannot become a subtask of itself", taskID)
+	}
+
+	tx, err := d.SQL.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", mapError(err))
+	}
+	defer tx.Rollback()
+
+	if err := requireRow(ctx, tx, `SELECT EXISTS (SELECT 1 FROM tasks WHERE id = ? AND deleted_at IS NULL)`, targetTaskID); err != nil {
+		return nil, taskNotFound(targetTaskID, err)
+	}
+
+	// Subtasks cannot be nested, so a task that has its own must not be demoted
+	var subTaskCount int
+	query := `SELECT COUNT(*) FROM subtasks WHERE task_id = ?`
+	if err := tx.QueryRowContext(ctx, query, taskID).Scan(&subTaskCount); err != nil {
+		return nil, fmt.Errorf("failed to count subtasks: %w", mapError(err))
+	}
+	if subTaskCount > 0 {
+		return nil, server.InvalidInput("task with id %s has subtasks and cannot be demoted", taskID)
+	}
+
+	query = `DELETE FROM tasks WHERE id = ? AND deleted_at IS NULL RETURNING ` + taskColumns
+	task, err := scanTask(tx.QueryRowContext(ctx, query, taskID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, server.NotFound("task", taskID)
+		}
+		return nil, fmt.Errorf("failed to demote task: %w", mapError(err))
+	}
+
+	query = `INSERT INTO subtasks (id, task_id, subtask_name, completed, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)`
+	_, err = tx.ExecContext(ctx, query, task.ID, targetTaskID, task.TaskName, task.Completed, task.CreatedAt, task.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to demote task: %w", mapError(err))
+	}
+
+	ids, err := subTaskOrder(ctx, tx, targetTaskID, task.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := renumberSubTasks(ctx, tx, append(ids, task.ID)); err != nil {
+		return nil, err
+	}
+
+	subTask, err := scanSubTask(tx.QueryRowContext(ctx, `SELECT `+subTaskColumns+` FROM subtasks WHERE id = ? AND deleted_at IS NULL`, task.ID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subtask: %w", mapError(err))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", mapError(err))
+	}
+
+	return subTask, nil
+}
+
+// requireRow runs an EXISTS query and returns sql.ErrNoRows if it is false
+func requireRow(ctx context.Context, tx *sql.Tx, query string, args ...any) error {
+	var exists bool
+	if err := tx.QueryRowContext(ctx, query, args...).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// rowQuerier is implemented by *sql.DB and *sql.Tx
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// taskListError explains why a list that tasks were to be put in was not
+// found: it does not exist or it is a smart list
+func taskListError(ctx context.Context, q rowQuerier, id string, err error) error {
+	if !errors.Is(err, sql.ErrNoRows) {
+		return listNotFound(id, err)
+	}
+	var smart bool
+	if err := q.QueryRowContext(ctx, `SELECT query IS NOT NULL FROM lists WHERE id = ? AND deleted_at IS NULL`, id).Scan(&smart); err != nil {
+		return listNotFound(id, err)
+	}
+	if smart {
+		return server.SmartListTasks(id)
+	}
+	return server.NotFound("list", id)
+}
+
+func listNotFound(id string, err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return server.NotFound("list", id)
+	}
+	return fmt.Errorf("failed to get list: %w", mapError(err))
+}
+
+func taskNotFound(id string, err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return server.NotFound("task", id)
+	}
+	return fmt.Errorf("failed to get task: %w", mapError(err))
+}
+
+// taskOrder returns the ids of a list's tasks in display order, leaving out excludeID
+func taskOrder(ctx context.Context, tx *sql.Tx, listID, excludeID string) ([]string, error) {
+	query := `SELECT id FROM tasks WHERE list_id = ? AND id <> ? AND deleted_at IS NULL ORDER BY position ASC, created_at DESC, rowid DESC`
+	rows, err := tx.QueryContext(ctx, query, listID, excludeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task order: %w", mapError(err))
+	}
+	ids, err := collectIDs(rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task order: %w", mapError(err))
+	}
+	return ids, nil
+}
+
+// subTaskOrder returns the ids of a task's subtasks in display order, leaving out excludeID
+func subTaskOrder(ctx context.Context, tx *sql.Tx, taskID, excludeID string) ([]string, error) {
+	query := `SELECT id FROM subtasks WHERE task_id = ? AND id <> ? AND deleted_at IS NULL ORDER BY position ASC, created_at DESC, rowid DESC`
+	rows, err := tx.QueryContext(ctx, query, taskID, excludeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subtask order: %w", mapError(err))
+	}
+	ids, err := collectIDs(rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subtask order: %w", mapError(err))
+	}
+	return ids, nil
+}
+
+// renumberTasks assigns positions 1..n to the given task ids
+func renumberTasks(ctx context.Context, tx *sql.Tx, ids []string) error {
+	for i, id := range ids {
+		query := `UPDATE tasks SET position = ?1, version = version + 1 WHERE id = ?2 AND deleted_at IS NULL AND position IS NOT ?1`
+		if _, err := tx.ExecContext(ctx, query, i+1, id); err != nil {
+			return fmt.Errorf("failed to update task position: %w", mapError(err))
+		}
+	}
+	return nil
+}
+
+// renumberSubTasks assigns positions 1..n to the given subtask ids
+func renumberSubTasks(ctx context.Context, tx *sql.Tx, ids []string) error {
+	for i, id := range ids {
+		query := `UPDATE subtasks SET position = ?1, version = version + 1 WHERE id = ?2 AND deleted_at IS NULL AND position IS NOT ?1`
+		if _, err := tx.ExecContext(ctx, query, i+1, id); err != nil {
+			return fmt.Errorf("failed to update subtask position: %w", mapError(err))
+		}
+	}
+	return nil
+}
+
+// collectIDs reads a single id column and closes rows
+func collectIDs(rows *sql.Rows) ([]string, error) {
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,73 @@
+package sqlite
+
+import (
+	"context"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+// Pages are read with a keyset: the condition picks up right after the cursor
+// and the extra row past the limit tells whether there is a next page.
+
+func (d *DB) GetTasksPageByListID(ctx context.Context, listID string, size int, cursor string) (*server.TaskPage, error) {
+	after, err := server.ParsePage(size, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE list_id = ? AND deleted_at IS NULL`
+	args := []any{listID}
+	if after != nil {
+		query += ` AND (position > ? OR position = ? AND (created_at < ? OR created_at = ? AND id < ?))`
+		args = append(args, after.Position, after.Position, after.CreatedAt, after.CreatedAt, after.ID)
+	}
+	query += ` ORDER BY position ASC, created_at DESC, id DESC LIMIT ?`
+	tasks, err := d.queryTasks(ctx, query, append(args, size+1)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return server.NewTaskPage(tasks, size), nil
+}
+
+func (d *DB) GetAllTasksPage(ctx context.Context, size int, cursor string) (*server.TaskPage, error) {
+	after, err := server.ParsePage(size, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE deleted_at IS NULL`
+	var args []any
+	if after != nil {
+		query += ` AND (created_at < ? OR created_at = ? AND id < ?)`
+		args = append(args, after.CreatedAt, after.CreatedAt, after.ID)
+	}
+	query += ` ORDER BY created_at DESC, id DESC LIMIT ?`
+	tasks, err := d.queryTasks(ctx, query, append(args, size+1)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return server.NewTaskPage(tasks, size), nil
+}
+
+func (d *DB) GetAllSubTasksPage(ctx context.Context, size int, cursor string) (*server.SubTaskPage, error) {
+	after, err := server.ParsePage(size, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + subTaskColumns + ` FROM subtasks WHERE deleted_at IS NULL`
+	var args []any
+	if after != nil {
+		query += ` AND (created_at < ? OR created_at = ? AND id < ?)`
+		args = append(args, after.CreatedAt, after.CreatedAt, after.ID)
+	}
+	query += ` ORDER BY created_at DESC, id DESC LIMIT ?`
+	subTasks, err := d.querySubTasks(ctx, query, append(args, size+1)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return server.NewSubTaskPage(subTasks, size), nil
+}
@@ -0,0 +1,143 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	server "github.com/HolySxn/To-Do/internal"
+	"github.com/google/uuid"
+)
+
+// CompleteTask marks a task completed and spawns its next occurrence if it recurs
+func (d *DB) CompleteTask(ctx context.Context, id string) (*server.TaskCompletion, error) {
+	tx, err := d.SQL.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", mapError(err))
+	}
+	defer tx.Rollback()
+
+	task, err := getTask(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	completion := &server.TaskCompletion{Task: *task}
+	if !task.Completed {
+		completion, err = completeTask(ctx, tx, task)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", mapError(err))
+	}
+
+	return completion, nil
+}
+
+func (d *DB) SetTaskRecurrence(ctx context.Context, id string, rule *string) (*server.Task, error) {
+	var recurrence *string
+	if rule != nil {
+		parsed, err := server.ParseRecurrence(*rule)
+		if err != nil {
+			return nil, err
+		}
+		canonical := parsed.String()
+		recurrence = &canonical
+	}
+
+	query := `UPDATE tasks SET recurrence = ?, version = version + 1, updated_at = ? WHERE id = ? AND deleted_at IS NULL RETURNING ` + taskColumns
+
+	task, err := scanTask(d.SQL.QueryRowContext(ctx, query, recurrence, now(), id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, server.NotFound("task", id)
+		}
+		return nil, fmt.Errorf("failed to set task recurrence: %w", mapError(err))
+	}
+
+	return task, nil
+}
+
+// getTask reads a task inside a transaction
+func getTask(ctx context.Context, tx *sql.Tx, id string) (*server.Task, error) {
+	task, err := scanTask(tx.QueryRowContext(ctx, `SELECT `+taskColumns+` FROM tasks WHERE id = ? AND deleted_at IS NULL`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, server.NotFound("task", id)
+		}
+		return nil, fmt.Errorf("failed to get task: %w", mapError(err))
+	}
+	return task, nil
+}
+
+// completeTask marks an incomplete task completed. For a recurring task the next
+// occurrence takes the same position, which lists it above the completed one,
+// and receives copies of the subtasks reset to incomplete and of the tags.
+func completeTask(ctx context.Context, tx *sql.Tx, task *server.Task) (*server.TaskCompletion, error) {
+	ts := now()
+	query := `UPDATE tasks SET completed = TRUE, version = version + 1, updated_at = ? WHERE id = ? AND deleted_at IS NULL RETURNING ` + taskColumns
+	completed, err := scanTask(tx.QueryRowContext(ctx, query, ts, task.ID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to complete task: %w", mapError(err))
+	}
+	completion := &server.TaskCompletion{Task: *completed}
+
+	next, err := server.NextOccurrence(*completed, time.Now(), time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute next occurrence: %w", mapError(err))
+	}
+	if next == nil {
+		return completion, nil
+	}
+
+	query = `INSERT INTO tasks (id, list_id, task_name, notes, priority, starred, position, due_date, due_at, remind_at, recurrence, created_at, updated_at)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10, ?11, ?12, ?12)
+		RETURNING ` + taskColumns
+	completion.Next, err = scanTask(tx.QueryRowContext(ctx, query, uuid.NewString(), next.ListID, next.TaskName, next.Notes, next.Priority, next.Starred,
+		completed.Position, next.DueDate, utc(next.DueAt), utc(next.RemindAt), next.Recurrence, ts))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create next occurrence: %w", mapError(err))
+	}
+
+	// Copies share a timestamp, so insert them last to first to keep their order
+	rows, err := tx.QueryContext(ctx, `SELECT subtask_name, position FROM subtasks
+		WHERE task_id = ? AND deleted_at IS NULL ORDER BY position DESC, created_at ASC, rowid ASC`, completed.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subtasks: %w", mapError(err))
+	}
+	type subTaskCopy struct {
+		name     string
+		position int
+	}
+	var copies []subTaskCopy
+	for rows.Next() {
+		var c subTaskCopy
+		if err := rows.Scan(&c.name, &c.position); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan subtask: %w", mapError(err))
+		}
+		copies = append(copies, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get subtasks: %w", mapError(err))
+	}
+
+	query = `INSERT INTO subtasks (id, task_id, subtask_name, position, created_at, updated_at) VALUES (?1, ?2, ?3, ?4, ?5, ?5)`
+	for _, c := range copies {
+		if _, err := tx.ExecContext(ctx, query, uuid.NewString(), completion.Next.ID, c.name, c.position, ts); err != nil {
+			return nil, fmt.Errorf("failed to copy subtasks: %w", mapError(err))
+		}
+	}
+	query = `INSERT INTO task_tags (task_id, tag_id) SELECT ?, tag_id FROM task_tags WHERE task_id = ?`
+	if _, err := tx.ExecContext(ctx, query, completion.Next.ID, completed.ID); err != nil {
+		return nil, fmt.Errorf("failed to copy tags: %w", mapError(err))
+	}
+
+	return completion, nil
+}
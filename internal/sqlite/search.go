@@ -0,0 +1,61 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+// The full-text tables are kept in sync by the triggers in the 0010_search
+// migration. They find the rows that contain a word starting with every term;
+// server.RankSearchHits ranks them.
+
+const searchQuery = `
+	SELECT 'list', l.id, l.title, l.id, l.title, NULL, NULL
+	FROM lists_search JOIN lists l ON l.rowid = lists_search.rowid
+	WHERE lists_search MATCH ?1 AND l.deleted_at IS NULL
+	UNION ALL
+	SELECT 'task', t.id, t.task_name, l.id, l.title, NULL, NULL
+	FROM tasks_search JOIN tasks t ON t.rowid = tasks_search.rowid JOIN lists l ON l.id = t.list_id
+	WHERE tasks_search MATCH ?1 AND t.deleted_at IS NULL
+	UNION ALL
+	SELECT 'subtask', s.id, s.subtask_name, l.id, l.title, t.id, t.task_name
+	FROM subtasks_search JOIN subtasks s ON s.rowid = subtasks_search.rowid
+		JOIN tasks t ON t.id = s.task_id JOIN lists l ON l.id = t.list_id
+	WHERE subtasks_search MATCH ?1 AND s.deleted_at IS NULL`
+
+func (d *DB) Search(ctx context.Context, query string) ([]server.SearchHit, error) {
+	terms := server.SearchTerms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	// Terms hold only letters and digits, so quoting them is enough
+	phrases := make([]string, len(terms))
+	for i, term := range terms {
+		phrases[i] = `"` + term + `"*`
+	}
+
+	rows, err := d.SQL.QueryContext(ctx, searchQuery, strings.Join(phrases, " "))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", mapError(err))
+	}
+	defer rows.Close()
+
+	var candidates []server.SearchHit
+	for rows.Next() {
+		var hit server.SearchHit
+		err := rows.Scan(&hit.Entity, &hit.ID, &hit.Name, &hit.ListID, &hit.ListTitle, &hit.TaskID, &hit.TaskName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan search hit: %w", mapError(err))
+		}
+		candidates = append(candidates, hit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to search: %w", mapError(err))
+	}
+
+	return server.RankSearchHits(candidates, terms), nil
+}
@@ -0,0 +1,108 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	server "github.com/HolySxn/To-Do/internal"
+	"github.com/HolySxn/To-Do/internal/query"
+	"github.com/google/uuid"
+	"modernc.org/sqlite"
+)
+
+var dialect = query.Dialect{
+	Param: func(n int) string { return fmt.Sprintf("?%d", n) },
+	Date:  func(param string) string { return param },
+	Lower: func(expr string) string { return "unicode_lower(" + expr + ")" },
+}
+
+// SQLite's lower() folds only ASCII letters, so queries fold names with
+// unicode_lower, which does what strings.ToLower does in the other stores
+func init() {
+	err := sqlite.RegisterDeterministicScalarFunction("unicode_lower", 1,
+		func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+			text, ok := args[0].(string)
+			if !ok {
+				return args[0], nil
+			}
+			return strings.ToLower(text), nil
+		})
+	if err != nil {
+		panic(fmt.Sprintf("failed to register unicode_lower: %v", err))
+	}
+}
+
+func (d *DB) CreateSmartList(ctx context.Context, title, q string) (*server.List, error) {
+	if _, err := query.Parse(q, time.Now()); err != nil {
+		return nil, err
+	}
+
+	stmt := `INSERT INTO lists (id, title, query, position, created_at, updated_at)
+		SELECT ?1, ?2, ?3, COALESCE(MAX(position), 0) + 1, ?4, ?4 FROM lists
+		RETURNING ` + listColumns
+
+	list, err := scanList(d.SQL.QueryRowContext(ctx, stmt, uuid.NewString(), title, q, now()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create smart list: %w", mapError(err))
+	}
+
+	return list, nil
+}
+
+func (d *DB) GetSmartListTasks(ctx context.Context, listID string, now time.Time) ([]server.Task, error) {
+	var q *string
+	err := d.SQL.QueryRowContext(ctx, `SELECT query FROM lists WHERE id = ? AND deleted_at IS NULL`, listID).Scan(&q)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, server.NotFound("list", listID)
+		}
+		return nil, fmt.Errorf("failed to get list: %w", mapError(err))
+	}
+	if q == nil {
+		return nil, server.NotSmartList(listID)
+	}
+
+	return d.QueryTasks(ctx, *q, now)
+}
+
+func (d *DB) QueryTasks(ctx context.Context, q string, now time.Time) ([]server.Task, error) {
+	parsed, err := query.Parse(q, now)
+	if err != nil {
+		return nil, err
+	}
+
+	where, args := parsed.SQL(dialect)
+	tasks, err := d.queryTasks(ctx, `SELECT `+taskColumns+` FROM tasks
+		WHERE deleted_at IS NULL AND `+where+`
+		ORDER BY created_at DESC, rowid DESC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	parsed.Sort(tasks)
+
+	return tasks, nil
+}
+
+// checkListQuery checks that a list is a smart list and that q parses
+func (d *DB) checkListQuery(ctx context.Context, id, q string) error {
+	if _, err := query.Parse(q, time.Now()); err != nil {
+		return err
+	}
+	var smart bool
+	err := d.SQL.QueryRowContext(ctx, `SELECT query IS NOT NULL FROM lists WHERE id = ? AND deleted_at IS NULL`, id).Scan(&smart)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return server.NotFound("list", id)
+		}
+		return fmt.Errorf("failed to get list: %w", mapError(err))
+	}
+	if !smart {
+		return server.NotSmartList(id)
+	}
+	return nil
+}
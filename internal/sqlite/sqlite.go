@@ -0,0 +1,111 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	server "github.com/HolySxn/To-Do/internal"
+	_ "modernc.org/sqlite"
+)
+
+var _ server.Store = (*DB)(nil)
+
+// DB is a server.Store backed by an embedded SQLite database file
+type DB struct {
+	SQL    *sql.DB
+	Logger *slog.Logger
+}
+
+// NewDB opens the database file and applies any pending schema migrations
+func NewDB(path string) (*DB, error) {
+	db, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Bring the schema up to date
+	db.Logger.Info("Applying database migrations")
+	if err := db.migrate(); err != nil {
+		db.Logger.Error("Failed to migrate database", "error", err)
+		db.SQL.Close()
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	db.Logger.Info("Database initialization completed successfully")
+	return db, nil
+}
+
+// Open opens the database file without touching the schema
+func Open(path string) (*DB, error) {
+	// Initialize logger
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+	}))
+
+	logger.Info("Opening SQLite database", "path", path)
+
+	if path != ":memory:" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			logger.Error("Failed to create database directory", "error", err)
+			return nil, fmt.Errorf("failed to create database directory: %w", err)
+		}
+	}
+
+	// Foreign keys are off by default in SQLite and are needed for cascading deletes.
+	// Immediate transactions take the write lock on BEGIN, so concurrent writers
+	// wait on busy_timeout instead of failing when upgrading a read lock.
+	dsn := path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_txlock=immediate&_time_format=sqlite"
+	sqlDB, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		logger.Error("Failed to open database", "error", err)
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// SQLite allows a single writer; one connection also keeps :memory: databases shared
+	sqlDB.SetMaxOpenConns(1)
+
+	if err := sqlDB.PingContext(context.Background()); err != nil {
+		sqlDB.Close()
+		logger.Error("Failed to ping database", "error", err)
+		return nil, fmt.Errorf("failed to ping database: %w", mapError(err))
+	}
+
+	db := &DB{
+		SQL:    sqlDB,
+		Logger: logger,
+	}
+
+	return db, nil
+}
+
+func (d *DB) Close() {
+	d.Logger.Info("Closing database connection")
+	d.SQL.Close()
+	d.Logger.Info("Database connection closed")
+}
+
+func (d *DB) migrate() error {
+	migrator, err := d.Migrator()
+	if err != nil {
+		return err
+	}
+
+	_, err = migrator.Up(context.Background())
+	return err
+}
+
+// now returns the timestamp stored in created_at/updated_at columns.
+// SQLite's CURRENT_TIMESTAMP only has second precision, which is too coarse for ordering.
+func now() time.Time {
+	return time.Now().UTC()
+}
+
+// scanner is implemented by *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...any) error
+}
@@ -0,0 +1,29 @@
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+
+	server "github.com/HolySxn/To-Do/internal"
+	"github.com/HolySxn/To-Do/internal/storetest"
+)
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) server.Store {
+		db, err := NewDB(filepath.Join(t.TempDir(), "todo.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return db
+	})
+}
+
+func TestStoreInMemory(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) server.Store {
+		db, err := NewDB(":memory:")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return db
+	})
+}
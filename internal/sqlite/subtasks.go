@@ -0,0 +1,172 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	server "github.com/HolySxn/To-Do/internal"
+	"github.com/google/uuid"
+)
+
+const subTaskColumns = `id, task_id, subtask_name, completed, position, version, created_at, updated_at, deleted_at`
+
+func scanSubTask(row scanner) (*server.SubTask, error) {
+	var subTask server.SubTask
+	err := row.Scan(
+		&subTask.ID,
+		&subTask.TaskID,
+		&subTask.SubTaskName,
+		&subTask.Completed,
+		&subTask.Position,
+		&subTask.Version,
+		&subTask.CreatedAt,
+		&subTask.UpdatedAt,
+		&subTask.DeletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &subTask, nil
+}
+
+func (d *DB) querySubTasks(ctx context.Context, query string, args ...any) ([]server.SubTask, error) {
+	rows, err := d.SQL.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subtasks: %w", mapError(err))
+	}
+	defer rows.Close()
+
+	var subTasks []server.SubTask
+	for rows.Next() {
+		subTask, err := scanSubTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan subtask: %w", mapError(err))
+		}
+		subTasks = append(subTasks, *subTask)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get subtasks: %w", mapError(err))
+	}
+
+	return subTasks, nil
+}
+
+// CRUD operations for SubTasks
+func (d *DB) CreateSubTask(ctx context.Context, taskID string, subTaskName string) (*server.SubTask, error) {
+	// New subtasks are placed above the existing ones
+	query := `INSERT INTO subtasks (id, task_id, subtask_name, position, created_at, updated_at)
+		SELECT ?1, ?2, ?3, (SELECT COALESCE(MIN(position), 1) - 1 FROM subtasks WHERE task_id = ?2), ?4, ?4
+		WHERE EXISTS (SELECT 1 FROM tasks WHERE id = ?2 AND deleted_at IS NULL)
+		RETURNING ` + subTaskColumns
+
+	subTask, err := scanSubTask(d.SQL.QueryRowContext(ctx, query, uuid.NewString(), taskID, subTaskName, now()))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("failed to create subtask: %w", server.NotFound("task", taskID))
+		}
+		return nil, fmt.Errorf("failed to create subtask: %w", mapError(err))
+	}
+
+	return subTask, nil
+}
+
+func (d *DB) GetSubTask(ctx context.Context, id string) (*server.SubTask, error) {
+	query := `SELECT ` + subTaskColumns + ` FROM subtasks WHERE id = ? AND deleted_at IS NULL`
+
+	subTask, err := scanSubTask(d.SQL.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, server.NotFound("subtask", id)
+		}
+		return nil, fmt.Errorf("failed to get subtask: %w", mapError(err))
+	}
+
+	return subTask, nil
+}
+
+func (d *DB) GetSubTasksByTaskID(ctx context.Context, taskID string) ([]server.SubTask, error) {
+	query := `SELECT ` + subTaskColumns + ` FROM subtasks WHERE task_id = ? AND deleted_at IS NULL ORDER BY position ASC, created_at DESC, rowid DESC`
+	return d.querySubTasks(ctx, query, taskID)
+}
+
+func (d *DB) GetAllSubTasks(ctx context.Context) ([]server.SubTask, error) {
+	query := `SELECT ` + subTaskColumns + ` FROM subtasks WHERE deleted_at IS NULL ORDER BY created_at DESC, rowid DESC`
+	return d.querySubTasks(ctx, query)
+}
+
+func (d *DB) UpdateSubTask(ctx context.Context, id, subTaskName string, completed bool, version int64) (*server.SubTask, error) {
+	query := `UPDATE subtasks SET subtask_name = ?1, completed = ?2, version = version + 1, updated_at = ?3 WHERE id = ?4 AND deleted_at IS NULL AND (?5 = 0 OR version = ?5) RETURNING ` + subTaskColumns
+
+	subTask, err := scanSubTask(d.SQL.QueryRowContext(ctx, query, subTaskName, completed, now(), id, version))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, d.subTaskConflict(ctx, id, version)
+		}
+		return nil, fmt.Errorf("failed to update subtask: %w", mapError(err))
+	}
+
+	return subTask, nil
+}
+
+func (d *DB) ToggleSubTaskCompletion(ctx context.Context, id string) (*server.SubTask, error) {
+	query := `UPDATE subtasks SET completed = NOT completed, version = version + 1, updated_at = ? WHERE id = ? AND deleted_at IS NULL RETURNING ` + subTaskColumns
+
+	subTask, err := scanSubTask(d.SQL.QueryRowContext(ctx, query, now(), id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, server.NotFound("subtask", id)
+		}
+		return nil, fmt.Errorf("failed to toggle subtask completion: %w", mapError(err))
+	}
+
+	return subTask, nil
+}
+
+// DeleteSubTask moves a subtask to the trash
+func (d *DB) DeleteSubTask(ctx context.Context, id string, version int64) error {
+	query := `UPDATE subtasks SET deleted_at = ?1, version = version + 1 WHERE id = ?2 AND deleted_at IS NULL AND (?3 = 0 OR version = ?3)`
+
+	result, err := d.SQL.ExecContext(ctx, query, now(), id, version)
+	if err != nil {
+		return fmt.Errorf("failed to delete subtask: %w", mapError(err))
+	}
+
+	if n, _ := result.RowsAffected(); n == 0 {
+		return d.subTaskConflict(ctx, id, version)
+	}
+
+	return nil
+}
+
+// ReorderSubTasks updates the positions of a task's subtasks based on the provided order
+func (d *DB) ReorderSubTasks(ctx context.Context, taskID string, subTaskIDs []string) error {
+	if len(subTaskIDs) == 0 {
+		return nil
+	}
+
+	// Start a transaction to ensure atomicity
+	tx, err := d.SQL.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", mapError(err))
+	}
+	defer tx.Rollback()
+
+	// Update positions for each subtask; ids from other tasks are ignored
+	ts := now()
+	for i, subTaskID := range subTaskIDs {
+		query := `UPDATE subtasks SET position = ?, version = version + 1, updated_at = ? WHERE id = ? AND deleted_at IS NULL AND task_id = ?`
+		_, err := tx.ExecContext(ctx, query, i+1, ts, subTaskID, taskID)
+		if err != nil {
+			return fmt.Errorf("failed to update subtask position: %w", mapError(err))
+		}
+	}
+
+	// Commit the transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", mapError(err))
+	}
+
+	return nil
+}
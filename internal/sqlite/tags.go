@@ -0,0 +1,240 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	server "github.com/HolySxn/To-Do/internal"
+	"github.com/google/uuid"
+)
+
+const tagColumns = `id, name, color, created_at, updated_at`
+
+func scanTag(row scanner) (*server.Tag, error) {
+	var tag server.Tag
+	err := row.Scan(
+		&tag.ID,
+		&tag.Name,
+		&tag.Color,
+		&tag.CreatedAt,
+		&tag.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (d *DB) queryTags(ctx context.Context, query string, args ...any) ([]server.Tag, error) {
+	rows, err := d.SQL.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", mapError(err))
+	}
+	defer rows.Close()
+
+	var tags []server.Tag
+	for rows.Next() {
+		tag, err := scanTag(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", mapError(err))
+		}
+		tags = append(tags, *tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", mapError(err))
+	}
+
+	return tags, nil
+}
+
+func (d *DB) CreateTag(ctx context.Context, name, color string) (*server.Tag, error) {
+	query := `INSERT INTO tags (id, name, color, created_at, updated_at) VALUES (?1, ?2, ?3, ?4, ?4) RETURNING ` + tagColumns
+
+	tag, err := scanTag(d.SQL.QueryRowContext(ctx, query, uuid.NewString(), name, color, now()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tag: %w", mapError(err))
+	}
+
+	return tag, nil
+}
+
+func (d *DB) GetTag(ctx context.Context, id string) (*server.Tag, error) {
+	query := `SELECT ` + tagColumns + ` FROM tags WHERE id = ?`
+
+	tag, err := scanTag(d.SQL.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, server.NotFound("tag", id)
+		}
+		return nil, fmt.Errorf("failed to get tag: %w", mapError(err))
+	}
+
+	return tag, nil
+}
+
+func (d *DB) GetAllTags(ctx context.Context) ([]server.Tag, error) {
+	return d.queryTags(ctx, `SELECT `+tagColumns+` FROM tags ORDER BY unicode_lower(name), name`)
+}
+
+func (d *DB) RenameTag(ctx context.Context, id, name string) (*server.Tag, error) {
+	query := `UPDATE tags SET name = ?, updated_at = ? WHERE id = ? RETURNING ` + tagColumns
+
+	tag, err := scanTag(d.SQL.QueryRowContext(ctx, query, name, now(), id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, server.NotFound("tag", id)
+		}
+		return nil, fmt.Errorf("failed to rename tag: %w", mapError(err))
+	}
+
+	return tag, nil
+}
+
+func (d *DB) SetTagColor(ctx context.Context, id, color string) (*server.Tag, error) {
+	query := `UPDATE tags SET color = ?, updated_at = ? WHERE id = ? RETURNING ` + tagColumns
+
+	tag, err := scanTag(d.SQL.QueryRowContext(ctx, query, color, now(), id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, server.NotFound("tag", id)
+		}
+		return nil, fmt.Errorf("failed to set tag color: %w", mapError(err))
+	}
+
+	return tag, nil
+}
+
+// DeleteTag removes a tag; its assignments go with it through ON DELETE CASCADE
+func (d *DB) DeleteTag(ctx context.Context, id string) error {
+	result, err := d.SQL.ExecContext(ctx, `DELETE FROM tags WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete tag: %w", mapError(err))
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return server.NotFound("tag", id)
+	}
+
+	return nil
+}
+
+func (d *DB) MergeTags(ctx context.Context, sourceID, targetID string) (*server.Tag, error) {
+	if sourceID == targetID {
+		return nil, server.InvalidInput("tag %s cannot be merged into itself", sourceID)
+	}
+
+	tx, err := d.SQL.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", mapError(err))
+	}
+	defer tx.Rollback()
+
+	if err := requireRow(ctx, tx, `SELECT EXISTS (SELECT 1 FROM tags WHERE id = ?)`, sourceID); err != nil {
+		return nil, tagNotFound(sourceID, err)
+	}
+	target, err := scanTag(tx.QueryRowContext(ctx, `SELECT `+tagColumns+` FROM tags WHERE id = ?`, targetID))
+	if err != nil {
+		return nil, tagNotFound(targetID, err)
+	}
+
+	query := `INSERT INTO task_tags (task_id, tag_id) SELECT task_id, ? FROM task_tags WHERE tag_id = ? ON CONFLICT DO NOTHING`
+	if _, err := tx.ExecContext(ctx, query, targetID, sourceID); err != nil {
+		return nil, fmt.Errorf("failed to merge tags: %w", mapError(err))
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM tags WHERE id = ?`, sourceID); err != nil {
+		return nil, fmt.Errorf("failed to delete tag: %w", mapError(err))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", mapError(err))
+	}
+
+	return target, nil
+}
+
+func (d *DB) AddTagToTask(ctx context.Context, taskID, tagID string) error {
+	tx, err := d.SQL.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", mapError(err))
+	}
+	defer tx.Rollback()
+
+	if err := requireRow(ctx, tx, `SELECT EXISTS (SELECT 1 FROM tasks WHERE id = ? AND deleted_at IS NULL)`, taskID); err != nil {
+		return taskNotFound(taskID, err)
+	}
+	if err := requireRow(ctx, tx, `SELECT EXISTS (SELECT 1 FROM tags WHERE id = ?)`, tagID); err != nil {
+		return tagNotFound(tagID, err)
+	}
+	query := `INSERT INTO task_tags (task_id, tag_id) VALUES (?, ?) ON CONFLICT DO NOTHING`
+	if _, err := tx.ExecContext(ctx, query, taskID, tagID); err != nil {
+		return fmt.Errorf("failed to add tag to task: %w", mapError(err))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", mapError(err))
+	}
+
+	return nil
+}
+
+func (d *DB) RemoveTagFromTask(ctx context.Context, taskID, tagID string) error {
+	tx, err := d.SQL.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", mapError(err))
+	}
+	defer tx.Rollback()
+
+	if err := requireRow(ctx, tx, `SELECT EXISTS (SELECT 1 FROM tasks WHERE id = ? AND deleted_at IS NULL)`, taskID); err != nil {
+		return taskNotFound(taskID, err)
+	}
+	if err := requireRow(ctx, tx, `SELECT EXISTS (SELECT 1 FROM tags WHERE id = ?)`, tagID); err != nil {
+		return tagNotFound(tagID, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM task_tags WHERE task_id = ? AND tag_id = ?`, taskID, tagID); err != nil {
+		return fmt.Errorf("failed to remove tag from task: %w", mapError(err))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", mapError(err))
+	}
+
+	return nil
+}
+
+func (d *DB) GetTaskTags(ctx context.Context, taskID string) ([]server.Tag, error) {
+	query := `SELECT ` + tagColumns + ` FROM tags
+		WHERE id IN (SELECT tag_id FROM task_tags WHERE task_id = ?)
+		ORDER BY unicode_lower(name), name`
+	return d.queryTags(ctx, query, taskID)
+}
+
+func (d *DB) GetTasksByTags(ctx context.Context, tagIDs []string, matchAll bool) ([]server.Task, error) {
+	ids := slices.Compact(slices.Sorted(slices.Values(tagIDs)))
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	required := 1
+	if matchAll {
+		required = len(ids)
+	}
+
+	list, err := json.Marshal(ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode tag ids: %w", err)
+	}
+
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE deleted_at IS NULL AND id IN (
+		SELECT task_id FROM task_tags WHERE tag_id IN (SELECT value FROM json_each(?)) GROUP BY task_id HAVING COUNT(*) >= ?)
+		ORDER BY created_at DESC, rowid DESC`
+	return d.queryTasks(ctx, query, string(list), required)
+}
+
+func tagNotFound(id string, err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return server.NotFound("tag", id)
+	}
+	return fmt.Errorf("failed to get tag: %w", mapError(err))
+}
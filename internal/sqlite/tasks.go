@@ -0,0 +1,214 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	server "github.com/HolySxn/To-Do/internal"
+	"github.com/google/uuid"
+)
+
+const taskColumns = `id, list_id, task_name, notes, completed, priority, starred, position, due_date, due_at, remind_at, recurrence, version, created_at, updated_at, deleted_at`
+
+func scanTask(row scanner) (*server.Task, error) {
+	var task server.Task
+	err := row.Scan(
+		&task.ID,
+		&task.ListID,
+		&task.TaskName,
+		&task.Notes,
+		&task.Completed,
+		&task.Priority,
+		&task.Starred,
+		&task.Position,
+		&task.DueDate,
+		&task.DueAt,
+		&task.RemindAt,
+		&task.Recurrence,
+		&task.Version,
+		&task.CreatedAt,
+		&task.UpdatedAt,
+		&task.DeletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+func (d *DB) queryTasks(ctx context.Context, query string, args ...any) ([]server.Task, error) {
+	rows, err := d.SQL.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tasks: %w", mapError(err))
+	}
+	defer rows.Close()
+
+	var tasks []server.Task
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan task: %w", mapError(err))
+		}
+		tasks = append(tasks, *task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get tasks: %w", mapError(err))
+	}
+
+	return tasks, nil
+}
+
+// CRUD operations for Tasks
+func (d *DB) CreateTask(ctx context.Context, listID, taskName string) (*server.Task, error) {
+	// New tasks are placed above the existing ones
+	query := `INSERT INTO tasks (id, list_id, task_name, position, created_at, updated_at)
+		SELECT ?1, ?2, ?3, (SELECT COALESCE(MIN(position), 1) - 1 FROM tasks WHERE list_id = ?2), ?4, ?4
+		WHERE EXISTS (SELECT 1 FROM lists WHERE id = ?2 AND deleted_at IS NULL AND query IS NULL)
+		RETURNING ` + taskColumns
+
+	task, err := scanTask(d.SQL.QueryRowContext(ctx, query, uuid.NewString(), listID, taskName, now()))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("failed to create task: %w", taskListError(ctx, d.SQL, listID, err))
+		}
+		return nil, fmt.Errorf("failed to create task: %w", mapError(err))
+	}
+
+	return task, nil
+}
+
+func (d *DB) GetTask(ctx context.Context, id string) (*server.Task, error) {
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE id = ? AND deleted_at IS NULL`
+
+	task, err := scanTask(d.SQL.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, server.NotFound("task", id)
+		}
+		return nil, fmt.Errorf("failed to get task: %w", mapError(err))
+	}
+
+	return task, nil
+}
+
+func (d *DB) GetTasksByListID(ctx context.Context, listID string) ([]server.Task, error) {
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE list_id = ? AND deleted_at IS NULL ORDER BY position ASC, created_at DESC, rowid DESC`
+	return d.queryTasks(ctx, query, listID)
+}
+
+func (d *DB) GetAllTasks(ctx context.Context) ([]server.Task, error) {
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE deleted_at IS NULL ORDER BY created_at DESC, rowid DESC`
+	return d.queryTasks(ctx, query)
+}
+
+func (d *DB) UpdateTask(ctx context.Context, id, taskName string, completed bool, version int64) (*server.Task, error) {
+	query := `UPDATE tasks SET task_name = ?1, completed = ?2, version = version + 1, updated_at = ?3 WHERE id = ?4 AND deleted_at IS NULL AND (?5 = 0 OR version = ?5) RETURNING ` + taskColumns
+
+	task, err := scanTask(d.SQL.QueryRowContext(ctx, query, taskName, completed, now(), id, version))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, d.taskConflict(ctx, id, version)
+		}
+		return nil, fmt.Errorf("failed to update task: %w", mapError(err))
+	}
+
+	return task, nil
+}
+
+func (d *DB) ToggleTaskCompletion(ctx context.Context, id string) (*server.Task, error) {
+	tx, err := d.SQL.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", mapError(err))
+	}
+	defer tx.Rollback()
+
+	task, err := getTask(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if task.Completed {
+		query := `UPDATE tasks SET completed = FALSE, version = version + 1, updated_at = ? WHERE id = ? AND deleted_at IS NULL RETURNING ` + taskColumns
+		task, err = scanTask(tx.QueryRowContext(ctx, query, now(), id))
+		if err != nil {
+			return nil, fmt.Errorf("failed to toggle task completion: %w", mapError(err))
+		}
+	} else {
+		completion, err := completeTask(ctx, tx, task)
+		if err != nil {
+			return nil, err
+		}
+		task = &completion.Task
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", mapError(err))
+	}
+
+	return task, nil
+}
+
+// DeleteTask moves a task to the trash together with its subtasks
+func (d *DB) DeleteTask(ctx context.Context, id string, version int64) error {
+	tx, err := d.SQL.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", mapError(err))
+	}
+	defer tx.Rollback()
+
+	deletedAt := now()
+	query := `UPDATE tasks SET deleted_at = ?1, version = version + 1 WHERE id = ?2 AND deleted_at IS NULL AND (?3 = 0 OR version = ?3)`
+	result, err := tx.ExecContext(ctx, query, deletedAt, id, version)
+	if err != nil {
+		return fmt.Errorf("failed to delete task: %w", mapError(err))
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		// Release the only connection before looking the task up again
+		tx.Rollback()
+		return d.taskConflict(ctx, id, version)
+	}
+
+	query = `UPDATE subtasks SET deleted_at = ?1, version = version + 1 WHERE task_id = ?2 AND deleted_at IS NULL`
+	if _, err := tx.ExecContext(ctx, query, deletedAt, id); err != nil {
+		return fmt.Errorf("failed to delete subtasks: %w", mapError(err))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", mapError(err))
+	}
+
+	return nil
+}
+
+// ReorderTasks updates the positions of a list's tasks based on the provided order
+func (d *DB) ReorderTasks(ctx context.Context, listID string, taskIDs []string) error {
+	if len(taskIDs) == 0 {
+		return nil
+	}
+
+	// Start a transaction to ensure atomicity
+	tx, err := d.SQL.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", mapError(err))
+	}
+	defer tx.Rollback()
+
+	// Update positions for each task; ids from other lists are ignored
+	ts := now()
+	for i, taskID := range taskIDs {
+		query := `UPDATE tasks SET position = ?, version = version + 1, updated_at = ? WHERE id = ? AND deleted_at IS NULL AND list_id = ?`
+		_, err := tx.ExecContext(ctx, query, i+1, ts, taskID, listID)
+		if err != nil {
+			return fmt.Errorf("failed to update task position: %w", mapError(err))
+		}
+	}
+
+	// Commit the transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", mapError(err))
+	}
+
+	return nil
+}
@@ -0,0 +1,217 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+// Rows trashed along with their parent share its deleted_at, which is how they
+// are told apart from rows deleted directly and found again on restore.
+
+func (d *DB) GetTrash(ctx context.Context) (*server.Trash, error) {
+	trash := &server.Trash{}
+
+	rows, err := d.SQL.QueryContext(ctx, `SELECT `+listColumns+` FROM lists WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC, rowid DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trashed lists: %w", mapError(err))
+	}
+	defer rows.Close()
+	for rows.Next() {
+		list, err := scanList(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan list: %w", mapError(err))
+		}
+		trash.Lists = append(trash.Lists, *list)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get trashed lists: %w", mapError(err))
+	}
+	rows.Close()
+
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE deleted_at IS NOT NULL
+		AND NOT EXISTS (SELECT 1 FROM lists WHERE lists.id = tasks.list_id AND lists.deleted_at = tasks.deleted_at)
+		ORDER BY deleted_at DESC, rowid DESC`
+	trash.Tasks, err = d.queryTasks(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	query = `SELECT ` + subTaskColumns + ` FROM subtasks WHERE deleted_at IS NOT NULL
+		AND NOT EXISTS (SELECT 1 FROM tasks WHERE tasks.id = subtasks.task_id AND tasks.deleted_at = subtasks.deleted_at)
+		ORDER BY deleted_at DESC, rowid DESC`
+	trash.SubTasks, err = d.querySubTasks(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return trash, nil
+}
+
+func (d *DB) RestoreList(ctx context.Context, id string) (*server.List, error) {
+	tx, err := d.SQL.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", mapError(err))
+	}
+	defer tx.Rollback()
+
+	ts := now()
+	if err := requireRow(ctx, tx, `SELECT EXISTS (SELECT 1 FROM lists WHERE id = ? AND deleted_at IS NOT NULL)`, id); err != nil {
+		return nil, listNotFound(id, err)
+	}
+	if err := restoreListChildren(ctx, tx, id, ts); err != nil {
+		return nil, err
+	}
+
+	query := `UPDATE lists SET deleted_at = NULL, version = version + 1, updated_at = ? WHERE id = ? RETURNING ` + listColumns
+	list, err := scanList(tx.QueryRowContext(ctx, query, ts, id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore list: %w", mapError(err))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", mapError(err))
+	}
+
+	return list, nil
+}
+
+func (d *DB) RestoreTask(ctx context.Context, id string) (*server.Task, error) {
+	tx, err := d.SQL.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", mapError(err))
+	}
+	defer tx.Rollback()
+
+	ts := now()
+	if err := requireRow(ctx, tx, `SELECT EXISTS (SELECT 1 FROM tasks WHERE id = ? AND deleted_at IS NOT NULL)`, id); err != nil {
+		return nil, taskNotFound(id, err)
+	}
+	query := `UPDATE lists SET deleted_at = NULL, version = version + 1, updated_at = ?
+		WHERE id = (SELECT list_id FROM tasks WHERE id = ?) AND deleted_at IS NOT NULL`
+	if _, err := tx.ExecContext(ctx, query, ts, id); err != nil {
+		return nil, fmt.Errorf("failed to restore list: %w", mapError(err))
+	}
+	if err := restoreTaskChildren(ctx, tx, id, ts); err != nil {
+		return nil, err
+	}
+
+	query = `UPDATE tasks SET deleted_at = NULL, version = version + 1, updated_at = ? WHERE id = ? RETURNING ` + taskColumns
+	task, err := scanTask(tx.QueryRowContext(ctx, query, ts, id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore task: %w", mapError(err))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", mapError(err))
+	}
+
+	return task, nil
+}
+
+func (d *DB) RestoreSubTask(ctx context.Context, id string) (*server.SubTask, error) {
+	tx, err := d.SQL.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", mapError(err))
+	}
+	defer tx.Rollback()
+
+	ts := now()
+	err = requireRow(ctx, tx, `SELECT EXISTS (SELECT 1 FROM subtasks WHERE id = ? AND deleted_at IS NOT NULL)`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, server.NotFound("subtask", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subtask: %w", mapError(err))
+	}
+	query := `UPDATE lists SET deleted_at = NULL, version = version + 1, updated_at = ?
+		WHERE id = (SELECT list_id FROM tasks WHERE id = (SELECT task_id FROM subtasks WHERE id = ?)) AND deleted_at IS NOT NULL`
+	if _, err := tx.ExecContext(ctx, query, ts, id); err != nil {
+		return nil, fmt.Errorf("failed to restore list: %w", mapError(err))
+	}
+	query = `UPDATE tasks SET deleted_at = NULL, version = version + 1, updated_at = ?
+		WHERE id = (SELECT task_id FROM subtasks WHERE id = ?) AND deleted_at IS NOT NULL`
+	if _, err := tx.ExecContext(ctx, query, ts, id); err != nil {
+		return nil, fmt.Errorf("failed to restore task: %w", mapError(err))
+	}
+
+	query = `UPDATE subtasks SET deleted_at = NULL, version = version + 1, updated_at = ? WHERE id = ? RETURNING ` + subTaskColumns
+	subTask, err := scanSubTask(tx.QueryRowContext(ctx, query, ts, id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore subtask: %w", mapError(err))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", mapError(err))
+	}
+
+	return subTask, nil
+}
+
+func (d *DB) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	tx, err := d.SQL.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", mapError(err))
+	}
+	defer tx.Rollback()
+
+	query := `DELETE FROM activity WHERE entity_id IN (
+		SELECT id FROM subtasks WHERE deleted_at < ?1
+		UNION ALL SELECT id FROM tasks WHERE deleted_at < ?1
+		UNION ALL SELECT id FROM lists WHERE deleted_at < ?1)`
+	if _, err := tx.ExecContext(ctx, query, before.UTC()); err != nil {
+		return 0, fmt.Errorf("failed to purge activity: %w", mapError(err))
+	}
+
+	// Children first so that every removed row is counted
+	var purged int64
+	for _, table := range []string{"subtasks", "tasks", "lists"} {
+		result, err := tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE deleted_at < ?`, before.UTC())
+		if err != nil {
+			return 0, fmt.Errorf("failed to purge %s: %w", table, mapError(err))
+		}
+		n, _ := result.RowsAffected()
+		purged += n
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", mapError(err))
+	}
+
+	return purged, nil
+}
+
+// restoreListChildren restores the tasks, and their subtasks, trashed together
+// with a list that is still in the trash
+func restoreListChildren(ctx context.Context, tx *sql.Tx, listID string, ts time.Time) error {
+	query := `UPDATE subtasks SET deleted_at = NULL, version = version + 1, updated_at = ?1
+		WHERE deleted_at = (SELECT deleted_at FROM lists WHERE id = ?2)
+		AND task_id IN (SELECT id FROM tasks WHERE list_id = ?2 AND deleted_at = subtasks.deleted_at)`
+	if _, err := tx.ExecContext(ctx, query, ts, listID); err != nil {
+		return fmt.Errorf("failed to restore subtasks: %w", mapError(err))
+	}
+
+	query = `UPDATE tasks SET deleted_at = NULL, version = version + 1, updated_at = ?1
+		WHERE list_id = ?2 AND deleted_at = (SELECT deleted_at FROM lists WHERE id = ?2)`
+	if _, err := tx.ExecContext(ctx, query, ts, listID); err != nil {
+		return fmt.Errorf("failed to restore tasks: %w", mapError(err))
+	}
+
+	return nil
+}
+
+// restoreTaskChildren restores the subtasks trashed together with a task that
+// is still in the trash
+func restoreTaskChildren(ctx context.Context, tx *sql.Tx, taskID string, ts time.Time) error {
+	query := `UPDATE subtasks SET deleted_at = NULL, version = version + 1, updated_at = ?1
+		WHERE task_id = ?2 AND deleted_at = (SELECT deleted_at FROM tasks WHERE id = ?2)`
+	if _, err := tx.ExecContext(ctx, query, ts, taskID); err != nil {
+		return fmt.Errorf("failed to restore subtasks: %w", mapError(err))
+	}
+
+	return nil
+}
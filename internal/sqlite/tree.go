@@ -0,0 +1,53 @@
+package sqlite
+
+import (
+	"context"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+func (d *DB) GetListTree(ctx context.Context, listID string) (*server.ListTree, error) {
+	list, err := d.GetList(ctx, listID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE list_id = ? AND deleted_at IS NULL ORDER BY position ASC, created_at DESC, rowid DESC`
+	tasks, err := d.queryTasks(ctx, query, listID)
+	if err != nil {
+		return nil, err
+	}
+
+	query = `SELECT ` + subTaskColumns + ` FROM subtasks
+		WHERE task_id IN (SELECT id FROM tasks WHERE list_id = ?) AND deleted_at IS NULL
+		ORDER BY position ASC, created_at DESC, rowid DESC`
+	subTasks, err := d.querySubTasks(ctx, query, listID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &server.BuildTrees([]server.List{*list}, tasks, subTasks)[0], nil
+}
+
+func (d *DB) GetWorkspaceTree(ctx context.Context) ([]server.ListTree, error) {
+	lists, err := d.GetAllLists(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Tasks and subtasks are trashed with their list, so the live ones all
+	// belong to live lists
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE deleted_at IS NULL ORDER BY position ASC, created_at DESC, rowid DESC`
+	tasks, err := d.queryTasks(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	query = `SELECT ` + subTaskColumns + ` FROM subtasks WHERE deleted_at IS NULL ORDER BY position ASC, created_at DESC, rowid DESC`
+	subTasks, err := d.querySubTasks(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return server.BuildTrees(lists, tasks, subTasks), nil
+}
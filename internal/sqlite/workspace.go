@@ -0,0 +1,209 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	server "github.com/HolySxn/To-Do/internal"
+	"github.com/HolySxn/To-Do/internal/query"
+)
+
+func (d *DB) ExportWorkspace(ctx context.Context) (*server.Workspace, error) {
+	lists, err := d.GetAllLists(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE deleted_at IS NULL ORDER BY position ASC, created_at DESC, rowid DESC`
+	tasks, err := d.queryTasks(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	query = `SELECT ` + subTaskColumns + ` FROM subtasks WHERE deleted_at IS NULL ORDER BY position ASC, created_at DESC, rowid DESC`
+	subTasks, err := d.querySubTasks(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := d.GetAllTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := d.SQL.QueryContext(ctx, `SELECT task_id, tag_id FROM task_tags`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task tags: %w", mapError(err))
+	}
+	defer rows.Close()
+	var taskTags []server.TaskTag
+	for rows.Next() {
+		var taskTag server.TaskTag
+		if err := rows.Scan(&taskTag.TaskID, &taskTag.TagID); err != nil {
+			return nil, fmt.Errorf("failed to scan task tag: %w", mapError(err))
+		}
+		taskTags = append(taskTags, taskTag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get task tags: %w", mapError(err))
+	}
+
+	return server.BuildWorkspace(lists, tasks, subTasks, tags, taskTags, now()), nil
+}
+
+func (d *DB) ImportWorkspace(ctx context.Context, doc *server.Workspace, mode server.ImportMode, dryRun bool) (*server.ImportReport, error) {
+	tx, err := d.SQL.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", mapError(err))
+	}
+	defer tx.Rollback()
+
+	state, err := importState(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := server.PlanImport(doc, mode, *state, now())
+	if err != nil {
+		return nil, err
+	}
+	if dryRun {
+		plan.Report.DryRun = true
+		return &plan.Report, nil
+	}
+
+	if mode == server.ImportReplace {
+		// Children first, so that every foreign key still holds
+		for _, table := range []string{"activity", "task_tags", "subtasks", "tasks", "lists", "tags"} {
+			if _, err := tx.ExecContext(ctx, `DELETE FROM `+table); err != nil {
+				return nil, fmt.Errorf("failed to clear %s: %w", table, mapError(err))
+			}
+		}
+	}
+	if err := insertPlan(ctx, tx, plan); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", mapError(err))
+	}
+
+	return &plan.Report, nil
+}
+
+// importState reads what planning an import needs to know
+func importState(ctx context.Context, tx *sql.Tx) (*server.ImportState, error) {
+	state := &server.ImportState{CheckQuery: checkQuery}
+	var err error
+	if state.Lists, err = collectRows(ctx, tx, `SELECT `+listColumns+` FROM lists`, scanList); err != nil {
+		return nil, fmt.Errorf("failed to get lists: %w", mapError(err))
+	}
+	if state.Tasks, err = collectRows(ctx, tx, `SELECT `+taskColumns+` FROM tasks`, scanTask); err != nil {
+		return nil, fmt.Errorf("failed to get tasks: %w", mapError(err))
+	}
+	if state.SubTasks, err = collectRows(ctx, tx, `SELECT `+subTaskColumns+` FROM subtasks`, scanSubTask); err != nil {
+		return nil, fmt.Errorf("failed to get subtasks: %w", mapError(err))
+	}
+	if state.Tags, err = collectRows(ctx, tx, `SELECT `+tagColumns+` FROM tags`, scanTag); err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", mapError(err))
+	}
+	state.TaskTags, err = collectRows(ctx, tx, `SELECT task_id, tag_id FROM task_tags`, func(row scanner) (*server.TaskTag, error) {
+		var taskTag server.TaskTag
+		err := row.Scan(&taskTag.TaskID, &taskTag.TagID)
+		return &taskTag, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task tags: %w", mapError(err))
+	}
+	return state, nil
+}
+
+// checkQuery is the ImportState.CheckQuery of the store
+func checkQuery(q string) error {
+	_, err := query.Parse(q, time.Now())
+	return err
+}
+
+// collectRows runs a query in tx and scans every row it returns
+func collectRows[T any](ctx context.Context, tx *sql.Tx, query string, scan func(scanner) (*T, error)) ([]T, error) {
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var result []T
+	for rows.Next() {
+		row, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *row)
+	}
+	return result, rows.Err()
+}
+
+// insertPlan writes the rows of an import plan. Every insert of a kind comes
+// before the updates of the kind, and parents before their children.
+func insertPlan(ctx context.Context, tx *sql.Tx, plan *server.ImportPlan) error {
+	for _, list := range plan.Lists {
+		query := `INSERT INTO lists (id, title, query, position, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)`
+		if _, err := tx.ExecContext(ctx, query, list.ID, list.Title, list.Query, list.Position, list.CreatedAt, list.UpdatedAt); err != nil {
+			return fmt.Errorf("failed to import list: %w", mapError(err))
+		}
+	}
+	for _, list := range plan.UpdatedLists {
+		query := `UPDATE lists SET title = ?, query = ?, version = ?, updated_at = ? WHERE id = ?`
+		if _, err := tx.ExecContext(ctx, query, list.Title, list.Query, list.Version, list.UpdatedAt, list.ID); err != nil {
+			return fmt.Errorf("failed to update list: %w", mapError(err))
+		}
+	}
+
+	for _, task := range plan.Tasks {
+		query := `INSERT INTO tasks (id, list_id, task_name, notes, completed, priority, starred, position, due_date, due_at, remind_at, recurrence, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+		_, err := tx.ExecContext(ctx, query, task.ID, task.ListID, task.TaskName, task.Notes, task.Completed, string(task.Priority), task.Starred,
+			task.Position, task.DueDate, utc(task.DueAt), utc(task.RemindAt), task.Recurrence, task.CreatedAt, task.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to import task: %w", mapError(err))
+		}
+	}
+	for _, task := range plan.UpdatedTasks {
+		query := `UPDATE tasks SET task_name = ?, notes = ?, completed = ?, priority = ?, starred = ?, due_date = ?, due_at = ?, remind_at = ?,
+			recurrence = ?, version = ?, updated_at = ? WHERE id = ?`
+		_, err := tx.ExecContext(ctx, query, task.TaskName, task.Notes, task.Completed, string(task.Priority), task.Starred, task.DueDate,
+			utc(task.DueAt), utc(task.RemindAt), task.Recurrence, task.Version, task.UpdatedAt, task.ID)
+		if err != nil {
+			return fmt.Errorf("failed to update task: %w", mapError(err))
+		}
+	}
+
+	for _, subTask := range plan.SubTasks {
+		query := `INSERT INTO subtasks (id, task_id, subtask_name, completed, position, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)`
+		_, err := tx.ExecContext(ctx, query, subTask.ID, subTask.TaskID, subTask.SubTaskName, subTask.Completed, subTask.Position, subTask.CreatedAt, subTask.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to import subtask: %w", mapError(err))
+		}
+	}
+	for _, subTask := range plan.UpdatedSubTasks {
+		query := `UPDATE subtasks SET subtask_name = ?, completed = ?, version = ?, updated_at = ? WHERE id = ?`
+		if _, err := tx.ExecContext(ctx, query, subTask.SubTaskName, subTask.Completed, subTask.Version, subTask.UpdatedAt, subTask.ID); err != nil {
+			return fmt.Errorf("failed to update subtask: %w", mapError(err))
+		}
+	}
+
+	for _, tag := range plan.Tags {
+		query := `INSERT INTO tags (id, name, color, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`
+		if _, err := tx.ExecContext(ctx, query, tag.ID, tag.Name, tag.Color, tag.CreatedAt, tag.UpdatedAt); err != nil {
+			return fmt.Errorf("failed to import tag: %w", mapError(err))
+		}
+	}
+
+	for _, taskTag := range plan.TaskTags {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO task_tags (task_id, tag_id) VALUES (?, ?)`, taskTag.TaskID, taskTag.TagID); err != nil {
+			return fmt.Errorf("failed to import task tag: %w", mapError(err))
+		}
+	}
+
+	return nil
+}
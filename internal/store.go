@@ -0,0 +1,226 @@
+package server
+
+import (
+	"context"
+	"time"
+)
+
+// ListStore persists lists
+type ListStore interface {
+	CreateList(ctx context.Context, title string) (*List, error)
+	GetList(ctx context.Context, id string) (*List, error)
+	GetAllLists(ctx context.Context) ([]List, error)
+	UpdateList(ctx context.Context, id string, title string, version int64) (*List, error)
+	// PatchList updates only the fields set in patch and returns the updated list
+	PatchList(ctx context.Context, id string, patch ListPatch) (*List, error)
+	DeleteList(ctx context.Context, id string, version int64) error
+	ReorderLists(ctx context.Context, listIDs []string) error
+}
+
+// TaskStore persists tasks
+type TaskStore interface {
+	CreateTask(ctx context.Context, listID, taskName string) (*Task, error)
+	GetTask(ctx context.Context, id string) (*Task, error)
+	GetTasksByListID(ctx context.Context, listID string) ([]Task, error)
+	GetAllTasks(ctx context.Context) ([]Task, error)
+	UpdateTask(ctx context.Context, id, taskName string, completed bool, version int64) (*Task, error)
+	// PatchTask updates only the fields set in patch and returns the updated task
+	PatchTask(ctx context.Context, id string, patch TaskPatch) (*Task, error)
+	// ToggleTaskCompletion flips a task's completion; completing a recurring task
+	// behaves like CompleteTask
+	ToggleTaskCompletion(ctx context.Context, id string) (*Task, error)
+	// CompleteTask marks a task completed and, if it recurs, atomically creates
+	// the next occurrence (see NextOccurrence) with copies of its subtasks reset
+	// to incomplete and of its tags. Completing an already completed task changes nothing.
+	CompleteTask(ctx context.Context, id string) (*TaskCompletion, error)
+	// SetTaskRecurrence sets or, with nil, clears the recurrence rule; the rule is
+	// validated and stored in canonical form
+	SetTaskRecurrence(ctx context.Context, id string, rule *string) (*Task, error)
+	DeleteTask(ctx context.Context, id string, version int64) error
+	ReorderTasks(ctx context.Context, listID string, taskIDs []string) error
+	// MoveTask moves a task to targetListID at the given 1-based position;
+	// a position outside the list appends it
+	MoveTask(ctx context.Context, taskID, targetListID string, position int) (*Task, error)
+	// DemoteTaskToSubTask turns a task without subtasks into the last subtask
+	// of targetTaskID, keeping its id, name, completion and timestamps
+	DemoteTaskToSubTask(ctx context.Context, taskID, targetTaskID string) (*SubTask, error)
+
+	SetTaskNotes(ctx context.Context, id, notes string) (*Task, error)
+	// SetTaskPriority rejects priorities that are not Valid
+	SetTaskPriority(ctx context.Context, id string, priority Priority) (*Task, error)
+	StarTask(ctx context.Context, id string, starred bool) (*Task, error)
+	// GetStarredTasks returns starred tasks across all lists, newest first
+	GetStarredTasks(ctx context.Context) ([]Task, error)
+
+	// SetTaskDue sets either a whole-day due date (YYYY-MM-DD) or a due moment;
+	// passing nil for both clears the due date
+	SetTaskDue(ctx context.Context, id string, dueDate *string, dueAt *time.Time) (*Task, error)
+	// SetTaskReminder sets or, with nil, clears the reminder time
+	SetTaskReminder(ctx context.Context, id string, remindAt *time.Time) (*Task, error)
+	// GetTasksDueBetween returns tasks due in [from, to), including whole-day
+	// tasks whose date overlaps the range in from's location, ordered by due time
+	GetTasksDueBetween(ctx context.Context, from, to time.Time) ([]Task, error)
+	// GetOverdueTasks returns incomplete tasks due before now, ordered by due time.
+	// Whole-day tasks are overdue once their date is before now's date.
+	GetOverdueTasks(ctx context.Context, now time.Time) ([]Task, error)
+	// GetDueReminders returns incomplete tasks whose reminder time is at or before now
+	GetDueReminders(ctx context.Context, now time.Time) ([]Task, error)
+}
+
+// SubTaskStore persists subtasks
+type SubTaskStore interface {
+	CreateSubTask(ctx context.Context, taskID string, subTaskName string) (*SubTask, error)
+	GetSubTask(ctx context.Context, id string) (*SubTask, error)
+	GetSubTasksByTaskID(ctx context.Context, taskID string) ([]SubTask, error)
+	GetAllSubTasks(ctx context.Context) ([]SubTask, error)
+	UpdateSubTask(ctx context.Context, id, subTaskName string, completed bool, version int64) (*SubTask, error)
+	// PatchSubTask updates only the fields set in patch and returns the updated subtask
+	PatchSubTask(ctx context.Context, id string, patch SubTaskPatch) (*SubTask, error)
+	ToggleSubTaskCompletion(ctx context.Context, id string) (*SubTask, error)
+	DeleteSubTask(ctx context.Context, id string, version int64) error
+	ReorderSubTasks(ctx context.Context, taskID string, subTaskIDs []string) error
+	// MoveSubTask moves a subtask to targetTaskID at the given 1-based position;
+	// a position outside the task appends it
+	MoveSubTask(ctx context.Context, subTaskID, targetTaskID string, position int) (*SubTask, error)
+	// PromoteSubTaskToTask turns a subtask into a task placed right after its
+	// former parent, keeping its id, name, completion and timestamps
+	PromoteSubTaskToTask(ctx context.Context, subTaskID string) (*Task, error)
+}
+
+// TrashStore keeps deleted rows until they are restored or purged
+type TrashStore interface {
+	// GetTrash returns the rows deleted directly, most recently deleted first
+	GetTrash(ctx context.Context) (*Trash, error)
+	// RestoreList, RestoreTask and RestoreSubTask bring a row back together with
+	// the children deleted along with it. Restoring a task or subtask whose
+	// list or task is in the trash restores that parent row as well.
+	RestoreList(ctx context.Context, id string) (*List, error)
+	RestoreTask(ctx context.Context, id string) (*Task, error)
+	RestoreSubTask(ctx context.Context, id string) (*SubTask, error)
+	// PurgeTrash permanently removes rows deleted before the given time and
+	// returns how many were removed
+	PurgeTrash(ctx context.Context, before time.Time) (int64, error)
+}
+
+// TagStore persists tags and their assignment to tasks. Creating or renaming a
+// tag to a name that is taken fails with ErrConflict. Tags stay on tasks in the
+// trash and are copied to the next occurrence of a recurring task.
+type TagStore interface {
+	CreateTag(ctx context.Context, name, color string) (*Tag, error)
+	GetTag(ctx context.Context, id string) (*Tag, error)
+	// GetAllTags returns every tag ordered by name
+	GetAllTags(ctx context.Context) ([]Tag, error)
+	RenameTag(ctx context.Context, id, name string) (*Tag, error)
+	SetTagColor(ctx context.Context, id, color string) (*Tag, error)
+	// DeleteTag removes a tag and takes it off every task; it does not go to the trash
+	DeleteTag(ctx context.Context, id string) error
+	// MergeTags puts targetID on every task tagged with sourceID, deletes
+	// sourceID and returns the target tag
+	MergeTags(ctx context.Context, sourceID, targetID string) (*Tag, error)
+	// AddTagToTask and RemoveTagFromTask do nothing when the task already has,
+	// or does not have, the tag
+	AddTagToTask(ctx context.Context, taskID, tagID string) error
+	RemoveTagFromTask(ctx context.Context, taskID, tagID string) error
+	// GetTaskTags returns a task's tags ordered by name
+	GetTaskTags(ctx context.Context, taskID string) ([]Tag, error)
+	// GetTasksByTags returns the tasks across all lists that have every one of
+	// tagIDs when matchAll is set, or any of them otherwise, newest first
+	GetTasksByTags(ctx context.Context, tagIDs []string, matchAll bool) ([]Task, error)
+}
+
+// SmartListStore keeps smart lists, which are lists that show the tasks
+// matching a query (see package query) rather than tasks of their own. They are
+// listed, ordered, patched and deleted like other lists; creating or moving a
+// task into one fails with ErrInvalidInput, as does setting the query of a
+// list that is not smart or setting a query that does not parse.
+type SmartListStore interface {
+	// CreateSmartList adds a smart list after the existing lists
+	CreateSmartList(ctx context.Context, title, query string) (*List, error)
+	// GetSmartListTasks runs the query of a smart list, resolving relative
+	// dates against now
+	GetSmartListTasks(ctx context.Context, listID string, now time.Time) ([]Task, error)
+	// QueryTasks returns the tasks across all lists that match a query, in the
+	// query's sort order
+	QueryTasks(ctx context.Context, query string, now time.Time) ([]Task, error)
+}
+
+// PageStore reads tasks and subtasks a page at a time. A page holds up to size
+// rows, which must be positive. An empty cursor reads the first page and the
+// NextCursor of a page the one after it; a malformed cursor fails with
+// ErrInvalidInput. Rows are ordered by a key ending in the id, so paging never
+// repeats or skips rows that are not changed in the meantime.
+type PageStore interface {
+	// GetTasksPageByListID pages through a list's tasks by position, newest
+	// first among tasks with the same position
+	GetTasksPageByListID(ctx context.Context, listID string, size int, cursor string) (*TaskPage, error)
+	// GetAllTasksPage pages through the tasks of every list, newest first
+	GetAllTasksPage(ctx context.Context, size int, cursor string) (*TaskPage, error)
+	// GetAllSubTasksPage pages through the subtasks of every task, newest first
+	GetAllSubTasksPage(ctx context.Context, size int, cursor string) (*SubTaskPage, error)
+}
+
+// TreeStore reads lists together with their tasks and subtasks in a single
+// call, so showing a list does not take a query per task
+type TreeStore interface {
+	GetListTree(ctx context.Context, listID string) (*ListTree, error)
+	// GetWorkspaceTree returns the tree of every list, ordered by position
+	GetWorkspaceTree(ctx context.Context) ([]ListTree, error)
+}
+
+// WorkspaceStore backs up and restores the whole workspace as a Workspace
+// document
+type WorkspaceStore interface {
+	// ExportWorkspace returns every list, task, subtask and tag outside the trash
+	ExportWorkspace(ctx context.Context) (*Workspace, error)
+	// ImportWorkspace writes doc, planned with PlanImport, in a single
+	// transaction. With dryRun nothing is written and the report tells what
+	// the import would change.
+	ImportWorkspace(ctx context.Context, doc *Workspace, mode ImportMode, dryRun bool) (*ImportReport, error)
+}
+
+// SearchStore finds lists, tasks and subtasks by name
+type SearchStore interface {
+	// Search returns the rows whose names contain a word starting with every
+	// term of the query, best match first; see SearchTerms and MatchName.
+	// A query without terms has no hits.
+	Search(ctx context.Context, query string) ([]SearchHit, error)
+}
+
+// ActivityStore reads the log of changes, which is written in the same
+// transaction as every change to a list, task or subtask. Activity of purged
+// rows is removed with them.
+type ActivityStore interface {
+	// GetTaskActivity returns the changes to a task and its subtasks, newest first
+	GetTaskActivity(ctx context.Context, taskID string) ([]Activity, error)
+	// GetListActivity returns the changes to a list and everything in it made at
+	// or after since, newest first. Tasks moved out of the list are included.
+	GetListActivity(ctx context.Context, listID string, since time.Time) ([]Activity, error)
+	// SetActor names who makes the changes logged from now on through this
+	// store. It is a setting of the session, not of the data: other stores
+	// connected to the same database keep their own. "" clears it.
+	SetActor(ctx context.Context, actor string) error
+}
+
+// Store is the storage backend used by the application.
+// Deleting a row moves it to the trash: deleting a list also trashes its tasks,
+// and deleting a task its subtasks. Trashed rows are left out of every other
+// call as if they did not exist.
+// Tasks and subtasks are returned by position; new ones are placed first.
+//
+// Update, Patch and Delete calls take the version the caller last saw; when it
+// no longer matches they fail with a *ConflictError holding the current row.
+// A version of 0 skips the check.
+type Store interface {
+	ListStore
+	TaskStore
+	SubTaskStore
+	PageStore
+	TrashStore
+	TagStore
+	SmartListStore
+	TreeStore
+	WorkspaceStore
+	SearchStore
+	ActivityStore
+	Close()
+}
@@ -0,0 +1,2609 @@
+// Package storetest provides a behavioral conformance suite for server.Store
+// implementations. Backends run it from their own tests:
+//
+//	func TestStore(t *testing.T) {
+//		storetest.Run(t, func(t *testing.T) server.Store { return memory.New() })
+//	}
+package storetest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+// unknownID is a well-formed id that no store will ever hand out
+const unknownID = "00000000-0000-0000-0000-000000000000"
+
+// Factory returns an empty store. It is called once per subtest.
+type Factory func(t *testing.T) server.Store
+
+// Run exercises the behavior every server.Store must provide
+func Run(t *testing.T, newStore Factory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, s server.Store)
+	}{
+		{"Lists", testLists},
+		{"ReorderLists", testReorderLists},
+		{"Tasks", testTasks},
+		{"ReorderTasks", testReorderTasks},
+		{"SubTasks", testSubTasks},
+		{"ReorderSubTasks", testReorderSubTasks},
+		{"Pages", testPages},
+		{"MoveTask", testMoveTask},
+		{"MoveSubTask", testMoveSubTask},
+		{"PromoteAndDemote", testPromoteAndDemote},
+		{"Patch", testPatch},
+		{"Versions", testVersions},
+		{"TaskDetails", testTaskDetails},
+		{"StarredTasks", testStarredTasks},
+		{"DueDates", testDueDates},
+		{"OverdueTasks", testOverdueTasks},
+		{"Reminders", testReminders},
+		{"Recurrence", testRecurrence},
+		{"RecurrenceLimits", testRecurrenceLimits},
+		{"CascadingDeletes", testCascadingDeletes},
+		{"Trash", testTrash},
+		{"Tags", testTags},
+		{"SmartLists", testSmartLists},
+		{"QueryCaseFolding", testQueryCaseFolding},
+		{"Trees", testTrees},
+		{"Workspace", testWorkspace},
+		{"WorkspaceImportErrors", testWorkspaceImportErrors},
+		{"WorkspaceAppend", testWorkspaceAppend},
+		{"Search", testSearch},
+		{"Activity", testActivity},
+		{"ActivityActor", testActivityActor},
+		{"NotFound", testNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStore(t)
+			t.Cleanup(s.Close)
+			tt.fn(t, s)
+		})
+	}
+}
+
+func testLists(t *testing.T, s server.Store) {
+	ctx := context.Background()
+
+	first := mustCreateList(t, s, "Inbox")
+	second := mustCreateList(t, s, "Work")
+	if first.ID == "" || first.ID == second.ID {
+		t.Fatalf("CreateList returned ids %q and %q, want distinct non-empty ids", first.ID, second.ID)
+	}
+	if first.Title != "Inbox" {
+		t.Errorf("CreateList title = %q, want %q", first.Title, "Inbox")
+	}
+	if second.Position <= first.Position {
+		t.Errorf("new list position = %d, want greater than %d", second.Position, first.Position)
+	}
+	if first.CreatedAt.IsZero() || first.UpdatedAt.IsZero() {
+		t.Errorf("CreateList did not set timestamps: %+v", first)
+	}
+
+	got, err := s.GetList(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("GetList: %v", err)
+	}
+	if got.ID != first.ID || got.Title != first.Title || got.Position != first.Position {
+		t.Errorf("GetList = %+v, want %+v", got, first)
+	}
+
+	updated, err := s.UpdateList(ctx, first.ID, "Personal", first.Version)
+	if err != nil {
+		t.Fatalf("UpdateList: %v", err)
+	}
+	if updated.Title != "Personal" || updated.Position != first.Position {
+		t.Errorf("UpdateList = %+v, want title %q at position %d", updated, "Personal", first.Position)
+	}
+
+	lists, err := s.GetAllLists(ctx)
+	if err != nil {
+		t.Fatalf("GetAllLists: %v", err)
+	}
+	assertListOrder(t, lists, first.ID, second.ID)
+
+	if err := s.DeleteList(ctx, first.ID, 0); err != nil {
+		t.Fatalf("DeleteList: %v", err)
+	}
+	lists, err = s.GetAllLists(ctx)
+	if err != nil {
+		t.Fatalf("GetAllLists: %v", err)
+	}
+	assertListOrder(t, lists, second.ID)
+}
+
+func testReorderLists(t *testing.T, s server.Store) {
+	ctx := context.Background()
+
+	a := mustCreateList(t, s, "A")
+	b := mustCreateList(t, s, "B")
+	c := mustCreateList(t, s, "C")
+
+	if err := s.ReorderLists(ctx, nil); err != nil {
+		t.Fatalf("ReorderLists(nil): %v", err)
+	}
+	if err := s.ReorderLists(ctx, []string{c.ID, a.ID, b.ID}); err != nil {
+		t.Fatalf("ReorderLists: %v", err)
+	}
+
+	lists, err := s.GetAllLists(ctx)
+	if err != nil {
+		t.Fatalf("GetAllLists: %v", err)
+	}
+	assertListOrder(t, lists, c.ID, a.ID, b.ID)
+	for i, list := range lists {
+		if list.Position != i+1 {
+			t.Errorf("list %q position = %d, want %d", list.Title, list.Position, i+1)
+		}
+	}
+
+	// New lists are appended after the reordered ones
+	d := mustCreateList(t, s, "D")
+	if d.Position != len(lists)+1 {
+		t.Errorf("new list position = %d, want %d", d.Position, len(lists)+1)
+	}
+}
+
+func testTasks(t *testing.T, s server.Store) {
+	ctx := context.Background()
+
+	list := mustCreateList(t, s, "Inbox")
+	other := mustCreateList(t, s, "Other")
+
+	first := mustCreateTask(t, s, list.ID, "Buy milk")
+	if first.ListID != list.ID || first.TaskName != "Buy milk" || first.Completed {
+		t.Errorf("CreateTask = %+v", first)
+	}
+	second := mustCreateTask(t, s, list.ID, "Walk dog")
+	elsewhere := mustCreateTask(t, s, other.ID, "Elsewhere")
+
+	got, err := s.GetTask(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("GetTask: %v", err)
+	}
+	if got.ID != first.ID || got.TaskName != first.TaskName {
+		t.Errorf("GetTask = %+v, want %+v", got, first)
+	}
+
+	tasks, err := s.GetTasksByListID(ctx, list.ID)
+	if err != nil {
+		t.Fatalf("GetTasksByListID: %v", err)
+	}
+	assertTaskIDs(t, tasks, second.ID, first.ID)
+
+	all, err := s.GetAllTasks(ctx)
+	if err != nil {
+		t.Fatalf("GetAllTasks: %v", err)
+	}
+	assertTaskIDs(t, all, elsewhere.ID, second.ID, first.ID)
+
+	updated, err := s.UpdateTask(ctx, first.ID, "Buy oat milk", true, first.Version)
+	if err != nil {
+		t.Fatalf("UpdateTask: %v", err)
+	}
+	if updated.TaskName != "Buy oat milk" || !updated.Completed || updated.ListID != list.ID {
+		t.Errorf("UpdateTask = %+v", updated)
+	}
+
+	toggled, err := s.ToggleTaskCompletion(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("ToggleTaskCompletion: %v", err)
+	}
+	if toggled.Completed {
+		t.Errorf("ToggleTaskCompletion left task completed")
+	}
+	toggled, err = s.ToggleTaskCompletion(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("ToggleTaskCompletion: %v", err)
+	}
+	if !toggled.Completed {
+		t.Errorf("ToggleTaskCompletion did not complete task")
+	}
+
+	if err := s.DeleteTask(ctx, first.ID, 0); err != nil {
+		t.Fatalf("DeleteTask: %v", err)
+	}
+	tasks, err = s.GetTasksByListID(ctx, list.ID)
+	if err != nil {
+		t.Fatalf("GetTasksByListID: %v", err)
+	}
+	assertTaskIDs(t, tasks, second.ID)
+
+	if _, err := s.CreateTask(ctx, unknownID, "Orphan"); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("CreateTask with unknown list: got %v, want ErrNotFound", err)
+	}
+}
+
+func testReorderTasks(t *testing.T, s server.Store) {
+	ctx := context.Background()
+
+	list := mustCreateList(t, s, "Inbox")
+	other := mustCreateList(t, s, "Other")
+	a := mustCreateTask(t, s, list.ID, "A")
+	b := mustCreateTask(t, s, list.ID, "B")
+	c := mustCreateTask(t, s, list.ID, "C")
+	foreign := mustCreateTask(t, s, other.ID, "Foreign")
+
+	if err := s.ReorderTasks(ctx, list.ID, nil); err != nil {
+		t.Fatalf("ReorderTasks(nil): %v", err)
+	}
+	if err := s.ReorderTasks(ctx, list.ID, []string{a.ID, c.ID, b.ID, foreign.ID}); err != nil {
+		t.Fatalf("ReorderTasks: %v", err)
+	}
+
+	tasks, err := s.GetTasksByListID(ctx, list.ID)
+	if err != nil {
+		t.Fatalf("GetTasksByListID: %v", err)
+	}
+	assertTaskIDs(t, tasks, a.ID, c.ID, b.ID)
+	for i, task := range tasks {
+		if task.Position != i+1 {
+			t.Errorf("task %q position = %d, want %d", task.TaskName, task.Position, i+1)
+		}
+	}
+
+	// Tasks of other lists are left alone
+	got, err := s.GetTask(ctx, foreign.ID)
+	if err != nil {
+		t.Fatalf("GetTask: %v", err)
+	}
+	if got.ListID != other.ID || got.Position != foreign.Position {
+		t.Errorf("ReorderTasks changed a task of another list: %+v", got)
+	}
+
+	// New tasks are placed first
+	d := mustCreateTask(t, s, list.ID, "D")
+	tasks, err = s.GetTasksByListID(ctx, list.ID)
+	if err != nil {
+		t.Fatalf("GetTasksByListID: %v", err)
+	}
+	assertTaskIDs(t, tasks, d.ID, a.ID, c.ID, b.ID)
+}
+
+func testSubTasks(t *testing.T, s server.Store) {
+	ctx := context.Background()
+
+	list := mustCreateList(t, s, "Inbox")
+	task := mustCreateTask(t, s, list.ID, "Plan trip")
+	other := mustCreateTask(t, s, list.ID, "Other")
+
+	first := mustCreateSubTask(t, s, task.ID, "Book flights")
+	if first.TaskID != task.ID || first.SubTaskName != "Book flights" || first.Completed {
+		t.Errorf("CreateSubTask = %+v", first)
+	}
+	second := mustCreateSubTask(t, s, task.ID, "Book hotel")
+	elsewhere := mustCreateSubTask(t, s, other.ID, "Elsewhere")
+
+	got, err := s.GetSubTask(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("GetSubTask: %v", err)
+	}
+	if got.ID != first.ID || got.SubTaskName != first.SubTaskName {
+		t.Errorf("GetSubTask = %+v, want %+v", got, first)
+	}
+
+	subTasks, err := s.GetSubTasksByTaskID(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetSubTasksByTaskID: %v", err)
+	}
+	assertSubTaskIDs(t, subTasks, second.ID, first.ID)
+
+	all, err := s.GetAllSubTasks(ctx)
+	if err != nil {
+		t.Fatalf("GetAllSubTasks: %v", err)
+	}
+	assertSubTaskIDs(t, all, elsewhere.ID, second.ID, first.ID)
+
+	updated, err := s.UpdateSubTask(ctx, first.ID, "Book cheap flights", true, first.Version)
+	if err != nil {
+		t.Fatalf("UpdateSubTask: %v", err)
+	}
+	if updated.SubTaskName != "Book cheap flights" || !updated.Completed || updated.TaskID != task.ID {
+		t.Errorf("UpdateSubTask = %+v", updated)
+	}
+
+	toggled, err := s.ToggleSubTaskCompletion(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("ToggleSubTaskCompletion: %v", err)
+	}
+	if toggled.Completed {
+		t.Errorf("ToggleSubTaskCompletion left subtask completed")
+	}
+
+	if err := s.DeleteSubTask(ctx, first.ID, 0); err != nil {
+		t.Fatalf("DeleteSubTask: %v", err)
+	}
+	subTasks, err = s.GetSubTasksByTaskID(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetSubTasksByTaskID: %v", err)
+	}
+	assertSubTaskIDs(t, subTasks, second.ID)
+
+	if _, err := s.CreateSubTask(ctx, unknownID, "Orphan"); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("CreateSubTask with unknown task: got %v, want ErrNotFound", err)
+	}
+}
+
+func testReorderSubTasks(t *testing.T, s server.Store) {
+	ctx := context.Background()
+
+	list := mustCreateList(t, s, "Inbox")
+	task := mustCreateTask(t, s, list.ID, "Plan trip")
+	other := mustCreateTask(t, s, list.ID, "Other")
+	a := mustCreateSubTask(t, s, task.ID, "A")
+	b := mustCreateSubTask(t, s, task.ID, "B")
+	c := mustCreateSubTask(t, s, task.ID, "C")
+	foreign := mustCreateSubTask(t, s, other.ID, "Foreign")
+
+	if err := s.ReorderSubTasks(ctx, task.ID, []string{b.ID, a.ID, c.ID, foreign.ID}); err != nil {
+		t.Fatalf("ReorderSubTasks: %v", err)
+	}
+
+	subTasks, err := s.GetSubTasksByTaskID(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetSubTasksByTaskID: %v", err)
+	}
+	assertSubTaskIDs(t, subTasks, b.ID, a.ID, c.ID)
+	for i, subTask := range subTasks {
+		if subTask.Position != i+1 {
+			t.Errorf("subtask %q position = %d, want %d", subTask.SubTaskName, subTask.Position, i+1)
+		}
+	}
+
+	got, err := s.GetSubTask(ctx, foreign.ID)
+	if err != nil {
+		t.Fatalf("GetSubTask: %v", err)
+	}
+	if got.TaskID != other.ID || got.Position != foreign.Position {
+		t.Errorf("ReorderSubTasks changed a subtask of another task: %+v", got)
+	}
+
+	// New subtasks are placed first
+	d := mustCreateSubTask(t, s, task.ID, "D")
+	subTasks, err = s.GetSubTasksByTaskID(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetSubTasksByTaskID: %v", err)
+	}
+	assertSubTaskIDs(t, subTasks, d.ID, b.ID, a.ID, c.ID)
+}
+
+func testPages(t *testing.T, s server.Store) {
+	ctx := context.Background()
+
+	inbox := mustCreateList(t, s, "Inbox")
+	other := mustCreateList(t, s, "Other")
+	for i := range 5 {
+		task := mustCreateTask(t, s, inbox.ID, fmt.Sprintf("Task %d", i))
+		mustCreateSubTask(t, s, task.ID, fmt.Sprintf("Step %d", i))
+	}
+	mustCreateTask(t, s, other.ID, "Elsewhere")
+
+	// Pages of a list follow GetTasksByListID
+	want, err := s.GetTasksByListID(ctx, inbox.ID)
+	if err != nil {
+		t.Fatalf("GetTasksByListID: %v", err)
+	}
+	var got []server.Task
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages == 3 {
+			t.Fatalf("GetTasksPageByListID returned more than 3 pages of 2 for 5 tasks")
+		}
+		page, err := s.GetTasksPageByListID(ctx, inbox.ID, 2, cursor)
+		if err != nil {
+			t.Fatalf("GetTasksPageByListID: %v", err)
+		}
+		got = append(got, page.Tasks...)
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	assertTaskIDs(t, got, taskIDs(want)...)
+
+	// Rows added above the cursor do not shift the following pages
+	first, err := s.GetTasksPageByListID(ctx, inbox.ID, 3, "")
+	if err != nil {
+		t.Fatalf("GetTasksPageByListID: %v", err)
+	}
+	mustCreateTask(t, s, inbox.ID, "Newer")
+	rest, err := s.GetTasksPageByListID(ctx, inbox.ID, 3, first.NextCursor)
+	if err != nil {
+		t.Fatalf("GetTasksPageByListID: %v", err)
+	}
+	if rest.NextCursor != "" {
+		t.Errorf("NextCursor = %q on the last page, want empty", rest.NextCursor)
+	}
+	assertTaskIDs(t, rest.Tasks, taskIDs(want[3:])...)
+
+	// Pages across lists hold every row once, newest first
+	var all []server.Task
+	cursor = ""
+	for {
+		page, err := s.GetAllTasksPage(ctx, 4, cursor)
+		if err != nil {
+			t.Fatalf("GetAllTasksPage: %v", err)
+		}
+		all = append(all, page.Tasks...)
+		if cursor = page.NextCursor; cursor == "" {
+			break
+		}
+	}
+	if len(all) != 7 {
+		t.Fatalf("GetAllTasksPage returned %d tasks over all pages, want 7", len(all))
+	}
+	for i := 1; i < len(all); i++ {
+		if all[i].CreatedAt.After(all[i-1].CreatedAt) || all[i].ID == all[i-1].ID {
+			t.Fatalf("GetAllTasksPage order = %v, want newest first without repeats", taskIDs(all))
+		}
+	}
+
+	var subTasks []server.SubTask
+	cursor = ""
+	for {
+		page, err := s.GetAllSubTasksPage(ctx, 2, cursor)
+		if err != nil {
+			t.Fatalf("GetAllSubTasksPage: %v", err)
+		}
+		subTasks = append(subTasks, page.SubTasks...)
+		if cursor = page.NextCursor; cursor == "" {
+			break
+		}
+	}
+	if len(subTasks) != 5 {
+		t.Fatalf("GetAllSubTasksPage returned %d subtasks over all pages, want 5", len(subTasks))
+	}
+
+	// A page of an empty list is empty rather than nil
+	page, err := s.GetTasksPageByListID(ctx, unknownID, 10, "")
+	if err != nil {
+		t.Fatalf("GetTasksPageByListID: %v", err)
+	}
+	if page.Tasks == nil || len(page.Tasks) != 0 || page.NextCursor != "" {
+		t.Errorf("empty page = %+v, want no tasks and no cursor", page)
+	}
+
+	for _, tt := range []struct {
+		size   int
+		cursor string
+	}{{0, ""}, {-1, ""}, {10, "not a cursor"}, {10, "e30"}} {
+		if _, err := s.GetAllTasksPage(ctx, tt.size, tt.cursor); !errors.Is(err, server.ErrInvalidInput) {
+			t.Errorf("GetAllTasksPage(%d, %q): err = %v, want ErrInvalidInput", tt.size, tt.cursor, err)
+		}
+	}
+}
+
+func testMoveTask(t *testing.T, s server.Store) {
+	ctx := context.Background()
+
+	from := mustCreateList(t, s, "From")
+	to := mustCreateList(t, s, "To")
+	moving := mustCreateTask(t, s, from.ID, "Moving")
+	stays := mustCreateTask(t, s, from.ID, "Stays")
+	subTask := mustCreateSubTask(t, s, moving.ID, "Comes along")
+	a := mustCreateTask(t, s, to.ID, "A")
+	b := mustCreateTask(t, s, to.ID, "B")
+
+	// to is ordered b, a; insert between them
+	moved, err := s.MoveTask(ctx, moving.ID, to.ID, 2)
+	if err != nil {
+		t.Fatalf("MoveTask: %v", err)
+	}
+	if moved.ListID != to.ID || moved.Position != 2 || moved.TaskName != "Moving" {
+		t.Errorf("MoveTask = %+v, want list %s at position 2", moved, to.ID)
+	}
+
+	tasks, err := s.GetTasksByListID(ctx, to.ID)
+	if err != nil {
+		t.Fatalf("GetTasksByListID: %v", err)
+	}
+	assertTaskIDs(t, tasks, b.ID, moving.ID, a.ID)
+
+	tasks, err = s.GetTasksByListID(ctx, from.ID)
+	if err != nil {
+		t.Fatalf("GetTasksByListID: %v", err)
+	}
+	assertTaskIDs(t, tasks, stays.ID)
+
+	if got, err := s.GetSubTask(ctx, subTask.ID); err != nil || got.TaskID != moving.ID {
+		t.Errorf("subtask after MoveTask = %+v, %v; want it to stay on the task", got, err)
+	}
+
+	// Position 0 appends, and moving within a list reorders it
+	if _, err := s.MoveTask(ctx, b.ID, to.ID, 0); err != nil {
+		t.Fatalf("MoveTask: %v", err)
+	}
+	tasks, err = s.GetTasksByListID(ctx, to.ID)
+	if err != nil {
+		t.Fatalf("GetTasksByListID: %v", err)
+	}
+	assertTaskIDs(t, tasks, moving.ID, a.ID, b.ID)
+
+	if _, err := s.MoveTask(ctx, moving.ID, unknownID, 1); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("MoveTask to unknown list: got %v, want ErrNotFound", err)
+	}
+	if _, err := s.MoveTask(ctx, unknownID, to.ID, 1); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("MoveTask of unknown task: got %v, want ErrNotFound", err)
+	}
+}
+
+func testMoveSubTask(t *testing.T, s server.Store) {
+	ctx := context.Background()
+
+	list := mustCreateList(t, s, "Inbox")
+	from := mustCreateTask(t, s, list.ID, "From")
+	to := mustCreateTask(t, s, list.ID, "To")
+	moving := mustCreateSubTask(t, s, from.ID, "Moving")
+	a := mustCreateSubTask(t, s, to.ID, "A")
+	b := mustCreateSubTask(t, s, to.ID, "B")
+
+	moved, err := s.MoveSubTask(ctx, moving.ID, to.ID, 1)
+	if err != nil {
+		t.Fatalf("MoveSubTask: %v", err)
+	}
+	if moved.TaskID != to.ID || moved.Position != 1 {
+		t.Errorf("MoveSubTask = %+v, want task %s at position 1", moved, to.ID)
+	}
+
+	subTasks, err := s.GetSubTasksByTaskID(ctx, to.ID)
+	if err != nil {
+		t.Fatalf("GetSubTasksByTaskID: %v", err)
+	}
+	assertSubTaskIDs(t, subTasks, moving.ID, b.ID, a.ID)
+
+	subTasks, err = s.GetSubTasksByTaskID(ctx, from.ID)
+	if err != nil {
+		t.Fatalf("GetSubTasksByTaskID: %v", err)
+	}
+	assertSubTaskIDs(t, subTasks)
+
+	if _, err := s.MoveSubTask(ctx, moving.ID, unknownID, 1); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("MoveSubTask to unknown task: got %v, want ErrNotFound", err)
+	}
+}
+
+func testPromoteAndDemote(t *testing.T, s server.Store) {
+	ctx := context.Background()
+
+	list := mustCreateList(t, s, "Inbox")
+	after := mustCreateTask(t, s, list.ID, "After")
+	parent := mustCreateTask(t, s, list.ID, "Parent")
+	subTask := mustCreateSubTask(t, s, parent.ID, "Promote me")
+	subTask, err := s.ToggleSubTaskCompletion(ctx, subTask.ID)
+	if err != nil {
+		t.Fatalf("ToggleSubTaskCompletion: %v", err)
+	}
+
+	task, err := s.PromoteSubTaskToTask(ctx, subTask.ID)
+	if err != nil {
+		t.Fatalf("PromoteSubTaskToTask: %v", err)
+	}
+	if task.ID != subTask.ID || task.ListID != list.ID || task.TaskName != subTask.SubTaskName || !task.Completed {
+		t.Errorf("PromoteSubTaskToTask = %+v, want it to carry over %+v", task, subTask)
+	}
+	if !task.CreatedAt.Equal(subTask.CreatedAt) || !task.UpdatedAt.Equal(subTask.UpdatedAt) {
+		t.Errorf("PromoteSubTaskToTask timestamps = %v/%v, want %v/%v", task.CreatedAt, task.UpdatedAt, subTask.CreatedAt, subTask.UpdatedAt)
+	}
+	if _, err := s.GetSubTask(ctx, subTask.ID); err == nil {
+		t.Errorf("subtask still exists after promotion")
+	}
+
+	tasks, err := s.GetTasksByListID(ctx, list.ID)
+	if err != nil {
+		t.Fatalf("GetTasksByListID: %v", err)
+	}
+	assertTaskIDs(t, tasks, parent.ID, task.ID, after.ID)
+
+	demoted, err := s.DemoteTaskToSubTask(ctx, task.ID, after.ID)
+	if err != nil {
+		t.Fatalf("DemoteTaskToSubTask: %v", err)
+	}
+	if demoted.ID != task.ID || demoted.TaskID != after.ID || demoted.SubTaskName != task.TaskName || !demoted.Completed {
+		t.Errorf("DemoteTaskToSubTask = %+v, want it to carry over %+v", demoted, task)
+	}
+	if !demoted.CreatedAt.Equal(task.CreatedAt) || !demoted.UpdatedAt.Equal(task.UpdatedAt) {
+		t.Errorf("DemoteTaskToSubTask timestamps = %v/%v, want %v/%v", demoted.CreatedAt, demoted.UpdatedAt, task.CreatedAt, task.UpdatedAt)
+	}
+	if _, err := s.GetTask(ctx, task.ID); err == nil {
+		t.Errorf("task still exists after demotion")
+	}
+
+	// A task with subtasks cannot be demoted, nor can a task become its own subtask
+	if _, err := s.DemoteTaskToSubTask(ctx, after.ID, parent.ID); !errors.Is(err, server.ErrInvalidInput) {
+		t.Errorf("DemoteTaskToSubTask of a task with subtasks: got %v, want ErrInvalidInput", err)
+	}
+	if _, err := s.DemoteTaskToSubTask(ctx, parent.ID, parent.ID); !errors.Is(err, server.ErrInvalidInput) {
+		t.Errorf("DemoteTaskToSubTask onto itself: got %v, want ErrInvalidInput", err)
+	}
+	if _, err := s.GetSubTask(ctx, demoted.ID); err != nil {
+		t.Errorf("failed demotion changed existing subtasks: %v", err)
+	}
+}
+
+func testPatch(t *testing.T, s server.Store) {
+	ctx := context.Background()
+	list := mustCreateList(t, s, "Inbox")
+	task := mustCreateTask(t, s, list.ID, "Draft")
+	subTask := mustCreateSubTask(t, s, task.ID, "Outline")
+
+	title := "Personal"
+	patchedList, err := s.PatchList(ctx, list.ID, server.ListPatch{Title: &title})
+	if err != nil {
+		t.Fatalf("PatchList: %v", err)
+	}
+	if patchedList.Title != title || patchedList.Position != list.Position {
+		t.Errorf("PatchList = %+v, want title %q and unchanged position", patchedList, title)
+	}
+
+	// Two patches touching different fields do not overwrite each other
+	completed := true
+	if _, err := s.PatchTask(ctx, task.ID, server.TaskPatch{Completed: &completed}); err != nil {
+		t.Fatalf("PatchTask completed: %v", err)
+	}
+	name := "Final"
+	priority := server.PriorityMedium
+	patched, err := s.PatchTask(ctx, task.ID, server.TaskPatch{TaskName: &name, Priority: &priority})
+	if err != nil {
+		t.Fatalf("PatchTask name: %v", err)
+	}
+	if patched.TaskName != name || !patched.Completed || patched.Priority != priority || patched.Starred || patched.Notes != "" {
+		t.Errorf("PatchTask = %+v, want renamed, completed, medium priority", patched)
+	}
+	if patched.UpdatedAt.Before(task.UpdatedAt) {
+		t.Errorf("PatchTask did not advance updated_at")
+	}
+
+	invalid := server.Priority("urgent")
+	if _, err := s.PatchTask(ctx, task.ID, server.TaskPatch{Priority: &invalid}); !errors.Is(err, server.ErrInvalidInput) {
+		t.Errorf("PatchTask with an unknown priority: got %v, want ErrInvalidInput", err)
+	}
+
+	// An empty patch returns the row unchanged
+	unchanged, err := s.PatchTask(ctx, task.ID, server.TaskPatch{})
+	if err != nil {
+		t.Fatalf("PatchTask empty: %v", err)
+	}
+	if unchanged.TaskName != name || !unchanged.Completed {
+		t.Errorf("empty PatchTask = %+v", unchanged)
+	}
+
+	if _, err := s.PatchSubTask(ctx, subTask.ID, server.SubTaskPatch{Completed: &completed}); err != nil {
+		t.Fatalf("PatchSubTask completed: %v", err)
+	}
+	subName := "Sections"
+	patchedSub, err := s.PatchSubTask(ctx, subTask.ID, server.SubTaskPatch{SubTaskName: &subName})
+	if err != nil {
+		t.Fatalf("PatchSubTask name: %v", err)
+	}
+	if patchedSub.SubTaskName != subName || !patchedSub.Completed || patchedSub.TaskID != task.ID {
+		t.Errorf("PatchSubTask = %+v, want renamed and still completed", patchedSub)
+	}
+
+	if _, err := s.PatchList(ctx, unknownID, server.ListPatch{Title: &title}); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("PatchList succeeded for unknown id: %v", err)
+	}
+	if _, err := s.PatchTask(ctx, unknownID, server.TaskPatch{}); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("PatchTask succeeded for unknown id: %v", err)
+	}
+	if _, err := s.PatchSubTask(ctx, unknownID, server.SubTaskPatch{Completed: &completed}); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("PatchSubTask succeeded for unknown id: %v", err)
+	}
+}
+
+func testVersions(t *testing.T, s server.Store) {
+	ctx := context.Background()
+	list := mustCreateList(t, s, "Inbox")
+	task := mustCreateTask(t, s, list.ID, "Draft")
+	subTask := mustCreateSubTask(t, s, task.ID, "Outline")
+	if list.Version != 1 || task.Version != 1 || subTask.Version != 1 {
+		t.Fatalf("new rows have versions %d/%d/%d, want 1", list.Version, task.Version, subTask.Version)
+	}
+
+	// Every change bumps the version
+	toggled, err := s.ToggleTaskCompletion(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("ToggleTaskCompletion: %v", err)
+	}
+	if toggled.Version <= task.Version {
+		t.Errorf("ToggleTaskCompletion version = %d, want more than %d", toggled.Version, task.Version)
+	}
+
+	// A write based on a stale version is rejected with the current row
+	_, err = s.UpdateTask(ctx, task.ID, "Renamed", false, task.Version)
+	assertConflict(t, err, task.ID)
+	var conflict *server.ConflictError
+	if errors.As(err, &conflict) {
+		current, ok := conflict.Current.(*server.Task)
+		if !ok || current.Version != toggled.Version || !current.Completed {
+			t.Errorf("conflict current = %+v, want the toggled task", conflict.Current)
+		}
+	}
+	got, err := s.GetTask(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetTask: %v", err)
+	}
+	if got.TaskName != task.TaskName {
+		t.Errorf("rejected update changed the name to %q", got.TaskName)
+	}
+
+	// The current version succeeds and advances
+	updated, err := s.UpdateTask(ctx, task.ID, "Renamed", true, toggled.Version)
+	if err != nil {
+		t.Fatalf("UpdateTask: %v", err)
+	}
+	if updated.Version <= toggled.Version {
+		t.Errorf("UpdateTask version = %d, want more than %d", updated.Version, toggled.Version)
+	}
+
+	name := "Patched"
+	_, err = s.PatchTask(ctx, task.ID, server.TaskPatch{TaskName: &name, Version: toggled.Version})
+	assertConflict(t, err, task.ID)
+	_, err = s.PatchTask(ctx, task.ID, server.TaskPatch{Version: toggled.Version})
+	assertConflict(t, err, task.ID)
+	if _, err := s.PatchTask(ctx, task.ID, server.TaskPatch{TaskName: &name, Version: updated.Version}); err != nil {
+		t.Fatalf("PatchTask: %v", err)
+	}
+
+	renamed, err := s.UpdateList(ctx, list.ID, "Work", list.Version)
+	if err != nil {
+		t.Fatalf("UpdateList: %v", err)
+	}
+	_, err = s.UpdateList(ctx, list.ID, "Home", list.Version)
+	assertConflict(t, err, list.ID)
+	title := "Home"
+	_, err = s.PatchList(ctx, list.ID, server.ListPatch{Title: &title, Version: list.Version})
+	assertConflict(t, err, list.ID)
+
+	_, err = s.UpdateSubTask(ctx, subTask.ID, "Sections", true, subTask.Version+1)
+	assertConflict(t, err, subTask.ID)
+	completed := true
+	_, err = s.PatchSubTask(ctx, subTask.ID, server.SubTaskPatch{Completed: &completed, Version: subTask.Version + 1})
+	assertConflict(t, err, subTask.ID)
+
+	// Deletes are guarded the same way
+	assertConflict(t, s.DeleteSubTask(ctx, subTask.ID, subTask.Version+1), subTask.ID)
+	if err := s.DeleteSubTask(ctx, subTask.ID, subTask.Version); err != nil {
+		t.Fatalf("DeleteSubTask: %v", err)
+	}
+	assertConflict(t, s.DeleteTask(ctx, task.ID, updated.Version), task.ID)
+	assertConflict(t, s.DeleteList(ctx, list.ID, list.Version), list.ID)
+	if err := s.DeleteList(ctx, list.ID, renamed.Version); err != nil {
+		t.Fatalf("DeleteList: %v", err)
+	}
+
+	// A missing row is reported as not found, not as a conflict
+	if err := s.DeleteTask(ctx, task.ID, updated.Version); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("DeleteTask of a deleted task = %v, want ErrNotFound", err)
+	}
+}
+
+func testTaskDetails(t *testing.T, s server.Store) {
+	ctx := context.Background()
+	list := mustCreateList(t, s, "Inbox")
+	task := mustCreateTask(t, s, list.ID, "Write report")
+	if task.Notes != "" || task.Priority != server.PriorityNone || task.Starred {
+		t.Errorf("new task details = %q/%q/%v, want empty/none/false", task.Notes, task.Priority, task.Starred)
+	}
+
+	// Notes have no length cap
+	notes := "# Outline\n\n- intro\n- **results** ✅\n" + strings.Repeat("x", 10000)
+	got, err := s.SetTaskNotes(ctx, task.ID, notes)
+	if err != nil {
+		t.Fatalf("SetTaskNotes: %v", err)
+	}
+	if got.Notes != notes {
+		t.Errorf("SetTaskNotes stored %d bytes, want %d", len(got.Notes), len(notes))
+	}
+
+	got, err = s.SetTaskPriority(ctx, task.ID, server.PriorityHigh)
+	if err != nil {
+		t.Fatalf("SetTaskPriority: %v", err)
+	}
+	if got.Priority != server.PriorityHigh {
+		t.Errorf("SetTaskPriority = %q, want %q", got.Priority, server.PriorityHigh)
+	}
+	if _, err := s.SetTaskPriority(ctx, task.ID, "urgent"); !errors.Is(err, server.ErrInvalidInput) {
+		t.Errorf("SetTaskPriority with an unknown priority: got %v, want ErrInvalidInput", err)
+	}
+
+	got, err = s.StarTask(ctx, task.ID, true)
+	if err != nil {
+		t.Fatalf("StarTask: %v", err)
+	}
+	if !got.Starred {
+		t.Errorf("StarTask did not star the task")
+	}
+
+	reread, err := s.GetTask(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetTask: %v", err)
+	}
+	if reread.Notes != notes || reread.Priority != server.PriorityHigh || !reread.Starred {
+		t.Errorf("GetTask details = %d bytes/%q/%v", len(reread.Notes), reread.Priority, reread.Starred)
+	}
+
+	// Details carry over to the next occurrence of a recurring task
+	rule := "FREQ=DAILY"
+	if _, err := s.SetTaskRecurrence(ctx, task.ID, &rule); err != nil {
+		t.Fatalf("SetTaskRecurrence: %v", err)
+	}
+	completion, err := s.CompleteTask(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("CompleteTask: %v", err)
+	}
+	if next := completion.Next; next == nil || next.Notes != notes || next.Priority != server.PriorityHigh || !next.Starred {
+		t.Errorf("next occurrence = %+v, want the same details", next)
+	}
+}
+
+func testStarredTasks(t *testing.T, s server.Store) {
+	ctx := context.Background()
+	inbox := mustCreateList(t, s, "Inbox")
+	work := mustCreateList(t, s, "Work")
+	first := mustCreateTask(t, s, inbox.ID, "First")
+	mustCreateTask(t, s, inbox.ID, "Plain")
+	second := mustCreateTask(t, s, work.ID, "Second")
+	third := mustCreateTask(t, s, work.ID, "Third")
+
+	for _, task := range []*server.Task{first, second, third} {
+		if _, err := s.StarTask(ctx, task.ID, true); err != nil {
+			t.Fatalf("StarTask: %v", err)
+		}
+	}
+	tasks, err := s.GetStarredTasks(ctx)
+	if err != nil {
+		t.Fatalf("GetStarredTasks: %v", err)
+	}
+	assertTaskIDs(t, tasks, third.ID, second.ID, first.ID)
+
+	if _, err := s.StarTask(ctx, second.ID, false); err != nil {
+		t.Fatalf("StarTask unstar: %v", err)
+	}
+	tasks, err = s.GetStarredTasks(ctx)
+	if err != nil {
+		t.Fatalf("GetStarredTasks: %v", err)
+	}
+	assertTaskIDs(t, tasks, third.ID, first.ID)
+}
+
+func testDueDates(t *testing.T, s server.Store) {
+	ctx := context.Background()
+	loc := time.FixedZone("UTC+5", 5*60*60)
+	list := mustCreateList(t, s, "Inbox")
+	dated := mustCreateTask(t, s, list.ID, "Dated")
+	timed := mustCreateTask(t, s, list.ID, "Timed")
+	later := mustCreateTask(t, s, list.ID, "Later")
+	mustCreateTask(t, s, list.ID, "Undated")
+
+	date := "2025-03-10"
+	got, err := s.SetTaskDue(ctx, dated.ID, &date, nil)
+	if err != nil {
+		t.Fatalf("SetTaskDue date: %v", err)
+	}
+	if got.DueDate == nil || *got.DueDate != date || got.DueAt != nil {
+		t.Errorf("SetTaskDue date = %v/%v, want %s/nil", got.DueDate, got.DueAt, date)
+	}
+
+	// 2025-03-10 09:30 in loc
+	dueAt := time.Date(2025, 3, 10, 9, 30, 0, 0, loc)
+	got, err = s.SetTaskDue(ctx, timed.ID, nil, &dueAt)
+	if err != nil {
+		t.Fatalf("SetTaskDue time: %v", err)
+	}
+	if got.DueAt == nil || !got.DueAt.Equal(dueAt) || got.DueDate != nil {
+		t.Errorf("SetTaskDue time = %v/%v, want nil/%v", got.DueDate, got.DueAt, dueAt)
+	}
+
+	laterAt := dueAt.Add(24 * time.Hour)
+	if _, err := s.SetTaskDue(ctx, later.ID, nil, &laterAt); err != nil {
+		t.Fatalf("SetTaskDue later: %v", err)
+	}
+
+	// Setting one kind of due date clears the other
+	got, err = s.SetTaskDue(ctx, later.ID, &date, &laterAt)
+	if err != nil {
+		t.Fatalf("SetTaskDue both: %v", err)
+	}
+	if got.DueDate == nil || got.DueAt != nil {
+		t.Errorf("SetTaskDue with both = %v/%v, want date only", got.DueDate, got.DueAt)
+	}
+	got, err = s.SetTaskDue(ctx, later.ID, nil, &laterAt)
+	if err != nil {
+		t.Fatalf("SetTaskDue later: %v", err)
+	}
+	if got.DueDate != nil || got.DueAt == nil {
+		t.Errorf("SetTaskDue time after date = %v/%v, want time only", got.DueDate, got.DueAt)
+	}
+
+	bad := "10/03/2025"
+	if _, err := s.SetTaskDue(ctx, dated.ID, &bad, nil); !errors.Is(err, server.ErrInvalidInput) {
+		t.Errorf("SetTaskDue with malformed date %q: got %v, want ErrInvalidInput", bad, err)
+	}
+
+	reread, err := s.GetTask(ctx, timed.ID)
+	if err != nil {
+		t.Fatalf("GetTask: %v", err)
+	}
+	if reread.DueAt == nil || !reread.DueAt.Equal(dueAt) {
+		t.Errorf("GetTask due_at = %v, want %v", reread.DueAt, dueAt)
+	}
+
+	// The local day of 2025-03-10 includes the whole-day task and the timed one
+	day := time.Date(2025, 3, 10, 0, 0, 0, 0, loc)
+	tasks, err := s.GetTasksDueBetween(ctx, day, day.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("GetTasksDueBetween: %v", err)
+	}
+	assertTaskIDs(t, tasks, dated.ID, timed.ID)
+
+	tasks, err = s.GetTasksDueBetween(ctx, day, day.AddDate(0, 0, 7))
+	if err != nil {
+		t.Fatalf("GetTasksDueBetween week: %v", err)
+	}
+	assertTaskIDs(t, tasks, dated.ID, timed.ID, later.ID)
+
+	// The end of the range is exclusive
+	tasks, err = s.GetTasksDueBetween(ctx, day.AddDate(0, 0, -1), dueAt)
+	if err != nil {
+		t.Fatalf("GetTasksDueBetween before: %v", err)
+	}
+	assertTaskIDs(t, tasks, dated.ID)
+
+	// Clearing the due date removes the task from range queries
+	if _, err := s.SetTaskDue(ctx, timed.ID, nil, nil); err != nil {
+		t.Fatalf("SetTaskDue clear: %v", err)
+	}
+	tasks, err = s.GetTasksDueBetween(ctx, day, day.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("GetTasksDueBetween after clear: %v", err)
+	}
+	assertTaskIDs(t, tasks, dated.ID)
+}
+
+func testOverdueTasks(t *testing.T, s server.Store) {
+	ctx := context.Background()
+	loc := time.FixedZone("UTC-3", -3*60*60)
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, loc)
+	list := mustCreateList(t, s, "Inbox")
+	yesterday := mustCreateTask(t, s, list.ID, "Yesterday")
+	today := mustCreateTask(t, s, list.ID, "Today")
+	hourAgo := mustCreateTask(t, s, list.ID, "Hour ago")
+	inAnHour := mustCreateTask(t, s, list.ID, "In an hour")
+	done := mustCreateTask(t, s, list.ID, "Done")
+
+	setDate := func(task *server.Task, date string) {
+		t.Helper()
+		if _, err := s.SetTaskDue(ctx, task.ID, &date, nil); err != nil {
+			t.Fatalf("SetTaskDue: %v", err)
+		}
+	}
+	setTime := func(task *server.Task, at time.Time) {
+		t.Helper()
+		if _, err := s.SetTaskDue(ctx, task.ID, nil, &at); err != nil {
+			t.Fatalf("SetTaskDue: %v", err)
+		}
+	}
+	setDate(yesterday, "2025-03-09")
+	setDate(today, "2025-03-10")
+	setTime(hourAgo, now.Add(-time.Hour))
+	setTime(inAnHour, now.Add(time.Hour))
+	setTime(done, now.Add(-48*time.Hour))
+	if _, err := s.ToggleTaskCompletion(ctx, done.ID); err != nil {
+		t.Fatalf("ToggleTaskCompletion: %v", err)
+	}
+
+	// Whole-day tasks due today are not overdue yet
+	tasks, err := s.GetOverdueTasks(ctx, now)
+	if err != nil {
+		t.Fatalf("GetOverdueTasks: %v", err)
+	}
+	assertTaskIDs(t, tasks, yesterday.ID, hourAgo.ID)
+}
+
+func testReminders(t *testing.T, s server.Store) {
+	ctx := context.Background()
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	list := mustCreateList(t, s, "Inbox")
+	past := mustCreateTask(t, s, list.ID, "Past")
+	exact := mustCreateTask(t, s, list.ID, "Exact")
+	future := mustCreateTask(t, s, list.ID, "Future")
+	done := mustCreateTask(t, s, list.ID, "Done")
+
+	setReminder := func(task *server.Task, at time.Time) {
+		t.Helper()
+		got, err := s.SetTaskReminder(ctx, task.ID, &at)
+		if err != nil {
+			t.Fatalf("SetTaskReminder: %v", err)
+		}
+		if got.RemindAt == nil || !got.RemindAt.Equal(at) {
+			t.Errorf("SetTaskReminder remind_at = %v, want %v", got.RemindAt, at)
+		}
+	}
+	setReminder(past, now.Add(-time.Minute))
+	setReminder(exact, now)
+	setReminder(future, now.Add(time.Minute))
+	setReminder(done, now.Add(-time.Hour))
+	if _, err := s.ToggleTaskCompletion(ctx, done.ID); err != nil {
+		t.Fatalf("ToggleTaskCompletion: %v", err)
+	}
+
+	tasks, err := s.GetDueReminders(ctx, now)
+	if err != nil {
+		t.Fatalf("GetDueReminders: %v", err)
+	}
+	assertTaskIDs(t, tasks, past.ID, exact.ID)
+
+	got, err := s.SetTaskReminder(ctx, past.ID, nil)
+	if err != nil {
+		t.Fatalf("SetTaskReminder clear: %v", err)
+	}
+	if got.RemindAt != nil {
+		t.Errorf("SetTaskReminder(nil) remind_at = %v, want nil", got.RemindAt)
+	}
+	tasks, err = s.GetDueReminders(ctx, now)
+	if err != nil {
+		t.Fatalf("GetDueReminders after clear: %v", err)
+	}
+	assertTaskIDs(t, tasks, exact.ID)
+}
+
+func testRecurrence(t *testing.T, s server.Store) {
+	ctx := context.Background()
+	list := mustCreateList(t, s, "Chores")
+	other := mustCreateTask(t, s, list.ID, "Other")
+	report := mustCreateTask(t, s, list.ID, "Weekly report")
+	draft := mustCreateSubTask(t, s, report.ID, "Draft")
+	send := mustCreateSubTask(t, s, report.ID, "Send")
+	if _, err := s.ToggleSubTaskCompletion(ctx, draft.ID); err != nil {
+		t.Fatalf("ToggleSubTaskCompletion: %v", err)
+	}
+
+	// Monday 2025-03-10; the rule repeats on Mondays and Thursdays
+	date := "2025-03-10"
+	if _, err := s.SetTaskDue(ctx, report.ID, &date, nil); err != nil {
+		t.Fatalf("SetTaskDue: %v", err)
+	}
+	rule := "rrule:freq=weekly;byday=th,mo;count=3"
+	got, err := s.SetTaskRecurrence(ctx, report.ID, &rule)
+	if err != nil {
+		t.Fatalf("SetTaskRecurrence: %v", err)
+	}
+	if got.Recurrence == nil || *got.Recurrence != "FREQ=WEEKLY;BYDAY=MO,TH;COUNT=3" {
+		t.Errorf("SetTaskRecurrence stored %v, want canonical rule", got.Recurrence)
+	}
+
+	completion, err := s.CompleteTask(ctx, report.ID)
+	if err != nil {
+		t.Fatalf("CompleteTask: %v", err)
+	}
+	if !completion.Task.Completed || completion.Task.ID != report.ID {
+		t.Errorf("CompleteTask task = %+v, want completed %s", completion.Task, report.ID)
+	}
+	next := completion.Next
+	if next == nil {
+		t.Fatalf("CompleteTask did not spawn the next occurrence")
+	}
+	if next.ID == report.ID || next.Completed || next.ListID != list.ID || next.TaskName != report.TaskName {
+		t.Errorf("next occurrence = %+v", next)
+	}
+	if next.DueDate == nil || *next.DueDate != "2025-03-13" {
+		t.Errorf("next due date = %v, want 2025-03-13", next.DueDate)
+	}
+	if next.Recurrence == nil || *next.Recurrence != "FREQ=WEEKLY;BYDAY=MO,TH;COUNT=2" {
+		t.Errorf("next recurrence = %v, want COUNT=2", next.Recurrence)
+	}
+
+	// The next occurrence takes the completed task's place
+	tasks, err := s.GetTasksByListID(ctx, list.ID)
+	if err != nil {
+		t.Fatalf("GetTasksByListID: %v", err)
+	}
+	assertTaskIDs(t, tasks, next.ID, report.ID, other.ID)
+
+	// Subtasks are copied in order and reset; the originals are left alone
+	subTasks, err := s.GetSubTasksByTaskID(ctx, next.ID)
+	if err != nil {
+		t.Fatalf("GetSubTasksByTaskID: %v", err)
+	}
+	if len(subTasks) != 2 || subTasks[0].SubTaskName != send.SubTaskName || subTasks[1].SubTaskName != draft.SubTaskName {
+		t.Fatalf("copied subtasks = %+v, want Send, Draft", subTasks)
+	}
+	for _, subTask := range subTasks {
+		if subTask.Completed || subTask.ID == draft.ID || subTask.ID == send.ID {
+			t.Errorf("copied subtask = %+v, want a new incomplete subtask", subTask)
+		}
+	}
+	original, err := s.GetSubTask(ctx, draft.ID)
+	if err != nil {
+		t.Fatalf("GetSubTask: %v", err)
+	}
+	if !original.Completed || original.TaskID != report.ID {
+		t.Errorf("original subtask = %+v, want unchanged", original)
+	}
+
+	// Completing twice does not spawn another occurrence
+	completion, err = s.CompleteTask(ctx, report.ID)
+	if err != nil {
+		t.Fatalf("CompleteTask again: %v", err)
+	}
+	if completion.Next != nil {
+		t.Errorf("CompleteTask on a completed task spawned %+v", completion.Next)
+	}
+
+	// ToggleTaskCompletion spawns the following occurrence too
+	toggled, err := s.ToggleTaskCompletion(ctx, next.ID)
+	if err != nil {
+		t.Fatalf("ToggleTaskCompletion: %v", err)
+	}
+	if !toggled.Completed {
+		t.Errorf("ToggleTaskCompletion did not complete the task")
+	}
+	tasks, err = s.GetTasksByListID(ctx, list.ID)
+	if err != nil {
+		t.Fatalf("GetTasksByListID: %v", err)
+	}
+	if len(tasks) != 4 || tasks[0].DueDate == nil || *tasks[0].DueDate != "2025-03-17" {
+		t.Fatalf("after toggle tasks = %+v, want a new occurrence due 2025-03-17 first", tasks)
+	}
+	last := tasks[0]
+	if last.Recurrence == nil || *last.Recurrence != "FREQ=WEEKLY;BYDAY=MO,TH;COUNT=1" {
+		t.Errorf("last recurrence = %v, want COUNT=1", last.Recurrence)
+	}
+
+	// The count is exhausted after the third occurrence
+	completion, err = s.CompleteTask(ctx, last.ID)
+	if err != nil {
+		t.Fatalf("CompleteTask last: %v", err)
+	}
+	if completion.Next != nil {
+		t.Errorf("exhausted rule spawned %+v", completion.Next)
+	}
+
+	// Un-completing leaves the spawned occurrence in place
+	if _, err := s.ToggleTaskCompletion(ctx, report.ID); err != nil {
+		t.Fatalf("ToggleTaskCompletion: %v", err)
+	}
+	tasks, err = s.GetTasksByListID(ctx, list.ID)
+	if err != nil {
+		t.Fatalf("GetTasksByListID: %v", err)
+	}
+	if len(tasks) != 4 {
+		t.Errorf("un-completing changed the task count to %d", len(tasks))
+	}
+}
+
+func testRecurrenceLimits(t *testing.T, s server.Store) {
+	ctx := context.Background()
+	list := mustCreateList(t, s, "Bills")
+	invoice := mustCreateTask(t, s, list.ID, "Invoices")
+
+	for _, rule := range []string{"", "FREQ=HOURLY", "FREQ=MONTHLY;BYDAY=MO", "FREQ=DAILY;COUNT=2;UNTIL=20250101", "INTERVAL=2"} {
+		if _, err := s.SetTaskRecurrence(ctx, invoice.ID, &rule); !errors.Is(err, server.ErrInvalidInput) {
+			t.Errorf("SetTaskRecurrence(%q): got %v, want ErrInvalidInput", rule, err)
+		}
+	}
+
+	// A timed task keeps its wall-clock time and reminder offset; months
+	// without the 31st are skipped
+	dueAt := time.Date(2025, 1, 31, 9, 0, 0, 0, time.Local)
+	remindAt := dueAt.Add(-time.Hour)
+	if _, err := s.SetTaskDue(ctx, invoice.ID, nil, &dueAt); err != nil {
+		t.Fatalf("SetTaskDue: %v", err)
+	}
+	if _, err := s.SetTaskReminder(ctx, invoice.ID, &remindAt); err != nil {
+		t.Fatalf("SetTaskReminder: %v", err)
+	}
+	rule := "FREQ=MONTHLY;UNTIL=20250331"
+	if _, err := s.SetTaskRecurrence(ctx, invoice.ID, &rule); err != nil {
+		t.Fatalf("SetTaskRecurrence: %v", err)
+	}
+
+	completion, err := s.CompleteTask(ctx, invoice.ID)
+	if err != nil {
+		t.Fatalf("CompleteTask: %v", err)
+	}
+	next := completion.Next
+	if next == nil {
+		t.Fatalf("CompleteTask did not spawn the next occurrence")
+	}
+	want := time.Date(2025, 3, 31, 9, 0, 0, 0, time.Local)
+	if next.DueAt == nil || !next.DueAt.Equal(want) || next.DueDate != nil {
+		t.Errorf("next due = %v/%v, want %v", next.DueDate, next.DueAt, want)
+	}
+	if next.RemindAt == nil || !next.RemindAt.Equal(want.Add(-time.Hour)) {
+		t.Errorf("next reminder = %v, want %v", next.RemindAt, want.Add(-time.Hour))
+	}
+
+	// The following occurrence would fall after UNTIL
+	completion, err = s.CompleteTask(ctx, next.ID)
+	if err != nil {
+		t.Fatalf("CompleteTask: %v", err)
+	}
+	if completion.Next != nil {
+		t.Errorf("rule past UNTIL spawned %+v", completion.Next)
+	}
+
+	// Clearing the rule stops recurrence
+	plain := mustCreateTask(t, s, list.ID, "Plain")
+	if _, err := s.SetTaskRecurrence(ctx, plain.ID, &rule); err != nil {
+		t.Fatalf("SetTaskRecurrence: %v", err)
+	}
+	got, err := s.SetTaskRecurrence(ctx, plain.ID, nil)
+	if err != nil {
+		t.Fatalf("SetTaskRecurrence clear: %v", err)
+	}
+	if got.Recurrence != nil {
+		t.Errorf("SetTaskRecurrence(nil) left %v", *got.Recurrence)
+	}
+	completion, err = s.CompleteTask(ctx, plain.ID)
+	if err != nil {
+		t.Fatalf("CompleteTask: %v", err)
+	}
+	if completion.Next != nil {
+		t.Errorf("non-recurring task spawned %+v", completion.Next)
+	}
+}
+
+func testCascadingDeletes(t *testing.T, s server.Store) {
+	ctx := context.Background()
+
+	list := mustCreateList(t, s, "Doomed")
+	kept := mustCreateList(t, s, "Kept")
+	task := mustCreateTask(t, s, list.ID, "Task")
+	keptTask := mustCreateTask(t, s, kept.ID, "Kept task")
+	subTask := mustCreateSubTask(t, s, task.ID, "Subtask")
+	keptSubTask := mustCreateSubTask(t, s, keptTask.ID, "Kept subtask")
+
+	if err := s.DeleteList(ctx, list.ID, 0); err != nil {
+		t.Fatalf("DeleteList: %v", err)
+	}
+	if _, err := s.GetTask(ctx, task.ID); err == nil {
+		t.Errorf("task survived deletion of its list")
+	}
+	if _, err := s.GetSubTask(ctx, subTask.ID); err == nil {
+		t.Errorf("subtask survived deletion of its list")
+	}
+
+	if err := s.DeleteTask(ctx, keptTask.ID, 0); err != nil {
+		t.Fatalf("DeleteTask: %v", err)
+	}
+	if _, err := s.GetSubTask(ctx, keptSubTask.ID); err == nil {
+		t.Errorf("subtask survived deletion of its task")
+	}
+	if _, err := s.GetList(ctx, kept.ID); err != nil {
+		t.Errorf("deleting a task removed its list: %v", err)
+	}
+}
+
+func testTrash(t *testing.T, s server.Store) {
+	ctx := context.Background()
+
+	list := mustCreateList(t, s, "Errands")
+	task := mustCreateTask(t, s, list.ID, "Groceries")
+	subTask := mustCreateSubTask(t, s, task.ID, "Milk")
+	other := mustCreateTask(t, s, list.ID, "Pharmacy")
+	otherSub := mustCreateSubTask(t, s, other.ID, "Refill")
+
+	// A directly deleted subtask is listed on its own
+	if err := s.DeleteSubTask(ctx, otherSub.ID, 0); err != nil {
+		t.Fatalf("DeleteSubTask: %v", err)
+	}
+	if subTasks, _ := s.GetSubTasksByTaskID(ctx, other.ID); len(subTasks) != 0 {
+		t.Errorf("GetSubTasksByTaskID after delete returned %d subtasks", len(subTasks))
+	}
+	stillOpen := mustCreateSubTask(t, s, other.ID, "Still open")
+
+	if err := s.DeleteList(ctx, list.ID, 0); err != nil {
+		t.Fatalf("DeleteList: %v", err)
+	}
+	if lists, _ := s.GetAllLists(ctx); len(lists) != 0 {
+		t.Errorf("GetAllLists after delete returned %d lists", len(lists))
+	}
+	if tasks, _ := s.GetAllTasks(ctx); len(tasks) != 0 {
+		t.Errorf("GetAllTasks after delete returned %d tasks", len(tasks))
+	}
+	if subTasks, _ := s.GetAllSubTasks(ctx); len(subTasks) != 0 {
+		t.Errorf("GetAllSubTasks after delete returned %d subtasks", len(subTasks))
+	}
+	if _, err := s.CreateTask(ctx, list.ID, "Late"); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("CreateTask in a trashed list: got %v, want ErrNotFound", err)
+	}
+	if _, err := s.UpdateTask(ctx, task.ID, "x", false, 0); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("UpdateTask of a trashed task: got %v, want ErrNotFound", err)
+	}
+	if err := s.DeleteList(ctx, list.ID, 0); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("DeleteList twice: got %v, want ErrNotFound", err)
+	}
+
+	trash, err := s.GetTrash(ctx)
+	if err != nil {
+		t.Fatalf("GetTrash: %v", err)
+	}
+	if len(trash.Lists) != 1 || trash.Lists[0].ID != list.ID || trash.Lists[0].DeletedAt == nil {
+		t.Fatalf("trashed lists = %+v, want only %s with DeletedAt", trash.Lists, list.ID)
+	}
+	if len(trash.Tasks) != 0 {
+		t.Errorf("trash lists %d tasks deleted with their list, want 0", len(trash.Tasks))
+	}
+	assertSubTaskIDs(t, trash.SubTasks, otherSub.ID)
+
+	// Restoring the list brings back what was deleted with it, but not the
+	// subtask deleted before
+	restored, err := s.RestoreList(ctx, list.ID)
+	if err != nil {
+		t.Fatalf("RestoreList: %v", err)
+	}
+	if restored.DeletedAt != nil || restored.Version <= list.Version {
+		t.Errorf("restored list = %+v, want DeletedAt nil and a newer version", restored)
+	}
+	tasks, err := s.GetTasksByListID(ctx, list.ID)
+	if err != nil {
+		t.Fatalf("GetTasksByListID: %v", err)
+	}
+	assertTaskIDs(t, tasks, other.ID, task.ID)
+	if _, err := s.GetSubTask(ctx, subTask.ID); err != nil {
+		t.Errorf("GetSubTask after restoring its list: %v", err)
+	}
+	if subTasks, _ := s.GetSubTasksByTaskID(ctx, other.ID); len(subTasks) != 1 {
+		t.Errorf("task %s has %d subtasks after restore, want 1", other.ID, len(subTasks))
+	}
+	if _, err := s.RestoreList(ctx, list.ID); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("RestoreList of a live list: got %v, want ErrNotFound", err)
+	}
+
+	// Restoring a subtask restores its deleted task and list as well, but
+	// not their other children
+	if err := s.DeleteTask(ctx, other.ID, 0); err != nil {
+		t.Fatalf("DeleteTask: %v", err)
+	}
+	if err := s.DeleteList(ctx, list.ID, 0); err != nil {
+		t.Fatalf("DeleteList: %v", err)
+	}
+	if _, err := s.RestoreSubTask(ctx, otherSub.ID); err != nil {
+		t.Fatalf("RestoreSubTask: %v", err)
+	}
+	if _, err := s.GetList(ctx, list.ID); err != nil {
+		t.Errorf("GetList after restoring a subtask: %v", err)
+	}
+	if _, err := s.GetTask(ctx, other.ID); err != nil {
+		t.Errorf("GetTask after restoring a subtask: %v", err)
+	}
+	if _, err := s.GetTask(ctx, task.ID); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("GetTask of a sibling: got %v, want ErrNotFound", err)
+	}
+	if _, err := s.GetSubTask(ctx, stillOpen.ID); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("GetSubTask of a sibling: got %v, want ErrNotFound", err)
+	}
+	if _, err := s.RestoreTask(ctx, task.ID); err != nil {
+		t.Fatalf("RestoreTask: %v", err)
+	}
+	if _, err := s.GetSubTask(ctx, subTask.ID); err != nil {
+		t.Errorf("GetSubTask after restoring its task: %v", err)
+	}
+
+	if _, err := s.RestoreTask(ctx, unknownID); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("RestoreTask for unknown id: got %v, want ErrNotFound", err)
+	}
+	if _, err := s.RestoreSubTask(ctx, unknownID); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("RestoreSubTask for unknown id: got %v, want ErrNotFound", err)
+	}
+
+	// Purging honors the cutoff and removes children deleted with their parent
+	if err := s.DeleteTask(ctx, task.ID, 0); err != nil {
+		t.Fatalf("DeleteTask: %v", err)
+	}
+	purged, err := s.PurgeTrash(ctx, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("PurgeTrash: %v", err)
+	}
+	if purged != 0 {
+		t.Errorf("PurgeTrash before the deletes removed %d rows, want 0", purged)
+	}
+	purged, err = s.PurgeTrash(ctx, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("PurgeTrash: %v", err)
+	}
+	if purged != 3 {
+		t.Errorf("PurgeTrash removed %d rows, want 3", purged)
+	}
+	if _, err := s.RestoreTask(ctx, task.ID); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("RestoreTask after purge: got %v, want ErrNotFound", err)
+	}
+	trash, err = s.GetTrash(ctx)
+	if err != nil {
+		t.Fatalf("GetTrash: %v", err)
+	}
+	if len(trash.Lists)+len(trash.Tasks)+len(trash.SubTasks) != 0 {
+		t.Errorf("trash after purge = %+v, want empty", trash)
+	}
+	if _, err := s.GetTask(ctx, other.ID); err != nil {
+		t.Errorf("PurgeTrash removed a live task: %v", err)
+	}
+}
+
+func testTags(t *testing.T, s server.Store) {
+	ctx := context.Background()
+
+	work, err := s.CreateTag(ctx, "Work", "#ff0000")
+	if err != nil {
+		t.Fatalf("CreateTag: %v", err)
+	}
+	if work.ID == "" || work.Name != "Work" || work.Color != "#ff0000" {
+		t.Errorf("CreateTag = %+v", work)
+	}
+	home, err := s.CreateTag(ctx, "home", "")
+	if err != nil {
+		t.Fatalf("CreateTag: %v", err)
+	}
+	urgent, err := s.CreateTag(ctx, "Urgent", "")
+	if err != nil {
+		t.Fatalf("CreateTag: %v", err)
+	}
+	if _, err := s.CreateTag(ctx, "WORK", ""); !errors.Is(err, server.ErrConflict) {
+		t.Errorf("CreateTag with a taken name: got %v, want ErrConflict", err)
+	}
+
+	// Tags are ordered by name regardless of case
+	tags, err := s.GetAllTags(ctx)
+	if err != nil {
+		t.Fatalf("GetAllTags: %v", err)
+	}
+	assertTagNames(t, tags, "home", "Urgent", "Work")
+
+	// So are names outside ASCII, though where they sort among the others
+	// depends on the database's collation
+	if _, err := s.CreateTag(ctx, "Énergie", ""); err != nil {
+		t.Fatalf("CreateTag: %v", err)
+	}
+	if _, err := s.CreateTag(ctx, "école", ""); err != nil {
+		t.Fatalf("CreateTag: %v", err)
+	}
+	if _, err := s.CreateTag(ctx, "ÉCOLE", ""); !errors.Is(err, server.ErrConflict) {
+		t.Errorf("CreateTag with a taken non-ASCII name: got %v, want ErrConflict", err)
+	}
+	tags, err = s.GetAllTags(ctx)
+	if err != nil {
+		t.Fatalf("GetAllTags: %v", err)
+	}
+	indexOf := func(name string) int {
+		return slices.IndexFunc(tags, func(tag server.Tag) bool { return tag.Name == name })
+	}
+	if indexOf("école") > indexOf("Énergie") {
+		t.Errorf("GetAllTags = %+v, want école before Énergie", tags)
+	}
+
+	if _, err := s.RenameTag(ctx, urgent.ID, "Home"); !errors.Is(err, server.ErrConflict) {
+		t.Errorf("RenameTag to a taken name: got %v, want ErrConflict", err)
+	}
+	renamed, err := s.RenameTag(ctx, urgent.ID, "URGENT")
+	if err != nil {
+		t.Fatalf("RenameTag to a different case of its own name: %v", err)
+	}
+	if renamed.Name != "URGENT" {
+		t.Errorf("RenameTag name = %q, want %q", renamed.Name, "URGENT")
+	}
+	colored, err := s.SetTagColor(ctx, home.ID, "#00aa00")
+	if err != nil {
+		t.Fatalf("SetTagColor: %v", err)
+	}
+	if colored.Color != "#00aa00" || colored.Name != "home" {
+		t.Errorf("SetTagColor = %+v", colored)
+	}
+
+	errands := mustCreateList(t, s, "Errands")
+	office := mustCreateList(t, s, "Office")
+	first := mustCreateTask(t, s, errands.ID, "First")
+	second := mustCreateTask(t, s, office.ID, "Second")
+	third := mustCreateTask(t, s, errands.ID, "Third")
+
+	for _, assign := range []struct{ task, tag string }{
+		{first.ID, work.ID}, {second.ID, work.ID}, {second.ID, home.ID}, {third.ID, home.ID}, {third.ID, home.ID},
+	} {
+		if err := s.AddTagToTask(ctx, assign.task, assign.tag); err != nil {
+			t.Fatalf("AddTagToTask: %v", err)
+		}
+	}
+	if err := s.AddTagToTask(ctx, unknownID, work.ID); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("AddTagToTask for unknown task: got %v, want ErrNotFound", err)
+	}
+	if err := s.AddTagToTask(ctx, first.ID, unknownID); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("AddTagToTask for unknown tag: got %v, want ErrNotFound", err)
+	}
+
+	tags, err = s.GetTaskTags(ctx, second.ID)
+	if err != nil {
+		t.Fatalf("GetTaskTags: %v", err)
+	}
+	assertTagNames(t, tags, "home", "Work")
+
+	// Matching any tag or all of them works across lists, newest first
+	assertTasksByTags(t, s, []string{work.ID}, false, second.ID, first.ID)
+	assertTasksByTags(t, s, []string{work.ID, home.ID}, false, third.ID, second.ID, first.ID)
+	assertTasksByTags(t, s, []string{work.ID, home.ID}, true, second.ID)
+	assertTasksByTags(t, s, []string{work.ID, urgent.ID}, true)
+	assertTasksByTags(t, s, nil, false)
+
+	if err := s.RemoveTagFromTask(ctx, second.ID, home.ID); err != nil {
+		t.Fatalf("RemoveTagFromTask: %v", err)
+	}
+	if err := s.RemoveTagFromTask(ctx, second.ID, home.ID); err != nil {
+		t.Errorf("RemoveTagFromTask twice: %v", err)
+	}
+	assertTasksByTags(t, s, []string{home.ID}, false, third.ID)
+
+	// Merging moves the assignments and removes the source tag
+	if _, err := s.MergeTags(ctx, work.ID, work.ID); !errors.Is(err, server.ErrInvalidInput) {
+		t.Errorf("MergeTags into itself: got %v, want ErrInvalidInput", err)
+	}
+	merged, err := s.MergeTags(ctx, home.ID, work.ID)
+	if err != nil {
+		t.Fatalf("MergeTags: %v", err)
+	}
+	if merged.ID != work.ID {
+		t.Errorf("MergeTags returned %s, want the target %s", merged.ID, work.ID)
+	}
+	if _, err := s.GetTag(ctx, home.ID); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("GetTag of a merged tag: got %v, want ErrNotFound", err)
+	}
+	assertTasksByTags(t, s, []string{work.ID}, false, third.ID, second.ID, first.ID)
+
+	// Trashed tasks keep their tags but are not listed
+	if err := s.DeleteTask(ctx, first.ID, 0); err != nil {
+		t.Fatalf("DeleteTask: %v", err)
+	}
+	assertTasksByTags(t, s, []string{work.ID}, false, third.ID, second.ID)
+	if _, err := s.RestoreTask(ctx, first.ID); err != nil {
+		t.Fatalf("RestoreTask: %v", err)
+	}
+	assertTasksByTags(t, s, []string{work.ID}, false, third.ID, second.ID, first.ID)
+
+	// The next occurrence of a recurring task gets its tags
+	rule := "FREQ=DAILY"
+	if _, err := s.SetTaskRecurrence(ctx, third.ID, &rule); err != nil {
+		t.Fatalf("SetTaskRecurrence: %v", err)
+	}
+	completion, err := s.CompleteTask(ctx, third.ID)
+	if err != nil {
+		t.Fatalf("CompleteTask: %v", err)
+	}
+	if completion.Next == nil {
+		t.Fatal("CompleteTask of a recurring task returned no next occurrence")
+	}
+	tags, err = s.GetTaskTags(ctx, completion.Next.ID)
+	if err != nil {
+		t.Fatalf("GetTaskTags: %v", err)
+	}
+	assertTagNames(t, tags, "Work")
+
+	if err := s.DeleteTag(ctx, work.ID); err != nil {
+		t.Fatalf("DeleteTag: %v", err)
+	}
+	if tags, _ := s.GetTaskTags(ctx, second.ID); len(tags) != 0 {
+		t.Errorf("task has %d tags after DeleteTag, want 0", len(tags))
+	}
+	if err := s.DeleteTag(ctx, work.ID); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("DeleteTag twice: got %v, want ErrNotFound", err)
+	}
+}
+
+func testSmartLists(t *testing.T, s server.Store) {
+	ctx := context.Background()
+	now := time.Now()
+	tomorrow, later := server.DateOf(now.AddDate(0, 0, 1)), server.DateOf(now.AddDate(0, 0, 10))
+	anHourAgo := now.Add(-time.Hour)
+	high, low, yes := server.PriorityHigh, server.PriorityLow, true
+
+	inbox := mustCreateList(t, s, "Inbox")
+	work := mustCreateList(t, s, "Work")
+	report := mustCreateTask(t, s, work.ID, "Write report")
+	milk := mustCreateTask(t, s, inbox.ID, "Buy milk")
+	bank := mustCreateTask(t, s, inbox.ID, "Call bank")
+	trip := mustCreateTask(t, s, inbox.ID, "Plan trip")
+
+	for _, step := range []error{
+		errOnly(s.SetTaskDue(ctx, report.ID, &tomorrow, nil)),
+		errOnly(s.PatchTask(ctx, report.ID, server.TaskPatch{Priority: &high})),
+		errOnly(s.SetTaskDue(ctx, milk.ID, &later, nil)),
+		errOnly(s.PatchTask(ctx, milk.ID, server.TaskPatch{Starred: &yes})),
+		errOnly(s.SetTaskDue(ctx, bank.ID, nil, &anHourAgo)),
+		errOnly(s.PatchTask(ctx, bank.ID, server.TaskPatch{Completed: &yes})),
+		errOnly(s.PatchTask(ctx, trip.ID, server.TaskPatch{Priority: &low})),
+	} {
+		if step != nil {
+			t.Fatalf("setting up tasks: %v", step)
+		}
+	}
+	job, err := s.CreateTag(ctx, "Job", "")
+	if err != nil {
+		t.Fatalf("CreateTag: %v", err)
+	}
+	if err := s.AddTagToTask(ctx, report.ID, job.ID); err != nil {
+		t.Fatalf("AddTagToTask: %v", err)
+	}
+
+	for _, tc := range []struct {
+		query string
+		ids   []string
+	}{
+		{"", []string{trip.ID, bank.ID, milk.ID, report.ID}},
+		{"completed:false", []string{trip.ID, milk.ID, report.ID}},
+		{"due:<7d", []string{bank.ID, report.ID}},
+		{"due:any sort:due", []string{bank.ID, report.ID, milk.ID}},
+		{"due:none", []string{trip.ID}},
+		{"due:overdue", []string{bank.ID}},
+		{"due:" + tomorrow, []string{report.ID}},
+		{"due:>tomorrow", []string{milk.ID}},
+		{"tag:JOB", []string{report.ID}},
+		{`list:"inbox" -starred:true`, []string{trip.ID, bank.ID}},
+		{"priority:>=medium", []string{report.ID}},
+		{"priority:<medium", []string{trip.ID, bank.ID, milk.ID}},
+		{"sort:priority", []string{report.ID, trip.ID, bank.ID, milk.ID}},
+		{"sort:name", []string{milk.ID, bank.ID, trip.ID, report.ID}},
+		{"MILK", []string{milk.ID}},
+		{`"buy milk" starred:true`, []string{milk.ID}},
+		{"-milk list:Inbox", []string{trip.ID, bank.ID}},
+		{"100%", nil},
+	} {
+		tasks, err := s.QueryTasks(ctx, tc.query, now)
+		if err != nil {
+			t.Errorf("QueryTasks(%q): %v", tc.query, err)
+			continue
+		}
+		got := make([]string, len(tasks))
+		for i, task := range tasks {
+			got[i] = task.ID
+		}
+		if strings.Join(got, ",") != strings.Join(tc.ids, ",") {
+			t.Errorf("QueryTasks(%q) = %v, want %v", tc.query, got, tc.ids)
+		}
+	}
+	for _, query := range []string{"due:someday", "color:red", `list:"Inbox`, "sort:due sort:name", "completed:maybe", "priority:urgent"} {
+		if _, err := s.QueryTasks(ctx, query, now); !errors.Is(err, server.ErrInvalidInput) {
+			t.Errorf("QueryTasks(%q): got %v, want ErrInvalidInput", query, err)
+		}
+	}
+
+	// Smart lists are listed with the others but hold no tasks of their own
+	soon, err := s.CreateSmartList(ctx, "Soon", "completed:false due:<7d")
+	if err != nil {
+		t.Fatalf("CreateSmartList: %v", err)
+	}
+	if soon.Query == nil || *soon.Query != "completed:false due:<7d" {
+		t.Errorf("CreateSmartList query = %v", soon.Query)
+	}
+	lists, err := s.GetAllLists(ctx)
+	if err != nil {
+		t.Fatalf("GetAllLists: %v", err)
+	}
+	assertListOrder(t, lists, inbox.ID, work.ID, soon.ID)
+	if lists[0].Query != nil {
+		t.Errorf("plain list has query %q", *lists[0].Query)
+	}
+	tasks, err := s.GetSmartListTasks(ctx, soon.ID, now)
+	if err != nil {
+		t.Fatalf("GetSmartListTasks: %v", err)
+	}
+	assertTaskIDs(t, tasks, report.ID)
+
+	if _, err := s.CreateTask(ctx, soon.ID, "Nowhere"); !errors.Is(err, server.ErrInvalidInput) {
+		t.Errorf("CreateTask in a smart list: got %v, want ErrInvalidInput", err)
+	}
+	if _, err := s.MoveTask(ctx, milk.ID, soon.ID, 0); !errors.Is(err, server.ErrInvalidInput) {
+		t.Errorf("MoveTask to a smart list: got %v, want ErrInvalidInput", err)
+	}
+	if _, err := s.CreateSmartList(ctx, "Someday", "due:someday"); !errors.Is(err, server.ErrInvalidInput) {
+		t.Errorf("CreateSmartList with a bad query: got %v, want ErrInvalidInput", err)
+	}
+
+	starred := "starred:true"
+	patched, err := s.PatchList(ctx, soon.ID, server.ListPatch{Query: &starred})
+	if err != nil {
+		t.Fatalf("PatchList query: %v", err)
+	}
+	if patched.Query == nil || *patched.Query != starred || patched.Title != "Soon" {
+		t.Errorf("PatchList = %+v", patched)
+	}
+	tasks, err = s.GetSmartListTasks(ctx, soon.ID, now)
+	if err != nil {
+		t.Fatalf("GetSmartListTasks: %v", err)
+	}
+	assertTaskIDs(t, tasks, milk.ID)
+	if _, err := s.PatchList(ctx, inbox.ID, server.ListPatch{Query: &starred}); !errors.Is(err, server.ErrInvalidInput) {
+		t.Errorf("PatchList query of a plain list: got %v, want ErrInvalidInput", err)
+	}
+	if _, err := s.GetSmartListTasks(ctx, inbox.ID, now); !errors.Is(err, server.ErrInvalidInput) {
+		t.Errorf("GetSmartListTasks of a plain list: got %v, want ErrInvalidInput", err)
+	}
+
+	if err := s.DeleteList(ctx, soon.ID, 0); err != nil {
+		t.Fatalf("DeleteList: %v", err)
+	}
+	if _, err := s.GetSmartListTasks(ctx, soon.ID, now); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("GetSmartListTasks of a deleted list: got %v, want ErrNotFound", err)
+	}
+}
+
+// errOnly drops the result of a store call that is only checked for errors
+// Query names are compared without case across all of Unicode, not only ASCII
+func testQueryCaseFolding(t *testing.T, s server.Store) {
+	ctx := context.Background()
+	cafe := mustCreateList(t, s, "Café")
+	task := mustCreateTask(t, s, cafe.ID, "Élan vital")
+	mustCreateTask(t, s, cafe.ID, "Other")
+	tag, err := s.CreateTag(ctx, "Über", "")
+	if err != nil {
+		t.Fatalf("CreateTag: %v", err)
+	}
+	if err := s.AddTagToTask(ctx, task.ID, tag.ID); err != nil {
+		t.Fatalf("AddTagToTask: %v", err)
+	}
+
+	for _, q := range []string{"élan", "ÉLAN", "list:CAFÉ tag:über", "tag:ÜBER"} {
+		tasks, err := s.QueryTasks(ctx, q, time.Now())
+		if err != nil {
+			t.Errorf("QueryTasks(%q): %v", q, err)
+			continue
+		}
+		if len(tasks) != 1 || tasks[0].ID != task.ID {
+			t.Errorf("QueryTasks(%q) = %v, want only %q", q, taskIDs(tasks), task.TaskName)
+		}
+	}
+}
+
+func errOnly[T any](_ T, err error) error {
+	return err
+}
+
+func testTrees(t *testing.T, s server.Store) {
+	ctx := context.Background()
+
+	home := mustCreateList(t, s, "Home")
+	work := mustCreateList(t, s, "Work")
+	empty := mustCreateList(t, s, "Empty")
+	laundry := mustCreateTask(t, s, home.ID, "Laundry")
+	dishes := mustCreateTask(t, s, home.ID, "Dishes")
+	report := mustCreateTask(t, s, work.ID, "Report")
+	wash := mustCreateSubTask(t, s, laundry.ID, "Wash")
+	fold := mustCreateSubTask(t, s, laundry.ID, "Fold")
+	draft := mustCreateSubTask(t, s, report.ID, "Draft")
+	if _, err := s.ToggleTaskCompletion(ctx, dishes.ID); err != nil {
+		t.Fatalf("ToggleTaskCompletion: %v", err)
+	}
+	if _, err := s.ToggleSubTaskCompletion(ctx, wash.ID); err != nil {
+		t.Fatalf("ToggleSubTaskCompletion: %v", err)
+	}
+
+	tree, err := s.GetListTree(ctx, home.ID)
+	if err != nil {
+		t.Fatalf("GetListTree: %v", err)
+	}
+	if tree.List.ID != home.ID || tree.CompletedTasks != 1 || tree.TotalTasks != 2 {
+		t.Errorf("list tree = %s %d/%d, want %s 1/2", tree.List.ID, tree.CompletedTasks, tree.TotalTasks, home.ID)
+	}
+	assertTaskIDs(t, treeTasks(tree.Tasks), dishes.ID, laundry.ID)
+	if got := tree.Tasks[1]; got.CompletedSubTasks != 1 || got.TotalSubTasks != 2 {
+		t.Errorf("task tree counts = %d/%d, want 1/2", got.CompletedSubTasks, got.TotalSubTasks)
+	}
+	assertSubTaskIDs(t, tree.Tasks[1].SubTasks, fold.ID, wash.ID)
+	if tree.Tasks[0].SubTasks == nil || len(tree.Tasks[0].SubTasks) != 0 {
+		t.Errorf("subtasks of a task without any = %#v, want empty", tree.Tasks[0].SubTasks)
+	}
+
+	// Trashed rows are left out, as are the children of trashed rows
+	if err := s.DeleteSubTask(ctx, fold.ID, 0); err != nil {
+		t.Fatalf("DeleteSubTask: %v", err)
+	}
+	if err := s.DeleteTask(ctx, report.ID, 0); err != nil {
+		t.Fatalf("DeleteTask: %v", err)
+	}
+	trees, err := s.GetWorkspaceTree(ctx)
+	if err != nil {
+		t.Fatalf("GetWorkspaceTree: %v", err)
+	}
+	got := make([]server.List, len(trees))
+	for i, tree := range trees {
+		got[i] = tree.List
+	}
+	assertListOrder(t, got, home.ID, work.ID, empty.ID)
+	assertSubTaskIDs(t, trees[0].Tasks[1].SubTasks, wash.ID)
+	if trees[0].Tasks[1].TotalSubTasks != 1 {
+		t.Errorf("TotalSubTasks = %d after delete, want 1", trees[0].Tasks[1].TotalSubTasks)
+	}
+	if trees[1].Tasks == nil || trees[1].TotalTasks != 0 || trees[2].TotalTasks != 0 {
+		t.Errorf("trees without tasks = %+v, %+v", trees[1], trees[2])
+	}
+
+	if _, err := s.RestoreTask(ctx, report.ID); err != nil {
+		t.Fatalf("RestoreTask: %v", err)
+	}
+	tree, err = s.GetListTree(ctx, work.ID)
+	if err != nil {
+		t.Fatalf("GetListTree: %v", err)
+	}
+	assertTaskIDs(t, treeTasks(tree.Tasks), report.ID)
+	assertSubTaskIDs(t, tree.Tasks[0].SubTasks, draft.ID)
+
+	if err := s.DeleteList(ctx, work.ID, 0); err != nil {
+		t.Fatalf("DeleteList: %v", err)
+	}
+	if _, err := s.GetListTree(ctx, work.ID); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("GetListTree of a deleted list: err = %v, want ErrNotFound", err)
+	}
+	if _, err := s.GetListTree(ctx, unknownID); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("GetListTree of an unknown list: err = %v, want ErrNotFound", err)
+	}
+	trees, err = s.GetWorkspaceTree(ctx)
+	if err != nil {
+		t.Fatalf("GetWorkspaceTree: %v", err)
+	}
+	if len(trees) != 2 {
+		t.Errorf("GetWorkspaceTree returned %d lists after delete, want 2", len(trees))
+	}
+}
+
+func testWorkspace(t *testing.T, s server.Store) {
+	ctx := context.Background()
+
+	home := mustCreateList(t, s, "Home")
+	laundry := mustCreateTask(t, s, home.ID, "Laundry")
+	dishes := mustCreateTask(t, s, home.ID, "Dishes")
+	fold := mustCreateSubTask(t, s, laundry.ID, "Fold")
+	mustCreateSubTask(t, s, laundry.ID, "Wash")
+	if _, err := s.SetTaskNotes(ctx, laundry.ID, "Use the **cold** cycle"); err != nil {
+		t.Fatalf("SetTaskNotes: %v", err)
+	}
+	if _, err := s.SetTaskPriority(ctx, laundry.ID, server.PriorityHigh); err != nil {
+		t.Fatalf("SetTaskPriority: %v", err)
+	}
+	date := "2030-05-01"
+	if _, err := s.SetTaskDue(ctx, laundry.ID, &date, nil); err != nil {
+		t.Fatalf("SetTaskDue: %v", err)
+	}
+	rule := "FREQ=WEEKLY"
+	if _, err := s.SetTaskRecurrence(ctx, laundry.ID, &rule); err != nil {
+		t.Fatalf("SetTaskRecurrence: %v", err)
+	}
+	if _, err := s.ToggleSubTaskCompletion(ctx, fold.ID); err != nil {
+		t.Fatalf("ToggleSubTaskCompletion: %v", err)
+	}
+	chores, err := s.CreateTag(ctx, "Chores", "#00ff00")
+	if err != nil {
+		t.Fatalf("CreateTag: %v", err)
+	}
+	if err := s.AddTagToTask(ctx, laundry.ID, chores.ID); err != nil {
+		t.Fatalf("AddTagToTask: %v", err)
+	}
+	if _, err := s.CreateSmartList(ctx, "Urgent", "priority:high"); err != nil {
+		t.Fatalf("CreateSmartList: %v", err)
+	}
+	gone := mustCreateTask(t, s, home.ID, "Gone")
+	if err := s.DeleteTask(ctx, gone.ID, 0); err != nil {
+		t.Fatalf("DeleteTask: %v", err)
+	}
+
+	doc, err := s.ExportWorkspace(ctx)
+	if err != nil {
+		t.Fatalf("ExportWorkspace: %v", err)
+	}
+	if doc.Version != server.WorkspaceVersion || len(doc.Lists) != 2 || len(doc.Tags) != 1 || doc.Tags[0] != (server.WorkspaceTag{Name: "Chores", Color: "#00ff00"}) {
+		t.Fatalf("exported workspace = %+v", doc)
+	}
+	if got := doc.Lists[0]; got.ID != home.ID || len(got.Tasks) != 2 || got.Tasks[0].ID != dishes.ID || got.Tasks[1].ID != laundry.ID {
+		t.Fatalf("exported list = %+v, want Home with Dishes and Laundry but not the trashed task", got)
+	}
+	exported := doc.Lists[0].Tasks[1]
+	if exported.Notes != "Use the **cold** cycle" || exported.Priority != server.PriorityHigh || exported.DueDate == nil || *exported.DueDate != date ||
+		exported.Recurrence == nil || *exported.Recurrence != rule || len(exported.Tags) != 1 || exported.Tags[0] != "Chores" ||
+		len(exported.SubTasks) != 2 || !exported.SubTasks[1].Completed {
+		t.Errorf("exported task = %+v", exported)
+	}
+	if smart := doc.Lists[1]; smart.Query == nil || *smart.Query != "priority:high" || len(smart.Tasks) != 0 {
+		t.Errorf("exported smart list = %+v", smart)
+	}
+
+	// Merging an export into the workspace it came from changes nothing
+	report, err := s.ImportWorkspace(ctx, doc, server.ImportMerge, false)
+	if err != nil {
+		t.Fatalf("ImportWorkspace merge: %v", err)
+	}
+	if report.Created != (server.ImportCounts{}) || report.Updated != (server.ImportCounts{}) || len(report.Remapped) != 0 || len(report.Rows) != 6 {
+		t.Errorf("merge report = %+v, want 6 unchanged rows", report)
+	}
+	for _, row := range report.Rows {
+		if row.Action != server.ImportUnchanged || row.SourceID != "" || len(row.Changed) != 0 {
+			t.Errorf("row = %+v, want it unchanged", row)
+		}
+	}
+	assertListCount(t, s, 2)
+
+	// Rows with the ids of live rows update them in place; the id of a trashed
+	// row is remapped and new rows go after every existing one
+	edited, err := s.ExportWorkspace(ctx)
+	if err != nil {
+		t.Fatalf("ExportWorkspace: %v", err)
+	}
+	edited.Lists[0].Tasks[0].Tags = []string{"Shop"}
+	edited.Lists[0].Tasks[1].TaskName = "Laundry and ironing"
+	edited.Lists[0].Tasks[1].SubTasks[1].Completed = false
+	edited.Lists[0].Tasks = append(edited.Lists[0].Tasks,
+		server.WorkspaceTask{ID: gone.ID, TaskName: "Gone"},
+		server.WorkspaceTask{TaskName: "Iron", Position: 10})
+	report, err = s.ImportWorkspace(ctx, edited, server.ImportMerge, true)
+	if err != nil {
+		t.Fatalf("ImportWorkspace dry run: %v", err)
+	}
+	created := server.ImportCounts{Tasks: 2, Tags: 1}
+	updated := server.ImportCounts{Tasks: 2, SubTasks: 1}
+	if !report.DryRun || report.Created != created || report.Updated != updated || len(report.Remapped) != 1 || report.Remapped[gone.ID] == "" {
+		t.Errorf("dry run report = %+v, want %+v created, %+v updated and the trashed id remapped", report, created, updated)
+	}
+	unchanged, err := s.GetTask(ctx, laundry.ID)
+	if err != nil {
+		t.Fatalf("GetTask: %v", err)
+	}
+	if unchanged.TaskName != "Laundry" {
+		t.Errorf("task after the dry run = %+v, want it unchanged", unchanged)
+	}
+
+	report, err = s.ImportWorkspace(ctx, edited, server.ImportMerge, false)
+	if err != nil {
+		t.Fatalf("ImportWorkspace merge: %v", err)
+	}
+	if report.Created != created || report.Updated != updated || len(report.Remapped) != 1 {
+		t.Errorf("merge report = %+v, want %+v created and %+v updated", report, created, updated)
+	}
+	rows := make(map[string]server.ImportRow)
+	for _, row := range report.Rows {
+		rows[row.ID] = row
+	}
+	if row := rows[laundry.ID]; row.Action != server.ImportUpdate || !slices.Equal(row.Changed, []string{"task_name"}) {
+		t.Errorf("Laundry row = %+v, want task_name updated", row)
+	}
+	if row := rows[dishes.ID]; row.Action != server.ImportUpdate || !slices.Equal(row.Changed, []string{"tags"}) {
+		t.Errorf("Dishes row = %+v, want tags updated", row)
+	}
+	if row := rows[fold.ID]; row.Entity != "subtask" || row.Action != server.ImportUpdate || !slices.Equal(row.Changed, []string{"completed"}) {
+		t.Errorf("Fold row = %+v, want completed updated", row)
+	}
+	if row := rows[report.Remapped[gone.ID]]; row.Action != server.ImportCreate || row.SourceID != gone.ID {
+		t.Errorf("Gone row = %+v, want it created from %s", row, gone.ID)
+	}
+	renamed, err := s.GetTask(ctx, laundry.ID)
+	if err != nil {
+		t.Fatalf("GetTask: %v", err)
+	}
+	if renamed.TaskName != "Laundry and ironing" || renamed.Version != unchanged.Version+1 || renamed.Position != unchanged.Position || renamed.Priority != server.PriorityHigh {
+		t.Errorf("updated task = %+v, want it renamed in place", renamed)
+	}
+	tasks, err := s.GetTasksByListID(ctx, home.ID)
+	if err != nil {
+		t.Fatalf("GetTasksByListID: %v", err)
+	}
+	if len(tasks) != 4 || tasks[2].TaskName != "Gone" || tasks[3].TaskName != "Iron" || tasks[2].Position <= gone.Position {
+		t.Errorf("tasks after merge = %+v, want Gone and Iron added after the trashed task", tasks)
+	}
+	tags, err := s.GetTaskTags(ctx, dishes.ID)
+	if err != nil {
+		t.Fatalf("GetTaskTags: %v", err)
+	}
+	if len(tags) != 1 || tags[0].Name != "Shop" {
+		t.Errorf("tags of Dishes = %+v, want Shop", tags)
+	}
+
+	// A second merge of the same export finds nothing to do
+	current, err := s.ExportWorkspace(ctx)
+	if err != nil {
+		t.Fatalf("ExportWorkspace: %v", err)
+	}
+	report, err = s.ImportWorkspace(ctx, current, server.ImportMerge, false)
+	if err != nil {
+		t.Fatalf("ImportWorkspace merge: %v", err)
+	}
+	if report.Created != (server.ImportCounts{}) || report.Updated != (server.ImportCounts{}) || len(report.Remapped) != 0 {
+		t.Errorf("second merge report = %+v, want nothing created or updated", report)
+	}
+	again, err := s.ExportWorkspace(ctx)
+	if err != nil {
+		t.Fatalf("ExportWorkspace: %v", err)
+	}
+	assertSameWorkspace(t, again, current)
+
+	// Replacing restores the exported workspace with its ids and drops the rest
+	report, err = s.ImportWorkspace(ctx, doc, server.ImportReplace, false)
+	if err != nil {
+		t.Fatalf("ImportWorkspace replace: %v", err)
+	}
+	deleted := server.ImportCounts{Lists: 2, Tasks: 5, SubTasks: 2, Tags: 2}
+	if report.Deleted != deleted || len(report.Remapped) != 0 || report.Created != (server.ImportCounts{Lists: 2, Tasks: 2, SubTasks: 2, Tags: 1}) {
+		t.Errorf("replace report = %+v, want %+v deleted and nothing remapped", report, deleted)
+	}
+	again, err = s.ExportWorkspace(ctx)
+	if err != nil {
+		t.Fatalf("ExportWorkspace: %v", err)
+	}
+	assertSameWorkspace(t, again, doc)
+	trash, err := s.GetTrash(ctx)
+	if err != nil {
+		t.Fatalf("GetTrash: %v", err)
+	}
+	if len(trash.Tasks) != 0 {
+		t.Errorf("trash after replace = %+v, want empty", trash)
+	}
+
+	// Rows without ids, as written by hand, get new ones
+	report, err = s.ImportWorkspace(ctx, &server.Workspace{Version: 1, Lists: []server.WorkspaceList{{
+		Title: "Groceries",
+		Tasks: []server.WorkspaceTask{{TaskName: "Milk", Tags: []string{"chores", "Shop"}}},
+	}}}, server.ImportMerge, false)
+	if err != nil {
+		t.Fatalf("ImportWorkspace without ids: %v", err)
+	}
+	if len(report.Remapped) != 0 || report.Created != (server.ImportCounts{Lists: 1, Tasks: 1, Tags: 1}) {
+		t.Errorf("report = %+v, want a list, a task and the Shop tag created", report)
+	}
+	assertListCount(t, s, 3)
+}
+
+func testWorkspaceImportErrors(t *testing.T, s server.Store) {
+	ctx := context.Background()
+
+	home := mustCreateList(t, s, "Home")
+	mustCreateTask(t, s, home.ID, "Laundry")
+	query := "due:someday"
+	for _, tt := range []struct {
+		name string
+		doc  *server.Workspace
+		mode server.ImportMode
+	}{
+		{"missing document", nil, server.ImportMerge},
+		{"unknown mode", &server.Workspace{Version: 1}, "append"},
+		{"later version", &server.Workspace{Version: server.WorkspaceVersion + 1}, server.ImportMerge},
+		{"empty title", &server.Workspace{Version: 1, Lists: []server.WorkspaceList{{Title: " "}}}, server.ImportReplace},
+		{"bad priority", &server.Workspace{Version: 1, Lists: []server.WorkspaceList{
+			{Title: "Fine", Tasks: []server.WorkspaceTask{{TaskName: "Fine"}}},
+			{Title: "Broken", Tasks: []server.WorkspaceTask{{TaskName: "Broken", Priority: "urgent"}}},
+		}}, server.ImportReplace},
+		{"bad query", &server.Workspace{Version: 1, Lists: []server.WorkspaceList{{Title: "Smart", Query: &query}}}, server.ImportMerge},
+		{"smart list with tasks", &server.Workspace{Version: 1, Lists: []server.WorkspaceList{
+			{Title: "Smart", Query: new(string), Tasks: []server.WorkspaceTask{{TaskName: "Task"}}},
+		}}, server.ImportMerge},
+	} {
+		if _, err := s.ImportWorkspace(ctx, tt.doc, tt.mode, false); !errors.Is(err, server.ErrInvalidInput) {
+			t.Errorf("ImportWorkspace with %s: err = %v, want ErrInvalidInput", tt.name, err)
+		}
+	}
+
+	// Failed imports leave the workspace as it was
+	lists, err := s.GetAllLists(ctx)
+	if err != nil {
+		t.Fatalf("GetAllLists: %v", err)
+	}
+	assertListOrder(t, lists, home.ID)
+	tasks, err := s.GetTasksByListID(ctx, home.ID)
+	if err != nil {
+		t.Fatalf("GetTasksByListID: %v", err)
+	}
+	if len(tasks) != 1 {
+		t.Errorf("got %d tasks after failed imports, want 1", len(tasks))
+	}
+}
+
+func testWorkspaceAppend(t *testing.T, s server.Store) {
+	ctx := context.Background()
+
+	home := mustCreateList(t, s, "Home")
+	dishes := mustCreateTask(t, s, home.ID, "Dishes")
+	laundry := mustCreateTask(t, s, home.ID, "Laundry")
+	smart, err := s.CreateSmartList(ctx, "Urgent", "priority:high")
+	if err != nil {
+		t.Fatalf("CreateSmartList: %v", err)
+	}
+
+	doc := &server.Workspace{Version: 1, Lists: []server.WorkspaceList{
+		{ID: home.ID, Append: true, Tasks: []server.WorkspaceTask{
+			{TaskName: "Vacuum", SubTasks: []server.WorkspaceSubTask{{SubTaskName: "Stairs"}}},
+			{TaskName: "Mop", Completed: true},
+		}},
+		{Title: "Garden", Tasks: []server.WorkspaceTask{{TaskName: "Weed"}}},
+		{ID: home.ID, Append: true, Tasks: []server.WorkspaceTask{{TaskName: "Dust"}}},
+	}}
+	report, err := s.ImportWorkspace(ctx, doc, server.ImportMerge, false)
+	if err != nil {
+		t.Fatalf("ImportWorkspace: %v", err)
+	}
+	if report.Created != (server.ImportCounts{Lists: 1, Tasks: 4, SubTasks: 1}) {
+		t.Errorf("report = %+v, want one list and four tasks created", report)
+	}
+	tree, err := s.GetListTree(ctx, home.ID)
+	if err != nil {
+		t.Fatalf("GetListTree: %v", err)
+	}
+	names := make([]string, 0, len(tree.Tasks))
+	for _, task := range tree.Tasks {
+		names = append(names, task.Task.TaskName)
+	}
+	if got, want := strings.Join(names, ", "), "Laundry, Dishes, Vacuum, Mop, Dust"; got != want {
+		t.Errorf("tasks = %s, want %s", got, want)
+	}
+	if tree.Tasks[0].Task.ID != laundry.ID || tree.Tasks[1].Task.ID != dishes.ID || len(tree.Tasks[2].SubTasks) != 1 || !tree.Tasks[3].Task.Completed {
+		t.Errorf("tree = %+v", tree)
+	}
+
+	for _, tt := range []struct {
+		name string
+		list server.WorkspaceList
+		mode server.ImportMode
+		want error
+	}{
+		{"missing list", server.WorkspaceList{ID: "7f0c7c1e-6a51-4c8e-9b1e-2f8f5a1d9c00", Append: true}, server.ImportMerge, server.ErrNotFound},
+		{"smart list", server.WorkspaceList{ID: smart.ID, Append: true}, server.ImportMerge, server.ErrInvalidInput},
+		{"replace", server.WorkspaceList{ID: home.ID, Append: true}, server.ImportReplace, server.ErrInvalidInput},
+	} {
+		doc := &server.Workspace{Version: 1, Lists: []server.WorkspaceList{tt.list}}
+		if _, err := s.ImportWorkspace(ctx, doc, tt.mode, false); !errors.Is(err, tt.want) {
+			t.Errorf("appending to a %s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func testSearch(t *testing.T, s server.Store) {
+	ctx := context.Background()
+
+	groceries := mustCreateList(t, s, "Groceries")
+	ideas := mustCreateList(t, s, "Milkshake ideas")
+	buy := mustCreateTask(t, s, groceries.ID, "Buy oat milk")
+	milk := mustCreateTask(t, s, groceries.ID, "Milk")
+	report := mustCreateTask(t, s, ideas.ID, "Write report")
+	check := mustCreateSubTask(t, s, buy.ID, "Check milk brand")
+	honey := mustCreateSubTask(t, s, buy.ID, "Milk & honey")
+
+	// Whole words outrank prefixes, and short names outrank long ones
+	hits := assertSearch(t, s, "milk", milk.ID, honey.ID, buy.ID, check.ID, ideas.ID)
+	if hits[0].Entity != "task" || hits[0].Rank != 1 || hits[0].ListID != groceries.ID || hits[0].ListTitle != "Groceries" || hits[0].TaskID != nil {
+		t.Errorf("task hit = %+v", hits[0])
+	}
+	if hit := hits[1]; hit.Entity != "subtask" || hit.TaskID == nil || *hit.TaskID != buy.ID || *hit.TaskName != "Buy oat milk" || hit.ListTitle != "Groceries" {
+		t.Errorf("subtask hit = %+v", hit)
+	}
+	if hits[1].Snippet != "<mark>Milk</mark> &amp; honey" {
+		t.Errorf("Snippet = %q, want %q", hits[1].Snippet, "<mark>Milk</mark> &amp; honey")
+	}
+	if hit := hits[4]; hit.Entity != "list" || hit.ListID != ideas.ID || hit.Snippet != "<mark>Milkshake</mark> ideas" {
+		t.Errorf("list hit = %+v", hit)
+	}
+
+	// Every term must match, in any order and regardless of case
+	assertSearch(t, s, "MILK bu", buy.ID)
+	assertSearch(t, s, "brand, milk!", check.ID)
+	assertSearch(t, s, "milk report")
+	assertSearch(t, s, "  ?! ")
+
+	// Renamed, deleted and restored rows are searched by their current state
+	renamed := "Write milk report"
+	if _, err := s.PatchTask(ctx, report.ID, server.TaskPatch{TaskName: &renamed}); err != nil {
+		t.Fatalf("PatchTask: %v", err)
+	}
+	assertSearch(t, s, "milk report", report.ID)
+	if err := s.DeleteTask(ctx, buy.ID, 0); err != nil {
+		t.Fatalf("DeleteTask: %v", err)
+	}
+	assertSearch(t, s, "milk", milk.ID, report.ID, ideas.ID)
+	if _, err := s.RestoreTask(ctx, buy.ID); err != nil {
+		t.Fatalf("RestoreTask: %v", err)
+	}
+	assertSearch(t, s, "oat", buy.ID)
+	assertSearch(t, s, "honey", honey.ID)
+}
+
+func testActivity(t *testing.T, s server.Store) {
+	ctx := context.Background()
+
+	home := mustCreateList(t, s, "Home")
+	work := mustCreateList(t, s, "Work")
+	task := mustCreateTask(t, s, home.ID, "Laundry")
+	subTask := mustCreateSubTask(t, s, task.ID, "Fold")
+
+	name := "Wash laundry"
+	if _, err := s.PatchTask(ctx, task.ID, server.TaskPatch{TaskName: &name}); err != nil {
+		t.Fatalf("PatchTask: %v", err)
+	}
+	if _, err := s.ToggleTaskCompletion(ctx, task.ID); err != nil {
+		t.Fatalf("ToggleTaskCompletion: %v", err)
+	}
+	// Reordering and writes that change nothing are not logged
+	if err := s.ReorderTasks(ctx, home.ID, []string{task.ID}); err != nil {
+		t.Fatalf("ReorderTasks: %v", err)
+	}
+	if _, err := s.PatchTask(ctx, task.ID, server.TaskPatch{TaskName: &name}); err != nil {
+		t.Fatalf("PatchTask: %v", err)
+	}
+	if _, err := s.MoveTask(ctx, task.ID, work.ID, 1); err != nil {
+		t.Fatalf("MoveTask: %v", err)
+	}
+	if _, err := s.ToggleSubTaskCompletion(ctx, subTask.ID); err != nil {
+		t.Fatalf("ToggleSubTaskCompletion: %v", err)
+	}
+	if err := s.DeleteSubTask(ctx, subTask.ID, 0); err != nil {
+		t.Fatalf("DeleteSubTask: %v", err)
+	}
+	if _, err := s.RestoreSubTask(ctx, subTask.ID); err != nil {
+		t.Fatalf("RestoreSubTask: %v", err)
+	}
+
+	activity, err := s.GetTaskActivity(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetTaskActivity: %v", err)
+	}
+	assertActions(t, activity,
+		"subtask restore", "subtask delete", "subtask toggle", "task move",
+		"task toggle", "task update", "subtask create", "task create")
+
+	update := activity[5]
+	if update.EntityID != task.ID || update.Name != name || update.TaskID == nil || *update.TaskID != task.ID {
+		t.Errorf("update entry = %+v, want task %s named %q", update, task.ID, name)
+	}
+	if len(update.Before) != 1 || update.Before["task_name"] != "Laundry" || update.After["task_name"] != name {
+		t.Errorf("update entry changes %v to %v, want only task_name", update.Before, update.After)
+	}
+	if toggle := activity[4]; toggle.Before["completed"] != false || toggle.After["completed"] != true {
+		t.Errorf("toggle entry changes %v to %v, want completed false to true", toggle.Before, toggle.After)
+	}
+	if move := activity[3]; move.ListID != work.ID || move.Before["list_id"] != home.ID || move.After["list_id"] != work.ID {
+		t.Errorf("move entry = %+v, want a move from %s to %s", move, home.ID, work.ID)
+	}
+	if deleted := activity[1]; deleted.Before["deleted_at"] != nil || deleted.After["deleted_at"] == nil {
+		t.Errorf("delete entry changes %v to %v, want deleted_at set", deleted.Before, deleted.After)
+	}
+	if create := activity[7]; create.Before != nil || create.After["task_name"] != "Laundry" || create.After["list_id"] != home.ID {
+		t.Errorf("create entry = %+v, want the new task's fields", create)
+	}
+	for i := 1; i < len(activity); i++ {
+		if activity[i].ID >= activity[i-1].ID || activity[i].At.After(activity[i-1].At) {
+			t.Errorf("activity is not newest first at %d", i)
+		}
+	}
+
+	// A moved task stays in the activity of the list it left
+	activity, err = s.GetListActivity(ctx, home.ID, time.Time{})
+	if err != nil {
+		t.Fatalf("GetListActivity: %v", err)
+	}
+	assertActions(t, activity, "task move", "task toggle", "task update", "subtask create", "task create", "list create")
+	activity, err = s.GetListActivity(ctx, work.ID, time.Time{})
+	if err != nil {
+		t.Fatalf("GetListActivity: %v", err)
+	}
+	assertActions(t, activity, "subtask restore", "subtask delete", "subtask toggle", "task move", "list create")
+	activity, err = s.GetListActivity(ctx, work.ID, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("GetListActivity: %v", err)
+	}
+	if len(activity) != 0 {
+		t.Errorf("GetListActivity since a later time returned %d entries, want 0", len(activity))
+	}
+
+	if activity, err := s.GetTaskActivity(ctx, unknownID); err != nil || len(activity) != 0 {
+		t.Errorf("GetTaskActivity for unknown id = %d entries, %v; want none", len(activity), err)
+	}
+
+	// Purging a row removes its activity
+	if err := s.DeleteTask(ctx, task.ID, 0); err != nil {
+		t.Fatalf("DeleteTask: %v", err)
+	}
+	if _, err := s.PurgeTrash(ctx, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("PurgeTrash: %v", err)
+	}
+	activity, err = s.GetTaskActivity(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetTaskActivity: %v", err)
+	}
+	if len(activity) != 0 {
+		t.Errorf("GetTaskActivity after purge returned %d entries, want 0", len(activity))
+	}
+	activity, err = s.GetListActivity(ctx, work.ID, time.Time{})
+	if err != nil {
+		t.Fatalf("GetListActivity: %v", err)
+	}
+	assertActions(t, activity, "list create")
+}
+
+func testActivityActor(t *testing.T, s server.Store) {
+	ctx := context.Background()
+
+	list := mustCreateList(t, s, "Home")
+	if err := s.SetActor(ctx, "alice"); err != nil {
+		t.Fatalf("SetActor: %v", err)
+	}
+	task := mustCreateTask(t, s, list.ID, "Laundry")
+	mustCreateSubTask(t, s, task.ID, "Fold")
+	if err := s.SetActor(ctx, "bob"); err != nil {
+		t.Fatalf("SetActor: %v", err)
+	}
+	if _, err := s.ToggleTaskCompletion(ctx, task.ID); err != nil {
+		t.Fatalf("ToggleTaskCompletion: %v", err)
+	}
+	if err := s.SetActor(ctx, ""); err != nil {
+		t.Fatalf("SetActor: %v", err)
+	}
+	if _, err := s.UpdateList(ctx, list.ID, "House", 0); err != nil {
+		t.Fatalf("UpdateList: %v", err)
+	}
+
+	activity, err := s.GetListActivity(ctx, list.ID, time.Time{})
+	if err != nil {
+		t.Fatalf("GetListActivity: %v", err)
+	}
+	assertActions(t, activity, "list update", "task toggle", "subtask create", "task create", "list create")
+	want := []string{"", "bob", "alice", "alice", ""}
+	for i, entry := range activity {
+		if entry.Actor != want[i] {
+			t.Errorf("%s %s: Actor = %q, want %q", entry.Entity, entry.Action, entry.Actor, want[i])
+		}
+	}
+}
+
+func testNotFound(t *testing.T, s server.Store) {
+	ctx := context.Background()
+	id := unknownID
+
+	if _, err := s.GetList(ctx, id); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("GetList for unknown id = %v, want ErrNotFound", err)
+	}
+	if _, err := s.UpdateList(ctx, id, "x", 0); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("UpdateList for unknown id = %v, want ErrNotFound", err)
+	}
+	if err := s.DeleteList(ctx, id, 0); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("DeleteList for unknown id = %v, want ErrNotFound", err)
+	}
+	if _, err := s.GetTask(ctx, id); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("GetTask for unknown id = %v, want ErrNotFound", err)
+	}
+	if _, err := s.UpdateTask(ctx, id, "x", false, 0); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("UpdateTask for unknown id = %v, want ErrNotFound", err)
+	}
+	if _, err := s.ToggleTaskCompletion(ctx, id); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("ToggleTaskCompletion for unknown id = %v, want ErrNotFound", err)
+	}
+	if err := s.DeleteTask(ctx, id, 0); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("DeleteTask for unknown id = %v, want ErrNotFound", err)
+	}
+	if _, err := s.GetSubTask(ctx, id); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("GetSubTask for unknown id = %v, want ErrNotFound", err)
+	}
+	if _, err := s.UpdateSubTask(ctx, id, "x", false, 0); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("UpdateSubTask for unknown id = %v, want ErrNotFound", err)
+	}
+	if _, err := s.ToggleSubTaskCompletion(ctx, id); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("ToggleSubTaskCompletion for unknown id = %v, want ErrNotFound", err)
+	}
+	if err := s.DeleteSubTask(ctx, id, 0); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("DeleteSubTask for unknown id = %v, want ErrNotFound", err)
+	}
+	if _, err := s.SetTaskDue(ctx, id, nil, nil); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("SetTaskDue for unknown id = %v, want ErrNotFound", err)
+	}
+	if _, err := s.SetTaskReminder(ctx, id, nil); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("SetTaskReminder for unknown id = %v, want ErrNotFound", err)
+	}
+	if _, err := s.SetTaskNotes(ctx, id, "x"); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("SetTaskNotes for unknown id = %v, want ErrNotFound", err)
+	}
+	if _, err := s.SetTaskPriority(ctx, id, server.PriorityLow); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("SetTaskPriority for unknown id = %v, want ErrNotFound", err)
+	}
+	if _, err := s.StarTask(ctx, id, true); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("StarTask for unknown id = %v, want ErrNotFound", err)
+	}
+	if _, err := s.SetTaskRecurrence(ctx, id, nil); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("SetTaskRecurrence for unknown id = %v, want ErrNotFound", err)
+	}
+	if _, err := s.CompleteTask(ctx, id); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("CompleteTask for unknown id = %v, want ErrNotFound", err)
+	}
+
+	tasks, err := s.GetTasksByListID(ctx, id)
+	if err != nil {
+		t.Fatalf("GetTasksByListID: %v", err)
+	}
+	if len(tasks) != 0 {
+		t.Errorf("GetTasksByListID returned %d tasks for unknown list", len(tasks))
+	}
+}
+
+func assertConflict(t *testing.T, err error, id string) {
+	t.Helper()
+	if !errors.Is(err, server.ErrConflict) {
+		t.Fatalf("got error %v, want a conflict", err)
+	}
+	var conflict *server.ConflictError
+	if !errors.As(err, &conflict) || conflict.ID != id || conflict.Current == nil {
+		t.Fatalf("conflict = %+v, want one for %s with the current row", conflict, id)
+	}
+}
+
+// assertSearch checks the ids of the hits of a search, best match first
+func assertSearch(t *testing.T, s server.Store, query string, ids ...string) []server.SearchHit {
+	t.Helper()
+	hits, err := s.Search(context.Background(), query)
+	if err != nil {
+		t.Fatalf("Search(%q): %v", query, err)
+	}
+	got := make([]string, len(hits))
+	for i, hit := range hits {
+		got[i] = hit.ID
+	}
+	assertIDs(t, "search hits", got, ids)
+	return hits
+}
+
+func assertTagNames(t *testing.T, tags []server.Tag, names ...string) {
+	t.Helper()
+	got := make([]string, len(tags))
+	for i, tag := range tags {
+		got[i] = tag.Name
+	}
+	if strings.Join(got, ", ") != strings.Join(names, ", ") {
+		t.Errorf("tags = %v, want %v", got, names)
+	}
+}
+
+func assertTasksByTags(t *testing.T, s server.Store, tagIDs []string, matchAll bool, ids ...string) {
+	t.Helper()
+	tasks, err := s.GetTasksByTags(context.Background(), tagIDs, matchAll)
+	if err != nil {
+		t.Fatalf("GetTasksByTags: %v", err)
+	}
+	assertTaskIDs(t, tasks, ids...)
+}
+
+// assertActions checks the entity and action of each entry, written as "task create"
+func assertActions(t *testing.T, activity []server.Activity, want ...string) {
+	t.Helper()
+	got := make([]string, len(activity))
+	for i, entry := range activity {
+		got[i] = entry.Entity + " " + entry.Action
+	}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("activity = %v, want %v", got, want)
+	}
+}
+
+func mustCreateList(t *testing.T, s server.Store, title string) *server.List {
+	t.Helper()
+	list, err := s.CreateList(context.Background(), title)
+	if err != nil {
+		t.Fatalf("CreateList(%q): %v", title, err)
+	}
+	return list
+}
+
+func mustCreateTask(t *testing.T, s server.Store, listID, name string) *server.Task {
+	t.Helper()
+	task, err := s.CreateTask(context.Background(), listID, name)
+	if err != nil {
+		t.Fatalf("CreateTask(%q): %v", name, err)
+	}
+	return task
+}
+
+func mustCreateSubTask(t *testing.T, s server.Store, taskID, name string) *server.SubTask {
+	t.Helper()
+	subTask, err := s.CreateSubTask(context.Background(), taskID, name)
+	if err != nil {
+		t.Fatalf("CreateSubTask(%q): %v", name, err)
+	}
+	return subTask
+}
+
+func assertListOrder(t *testing.T, lists []server.List, ids ...string) {
+	t.Helper()
+	got := make([]string, len(lists))
+	for i, list := range lists {
+		got[i] = list.ID
+	}
+	assertIDs(t, "lists", got, ids)
+}
+
+func assertTaskIDs(t *testing.T, tasks []server.Task, ids ...string) {
+	t.Helper()
+	got := make([]string, len(tasks))
+	for i, task := range tasks {
+		got[i] = task.ID
+	}
+	assertIDs(t, "tasks", got, ids)
+}
+
+func assertSubTaskIDs(t *testing.T, subTasks []server.SubTask, ids ...string) {
+	t.Helper()
+	got := make([]string, len(subTasks))
+	for i, subTask := range subTasks {
+		got[i] = subTask.ID
+	}
+	assertIDs(t, "subtasks", got, ids)
+}
+
+func treeTasks(trees []server.TaskTree) []server.Task {
+	tasks := make([]server.Task, len(trees))
+	for i, tree := range trees {
+		tasks[i] = tree.Task
+	}
+	return tasks
+}
+
+func taskIDs(tasks []server.Task) []string {
+	ids := make([]string, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	return ids
+}
+
+func assertListCount(t *testing.T, s server.Store, want int) {
+	t.Helper()
+	lists, err := s.GetAllLists(context.Background())
+	if err != nil {
+		t.Fatalf("GetAllLists: %v", err)
+	}
+	if len(lists) != want {
+		t.Fatalf("got %d lists, want %d", len(lists), want)
+	}
+}
+
+// assertSameWorkspace compares the rows of two workspace documents, ignoring
+// positions, which imports renumber
+func assertSameWorkspace(t *testing.T, got, want *server.Workspace) {
+	t.Helper()
+	normalize := func(doc *server.Workspace) string {
+		copied := *doc
+		copied.ExportedAt = time.Time{}
+		copied.Lists = nil
+		for _, list := range doc.Lists {
+			list.Position = 0
+			tasks := list.Tasks
+			list.Tasks = nil
+			for _, task := range tasks {
+				task.Position = 0
+				subTasks := task.SubTasks
+				task.SubTasks = nil
+				for _, subTask := range subTasks {
+					subTask.Position = 0
+					task.SubTasks = append(task.SubTasks, subTask)
+				}
+				list.Tasks = append(list.Tasks, task)
+			}
+			copied.Lists = append(copied.Lists, list)
+		}
+		data, err := json.Marshal(copied)
+		if err != nil {
+			t.Fatalf("json.Marshal: %v", err)
+		}
+		return string(data)
+	}
+	if got, want := normalize(got), normalize(want); got != want {
+		t.Fatalf("workspace =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func assertIDs(t *testing.T, kind string, got, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d %s %v, want %d %v", len(got), kind, got, len(want), want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("%s order = %v, want %v", kind, got, want)
+		}
+	}
+}
@@ -0,0 +1,417 @@
+// Package todotxt converts tasks to and from the todo.txt format, one task
+// per line:
+//
+//	x 2024-05-02 2024-04-30 Call Mom +Family @phone
+//	(A) 2024-05-01 Review the pull request +Work @github due:2024-05-03 id:1
+//	2024-05-01 Run the tests p:1
+//
+// A line is a task unless it refers to its parent task with p:, in which case
+// it is a subtask; tasks with subtasks are given an id: for them to refer to.
+// The list of a task is written as a +project and its tags as @contexts, with
+// white space in names replaced by dashes. Priorities are written as (A) for
+// high, (B) for medium and (C) for low; completed tasks keep theirs as pri:.
+// The completion date is the date of the last update of a completed task.
+//
+// Metadata at the end of a line is taken out of the task name when parsing and
+// written back there, so lines written by Render read back unchanged. Projects,
+// contexts and due dates elsewhere in a line are honored but stay in the name,
+// and Render does not repeat them. Notes, stars, times of day and recurrence
+// are not part of the format.
+package todotxt
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+// Keys of the key:value metadata read and written by this package
+const (
+	keyDue      = "due"
+	keyPriority = "pri"
+	keyID       = "id"
+	keyParent   = "p"
+)
+
+// Token returns a list title or tag name as a +project or @context name
+func Token(name string) string {
+	return strings.Join(strings.Fields(name), "-")
+}
+
+// Render writes the tasks and subtasks of a workspace document, list after
+// list, with dates in loc. Lists without a title, such as the lists Parse
+// appends to, are written without a project.
+func Render(doc *server.Workspace, loc *time.Location) string {
+	var b strings.Builder
+	parents := 0
+	for _, list := range doc.Lists {
+		for _, task := range list.Tasks {
+			parent := ""
+			if len(task.SubTasks) > 0 {
+				parents++
+				parent = fmt.Sprint(parents)
+			}
+			b.WriteString(renderTask(list.Title, task, parent, loc))
+			b.WriteString("\n")
+			for _, subTask := range task.SubTasks {
+				b.WriteString(renderSubTask(subTask, parent, loc))
+				b.WriteString("\n")
+			}
+		}
+	}
+	return b.String()
+}
+
+func renderTask(project string, task server.WorkspaceTask, id string, loc *time.Location) string {
+	var fields []string
+	letter := priorityLetters[task.Priority]
+	if task.Completed {
+		fields = completion(task.UpdatedAt, task.CreatedAt, loc)
+	} else {
+		if letter != "" {
+			fields = append(fields, "("+letter+")")
+		}
+		if !task.CreatedAt.IsZero() {
+			fields = append(fields, date(task.CreatedAt, loc))
+		}
+	}
+	fields = append(fields, task.TaskName)
+
+	// Metadata already in the name is not repeated
+	present := make(map[string]bool)
+	for _, field := range strings.Fields(task.TaskName) {
+		present[strings.ToLower(field)] = true
+	}
+	add := func(token string) {
+		if !present[strings.ToLower(token)] {
+			present[strings.ToLower(token)] = true
+			fields = append(fields, token)
+		}
+	}
+	if project != "" {
+		add("+" + Token(project))
+	}
+	for _, tag := range task.Tags {
+		add("@" + Token(tag))
+	}
+	if task.DueDate != nil {
+		add(keyDue + ":" + *task.DueDate)
+	} else if task.DueAt != nil {
+		add(keyDue + ":" + date(*task.DueAt, loc))
+	}
+	if task.Completed && letter != "" {
+		add(keyPriority + ":" + letter)
+	}
+	if id != "" {
+		add(keyID + ":" + id)
+	}
+	return strings.Join(fields, " ")
+}
+
+func renderSubTask(subTask server.WorkspaceSubTask, parent string, loc *time.Location) string {
+	var fields []string
+	if subTask.Completed {
+		fields = completion(subTask.UpdatedAt, subTask.CreatedAt, loc)
+	} else if !subTask.CreatedAt.IsZero() {
+		fields = append(fields, date(subTask.CreatedAt, loc))
+	}
+	return strings.Join(append(fields, subTask.SubTaskName, keyParent+":"+parent), " ")
+}
+
+// completion returns the x that starts a completed line with its dates. A
+// creation date is only written after a completion date, as one date alone is
+// read as the completion date.
+func completion(completed, created time.Time, loc *time.Location) []string {
+	fields := []string{"x"}
+	if !completed.IsZero() {
+		fields = append(fields, date(completed, loc))
+		if !created.IsZero() {
+			fields = append(fields, date(created, loc))
+		}
+	}
+	return fields
+}
+
+func date(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format(server.DateLayout)
+}
+
+var (
+	priorityLetters = map[server.Priority]string{
+		server.PriorityHigh:   "A",
+		server.PriorityMedium: "B",
+		server.PriorityLow:    "C",
+	}
+	priorityPattern = regexp.MustCompile(`^\(([A-Z])\)$`)
+	datePattern     = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}$`)
+	keyValuePattern = regexp.MustCompile(`^([^\s:]+):([^\s:/][^\s]*)$`)
+)
+
+// priority returns the priority of an (A) to (Z) letter; letters after C are
+// low
+func priority(letter string) server.Priority {
+	switch letter {
+	case "A":
+		return server.PriorityHigh
+	case "B":
+		return server.PriorityMedium
+	default:
+		return server.PriorityLow
+	}
+}
+
+// Options tell Parse where tasks go
+type Options struct {
+	// ListID is the existing list that tasks without a project are appended
+	// to; without one such tasks are rejected
+	ListID string
+	// Lists are the existing lists; a task whose project is the Token of one
+	// of their titles, regardless of case, is appended to it instead of to a
+	// new list
+	Lists []server.List
+	// Tags are the existing tags, matched to contexts like lists to projects
+	Tags []server.Tag
+	// Location is the time zone of the dates, which are read as midnight
+	Location *time.Location
+}
+
+// line is a parsed line before subtasks are attached to their parents
+type line struct {
+	number  int
+	project string
+	task    server.WorkspaceTask
+	id      string
+	parent  string
+}
+
+// Parse reads todo.txt lines into a workspace document to import by merging.
+// Each project becomes a list. Errors match server.ErrInvalidInput and give
+// the number of the offending line.
+func Parse(text string, opts Options) (*server.Workspace, error) {
+	if opts.Location == nil {
+		opts.Location = time.Local
+	}
+	lists := make(map[string]string)
+	for _, list := range opts.Lists {
+		if list.Query == nil {
+			lists[strings.ToLower(Token(list.Title))] = list.ID
+		}
+	}
+	tags := make(map[string]string)
+	for _, tag := range opts.Tags {
+		tags[strings.ToLower(Token(tag.Name))] = tag.Name
+	}
+
+	var lines []*line
+	ids := make(map[string]*line)
+	for i, text := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		parsed, err := parseLine(text, opts.Location)
+		if err != nil {
+			return nil, server.InvalidInput("line %d: %s", i+1, err)
+		}
+		parsed.number = i + 1
+		for j, name := range parsed.task.Tags {
+			if existing, ok := tags[strings.ToLower(name)]; ok {
+				parsed.task.Tags[j] = existing
+			}
+		}
+		if parsed.id != "" {
+			if _, ok := ids[parsed.id]; ok {
+				return nil, server.InvalidInput("line %d: id:%s is used by an earlier line", parsed.number, parsed.id)
+			}
+			ids[parsed.id] = parsed
+		}
+		lines = append(lines, parsed)
+	}
+
+	doc := &server.Workspace{Version: server.WorkspaceVersion}
+	listIndex := make(map[string]int)
+	var tasks []*line
+	for _, parsed := range lines {
+		if parsed.parent != "" {
+			continue
+		}
+		key := strings.ToLower(parsed.project)
+		if _, ok := listIndex[key]; !ok {
+			list := server.WorkspaceList{Title: parsed.project}
+			switch id, ok := lists[key]; {
+			case parsed.project == "" && opts.ListID == "":
+				return nil, server.InvalidInput("line %d: tasks without a +project need a list to be added to", parsed.number)
+			case parsed.project == "":
+				list = server.WorkspaceList{ID: opts.ListID, Append: true}
+			case ok:
+				list = server.WorkspaceList{ID: id, Append: true}
+			}
+			listIndex[key] = len(doc.Lists)
+			doc.Lists = append(doc.Lists, list)
+		}
+		tasks = append(tasks, parsed)
+	}
+	if len(doc.Lists) == 0 {
+		return nil, server.InvalidInput("no tasks found")
+	}
+
+	// Subtasks of subtasks are flattened into subtasks of the task at the top
+	subTasks := make(map[*line][]server.WorkspaceSubTask)
+	for _, parsed := range lines {
+		if parsed.parent == "" {
+			continue
+		}
+		top, err := topParent(parsed, ids)
+		if err != nil {
+			return nil, err
+		}
+		subTasks[top] = append(subTasks[top], server.WorkspaceSubTask{
+			SubTaskName: parsed.task.TaskName,
+			Completed:   parsed.task.Completed,
+			CreatedAt:   parsed.task.CreatedAt,
+			UpdatedAt:   parsed.task.UpdatedAt,
+		})
+	}
+	for _, parsed := range tasks {
+		parsed.task.SubTasks = subTasks[parsed]
+		list := &doc.Lists[listIndex[strings.ToLower(parsed.project)]]
+		list.Tasks = append(list.Tasks, parsed.task)
+	}
+	return doc, nil
+}
+
+// topParent follows the p: references of a subtask line up to a task line
+func topParent(parsed *line, ids map[string]*line) (*line, error) {
+	start := parsed
+	seen := map[*line]bool{parsed: true}
+	for parsed.parent != "" {
+		parent, ok := ids[parsed.parent]
+		if !ok {
+			return nil, server.InvalidInput("line %d: no line has id:%s", parsed.number, parsed.parent)
+		}
+		if seen[parent] {
+			return nil, server.InvalidInput("line %d: the parents of this line form a cycle", start.number)
+		}
+		seen[parent] = true
+		parsed = parent
+	}
+	return parsed, nil
+}
+
+// parseLine reads one line; its errors are the messages InvalidInput wraps
+func parseLine(text string, loc *time.Location) (*line, error) {
+	fields := strings.Fields(text)
+	parsed := &line{}
+	task := &parsed.task
+
+	// Dates and the priority only count at the start of the line
+	var dates []time.Time
+	readDate := func() error {
+		if len(fields) == 0 || !datePattern.MatchString(fields[0]) {
+			return nil
+		}
+		t, err := time.ParseInLocation(server.DateLayout, fields[0], loc)
+		if err != nil {
+			return fmt.Errorf("%q is not a date", fields[0])
+		}
+		dates = append(dates, t)
+		fields = fields[1:]
+		return nil
+	}
+	if fields[0] == "x" {
+		task.Completed = true
+		fields = fields[1:]
+		for range 2 {
+			if err := readDate(); err != nil {
+				return nil, err
+			}
+		}
+		if len(dates) > 0 {
+			task.UpdatedAt = dates[0]
+		}
+		if len(dates) > 1 {
+			task.CreatedAt = dates[1]
+		}
+	} else {
+		if m := priorityPattern.FindStringSubmatch(fields[0]); m != nil {
+			task.Priority = priority(m[1])
+			fields = fields[1:]
+		}
+		if err := readDate(); err != nil {
+			return nil, err
+		}
+		if len(dates) > 0 {
+			task.CreatedAt = dates[0]
+		}
+	}
+
+	// Metadata is read from the whole description, and the run of it at the
+	// end is taken out of the name. For subtasks, which have no list or tags,
+	// only p: and id: are taken out.
+	projectAt := -1
+	for i, field := range fields {
+		if strings.HasPrefix(field, "+") && len(field) > 1 {
+			projectAt = i
+		}
+	}
+	if projectAt >= 0 {
+		parsed.project = fields[projectAt][1:]
+	}
+	metadata := make([]bool, len(fields))
+	for i, field := range fields {
+		switch {
+		case i == projectAt:
+			metadata[i] = true
+		case strings.HasPrefix(field, "@") && len(field) > 1:
+			task.Tags = append(task.Tags, field[1:])
+			metadata[i] = true
+		default:
+			m := keyValuePattern.FindStringSubmatch(field)
+			if m == nil {
+				continue
+			}
+			switch key, value := strings.ToLower(m[1]), m[2]; key {
+			case keyDue:
+				if _, err := time.Parse(server.DateLayout, value); err != nil {
+					return nil, fmt.Errorf("due date %q is not a date; use YYYY-MM-DD", value)
+				}
+				task.DueDate = &value
+				metadata[i] = true
+			case keyPriority:
+				value = strings.ToUpper(value)
+				if len(value) != 1 || value[0] < 'A' || value[0] > 'Z' {
+					return nil, fmt.Errorf("pri:%s is not a priority; use a letter from A to Z", value)
+				}
+				task.Priority = priority(value)
+				metadata[i] = true
+			case keyID:
+				parsed.id = value
+				metadata[i] = true
+			case keyParent:
+				parsed.parent = value
+				metadata[i] = true
+			}
+		}
+	}
+	end := len(fields)
+	for end > 0 && metadata[end-1] {
+		field := fields[end-1]
+		if parsed.parent != "" && !strings.HasPrefix(field, keyID+":") && !strings.HasPrefix(field, keyParent+":") {
+			break
+		}
+		end--
+	}
+	task.TaskName = strings.Join(fields[:end], " ")
+	if task.TaskName == "" {
+		return nil, fmt.Errorf("task has no description")
+	}
+	if task.UpdatedAt.IsZero() {
+		task.UpdatedAt = task.CreatedAt
+	}
+	if task.Tags == nil {
+		task.Tags = []string{}
+	}
+	return parsed, nil
+}
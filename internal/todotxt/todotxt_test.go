@@ -0,0 +1,253 @@
+package todotxt
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+// inboxID is the list that tasks without a project are added to
+const inboxID = "6f1d3c2a-8b4e-4f7a-9c1d-2e3f4a5b6c7d"
+
+func options() Options {
+	return Options{ListID: inboxID, Location: time.UTC}
+}
+
+func parse(t *testing.T, text string) *server.Workspace {
+	t.Helper()
+	doc, err := Parse(text, options())
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", text, err)
+	}
+	return doc
+}
+
+// parseTask parses a single task line
+func parseTask(t *testing.T, text string) server.WorkspaceTask {
+	t.Helper()
+	doc := parse(t, text)
+	if len(doc.Lists) != 1 || len(doc.Lists[0].Tasks) != 1 {
+		t.Fatalf("Parse(%q) = %+v, want a single task", text, doc)
+	}
+	return doc.Lists[0].Tasks[0]
+}
+
+// specLines are the examples of the todo.txt format description at
+// https://github.com/todotxt/todo.txt, which are written back unchanged
+var specLines = []string{
+	// Priority
+	"(A) Thank Mom for the meatballs @phone",
+	"(B) Schedule Goodwill pickup +GarageSale @phone",
+	"Post signs around the neighborhood +GarageSale",
+	"@GroceryStore Eskimo pies",
+	"(A) Call Mom",
+	"Really gotta call Mom (A) @phone @someday",
+	"(b) Get back to the boss",
+	"(B)->Submit TPS report",
+	// Creation date
+	"2011-03-02 Document +TodoTxt task format",
+	"(A) 2011-03-02 Call Mom",
+	"(A) Call Mom 2011-03-02",
+	// Projects and contexts
+	"(A) Call Mom +Family +PeaceLoveAndHappiness @iphone @phone",
+	"Email SoAndSo at soandso@example.com",
+	"Learn how to add 2+2",
+	// Completion
+	"x 2011-03-03 Call Mom",
+	"xylophone lesson",
+	"X 2012-01-01 Make resolutions",
+	"(A) x Find ticket prices",
+	"x 2011-03-02 2011-03-01 Review Tim's pull request +TodoTxtTouch @github",
+	// Additional metadata
+	"(B) 2010-01-01 Pay the rent due:2010-01-02",
+	"x 2010-01-02 2010-01-01 Pay the rent due:2010-01-02 pri:B",
+	"Read https://github.com/todotxt/todo.txt before 10:30",
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, line := range specLines {
+		if got := Render(parse(t, line), time.UTC); got != line+"\n" {
+			t.Errorf("Render(Parse(%q)) = %q", line, got)
+		}
+	}
+
+	// All of them at once, grouped by project
+	text := strings.Join(specLines, "\n") + "\n"
+	doc := parse(t, text)
+	got := Render(doc, time.UTC)
+	if again := Render(parse(t, got), time.UTC); again != got {
+		t.Errorf("rendering a second time =\n%s\nwant\n%s", again, got)
+	}
+	if lines := strings.Count(got, "\n"); lines != len(specLines) {
+		t.Errorf("rendered %d lines, want %d", lines, len(specLines))
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	for _, tt := range []struct {
+		in, want string
+	}{
+		{"(A)  Call   Mom ", "(A) Call Mom"},
+		{"Call Mom @phone +Family", "Call Mom +Family @phone"},
+		{"Pay the rent due:2010-01-02 @home", "Pay the rent @home due:2010-01-02"},
+		{"Call Mom @phone @phone", "Call Mom @phone"},
+		{"x 2011-03-03 (A) Call Mom", "x 2011-03-03 (A) Call Mom"},
+		{"x Call Mom pri:a", "x Call Mom pri:A"},
+		{"(D) Someday", "(C) Someday"},
+	} {
+		if got := Render(parse(t, tt.in), time.UTC); got != tt.want+"\n" {
+			t.Errorf("Render(Parse(%q)) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFields(t *testing.T) {
+	task := parseTask(t, "(A) Call Mom +Family +PeaceLoveAndHappiness @iphone @phone")
+	if task.TaskName != "Call Mom +Family" || task.Priority != server.PriorityHigh || task.Completed ||
+		strings.Join(task.Tags, ",") != "iphone,phone" {
+		t.Errorf("task = %+v", task)
+	}
+	doc := parse(t, "(A) Call Mom +Family +PeaceLoveAndHappiness @iphone @phone")
+	if doc.Lists[0].Title != "PeaceLoveAndHappiness" || doc.Lists[0].Append {
+		t.Errorf("list = %+v, want a new list named after the last project", doc.Lists[0])
+	}
+
+	task = parseTask(t, "x 2011-03-02 2011-03-01 Review Tim's pull request +TodoTxtTouch @github")
+	completed := time.Date(2011, 3, 2, 0, 0, 0, 0, time.UTC)
+	created := time.Date(2011, 3, 1, 0, 0, 0, 0, time.UTC)
+	if !task.Completed || !task.UpdatedAt.Equal(completed) || !task.CreatedAt.Equal(created) || task.TaskName != "Review Tim's pull request" {
+		t.Errorf("completed task = %+v", task)
+	}
+
+	task = parseTask(t, "(B) 2010-01-01 Pay the rent due:2010-01-02")
+	if task.Priority != server.PriorityMedium || task.DueDate == nil || *task.DueDate != "2010-01-02" || !task.CreatedAt.Equal(task.UpdatedAt) {
+		t.Errorf("task with a due date = %+v", task)
+	}
+
+	for _, line := range []string{"Really gotta call Mom (A) @phone @someday", "(b) Get back to the boss", "(B)->Submit TPS report", "X 2012-01-01 Make resolutions"} {
+		if task := parseTask(t, line); task.Priority != "" || task.Completed {
+			t.Errorf("Parse(%q) = %+v, want an open task without priority", line, task)
+		}
+	}
+
+	doc = parse(t, "(A) Thank Mom for the meatballs @phone")
+	if list := doc.Lists[0]; list.ID != inboxID || !list.Append {
+		t.Errorf("list = %+v, want tasks without a project added to the given list", list)
+	}
+}
+
+func TestSubTasks(t *testing.T) {
+	text := "(A) 2024-05-01 Plan the trip +Travel id:1\n" +
+		"2024-05-01 Book flights p:1\n" +
+		"x 2024-05-03 2024-05-01 Renew passport p:1\n" +
+		"Pack p:1\n"
+	doc := parse(t, text)
+	if got := Render(doc, time.UTC); got != text {
+		t.Errorf("Render(Parse()) =\n%s\nwant\n%s", got, text)
+	}
+	task := doc.Lists[0].Tasks[0]
+	if len(doc.Lists[0].Tasks) != 1 || len(task.SubTasks) != 3 || !task.SubTasks[1].Completed || task.SubTasks[2].SubTaskName != "Pack" {
+		t.Errorf("tasks = %+v, want one task with three subtasks", doc.Lists[0].Tasks)
+	}
+
+	// Parents may come after their subtasks, and subtasks of subtasks are
+	// flattened
+	doc = parse(t, "Pack socks p:b\nPack p:a id:b\nTrip +Travel id:a\n")
+	if task := doc.Lists[0].Tasks; len(task) != 1 || len(task[0].SubTasks) != 2 {
+		t.Errorf("tasks = %+v, want one task with two subtasks", task)
+	}
+
+	// Subtasks keep projects and contexts in their names
+	doc = parse(t, "Trip +Travel id:1\nPack @home p:1\n")
+	if name := doc.Lists[0].Tasks[0].SubTasks[0].SubTaskName; name != "Pack @home" {
+		t.Errorf("subtask name = %q, want %q", name, "Pack @home")
+	}
+}
+
+func TestWorkspace(t *testing.T) {
+	due := "2024-05-10"
+	created := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	updated := time.Date(2024, 5, 2, 23, 30, 0, 0, time.UTC)
+	doc := &server.Workspace{Version: server.WorkspaceVersion, Lists: []server.WorkspaceList{
+		{Title: "Home Chores", Tasks: []server.WorkspaceTask{
+			{TaskName: "Laundry", Priority: server.PriorityLow, Tags: []string{"Deep Work", "home"}, DueDate: &due, CreatedAt: created, UpdatedAt: created,
+				SubTasks: []server.WorkspaceSubTask{{SubTaskName: "Fold", Completed: true, CreatedAt: created, UpdatedAt: updated}}},
+			{TaskName: "Dishes", Completed: true, Priority: server.PriorityHigh, Tags: []string{}, CreatedAt: created, UpdatedAt: updated},
+		}},
+		{Title: "Work", Tasks: []server.WorkspaceTask{{TaskName: "Report", Priority: server.PriorityNone, Tags: []string{}, CreatedAt: created, UpdatedAt: created}}},
+	}}
+	want := "(C) 2024-05-01 Laundry +Home-Chores @Deep-Work @home due:2024-05-10 id:1\n" +
+		"x 2024-05-02 2024-05-01 Fold p:1\n" +
+		"x 2024-05-02 2024-05-01 Dishes +Home-Chores pri:A\n" +
+		"2024-05-01 Report +Work\n"
+	got := Render(doc, time.UTC)
+	if got != want {
+		t.Fatalf("Render =\n%s\nwant\n%s", got, want)
+	}
+
+	// Dates are written in the given time zone
+	if got := Render(doc, time.FixedZone("UTC+1", 3600)); !strings.HasPrefix(got, "(C) 2024-05-01 Laundry") || !strings.Contains(got, "x 2024-05-03 2024-05-01 Fold") {
+		t.Errorf("Render in UTC+1 =\n%s", got)
+	}
+
+	parsed := parse(t, got)
+	if len(parsed.Lists) != 2 || parsed.Lists[0].Title != "Home-Chores" || parsed.Lists[1].Title != "Work" {
+		t.Fatalf("lists = %+v", parsed.Lists)
+	}
+	laundry := parsed.Lists[0].Tasks[0]
+	if laundry.TaskName != "Laundry" || laundry.Priority != server.PriorityLow || strings.Join(laundry.Tags, ",") != "Deep-Work,home" ||
+		laundry.DueDate == nil || *laundry.DueDate != due || len(laundry.SubTasks) != 1 || !laundry.SubTasks[0].Completed {
+		t.Errorf("parsed task = %+v", laundry)
+	}
+	if dishes := parsed.Lists[0].Tasks[1]; !dishes.Completed || dishes.Priority != server.PriorityHigh || !dishes.UpdatedAt.Equal(time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("parsed completed task = %+v", dishes)
+	}
+}
+
+func TestExistingLists(t *testing.T) {
+	opts := options()
+	opts.Lists = []server.List{
+		{ID: "0b7f7f5e-9d0c-4f9a-8a8e-3f1c2d4e5f60", Title: "Garage Sale"},
+		{ID: "1c8f8f6e-0d1c-4f0a-9a9e-4f2d3e5f6071", Title: "Family", Query: new(string)},
+	}
+	opts.Tags = []server.Tag{{Name: "Deep Work"}}
+	doc, err := Parse("Post signs +garage-sale @deep-work\nCall Mom +Family\n", opts)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(doc.Lists) != 2 || doc.Lists[0].ID != opts.Lists[0].ID || !doc.Lists[0].Append {
+		t.Fatalf("lists = %+v, want the tasks of +garage-sale added to Garage Sale", doc.Lists)
+	}
+	if tags := doc.Lists[0].Tasks[0].Tags; len(tags) != 1 || tags[0] != "Deep Work" {
+		t.Errorf("tags = %q, want the existing Deep Work tag", tags)
+	}
+	if list := doc.Lists[1]; list.Append || list.Title != "Family" {
+		t.Errorf("list = %+v, want a new list rather than the smart list", list)
+	}
+}
+
+func TestRejected(t *testing.T) {
+	for _, tt := range []struct {
+		text string
+		opts Options
+	}{
+		{"", options()},
+		{"Call Mom", Options{}},
+		{"x 2011-03-03", options()},
+		{"(A) +Family", options()},
+		{"Pay the rent due:tomorrow", options()},
+		{"Pay the rent due:2010-02-30", options()},
+		{"Call Mom pri:AA", options()},
+		{"Pack p:1", options()},
+		{"Trip id:1\nPlan id:1", options()},
+		{"Trip +Travel\nPack p:2 id:1\nUnpack p:1 id:2", options()},
+		{"2011-13-01 Call Mom", options()},
+	} {
+		if _, err := Parse(tt.text, tt.opts); !errors.Is(err, server.ErrInvalidInput) {
+			t.Errorf("Parse(%q): err = %v, want ErrInvalidInput", tt.text, err)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package server
+
+// BuildTrees nests tasks under lists and subtasks under tasks, keeping the
+// order of each slice, and counts what is completed. Tasks and subtasks whose
+// list or task is not given are left out. Slices are never nil, so trees
+// marshal to empty arrays.
+func BuildTrees(lists []List, tasks []Task, subTasks []SubTask) []ListTree {
+	subTasksByTask := make(map[string][]SubTask)
+	for _, subTask := range subTasks {
+		subTasksByTask[subTask.TaskID] = append(subTasksByTask[subTask.TaskID], subTask)
+	}
+
+	tasksByList := make(map[string][]TaskTree)
+	for _, task := range tasks {
+		tree := TaskTree{Task: task, SubTasks: subTasksByTask[task.ID]}
+		if tree.SubTasks == nil {
+			tree.SubTasks = []SubTask{}
+		}
+		for _, subTask := range tree.SubTasks {
+			if subTask.Completed {
+				tree.CompletedSubTasks++
+			}
+		}
+		tree.TotalSubTasks = len(tree.SubTasks)
+		tasksByList[task.ListID] = append(tasksByList[task.ListID], tree)
+	}
+
+	trees := make([]ListTree, 0, len(lists))
+	for _, list := range lists {
+		tree := ListTree{List: list, Smart: list.Query != nil, Tasks: tasksByList[list.ID]}
+		if tree.Tasks == nil {
+			tree.Tasks = []TaskTree{}
+		}
+		for _, task := range tree.Tasks {
+			if task.Task.Completed {
+				tree.CompletedTasks++
+			}
+		}
+		tree.TotalTasks = len(tree.Tasks)
+		trees = append(trees, tree)
+	}
+	return trees
+}
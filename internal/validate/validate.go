@@ -0,0 +1,228 @@
+// Package validate cleans and checks user input before it reaches a store.
+//
+// A Validator collects the problems of every checked argument so a call can
+// report all of them at once:
+//
+//	v := validate.New()
+//	listID = v.ID("list_id", listID)
+//	name = v.Name("task_name", name)
+//	if err := v.Err(); err != nil {
+//		return nil, err
+//	}
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	server "github.com/HolySxn/To-Do/internal"
+	"github.com/HolySxn/To-Do/internal/query"
+	"github.com/google/uuid"
+)
+
+// MaxNameLength matches the VARCHAR(255) title and name columns, counted in
+// characters rather than bytes
+const MaxNameLength = 255
+
+// Page sizes in rows
+const (
+	// DefaultPageSize is used when a caller passes a page size of 0
+	DefaultPageSize = 100
+	// MaxPageSize bounds how many rows one call loads
+	MaxPageSize = 1000
+)
+
+// Validator accumulates field errors; the zero value is ready to use
+type Validator struct {
+	fields []server.FieldError
+}
+
+// New returns an empty Validator
+func New() *Validator {
+	return &Validator{}
+}
+
+// Err returns a *server.ValidationError listing every rejected field, or nil
+func (v *Validator) Err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+	return &server.ValidationError{Fields: v.fields}
+}
+
+func (v *Validator) fail(field, format string, args ...any) {
+	v.fields = append(v.fields, server.FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// Name cleans a single-line title or name, which must not be empty once
+// control characters and surrounding white space are removed
+func (v *Validator) Name(field, value string) string {
+	value = CleanLine(value)
+	switch n := utf8.RuneCountInString(value); {
+	case n == 0:
+		v.fail(field, "must not be empty")
+	case n > MaxNameLength:
+		v.fail(field, "must be at most %d characters, got %d", MaxNameLength, n)
+	}
+	return value
+}
+
+// OptionalName checks value with Name unless it is nil
+func (v *Validator) OptionalName(field string, value *string) *string {
+	if value == nil {
+		return nil
+	}
+	name := v.Name(field, *value)
+	return &name
+}
+
+// ID checks that value is a UUID and returns it in canonical lower-case form
+func (v *Validator) ID(field, value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		v.fail(field, "is required")
+		return value
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		v.fail(field, "must be a UUID, got %q", value)
+		return value
+	}
+	return id.String()
+}
+
+// IDs checks every element with ID, naming them field[0], field[1] and so on
+func (v *Validator) IDs(field string, values []string) []string {
+	ids := make([]string, len(values))
+	for i, value := range values {
+		ids[i] = v.ID(fmt.Sprintf("%s[%d]", field, i), value)
+	}
+	return ids
+}
+
+// PageSize checks a page size, replacing 0 with DefaultPageSize
+func (v *Validator) PageSize(field string, size int) int {
+	if size == 0 {
+		return DefaultPageSize
+	}
+	if size < 1 || size > MaxPageSize {
+		v.fail(field, "must be between 1 and %d, got %d", MaxPageSize, size)
+	}
+	return size
+}
+
+// Cursor checks that value is empty or a cursor returned with a previous page
+func (v *Validator) Cursor(field, value string) string {
+	value = strings.TrimSpace(value)
+	if _, err := server.DecodeCursor(value); err != nil {
+		v.fail(field, "must be the next_cursor of a previous page")
+	}
+	return value
+}
+
+// Query cleans a task query and checks that it parses; see package query
+func (v *Validator) Query(field, value string) string {
+	value = CleanLine(value)
+	var syntax *query.SyntaxError
+	if _, err := query.Parse(value, time.Now()); errors.As(err, &syntax) {
+		v.fail(field, "%s", syntax.Message)
+	}
+	return value
+}
+
+// OptionalQuery checks value with Query unless it is nil
+func (v *Validator) OptionalQuery(field string, value *string) *string {
+	if value == nil {
+		return nil
+	}
+	q := v.Query(field, *value)
+	return &q
+}
+
+// Color checks a #rrggbb color and returns it in lower case; an empty color
+// stands for the default one
+func (v *Validator) Color(field, value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return value
+	}
+	if len(value) != 7 || value[0] != '#' || strings.Trim(value[1:], "0123456789abcdef") != "" {
+		v.fail(field, "must be a color such as #1e90ff, got %q", value)
+	}
+	return value
+}
+
+// Workspace cleans the names, notes, colors and queries of a workspace document
+// in place, naming fields after their path such as lists[0].tasks[2].task_name
+func (v *Validator) Workspace(field string, doc *server.Workspace) {
+	if doc == nil {
+		v.fail(field, "is required")
+		return
+	}
+	for i := range doc.Tags {
+		tag := &doc.Tags[i]
+		path := fmt.Sprintf("%s.tags[%d]", field, i)
+		tag.Name = v.Name(path+".name", tag.Name)
+		tag.Color = v.Color(path+".color", tag.Color)
+	}
+	for i := range doc.Lists {
+		list := &doc.Lists[i]
+		path := fmt.Sprintf("%s.lists[%d]", field, i)
+		if list.Append {
+			list.ID = v.ID(path+".id", list.ID)
+		} else {
+			list.Title = v.Name(path+".title", list.Title)
+			list.Query = v.OptionalQuery(path+".query", list.Query)
+		}
+		for j := range list.Tasks {
+			task := &list.Tasks[j]
+			path := fmt.Sprintf("%s.tasks[%d]", path, j)
+			task.TaskName = v.Name(path+".task_name", task.TaskName)
+			task.Notes = CleanText(task.Notes)
+			for k, name := range task.Tags {
+				task.Tags[k] = v.Name(fmt.Sprintf("%s.tags[%d]", path, k), name)
+			}
+			for k := range task.SubTasks {
+				subTask := &task.SubTasks[k]
+				subTask.SubTaskName = v.Name(fmt.Sprintf("%s.subtasks[%d].subtask_name", path, k), subTask.SubTaskName)
+			}
+		}
+	}
+}
+
+// CleanLine drops invalid UTF-8 and control characters, turning line breaks
+// and tabs into spaces, and trims surrounding white space
+func CleanLine(value string) string {
+	return strings.TrimSpace(strings.Map(func(r rune) rune {
+		switch {
+		case r == '\t' || r == '\n' || r == '\r':
+			return ' '
+		case unicode.IsControl(r):
+			return -1
+		}
+		return r
+	}, strings.ToValidUTF8(value, "")))
+}
+
+// CleanText drops invalid UTF-8 and control characters from multi-line text
+// such as notes, keeping line breaks and tabs with CRLF and CR normalized to
+// LF. Unlike CleanLine it leaves surrounding white space, so indentation at
+// the start of the text survives, and it sets no length limit
+func CleanText(value string) string {
+	value = strings.ReplaceAll(strings.ToValidUTF8(value, ""), "\r\n", "\n")
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\r':
+			return '\n'
+		case r == '\t' || r == '\n':
+			return r
+		case unicode.IsControl(r):
+			return -1
+		}
+		return r
+	}, value)
+}
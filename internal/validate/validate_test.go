@@ -0,0 +1,120 @@
+package validate
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+func TestName(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+		ok    bool
+	}{
+		{"Groceries", "Groceries", true},
+		{"  Buy\tmilk\r\n", "Buy milk", true},
+		{"Bell\x07", "Bell", true},
+		{"bad \xff utf-8", "bad  utf-8", true},
+		{"", "", false},
+		{" \n\t", "", false},
+		{"\x00\x01", "", false},
+		{strings.Repeat("é", MaxNameLength), strings.Repeat("é", MaxNameLength), true},
+		{strings.Repeat("é", MaxNameLength+1), strings.Repeat("é", MaxNameLength+1), false},
+	}
+	for _, tt := range tests {
+		v := New()
+		got := v.Name("name", tt.value)
+		if got != tt.want {
+			t.Errorf("Name(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+		if err := v.Err(); (err == nil) != tt.ok {
+			t.Errorf("Name(%q) error = %v, want ok %v", tt.value, err, tt.ok)
+		}
+	}
+}
+
+func TestCleanText(t *testing.T) {
+	for _, tt := range []struct{ value, want string }{
+		{"line one\r\nline two\rthree\x00\t \n", "line one\nline two\nthree\t \n"},
+		{"    indented code\n", "    indented code\n"},
+		{"", ""},
+	} {
+		if got := CleanText(tt.value); got != tt.want {
+			t.Errorf("CleanText(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestID(t *testing.T) {
+	v := New()
+	if got := v.ID("id", " 3F2504E0-4F89-11D3-9A0C-0305E82C3301 "); got != "3f2504e0-4f89-11d3-9a0c-0305e82c3301" {
+		t.Errorf("ID() = %q, want the canonical form", got)
+	}
+	if err := v.Err(); err != nil {
+		t.Fatal(err)
+	}
+	v.ID("list_id", "")
+	v.IDs("task_ids", []string{"3f2504e0-4f89-11d3-9a0c-0305e82c3301", "nope"})
+	var validation *server.ValidationError
+	if !errors.As(v.Err(), &validation) {
+		t.Fatalf("Err() = %v, want a ValidationError", v.Err())
+	}
+	want := []server.FieldError{
+		{Field: "list_id", Message: "is required"},
+		{Field: "task_ids[1]", Message: `must be a UUID, got "nope"`},
+	}
+	if len(validation.Fields) != len(want) {
+		t.Fatalf("Fields = %+v, want %+v", validation.Fields, want)
+	}
+	for i := range want {
+		if validation.Fields[i] != want[i] {
+			t.Errorf("Fields[%d] = %+v, want %+v", i, validation.Fields[i], want[i])
+		}
+	}
+}
+
+func TestColor(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+		ok    bool
+	}{
+		{"#1E90FF", "#1e90ff", true},
+		{" #00ff00 ", "#00ff00", true},
+		{"", "", true},
+		{"red", "red", false},
+		{"#fff", "#fff", false},
+		{"#12345g", "#12345g", false},
+	}
+	for _, tt := range tests {
+		v := New()
+		if got := v.Color("color", tt.value); got != tt.want {
+			t.Errorf("Color(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+		if err := v.Err(); (err == nil) != tt.ok {
+			t.Errorf("Color(%q) error = %v, want ok %v", tt.value, err, tt.ok)
+		}
+	}
+}
+
+func TestOptional(t *testing.T) {
+	v := New()
+	if v.OptionalName("title", nil) != nil {
+		t.Error("optional checks of nil returned a value")
+	}
+	name := "  Title "
+	if got := v.OptionalName("title", &name); got == nil || *got != "Title" {
+		t.Errorf("OptionalName() = %v, want Title", got)
+	}
+	if err := v.Err(); err != nil {
+		t.Fatal(err)
+	}
+	empty := ""
+	v.OptionalName("title", &empty)
+	if v.Err() == nil {
+		t.Error("OptionalName() accepted an empty title")
+	}
+}
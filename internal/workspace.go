@@ -0,0 +1,585 @@
+package server
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WorkspaceVersion is the version of the Workspace documents written by
+// ExportWorkspace. Documents of a later version are rejected on import.
+const WorkspaceVersion = 1
+
+// TaskTag is the assignment of a tag to a task
+type TaskTag struct {
+	TaskID string
+	TagID  string
+}
+
+// BuildWorkspace nests rows read by a store into a Workspace. Tasks and
+// subtasks are expected in display order.
+func BuildWorkspace(lists []List, tasks []Task, subTasks []SubTask, tags []Tag, taskTags []TaskTag, now time.Time) *Workspace {
+	doc := &Workspace{
+		Version:    WorkspaceVersion,
+		ExportedAt: now.UTC(),
+		Tags:       []WorkspaceTag{},
+		Lists:      []WorkspaceList{},
+	}
+
+	tagNames := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		tagNames[tag.ID] = tag.Name
+		doc.Tags = append(doc.Tags, WorkspaceTag{Name: tag.Name, Color: tag.Color})
+	}
+	taskTagNames := make(map[string][]string)
+	for _, taskTag := range taskTags {
+		if name, ok := tagNames[taskTag.TagID]; ok {
+			taskTagNames[taskTag.TaskID] = append(taskTagNames[taskTag.TaskID], name)
+		}
+	}
+
+	for _, tree := range BuildTrees(lists, tasks, subTasks) {
+		list := WorkspaceList{
+			ID:        tree.List.ID,
+			Title:     tree.List.Title,
+			Query:     tree.List.Query,
+			Position:  tree.List.Position,
+			CreatedAt: tree.List.CreatedAt.UTC(),
+			UpdatedAt: tree.List.UpdatedAt.UTC(),
+			Tasks:     []WorkspaceTask{},
+		}
+		for _, taskTree := range tree.Tasks {
+			task := taskTree.Task
+			names := taskTagNames[task.ID]
+			if names == nil {
+				names = []string{}
+			}
+			slices.SortFunc(names, func(a, b string) int { return strings.Compare(strings.ToLower(a), strings.ToLower(b)) })
+			exported := WorkspaceTask{
+				ID:         task.ID,
+				TaskName:   task.TaskName,
+				Notes:      task.Notes,
+				Completed:  task.Completed,
+				Priority:   task.Priority,
+				Starred:    task.Starred,
+				Position:   task.Position,
+				DueDate:    task.DueDate,
+				DueAt:      utcTime(task.DueAt),
+				RemindAt:   utcTime(task.RemindAt),
+				Recurrence: task.Recurrence,
+				Tags:       names,
+				CreatedAt:  task.CreatedAt.UTC(),
+				UpdatedAt:  task.UpdatedAt.UTC(),
+				SubTasks:   []WorkspaceSubTask{},
+			}
+			for _, subTask := range taskTree.SubTasks {
+				exported.SubTasks = append(exported.SubTasks, WorkspaceSubTask{
+					ID:          subTask.ID,
+					SubTaskName: subTask.SubTaskName,
+					Completed:   subTask.Completed,
+					Position:    subTask.Position,
+					CreatedAt:   subTask.CreatedAt.UTC(),
+					UpdatedAt:   subTask.UpdatedAt.UTC(),
+				})
+			}
+			list.Tasks = append(list.Tasks, exported)
+		}
+		doc.Lists = append(doc.Lists, list)
+	}
+
+	return doc
+}
+
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
+
+// ImportState is what planning an import needs to know about a workspace:
+// every list, task, subtask and tag, including the trash, and which tasks have
+// which tags
+type ImportState struct {
+	Lists    []List
+	Tasks    []Task
+	SubTasks []SubTask
+	Tags     []Tag
+	TaskTags []TaskTag
+	// CheckQuery returns an error for a smart list query that does not parse.
+	// Package query depends on this one, so stores pass it in.
+	CheckQuery func(query string) error
+}
+
+// ImportPlan holds the rows an import writes, with their final ids and
+// positions, and the report to return
+type ImportPlan struct {
+	Report ImportReport
+	// Lists, Tasks and SubTasks hold the rows to insert
+	Lists    []List
+	Tasks    []Task
+	SubTasks []SubTask
+	// UpdatedLists, UpdatedTasks and UpdatedSubTasks hold existing rows with
+	// the fields of the document, their version bumped and the update time set
+	UpdatedLists    []List
+	UpdatedTasks    []Task
+	UpdatedSubTasks []SubTask
+	// Tags holds only the tags to create; existing tags with the same name,
+	// regardless of case, are reused when merging
+	Tags []Tag
+	// TaskTags holds only the assignments to add
+	TaskTags []TaskTag
+}
+
+// PlanImport checks doc and works out the rows importing it writes to a
+// workspace in the given state.
+//
+// When merging, a list, task or subtask whose id is that of a live row of the
+// same kind updates that row in place: its fields take the document's values,
+// tags are added to it and new children are appended, but it keeps its parent
+// and position. A smart list only matches a smart list and a plain list a plain
+// one. Other rows are created and keep their ids unless an id is missing, is
+// not a UUID or is taken, by a row that doesn't match, by a row in the trash
+// or by an earlier row of doc; those rows get new ids, and all but the missing
+// ones are reported as remapped. Importing the same document twice thus
+// changes nothing the second time.
+//
+// Created lists, tasks and subtasks are numbered in the order of their
+// positions, after the existing ones of their parent. Missing timestamps are
+// set to the update time or, without one, to now.
+func PlanImport(doc *Workspace, mode ImportMode, state ImportState, now time.Time) (*ImportPlan, error) {
+	if doc == nil {
+		return nil, InvalidInput("missing workspace document")
+	}
+	if doc.Version < 1 || doc.Version > WorkspaceVersion {
+		return nil, InvalidInput("unsupported workspace version %d; versions 1 to %d can be imported", doc.Version, WorkspaceVersion)
+	}
+	if !mode.Valid() {
+		return nil, InvalidInput("import mode must be %q or %q, got %q", ImportMerge, ImportReplace, mode)
+	}
+
+	p := &importPlanner{
+		plan:        &ImportPlan{Report: ImportReport{Mode: mode, Remapped: map[string]string{}, Rows: []ImportRow{}}},
+		taken:       make(map[string]bool),
+		used:        make(map[string]bool),
+		lists:       make(map[string]List),
+		tasks:       make(map[string]Task),
+		subTasks:    make(map[string]SubTask),
+		taskTags:    make(map[TaskTag]bool),
+		tags:        make(map[string]string),
+		lastTask:    make(map[string]int),
+		lastSubTask: make(map[string]int),
+		checkQuery:  state.CheckQuery,
+		now:         now.UTC(),
+	}
+	if mode == ImportMerge {
+		p.addState(state)
+	} else {
+		p.plan.Report.Deleted = ImportCounts{
+			Lists:    len(state.Lists),
+			Tasks:    len(state.Tasks),
+			SubTasks: len(state.SubTasks),
+			Tags:     len(state.Tags),
+		}
+	}
+
+	for _, tag := range doc.Tags {
+		if _, err := p.tag(tag.Name, tag.Color); err != nil {
+			return nil, err
+		}
+	}
+	for _, list := range byPosition(doc.Lists, func(list WorkspaceList) int { return list.Position }) {
+		if list.Append {
+			if mode != ImportMerge {
+				return nil, InvalidInput("tasks can only be added to an existing list when merging")
+			}
+			if err := p.appendTasks(list); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := p.addList(list); err != nil {
+			return nil, err
+		}
+	}
+
+	p.plan.Report.Created = ImportCounts{
+		Lists:    len(p.plan.Lists),
+		Tasks:    len(p.plan.Tasks),
+		SubTasks: len(p.plan.SubTasks),
+		Tags:     len(p.plan.Tags),
+	}
+	return p.plan, nil
+}
+
+type importPlanner struct {
+	plan *ImportPlan
+	// taken holds the ids of existing rows and used the ids given to rows
+	// of the document so far
+	taken map[string]bool
+	used  map[string]bool
+	// lists, tasks and subTasks hold the live rows that imported rows may
+	// update, and taskTags their tags
+	lists    map[string]List
+	tasks    map[string]Task
+	subTasks map[string]SubTask
+	taskTags map[TaskTag]bool
+	// tags maps lower-case tag names to ids
+	tags map[string]string
+	// lastList, lastTask and lastSubTask are the highest positions taken,
+	// including the trash, among lists and by parent id
+	lastList    int
+	lastTask    map[string]int
+	lastSubTask map[string]int
+	checkQuery  func(query string) error
+	now         time.Time
+}
+
+// addState records the rows of a workspace that is merged into
+func (p *importPlanner) addState(state ImportState) {
+	for _, list := range state.Lists {
+		p.taken[list.ID] = true
+		p.lastList = max(p.lastList, list.Position)
+		if list.DeletedAt == nil {
+			p.lists[list.ID] = list
+		}
+	}
+	for _, task := range state.Tasks {
+		p.taken[task.ID] = true
+		p.lastTask[task.ListID] = max(p.lastTask[task.ListID], task.Position)
+		if task.DeletedAt == nil {
+			p.tasks[task.ID] = task
+		}
+	}
+	for _, subTask := range state.SubTasks {
+		p.taken[subTask.ID] = true
+		p.lastSubTask[subTask.TaskID] = max(p.lastSubTask[subTask.TaskID], subTask.Position)
+		if subTask.DeletedAt == nil {
+			p.subTasks[subTask.ID] = subTask
+		}
+	}
+	for _, taskTag := range state.TaskTags {
+		p.taskTags[taskTag] = true
+	}
+	for _, tag := range state.Tags {
+		p.tags[strings.ToLower(tag.Name)] = tag.ID
+	}
+}
+
+// id returns the id an imported row is given, and whether it is that of an
+// existing row that matches reports the row updates
+func (p *importPlanner) id(id string, matches func(id string) bool) (string, bool) {
+	if parsed, err := uuid.Parse(id); err == nil && !p.used[parsed.String()] {
+		id := parsed.String()
+		if matches(id) {
+			p.used[id] = true
+			return id, true
+		}
+		if !p.taken[id] {
+			p.used[id] = true
+			return id, false
+		}
+	}
+	newID := uuid.NewString()
+	p.used[newID] = true
+	if id != "" {
+		p.plan.Report.Remapped[id] = newID
+	}
+	return newID, false
+}
+
+// report adds the row of the report for an imported row
+func (p *importPlanner) report(entity, id, sourceID, name string, matched bool, changed []string) {
+	row := ImportRow{Entity: entity, ID: id, Name: name, Action: ImportCreate}
+	if _, ok := p.plan.Report.Remapped[sourceID]; ok && sourceID != id {
+		row.SourceID = sourceID
+	}
+	if matched {
+		row.Action, row.Changed = ImportUnchanged, changed
+		if len(changed) > 0 {
+			row.Action = ImportUpdate
+		}
+	}
+	p.plan.Report.Rows = append(p.plan.Report.Rows, row)
+}
+
+// tag returns the id of the tag with the given name, creating it if needed
+func (p *importPlanner) tag(name, color string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", InvalidInput("tag names must not be empty")
+	}
+	if id, ok := p.tags[strings.ToLower(name)]; ok {
+		return id, nil
+	}
+	tag := Tag{ID: uuid.NewString(), Name: name, Color: strings.ToLower(color), CreatedAt: p.now, UpdatedAt: p.now}
+	p.tags[strings.ToLower(name)] = tag.ID
+	p.plan.Tags = append(p.plan.Tags, tag)
+	p.report("tag", tag.ID, "", tag.Name, false, nil)
+	return tag.ID, nil
+}
+
+// timestamps fills in missing timestamps; a row that was only updated at a
+// known time is taken to have been created then
+func (p *importPlanner) timestamps(created, updated time.Time) (time.Time, time.Time) {
+	if created.IsZero() {
+		created = updated
+	}
+	if created.IsZero() {
+		created = p.now
+	}
+	if updated.IsZero() {
+		updated = created
+	}
+	return created.UTC(), updated.UTC()
+}
+
+func (p *importPlanner) addList(doc WorkspaceList) error {
+	if strings.TrimSpace(doc.Title) == "" {
+		return InvalidInput("list %d has no title", doc.Position)
+	}
+	if doc.Query != nil {
+		if err := p.checkQuery(*doc.Query); err != nil {
+			return fmt.Errorf("list %q: %w", doc.Title, err)
+		}
+	}
+	id, matched := p.id(doc.ID, func(id string) bool {
+		list, ok := p.lists[id]
+		return ok && (list.Query != nil) == (doc.Query != nil)
+	})
+	if doc.Query != nil && len(doc.Tasks) > 0 {
+		return SmartListTasks(id)
+	}
+
+	var list List
+	if matched {
+		list = p.lists[id]
+		var changed []string
+		if list.Title != doc.Title {
+			list.Title = doc.Title
+			changed = append(changed, "title")
+		}
+		if !sameString(list.Query, doc.Query) {
+			list.Query = doc.Query
+			changed = append(changed, "query")
+		}
+		if len(changed) > 0 {
+			list.Version++
+			list.UpdatedAt = p.now
+			p.plan.UpdatedLists = append(p.plan.UpdatedLists, list)
+			p.plan.Report.Updated.Lists++
+		}
+		p.report("list", list.ID, doc.ID, list.Title, true, changed)
+	} else {
+		p.lastList++
+		list = List{ID: id, Title: doc.Title, Query: doc.Query, Position: p.lastList, Version: 1}
+		list.CreatedAt, list.UpdatedAt = p.timestamps(doc.CreatedAt, doc.UpdatedAt)
+		p.plan.Lists = append(p.plan.Lists, list)
+		p.report("list", list.ID, doc.ID, list.Title, false, nil)
+	}
+
+	for _, task := range byPosition(doc.Tasks, func(task WorkspaceTask) int { return task.Position }) {
+		if err := p.addTask(list, task); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendTasks adds the tasks of doc to the existing list it names
+func (p *importPlanner) appendTasks(doc WorkspaceList) error {
+	list, ok := p.lists[doc.ID]
+	if !ok {
+		return NotFound("list", doc.ID)
+	}
+	if list.Query != nil {
+		return SmartListTasks(doc.ID)
+	}
+	for _, task := range byPosition(doc.Tasks, func(task WorkspaceTask) int { return task.Position }) {
+		if err := p.addTask(list, task); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *importPlanner) addTask(list List, doc WorkspaceTask) error {
+	if strings.TrimSpace(doc.TaskName) == "" {
+		return InvalidInput("a task of list %q has no name", list.Title)
+	}
+	fields := Task{
+		TaskName:  doc.TaskName,
+		Notes:     doc.Notes,
+		Completed: doc.Completed,
+		Priority:  doc.Priority,
+		Starred:   doc.Starred,
+		DueDate:   doc.DueDate,
+		DueAt:     utcTime(doc.DueAt),
+		RemindAt:  utcTime(doc.RemindAt),
+	}
+	if fields.Priority == "" {
+		fields.Priority = PriorityNone
+	}
+	if !fields.Priority.Valid() {
+		return InvalidInput("task %q has priority %q; use none, low, medium or high", fields.TaskName, fields.Priority)
+	}
+	if fields.DueDate != nil && fields.DueAt != nil {
+		return InvalidInput("task %q has both a due date and a due time", fields.TaskName)
+	}
+	if fields.DueDate != nil {
+		if _, err := time.Parse(DateLayout, *fields.DueDate); err != nil {
+			return InvalidInput("task %q has due date %q; use YYYY-MM-DD", fields.TaskName, *fields.DueDate)
+		}
+	}
+	if doc.Recurrence != nil {
+		rule, err := ParseRecurrence(*doc.Recurrence)
+		if err != nil {
+			return err
+		}
+		canonical := rule.String()
+		fields.Recurrence = &canonical
+	}
+
+	id, matched := p.id(doc.ID, func(id string) bool {
+		_, ok := p.tasks[id]
+		return ok
+	})
+	var task Task
+	var changed []string
+	if matched {
+		task = p.tasks[id]
+		changed = taskFieldChanges(task, fields)
+		task.TaskName, task.Notes, task.Completed, task.Priority, task.Starred = fields.TaskName, fields.Notes, fields.Completed, fields.Priority, fields.Starred
+		task.DueDate, task.DueAt, task.RemindAt, task.Recurrence = fields.DueDate, fields.DueAt, fields.RemindAt, fields.Recurrence
+		if len(changed) > 0 {
+			task.Version++
+			task.UpdatedAt = p.now
+			p.plan.UpdatedTasks = append(p.plan.UpdatedTasks, task)
+		}
+	} else {
+		task = fields
+		task.ID, task.ListID, task.Version = id, list.ID, 1
+		p.lastTask[list.ID]++
+		task.Position = p.lastTask[list.ID]
+		task.CreatedAt, task.UpdatedAt = p.timestamps(doc.CreatedAt, doc.UpdatedAt)
+		p.plan.Tasks = append(p.plan.Tasks, task)
+	}
+
+	tagged := false
+	for _, name := range doc.Tags {
+		tagID, err := p.tag(name, "")
+		if err != nil {
+			return err
+		}
+		taskTag := TaskTag{TaskID: task.ID, TagID: tagID}
+		if !p.taskTags[taskTag] {
+			p.taskTags[taskTag] = true
+			p.plan.TaskTags = append(p.plan.TaskTags, taskTag)
+			tagged = true
+		}
+	}
+	if matched && tagged {
+		changed = append(changed, "tags")
+	}
+	if len(changed) > 0 {
+		p.plan.Report.Updated.Tasks++
+	}
+	p.report("task", task.ID, doc.ID, task.TaskName, matched, changed)
+
+	for _, doc := range byPosition(doc.SubTasks, func(subTask WorkspaceSubTask) int { return subTask.Position }) {
+		if err := p.addSubTask(task, doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *importPlanner) addSubTask(task Task, doc WorkspaceSubTask) error {
+	if strings.TrimSpace(doc.SubTaskName) == "" {
+		return InvalidInput("a subtask of task %q has no name", task.TaskName)
+	}
+	id, matched := p.id(doc.ID, func(id string) bool {
+		_, ok := p.subTasks[id]
+		return ok
+	})
+	if matched {
+		subTask := p.subTasks[id]
+		var changed []string
+		if subTask.SubTaskName != doc.SubTaskName {
+			subTask.SubTaskName = doc.SubTaskName
+			changed = append(changed, "subtask_name")
+		}
+		if subTask.Completed != doc.Completed {
+			subTask.Completed = doc.Completed
+			changed = append(changed, "completed")
+		}
+		if len(changed) > 0 {
+			subTask.Version++
+			subTask.UpdatedAt = p.now
+			p.plan.UpdatedSubTasks = append(p.plan.UpdatedSubTasks, subTask)
+			p.plan.Report.Updated.SubTasks++
+		}
+		p.report("subtask", subTask.ID, doc.ID, subTask.SubTaskName, true, changed)
+		return nil
+	}
+
+	p.lastSubTask[task.ID]++
+	subTask := SubTask{
+		ID:          id,
+		TaskID:      task.ID,
+		SubTaskName: doc.SubTaskName,
+		Completed:   doc.Completed,
+		Position:    p.lastSubTask[task.ID],
+		Version:     1,
+	}
+	subTask.CreatedAt, subTask.UpdatedAt = p.timestamps(doc.CreatedAt, doc.UpdatedAt)
+	p.plan.SubTasks = append(p.plan.SubTasks, subTask)
+	p.report("subtask", subTask.ID, doc.ID, subTask.SubTaskName, false, nil)
+	return nil
+}
+
+// taskFieldChanges returns the JSON names of the fields an import sets that
+// differ between a task and the imported one
+func taskFieldChanges(current, imported Task) []string {
+	var changed []string
+	for _, field := range []struct {
+		name string
+		same bool
+	}{
+		{"task_name", current.TaskName == imported.TaskName},
+		{"notes", current.Notes == imported.Notes},
+		{"completed", current.Completed == imported.Completed},
+		{"priority", current.Priority == imported.Priority},
+		{"starred", current.Starred == imported.Starred},
+		{"due_date", sameString(current.DueDate, imported.DueDate)},
+		{"due_at", sameTime(current.DueAt, imported.DueAt)},
+		{"remind_at", sameTime(current.RemindAt, imported.RemindAt)},
+		{"recurrence", sameString(current.Recurrence, imported.Recurrence)},
+	} {
+		if !field.same {
+			changed = append(changed, field.name)
+		}
+	}
+	return changed
+}
+
+func sameString(a, b *string) bool {
+	return (a == nil) == (b == nil) && (a == nil || *a == *b)
+}
+
+func sameTime(a, b *time.Time) bool {
+	return (a == nil) == (b == nil) && (a == nil || a.Equal(*b))
+}
+
+// byPosition returns a copy of rows sorted by position, keeping the order of
+// rows with the same position
+func byPosition[T any](rows []T, position func(T) int) []T {
+	sorted := slices.Clone(rows)
+	slices.SortStableFunc(sorted, func(a, b T) int { return cmp.Compare(position(a), position(b)) })
+	return sorted
+}
@@ -0,0 +1,130 @@
+package server
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestPlanImportMerge(t *testing.T) {
+	now := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	query := "priority:high"
+	trashed := now.Add(-time.Hour)
+	state := ImportState{
+		Lists: []List{
+			{ID: "11111111-1111-1111-1111-111111111111", Title: "Home", Position: 1, Version: 3},
+			{ID: "22222222-2222-2222-2222-222222222222", Title: "Urgent", Query: &query, Position: 2, Version: 1},
+			{ID: "33333333-3333-3333-3333-333333333333", Title: "Old", Position: 5, Version: 1, DeletedAt: &trashed},
+		},
+		Tasks: []Task{
+			{ID: "44444444-4444-4444-4444-444444444444", ListID: "11111111-1111-1111-1111-111111111111", TaskName: "Laundry", Priority: PriorityNone, Position: 1, Version: 2},
+			{ID: "55555555-5555-5555-5555-555555555555", ListID: "11111111-1111-1111-1111-111111111111", TaskName: "Gone", Priority: PriorityNone, Position: 4, Version: 1, DeletedAt: &trashed},
+		},
+		Tags:       []Tag{{ID: "66666666-6666-6666-6666-666666666666", Name: "Chores"}},
+		TaskTags:   []TaskTag{{TaskID: "44444444-4444-4444-4444-444444444444", TagID: "66666666-6666-6666-6666-666666666666"}},
+		CheckQuery: func(string) error { return nil },
+	}
+	doc := &Workspace{Version: 1, Lists: []WorkspaceList{
+		{ID: "11111111-1111-1111-1111-111111111111", Title: "Home", Tasks: []WorkspaceTask{
+			{ID: "44444444-4444-4444-4444-444444444444", TaskName: "Laundry", Priority: PriorityHigh, Tags: []string{"chores", "Shop"}},
+			{ID: "55555555-5555-5555-5555-555555555555", TaskName: "Gone"},
+		}},
+		// A plain list does not match a smart one, nor a list in the trash
+		{ID: "22222222-2222-2222-2222-222222222222", Title: "Not smart", Position: 1},
+		{ID: "33333333-3333-3333-3333-333333333333", Title: "Old", Position: 2},
+	}}
+
+	plan, err := PlanImport(doc, ImportMerge, state, now)
+	if err != nil {
+		t.Fatalf("PlanImport: %v", err)
+	}
+	report := plan.Report
+	if want := (ImportCounts{Lists: 2, Tasks: 1, Tags: 1}); report.Created != want {
+		t.Errorf("Created = %+v, want %+v", report.Created, want)
+	}
+	if want := (ImportCounts{Tasks: 1}); report.Updated != want {
+		t.Errorf("Updated = %+v, want %+v", report.Updated, want)
+	}
+	if len(report.Remapped) != 3 {
+		t.Errorf("Remapped = %v, want the trashed task, the smart list and the trashed list", report.Remapped)
+	}
+
+	if len(plan.UpdatedLists) != 0 || len(plan.UpdatedTasks) != 1 {
+		t.Fatalf("plan updates %d lists and %d tasks, want only Laundry", len(plan.UpdatedLists), len(plan.UpdatedTasks))
+	}
+	laundry := plan.UpdatedTasks[0]
+	if laundry.Priority != PriorityHigh || laundry.Version != 3 || laundry.Position != 1 || !laundry.UpdatedAt.Equal(now) {
+		t.Errorf("updated task = %+v, want priority high, version 3 and position 1", laundry)
+	}
+	if len(plan.TaskTags) != 1 || plan.TaskTags[0].TaskID != laundry.ID || plan.TaskTags[0].TagID != plan.Tags[0].ID {
+		t.Errorf("TaskTags = %+v, want only the new Shop tag added", plan.TaskTags)
+	}
+
+	// New rows go after the existing ones, the trash included
+	if len(plan.Tasks) != 1 || plan.Tasks[0].Position != 5 || plan.Tasks[0].ID == "55555555-5555-5555-5555-555555555555" {
+		t.Errorf("created tasks = %+v, want Gone with a new id at position 5", plan.Tasks)
+	}
+	if len(plan.Lists) != 2 || plan.Lists[0].Position != 6 || plan.Lists[1].Position != 7 {
+		t.Errorf("created lists = %+v, want positions 6 and 7", plan.Lists)
+	}
+
+	var actions []string
+	for _, row := range report.Rows {
+		actions = append(actions, row.Entity+" "+row.Action)
+	}
+	want := []string{"list unchanged", "tag create", "task update", "task create", "list create", "list create"}
+	if !slices.Equal(actions, want) {
+		t.Errorf("rows = %v, want %v", actions, want)
+	}
+	if row := report.Rows[2]; !slices.Equal(row.Changed, []string{"priority", "tags"}) {
+		t.Errorf("Changed = %v, want priority and tags", row.Changed)
+	}
+	if row := report.Rows[3]; row.SourceID != "55555555-5555-5555-5555-555555555555" {
+		t.Errorf("SourceID = %q, want the trashed task's id", row.SourceID)
+	}
+}
+
+func TestPlanImportReplace(t *testing.T) {
+	state := ImportState{
+		Lists:      []List{{ID: "11111111-1111-1111-1111-111111111111", Title: "Home", Position: 1}},
+		Tasks:      []Task{{ID: "44444444-4444-4444-4444-444444444444", ListID: "11111111-1111-1111-1111-111111111111", TaskName: "Laundry", Position: 1}},
+		CheckQuery: func(string) error { return nil },
+	}
+	doc := &Workspace{Version: 1, Lists: []WorkspaceList{{ID: "11111111-1111-1111-1111-111111111111", Title: "Home"}}}
+
+	plan, err := PlanImport(doc, ImportReplace, state, time.Now())
+	if err != nil {
+		t.Fatalf("PlanImport: %v", err)
+	}
+	if len(plan.Lists) != 1 || plan.Lists[0].ID != "11111111-1111-1111-1111-111111111111" || len(plan.UpdatedLists) != 0 {
+		t.Errorf("plan = %+v, want Home created with its id", plan)
+	}
+	if want := (ImportCounts{Lists: 1, Tasks: 1}); plan.Report.Deleted != want {
+		t.Errorf("Deleted = %+v, want %+v", plan.Report.Deleted, want)
+	}
+}
+
+func TestPlanImportErrors(t *testing.T) {
+	errQuery := InvalidInput("bad query")
+	state := ImportState{CheckQuery: func(string) error { return errQuery }}
+	query := "due:someday"
+	for _, tt := range []struct {
+		name string
+		doc  *Workspace
+		mode ImportMode
+	}{
+		{"missing document", nil, ImportMerge},
+		{"unknown mode", &Workspace{Version: 1}, "append"},
+		{"version 0", &Workspace{}, ImportMerge},
+		{"bad query", &Workspace{Version: 1, Lists: []WorkspaceList{{Title: "Smart", Query: &query}}}, ImportMerge},
+		{"both due fields", &Workspace{Version: 1, Lists: []WorkspaceList{{Title: "Home", Tasks: []WorkspaceTask{
+			{TaskName: "Laundry", DueDate: new(string), DueAt: new(time.Time)},
+		}}}}, ImportMerge},
+		{"append when replacing", &Workspace{Version: 1, Lists: []WorkspaceList{{Append: true}}}, ImportReplace},
+	} {
+		if _, err := PlanImport(tt.doc, tt.mode, state, time.Now()); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("PlanImport with %s: err = %v, want ErrInvalidInput", tt.name, err)
+		}
+	}
+}
@@ -2,7 +2,14 @@ package main
 
 import (
 	"embed"
+	"log"
+	"log/slog"
+	"os"
 
+	server "github.com/HolySxn/To-Do/internal"
+	"github.com/HolySxn/To-Do/internal/config"
+	"github.com/HolySxn/To-Do/internal/db"
+	"github.com/HolySxn/To-Do/internal/sqlite"
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
 	"github.com/wailsapp/wails/v2/pkg/options/assetserver"
@@ -15,11 +22,29 @@ var assets embed.FS
 var icon []byte
 
 func main() {
+	// Load configuration
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	logger := newLogger(cfg.App.LogLevel)
+	logger.Info("Initializing To-Do application", "config", cfg)
+
+	// Initialize the configured storage backend
+	store, err := openStore(cfg, logger)
+	if err != nil {
+		logger.Error("Failed to connect to database", "driver", cfg.Database.Driver, "error", err)
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	logger.Info("Database connection established successfully")
+
 	// Create an instance of the app structure
-	app := NewApp()
+	app := NewApp(store, cfg, logger)
 
 	// Create application with options
-	err := wails.Run(&options.App{
+	err = wails.Run(&options.App{
 		Title:  "Tasks",
 		Width:  1024,
 		Height: 768,
@@ -32,6 +57,7 @@ func main() {
 		Bind: []interface{}{
 			app,
 		},
+		ErrorFormatter: formatError,
 
 		Linux: &linux.Options{
 			Icon:             icon,
@@ -43,3 +69,38 @@ func main() {
 		println("Error:", err.Error())
 	}
 }
+
+// formatError turns errors returned by bound methods into {code, message, details}
+// so the frontend can tell not found, invalid input, conflicts and outages apart
+func formatError(err error) any {
+	return server.Describe(err)
+}
+
+// openStore connects to the backend selected by DB_DRIVER
+func openStore(cfg *config.Config, logger *slog.Logger) (server.Store, error) {
+	switch cfg.Database.Driver {
+	case config.DriverSQLite:
+		logger.Info("Opening database", "driver", cfg.Database.Driver, "path", cfg.Database.Path)
+		return sqlite.NewDB(cfg.Database.Path)
+	default:
+		logger.Info("Connecting to database", "host", cfg.Database.Host, "port", cfg.Database.Port, "database", cfg.Database.Name)
+		return db.NewDB(cfg.GetConnectionString())
+	}
+}
+
+// newLogger initializes a structured logger with the configured level
+func newLogger(level string) *slog.Logger {
+	logLevel := slog.LevelInfo
+	switch level {
+	case "debug":
+		logLevel = slog.LevelDebug
+	case "warn":
+		logLevel = slog.LevelWarn
+	case "error":
+		logLevel = slog.LevelError
+	}
+
+	return slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: logLevel,
+	}))
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+func TestMarkdown(t *testing.T) {
+	a := newTestApp(t)
+	list, _ := a.CreateList("Groceries")
+	milk, _ := a.CreateTask(list.ID, "Milk")
+	a.CreateSubTask(milk.ID, "Oat")
+	a.CreateTask(list.ID, "Bread")
+	if _, err := a.ToggleTaskCompletion(milk.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	text, err := a.ExportListMarkdown(list.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "# Groceries\n\n- [ ] Bread\n- [x] Milk\n  - [ ] Oat\n"; text != want {
+		t.Errorf("ExportListMarkdown() = %q, want %q", text, want)
+	}
+
+	report, err := a.ImportMarkdown("- [ ] Eggs\n  - [ ] Free range\n\n"+text, list.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Created != (server.ImportCounts{Lists: 1, Tasks: 3, SubTasks: 2}) {
+		t.Errorf("report = %+v, want a list, three tasks and two subtasks created", report)
+	}
+	tasks, _ := a.GetTasksByListID(list.ID)
+	if len(tasks) != 3 || tasks[2].TaskName != "Eggs" {
+		t.Errorf("GetTasksByListID() = %+v, want Eggs added last", tasks)
+	}
+	if lists, _ := a.GetAllLists(); len(lists) != 2 || lists[1].Title != "Groceries" {
+		t.Errorf("GetAllLists() = %+v, want a second Groceries list", lists)
+	}
+
+	// Undoing moves the added list and tasks to the trash
+	if _, err := a.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if tasks, _ := a.GetTasksByListID(list.ID); len(tasks) != 2 {
+		t.Errorf("GetTasksByListID() after undo = %+v, want Eggs gone", tasks)
+	}
+	if lists, _ := a.GetAllLists(); len(lists) != 1 {
+		t.Errorf("GetAllLists() after undo = %+v, want the imported list gone", lists)
+	}
+
+	for _, tt := range []struct {
+		name, text, listID string
+	}{
+		{"items without a list", "- [ ] Eggs", ""},
+		{"no items", "Just some text", list.ID},
+		{"empty heading", "#\n- [ ] Eggs", ""},
+		{"bad list id", "- [ ] Eggs", "groceries"},
+	} {
+		if _, err := a.ImportMarkdown(tt.text, tt.listID); !errors.Is(err, server.ErrInvalidInput) {
+			t.Errorf("ImportMarkdown() with %s: err = %v, want ErrInvalidInput", tt.name, err)
+		}
+	}
+	if _, err := a.ExportListMarkdown("7f0c7c1e-6a51-4c8e-9b1e-2f8f5a1d9c00"); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("ExportListMarkdown() of a missing list: err = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+func TestSmartLists(t *testing.T) {
+	a := newTestApp(t)
+	list, _ := a.CreateList("Chores")
+	if _, err := a.CreateTask(list.ID, "Buy milk"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.CreateTask(list.ID, "Write report"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.CreateSmartList("Broken", "bogus:1"); !errors.Is(err, server.ErrInvalidInput) {
+		t.Errorf("CreateSmartList() with an unknown field: error = %v, want invalid input", err)
+	}
+
+	smart, err := a.CreateSmartList("Milk", "MILK")
+	if err != nil {
+		t.Fatal(err)
+	}
+	taskNames := func() []string {
+		t.Helper()
+		tasks, err := a.GetSmartListTasks(smart.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names := make([]string, len(tasks))
+		for i, task := range tasks {
+			names[i] = task.TaskName
+		}
+		return names
+	}
+	if got := taskNames(); len(got) != 1 || got[0] != "Buy milk" {
+		t.Errorf("GetSmartListTasks() = %q, want Buy milk", got)
+	}
+	if _, err := a.SetListQuery(smart.ID, "report"); err != nil {
+		t.Fatal(err)
+	}
+	if got := taskNames(); len(got) != 1 || got[0] != "Write report" {
+		t.Errorf("GetSmartListTasks() after SetListQuery = %q, want Write report", got)
+	}
+	if _, err := a.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if got := taskNames(); len(got) != 1 || got[0] != "Buy milk" {
+		t.Errorf("GetSmartListTasks() after undo = %q, want Buy milk", got)
+	}
+
+	if _, err := a.SetListQuery(list.ID, "milk"); !errors.Is(err, server.ErrInvalidInput) {
+		t.Errorf("SetListQuery() of a plain list: error = %v, want invalid input", err)
+	}
+	if _, err := a.CreateTask(smart.ID, "Nowhere"); !errors.Is(err, server.ErrInvalidInput) {
+		t.Errorf("CreateTask() in a smart list: error = %v, want invalid input", err)
+	}
+}
+
+func TestSmartListTree(t *testing.T) {
+	a := newTestApp(t)
+	list, _ := a.CreateList("Chores")
+	a.CreateTask(list.ID, "Buy milk")
+	smart, err := a.CreateSmartList("Milk", "milk")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	trees, err := a.GetWorkspaceTree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trees) != 2 || trees[0].Smart || !trees[1].Smart {
+		t.Fatalf("GetWorkspaceTree() = %+v, want only the second list smart", trees)
+	}
+	if len(trees[1].Tasks) != 0 {
+		t.Errorf("smart list tree has %d tasks, want none of its own", len(trees[1].Tasks))
+	}
+	tree, err := a.GetListTree(smart.ID)
+	if err != nil || !tree.Smart {
+		t.Errorf("GetListTree() = %+v, %v; want a smart list", tree, err)
+	}
+}
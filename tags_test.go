@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+func TestUndoTags(t *testing.T) {
+	a := newTestApp(t)
+	list, _ := a.CreateList("Chores")
+	first, _ := a.CreateTask(list.ID, "a")
+	second, _ := a.CreateTask(list.ID, "b")
+	work, err := a.CreateTag("Work", "#FF0000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if work.Color != "#ff0000" {
+		t.Errorf("Color = %q, want it in lower case", work.Color)
+	}
+	home, err := a.CreateTag("Home", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.AddTagToTask(first.ID, work.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.AddTagToTask(second.ID, home.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.RenameTag(work.ID, "Job"); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.DeleteTag(work.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Undoing the delete creates the tag again and puts it back on its tasks
+	for range 2 {
+		if _, err := a.Undo(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tags, err := a.GetTaskTags(first.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 1 || tags[0].Name != "Work" {
+		t.Fatalf("GetTaskTags() after undo = %+v, want Work", tags)
+	}
+	work = &tags[0]
+
+	if _, err := a.MergeTags(home.ID, work.ID); err != nil {
+		t.Fatal(err)
+	}
+	if tasks, _ := a.GetTasksByTag(work.ID); len(tasks) != 2 {
+		t.Errorf("GetTasksByTag() after the merge = %d tasks, want 2", len(tasks))
+	}
+	if _, err := a.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	tasks, _ := a.GetTasksByTag(work.ID)
+	if len(tasks) != 1 || tasks[0].ID != first.ID {
+		t.Errorf("GetTasksByTag() after undoing the merge = %+v, want only a", tasks)
+	}
+	tags, _ = a.GetTaskTags(second.ID)
+	if len(tags) != 1 || tags[0].Name != "Home" {
+		t.Errorf("GetTaskTags() after undoing the merge = %+v, want Home back", tags)
+	}
+}
+
+func TestTagValidation(t *testing.T) {
+	a := newTestApp(t)
+	if _, err := a.CreateTag("Work", "red"); !errors.Is(err, server.ErrInvalidInput) {
+		t.Errorf("CreateTag() with a named color: error = %v, want invalid input", err)
+	}
+	if _, err := a.CreateTag(" ", ""); !errors.Is(err, server.ErrInvalidInput) {
+		t.Errorf("CreateTag() with a blank name: error = %v, want invalid input", err)
+	}
+}
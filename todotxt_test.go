@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+func TestTodoTxt(t *testing.T) {
+	a := newTestApp(t)
+	list, _ := a.CreateList("Home Chores")
+	laundry, _ := a.CreateTask(list.ID, "Laundry")
+	a.CreateSubTask(laundry.ID, "Fold")
+	tag, _ := a.CreateTag("Deep Work", "")
+	if err := a.AddTagToTask(laundry.ID, tag.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	text, err := a.ExportTodoTxt()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], "Laundry +Home-Chores @Deep-Work id:1") || !strings.HasSuffix(lines[1], "Fold p:1") {
+		t.Errorf("ExportTodoTxt() = %q, want Laundry with its subtask", text)
+	}
+
+	// Projects match lists and contexts match tags by name, ignoring the
+	// dashes that stand for spaces
+	report, err := a.ImportTodoTxt("(A) Dishes +home-chores @deep-work\nx 2020-01-01 Old +Attic @dusty\nLoose end\n", list.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Created != (server.ImportCounts{Lists: 1, Tasks: 3, Tags: 1}) {
+		t.Errorf("report = %+v, want the Attic list, three tasks and the dusty tag created", report)
+	}
+	tasks, _ := a.GetTasksByListID(list.ID)
+	if len(tasks) != 3 {
+		t.Fatalf("GetTasksByListID() = %+v, want Dishes and Loose end added", tasks)
+	}
+	dishes := tasks[1]
+	if dishes.TaskName != "Dishes" || dishes.Priority != server.PriorityHigh {
+		t.Errorf("imported task = %+v, want Dishes with high priority", dishes)
+	}
+	if tags, _ := a.GetTaskTags(dishes.ID); len(tags) != 1 || tags[0].ID != tag.ID {
+		t.Errorf("GetTaskTags() = %+v, want the existing Deep Work tag", tags)
+	}
+
+	if _, err := a.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := a.ExportTodoTxt(); again != text {
+		t.Errorf("ExportTodoTxt() after undo = %q, want %q", again, text)
+	}
+
+	for _, tt := range []struct {
+		name, text, listID string
+	}{
+		{"tasks without a list", "Loose end", ""},
+		{"an empty file", "", list.ID},
+		{"a bad due date", "Pay the rent due:tomorrow", list.ID},
+		{"a bad list id", "Loose end", "home"},
+	} {
+		if _, err := a.ImportTodoTxt(tt.text, tt.listID); !errors.Is(err, server.ErrInvalidInput) {
+			t.Errorf("ImportTodoTxt() with %s: err = %v, want ErrInvalidInput", tt.name, err)
+		}
+	}
+}
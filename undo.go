@@ -0,0 +1,843 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	server "github.com/HolySxn/To-Do/internal"
+	"github.com/HolySxn/To-Do/internal/history"
+)
+
+// Undo reverts the most recent change made through the App bindings
+func (a *App) Undo() (*history.Entry, error) {
+	a.logger.Info("Undoing last change")
+	entry, err := a.history.Undo(a.ctx)
+	if err != nil {
+		a.logger.Error("Failed to undo", "error", err)
+		return nil, err
+	}
+	a.logger.Info("Change undone successfully", "id", entry.ID, "label", entry.Label)
+	return entry, nil
+}
+
+// Redo applies the most recently undone change again
+func (a *App) Redo() (*history.Entry, error) {
+	a.logger.Info("Redoing last undone change")
+	entry, err := a.history.Redo(a.ctx)
+	if err != nil {
+		a.logger.Error("Failed to redo", "error", err)
+		return nil, err
+	}
+	a.logger.Info("Change redone successfully", "id", entry.ID, "label", entry.Label)
+	return entry, nil
+}
+
+// GetHistory returns the changes that can be undone and redone, most recent first
+func (a *App) GetHistory() history.Snapshot {
+	a.logger.Info("Getting history")
+	return a.history.Snapshot()
+}
+
+// The record helpers below add a change that has just been made to the history.
+// Undoing a change writes back the fields it changed, so each undo and redo is
+// a regular store call. Before writing a row it is read back: when a field
+// about to be written no longer holds what the change left there, the row was
+// changed elsewhere and the call fails with a *server.ConflictError instead of
+// overwriting that change. Otherwise the write passes the version just read,
+// so that a change made in between is rejected by the store. Versions alone
+// cannot tell, since reordering and deleting a parent bump them too.
+
+// recordListEdit records a change of list fields from before to after
+func (a *App) recordListEdit(label string, before, after *server.List) {
+	from, to := *before, *after
+	a.history.Record(label, func(ctx context.Context) error {
+		return a.putList(ctx, to, from)
+	}, func(ctx context.Context) error {
+		return a.putList(ctx, from, to)
+	})
+}
+
+// putList writes the fields of to that differ from from
+func (a *App) putList(ctx context.Context, from, to server.List) error {
+	var patch server.ListPatch
+	if from.Title != to.Title {
+		patch.Title = &to.Title
+	}
+	if !sameString(from.Query, to.Query) {
+		patch.Query = to.Query
+	}
+	if patch.Empty() {
+		return nil
+	}
+	current, err := a.store.GetList(ctx, to.ID)
+	if err != nil {
+		return err
+	}
+	if (patch.Title != nil && current.Title != from.Title) || (patch.Query != nil && !sameString(current.Query, from.Query)) {
+		return changedElsewhere("list", from.ID, from.Version, current)
+	}
+	patch.Version = current.Version
+	_, err = a.store.PatchList(ctx, to.ID, patch)
+	return err
+}
+
+// recordTaskEdit records a change of task fields from before to after
+func (a *App) recordTaskEdit(label string, before, after *server.Task) {
+	from, to := *before, *after
+	a.history.Record(label, func(ctx context.Context) error {
+		return a.putTask(ctx, to, from)
+	}, func(ctx context.Context) error {
+		return a.putTask(ctx, from, to)
+	})
+}
+
+// putTask writes the fields of to that differ from from. The list and position
+// are left alone; moves are recorded separately.
+func (a *App) putTask(ctx context.Context, from, to server.Task) error {
+	changed := taskChanges(from, to)
+	if changed.none() {
+		return nil
+	}
+	current, err := a.store.GetTask(ctx, to.ID)
+	if err != nil {
+		return err
+	}
+	if changed.any(taskChanges(from, *current)) {
+		return changedElsewhere("task", from.ID, from.Version, current)
+	}
+
+	patch := server.TaskPatch{Version: current.Version}
+	if changed.name {
+		patch.TaskName = &to.TaskName
+	}
+	if changed.notes {
+		patch.Notes = &to.Notes
+	}
+	if changed.completed {
+		patch.Completed = &to.Completed
+	}
+	if changed.priority {
+		patch.Priority = &to.Priority
+	}
+	if changed.starred {
+		patch.Starred = &to.Starred
+	}
+	if !patch.Empty() {
+		if _, err := a.store.PatchTask(ctx, to.ID, patch); err != nil {
+			return err
+		}
+	}
+	if changed.due {
+		if _, err := a.store.SetTaskDue(ctx, to.ID, to.DueDate, to.DueAt); err != nil {
+			return err
+		}
+	}
+	if changed.reminder {
+		if _, err := a.store.SetTaskReminder(ctx, to.ID, to.RemindAt); err != nil {
+			return err
+		}
+	}
+	if changed.recurrence {
+		if _, err := a.store.SetTaskRecurrence(ctx, to.ID, to.Recurrence); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// taskFields flags the task fields that undo writes back
+type taskFields struct {
+	name, notes, completed, priority, starred, due, reminder, recurrence bool
+}
+
+// taskChanges returns the fields that differ between a and b
+func taskChanges(a, b server.Task) taskFields {
+	return taskFields{
+		name:       a.TaskName != b.TaskName,
+		notes:      a.Notes != b.Notes,
+		completed:  a.Completed != b.Completed,
+		priority:   a.Priority != b.Priority,
+		starred:    a.Starred != b.Starred,
+		due:        !sameString(a.DueDate, b.DueDate) || !sameTime(a.DueAt, b.DueAt),
+		reminder:   !sameTime(a.RemindAt, b.RemindAt),
+		recurrence: !sameString(a.Recurrence, b.Recurrence),
+	}
+}
+
+func (f taskFields) none() bool {
+	return f == taskFields{}
+}
+
+// any reports whether f and g flag a field in common
+func (f taskFields) any(g taskFields) bool {
+	return f.name && g.name || f.notes && g.notes || f.completed && g.completed || f.priority && g.priority ||
+		f.starred && g.starred || f.due && g.due || f.reminder && g.reminder || f.recurrence && g.recurrence
+}
+
+// recordCompletion records completing a task. Undoing it reopens the task and
+// deletes the occurrence it spawned; redoing it spawns a new one.
+func (a *App) recordCompletion(label string, before *server.Task, completion *server.TaskCompletion) {
+	from, to, next := *before, completion.Task, completion.Next
+	a.history.Record(label, func(ctx context.Context) error {
+		if next != nil {
+			if err := a.deleteTaskOp(*next)(ctx); err != nil {
+				return err
+			}
+		}
+		return a.putTask(ctx, to, from)
+	}, func(ctx context.Context) error {
+		redone, err := a.store.CompleteTask(ctx, from.ID)
+		if err != nil {
+			return err
+		}
+		to, next = redone.Task, redone.Next
+		return nil
+	})
+}
+
+// recordSubTaskEdit records a change of subtask fields from before to after
+func (a *App) recordSubTaskEdit(label string, before, after *server.SubTask) {
+	from, to := *before, *after
+	a.history.Record(label, func(ctx context.Context) error {
+		return a.putSubTask(ctx, to, from)
+	}, func(ctx context.Context) error {
+		return a.putSubTask(ctx, from, to)
+	})
+}
+
+// putSubTask writes the fields of to that differ from from
+func (a *App) putSubTask(ctx context.Context, from, to server.SubTask) error {
+	var patch server.SubTaskPatch
+	if from.SubTaskName != to.SubTaskName {
+		patch.SubTaskName = &to.SubTaskName
+	}
+	if from.Completed != to.Completed {
+		patch.Completed = &to.Completed
+	}
+	if patch.Empty() {
+		return nil
+	}
+	current, err := a.store.GetSubTask(ctx, to.ID)
+	if err != nil {
+		return err
+	}
+	if (patch.SubTaskName != nil && current.SubTaskName != from.SubTaskName) || (patch.Completed != nil && current.Completed != from.Completed) {
+		return changedElsewhere("subtask", from.ID, from.Version, current)
+	}
+	patch.Version = current.Version
+	_, err = a.store.PatchSubTask(ctx, to.ID, patch)
+	return err
+}
+
+// changedElsewhere returns the error for undoing or redoing a change to a row
+// that no longer holds what the change left there, at version
+func changedElsewhere(entity, id string, version int64, current any) error {
+	return &server.ConflictError{Entity: entity, ID: id, Version: version, Current: current}
+}
+
+// Deleted rows stay in the trash, so creating and deleting are undone by moving
+// a row, together with its children, in or out of the trash. Restoring keeps
+// positions, which brings a deleted list back exactly where it was. Rows in the
+// trash cannot be edited, so only deleting checks for changes made elsewhere;
+// the recorded row is the one the change left.
+
+func (a *App) deleteListOp(recorded server.List) history.Op {
+	return func(ctx context.Context) error {
+		current, err := a.store.GetList(ctx, recorded.ID)
+		if err != nil {
+			return err
+		}
+		if current.Title != recorded.Title || !sameString(current.Query, recorded.Query) {
+			return changedElsewhere("list", recorded.ID, recorded.Version, current)
+		}
+		return a.store.DeleteList(ctx, recorded.ID, current.Version)
+	}
+}
+
+func (a *App) restoreListOp(id string) history.Op {
+	return func(ctx context.Context) error {
+		_, err := a.store.RestoreList(ctx, id)
+		return err
+	}
+}
+
+func (a *App) deleteTaskOp(recorded server.Task) history.Op {
+	return func(ctx context.Context) error {
+		current, err := a.store.GetTask(ctx, recorded.ID)
+		if err != nil {
+			return err
+		}
+		if current.ListID != recorded.ListID || !taskChanges(recorded, *current).none() {
+			return changedElsewhere("task", recorded.ID, recorded.Version, current)
+		}
+		return a.store.DeleteTask(ctx, recorded.ID, current.Version)
+	}
+}
+
+func (a *App) restoreTaskOp(id string) history.Op {
+	return func(ctx context.Context) error {
+		_, err := a.store.RestoreTask(ctx, id)
+		return err
+	}
+}
+
+func (a *App) deleteSubTaskOp(recorded server.SubTask) history.Op {
+	return func(ctx context.Context) error {
+		current, err := a.store.GetSubTask(ctx, recorded.ID)
+		if err != nil {
+			return err
+		}
+		if current.TaskID != recorded.TaskID || current.SubTaskName != recorded.SubTaskName || current.Completed != recorded.Completed {
+			return changedElsewhere("subtask", recorded.ID, recorded.Version, current)
+		}
+		return a.store.DeleteSubTask(ctx, recorded.ID, current.Version)
+	}
+}
+
+func (a *App) restoreSubTaskOp(id string) history.Op {
+	return func(ctx context.Context) error {
+		_, err := a.store.RestoreSubTask(ctx, id)
+		return err
+	}
+}
+
+// importSnapshot holds the rows, and task tags, that a merge may update, as
+// they were before the import
+type importSnapshot struct {
+	lists    map[string]server.List
+	tasks    map[string]server.Task
+	taskTags map[string][]string
+	subTasks map[string]server.SubTask
+}
+
+// snapshotImport reads the existing rows whose ids doc names, which are the
+// rows merging doc may update
+func (a *App) snapshotImport(ctx context.Context, doc *server.Workspace) (*importSnapshot, error) {
+	snapshot := &importSnapshot{
+		lists:    make(map[string]server.List),
+		tasks:    make(map[string]server.Task),
+		taskTags: make(map[string][]string),
+		subTasks: make(map[string]server.SubTask),
+	}
+	for _, list := range doc.Lists {
+		if id, ok := importedID(list.ID); ok && !list.Append {
+			existing, err := a.store.GetList(ctx, id)
+			if err := found(err); err != nil {
+				return nil, err
+			}
+			if existing != nil {
+				snapshot.lists[id] = *existing
+			}
+		}
+		for _, task := range list.Tasks {
+			if id, ok := importedID(task.ID); ok {
+				existing, err := a.store.GetTask(ctx, id)
+				if err := found(err); err != nil {
+					return nil, err
+				}
+				if existing != nil {
+					tags, err := a.store.GetTaskTags(ctx, id)
+					if err != nil {
+						return nil, err
+					}
+					snapshot.tasks[id] = *existing
+					snapshot.taskTags[id] = tagIDs(tags)
+				}
+			}
+			for _, subTask := range task.SubTasks {
+				id, ok := importedID(subTask.ID)
+				if !ok {
+					continue
+				}
+				existing, err := a.store.GetSubTask(ctx, id)
+				if err := found(err); err != nil {
+					return nil, err
+				}
+				if existing != nil {
+					snapshot.subTasks[id] = *existing
+				}
+			}
+		}
+	}
+	return snapshot, nil
+}
+
+// importedID returns the id a row of an imported document keeps when it
+// matches an existing row
+func importedID(id string) (string, bool) {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return "", false
+	}
+	return parsed.String(), true
+}
+
+// found treats a missing row as no error
+func found(err error) error {
+	if errors.Is(err, server.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+func tagIDs(tags []server.Tag) []string {
+	ids := make([]string, len(tags))
+	for i, tag := range tags {
+		ids[i] = tag.ID
+	}
+	return ids
+}
+
+// recordImport records a merge from the rows of its report. Undoing it moves
+// the created lists, tasks and subtasks to the trash, writes back the fields
+// of the updated rows and takes off the tags it added. Created tags are kept.
+func (a *App) recordImport(label string, before *importSnapshot, report *server.ImportReport) {
+	var (
+		createdLists    []server.List
+		createdTasks    []server.Task
+		createdSubTasks []server.SubTask
+		updates         []history.Op
+		reverts         []history.Op
+		created         = make(map[string]bool)
+	)
+	for _, row := range report.Rows {
+		if row.Action == server.ImportUnchanged {
+			continue
+		}
+		update := row.Action == server.ImportUpdate
+		switch row.Entity {
+		case "list":
+			list, err := a.store.GetList(a.ctx, row.ID)
+			if err != nil {
+				a.logger.Error("Failed to record import", "list_id", row.ID, "error", err)
+				return
+			}
+			if !update {
+				created[list.ID] = true
+				createdLists = append(createdLists, *list)
+				continue
+			}
+			from, to := before.lists[list.ID], *list
+			reverts = append(reverts, func(ctx context.Context) error { return a.putList(ctx, to, from) })
+			updates = append(updates, func(ctx context.Context) error { return a.putList(ctx, from, to) })
+		case "task":
+			task, err := a.store.GetTask(a.ctx, row.ID)
+			if err != nil {
+				a.logger.Error("Failed to record import", "task_id", row.ID, "error", err)
+				return
+			}
+			if !update {
+				created[task.ID] = true
+				if !created[task.ListID] {
+					createdTasks = append(createdTasks, *task)
+				}
+				continue
+			}
+			tags, err := a.store.GetTaskTags(a.ctx, task.ID)
+			if err != nil {
+				a.logger.Error("Failed to record import", "task_id", row.ID, "error", err)
+				return
+			}
+			from, to := before.tasks[task.ID], *task
+			addedTags := added(before.taskTags[task.ID], tags, func(tag server.Tag) string { return tag.ID })
+			reverts = append(reverts, func(ctx context.Context) error {
+				for _, tag := range addedTags {
+					if err := a.store.RemoveTagFromTask(ctx, to.ID, a.tagID(tag.ID)); err != nil {
+						return err
+					}
+				}
+				return a.putTask(ctx, to, from)
+			})
+			updates = append(updates, func(ctx context.Context) error {
+				if err := a.putTask(ctx, from, to); err != nil {
+					return err
+				}
+				for _, tag := range addedTags {
+					if err := a.store.AddTagToTask(ctx, to.ID, a.tagID(tag.ID)); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		case "subtask":
+			subTask, err := a.store.GetSubTask(a.ctx, row.ID)
+			if err != nil {
+				a.logger.Error("Failed to record import", "subtask_id", row.ID, "error", err)
+				return
+			}
+			if !update {
+				if !created[subTask.TaskID] {
+					createdSubTasks = append(createdSubTasks, *subTask)
+				}
+				continue
+			}
+			from, to := before.subTasks[subTask.ID], *subTask
+			reverts = append(reverts, func(ctx context.Context) error { return a.putSubTask(ctx, to, from) })
+			updates = append(updates, func(ctx context.Context) error { return a.putSubTask(ctx, from, to) })
+		}
+	}
+
+	a.history.Record(label, func(ctx context.Context) error {
+		for _, subTask := range createdSubTasks {
+			if err := a.deleteSubTaskOp(subTask)(ctx); err != nil {
+				return err
+			}
+		}
+		for _, task := range createdTasks {
+			if err := a.deleteTaskOp(task)(ctx); err != nil {
+				return err
+			}
+		}
+		for _, list := range createdLists {
+			if err := a.deleteListOp(list)(ctx); err != nil {
+				return err
+			}
+		}
+		for _, revert := range slices.Backward(reverts) {
+			if err := revert(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, func(ctx context.Context) error {
+		for _, update := range updates {
+			if err := update(ctx); err != nil {
+				return err
+			}
+		}
+		for _, list := range createdLists {
+			if _, err := a.store.RestoreList(ctx, list.ID); err != nil {
+				return err
+			}
+		}
+		for _, task := range createdTasks {
+			if _, err := a.store.RestoreTask(ctx, task.ID); err != nil {
+				return err
+			}
+		}
+		for _, subTask := range createdSubTasks {
+			if _, err := a.store.RestoreSubTask(ctx, subTask.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// added returns the rows in after whose ids are not in before
+func added[T any](before []string, after []T, id func(T) string) []T {
+	existing := make(map[string]bool, len(before))
+	for _, id := range before {
+		existing[id] = true
+	}
+	var rows []T
+	for _, row := range after {
+		if !existing[id(row)] {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// recordListOrder records reordering the lists from before to after
+func (a *App) recordListOrder(label string, before, after []string) {
+	a.history.Record(label, func(ctx context.Context) error {
+		return a.store.ReorderLists(ctx, before)
+	}, func(ctx context.Context) error {
+		return a.store.ReorderLists(ctx, after)
+	})
+}
+
+// recordTaskOrder records reordering a list's tasks from before to after
+func (a *App) recordTaskOrder(label, listID string, before, after []string) {
+	a.history.Record(label, func(ctx context.Context) error {
+		return a.store.ReorderTasks(ctx, listID, before)
+	}, func(ctx context.Context) error {
+		return a.store.ReorderTasks(ctx, listID, after)
+	})
+}
+
+// recordSubTaskOrder records reordering a task's subtasks from before to after
+func (a *App) recordSubTaskOrder(label, taskID string, before, after []string) {
+	a.history.Record(label, func(ctx context.Context) error {
+		return a.store.ReorderSubTasks(ctx, taskID, before)
+	}, func(ctx context.Context) error {
+		return a.store.ReorderSubTasks(ctx, taskID, after)
+	})
+}
+
+// listOrder returns the ids of all lists in display order
+func (a *App) listOrder(ctx context.Context) ([]string, error) {
+	lists, err := a.store.GetAllLists(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(lists))
+	for i, list := range lists {
+		ids[i] = list.ID
+	}
+	return ids, nil
+}
+
+// taskOrder returns the ids of a list's tasks in display order
+func (a *App) taskOrder(ctx context.Context, listID string) ([]string, error) {
+	tasks, err := a.store.GetTasksByListID(ctx, listID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	return ids, nil
+}
+
+// subTaskOrder returns the ids of a task's subtasks in display order
+func (a *App) subTaskOrder(ctx context.Context, taskID string) ([]string, error) {
+	subTasks, err := a.store.GetSubTasksByTaskID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(subTasks))
+	for i, subTask := range subTasks {
+		ids[i] = subTask.ID
+	}
+	return ids, nil
+}
+
+// taskPosition returns the 1-based position of a task in its list, as taken by MoveTask
+func (a *App) taskPosition(ctx context.Context, task *server.Task) (int, error) {
+	ids, err := a.taskOrder(ctx, task.ListID)
+	if err != nil {
+		return 0, err
+	}
+	return slices.Index(ids, task.ID) + 1, nil
+}
+
+// subTaskPosition returns the 1-based position of a subtask in its task, as taken by MoveSubTask
+func (a *App) subTaskPosition(ctx context.Context, subTask *server.SubTask) (int, error) {
+	ids, err := a.subTaskOrder(ctx, subTask.TaskID)
+	if err != nil {
+		return 0, err
+	}
+	return slices.Index(ids, subTask.ID) + 1, nil
+}
+
+// recordTaskMove records moving a task from listID at position
+func (a *App) recordTaskMove(label, taskID, listID string, position int, targetListID string, targetPosition int) {
+	a.history.Record(label, func(ctx context.Context) error {
+		_, err := a.store.MoveTask(ctx, taskID, listID, position)
+		return err
+	}, func(ctx context.Context) error {
+		_, err := a.store.MoveTask(ctx, taskID, targetListID, targetPosition)
+		return err
+	})
+}
+
+// recordSubTaskMove records moving a subtask from taskID at position
+func (a *App) recordSubTaskMove(label, subTaskID, taskID string, position int, targetTaskID string, targetPosition int) {
+	a.history.Record(label, func(ctx context.Context) error {
+		_, err := a.store.MoveSubTask(ctx, subTaskID, taskID, position)
+		return err
+	}, func(ctx context.Context) error {
+		_, err := a.store.MoveSubTask(ctx, subTaskID, targetTaskID, targetPosition)
+		return err
+	})
+}
+
+// recordDemotion records turning before, found at position in its list, into a
+// subtask of targetTaskID. Undoing it promotes the subtask again and puts back
+// the task fields a subtask does not keep.
+func (a *App) recordDemotion(label string, before *server.Task, position int, targetTaskID string) {
+	from := *before
+	a.history.Record(label, func(ctx context.Context) error {
+		promoted, err := a.store.PromoteSubTaskToTask(ctx, from.ID)
+		if err != nil {
+			return err
+		}
+		if _, err := a.store.MoveTask(ctx, from.ID, from.ListID, position); err != nil {
+			return err
+		}
+		return a.putTask(ctx, *promoted, from)
+	}, func(ctx context.Context) error {
+		_, err := a.store.DemoteTaskToSubTask(ctx, from.ID, targetTaskID)
+		return err
+	})
+}
+
+// recordPromotion records turning before, found at position in its task, into a task
+func (a *App) recordPromotion(label string, before *server.SubTask, position int) {
+	from := *before
+	a.history.Record(label, func(ctx context.Context) error {
+		if _, err := a.store.DemoteTaskToSubTask(ctx, from.ID, from.TaskID); err != nil {
+			return err
+		}
+		_, err := a.store.MoveSubTask(ctx, from.ID, from.TaskID, position)
+		return err
+	}, func(ctx context.Context) error {
+		_, err := a.store.PromoteSubTaskToTask(ctx, from.ID)
+		return err
+	})
+}
+
+// Tags are deleted for good, so undoing a deletion creates the tag again under
+// a new id. The changes recorded before it still name the old id; tagID
+// resolves it to the id the tag has now.
+
+// tagID returns the current id of a tag that undo may have recreated
+func (a *App) tagID(id string) string {
+	for {
+		next, ok := a.tagIDs[id]
+		if !ok {
+			return id
+		}
+		id = next
+	}
+}
+
+// recreateTag creates a deleted tag again and tags the given tasks with it.
+// Tasks that have been deleted since cannot be tagged and are skipped.
+func (a *App) recreateTag(ctx context.Context, tag server.Tag, taskIDs []string) error {
+	created, err := a.store.CreateTag(ctx, tag.Name, tag.Color)
+	if err != nil {
+		return err
+	}
+	a.tagIDs[a.tagID(tag.ID)] = created.ID
+	for _, taskID := range taskIDs {
+		if err := a.store.AddTagToTask(ctx, taskID, created.ID); err != nil && !errors.Is(err, server.ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordTagCreation records creating a tag
+func (a *App) recordTagCreation(label string, tag *server.Tag) {
+	created := *tag
+	a.history.Record(label, func(ctx context.Context) error {
+		return a.store.DeleteTag(ctx, a.tagID(created.ID))
+	}, func(ctx context.Context) error {
+		return a.recreateTag(ctx, created, nil)
+	})
+}
+
+// recordTagEdit records a change of tag fields from before to after
+func (a *App) recordTagEdit(label string, before, after *server.Tag) {
+	from, to := *before, *after
+	a.history.Record(label, func(ctx context.Context) error {
+		return a.putTag(ctx, to, from)
+	}, func(ctx context.Context) error {
+		return a.putTag(ctx, from, to)
+	})
+}
+
+// putTag writes the fields of to that differ from from
+func (a *App) putTag(ctx context.Context, from, to server.Tag) error {
+	id := a.tagID(to.ID)
+	if from.Name != to.Name {
+		if _, err := a.store.RenameTag(ctx, id, to.Name); err != nil {
+			return err
+		}
+	}
+	if from.Color != to.Color {
+		if _, err := a.store.SetTagColor(ctx, id, to.Color); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordTagDeletion records deleting a tag that taskIDs had
+func (a *App) recordTagDeletion(label string, tag *server.Tag, taskIDs []string) {
+	deleted := *tag
+	a.history.Record(label, func(ctx context.Context) error {
+		return a.recreateTag(ctx, deleted, taskIDs)
+	}, func(ctx context.Context) error {
+		return a.store.DeleteTag(ctx, a.tagID(deleted.ID))
+	})
+}
+
+// recordTagMerge records merging source, which sourceTasks had, into the tag
+// targetID, which targetTasks had. Undoing it takes the target off the tasks
+// that only got it through the merge.
+func (a *App) recordTagMerge(label string, source *server.Tag, sourceTasks []string, targetID string, targetTasks []string) {
+	merged := *source
+	a.history.Record(label, func(ctx context.Context) error {
+		if err := a.recreateTag(ctx, merged, sourceTasks); err != nil {
+			return err
+		}
+		for _, taskID := range sourceTasks {
+			if slices.Contains(targetTasks, taskID) {
+				continue
+			}
+			if err := a.store.RemoveTagFromTask(ctx, taskID, a.tagID(targetID)); err != nil && !errors.Is(err, server.ErrNotFound) {
+				return err
+			}
+		}
+		return nil
+	}, func(ctx context.Context) error {
+		_, err := a.store.MergeTags(ctx, a.tagID(merged.ID), a.tagID(targetID))
+		return err
+	})
+}
+
+// recordTagAssignment records adding a tag to a task, or removing it when added is false
+func (a *App) recordTagAssignment(label, taskID, tagID string, added bool) {
+	add := func(ctx context.Context) error { return a.store.AddTagToTask(ctx, taskID, a.tagID(tagID)) }
+	remove := func(ctx context.Context) error { return a.store.RemoveTagFromTask(ctx, taskID, a.tagID(tagID)) }
+	if added {
+		a.history.Record(label, remove, add)
+	} else {
+		a.history.Record(label, add, remove)
+	}
+}
+
+// taggedTasks returns the ids of the tasks that have a tag
+func (a *App) taggedTasks(ctx context.Context, tagID string) ([]string, error) {
+	tasks, err := a.store.GetTasksByTags(ctx, []string{tagID}, false)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	return ids, nil
+}
+
+// taskTagged returns a task and whether it has a tag
+func (a *App) taskTagged(ctx context.Context, taskID, tagID string) (*server.Task, bool, error) {
+	task, err := a.store.GetTask(ctx, taskID)
+	if err != nil {
+		return nil, false, err
+	}
+	tags, err := a.store.GetTaskTags(ctx, taskID)
+	if err != nil {
+		return nil, false, err
+	}
+	return task, slices.ContainsFunc(tags, func(tag server.Tag) bool { return tag.ID == tagID }), nil
+}
+
+// quoted labels a change with the name of the row it affects
+func quoted(action, name string) string {
+	return fmt.Sprintf("%s %q", action, name)
+}
+
+func sameString(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+func TestUndoDeleteList(t *testing.T) {
+	a := newTestApp(t)
+	list, err := a.CreateList("One")
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err := a.CreateTask(list.ID, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	subtask, err := a.CreateSubTask(task.ID, "s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	renamed, err := a.UpdateList(list.ID, "Uno", list.Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.DeleteList(list.ID, renamed.Version); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := a.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.GetSubTask(subtask.ID); err != nil {
+		t.Errorf("GetSubTask() after undoing the delete: %v", err)
+	}
+	if _, err := a.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := a.GetList(list.ID); got == nil || got.Title != "One" {
+		t.Errorf("GetList() after undoing the rename = %+v, want the old title", got)
+	}
+	if _, err := a.Redo(); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := a.GetList(list.ID); got == nil || got.Title != "Uno" {
+		t.Errorf("GetList() after redoing the rename = %+v, want the new title", got)
+	}
+}
+
+func TestUndoMoves(t *testing.T) {
+	a := newTestApp(t)
+	one, _ := a.CreateList("One")
+	two, _ := a.CreateList("Two")
+	first, _ := a.CreateTask(one.ID, "a")
+	second, _ := a.CreateTask(one.ID, "b")
+	subtask, _ := a.CreateSubTask(first.ID, "s")
+
+	if _, err := a.MoveTask(second.ID, two.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	tasks, _ := a.GetTasksByListID(one.ID)
+	if len(tasks) != 2 || tasks[0].ID != second.ID {
+		t.Fatalf("tasks after undoing the move = %+v, want b back in place", tasks)
+	}
+
+	if _, err := a.SetTaskNotes(second.ID, "notes"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.DemoteTaskToSubTask(second.ID, first.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	task, err := a.GetTask(second.ID)
+	if err != nil || task.Notes != "notes" || task.ListID != one.ID {
+		t.Errorf("GetTask() after undoing the demotion = %+v, %v", task, err)
+	}
+
+	if _, err := a.PromoteSubTaskToTask(subtask.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.GetSubTask(subtask.ID); err != nil {
+		t.Errorf("GetSubTask() after undoing the promotion: %v", err)
+	}
+
+	if err := a.ReorderLists([]string{two.ID, one.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if lists, _ := a.GetAllLists(); lists[0].ID != one.ID {
+		t.Errorf("lists after undoing the reorder start with %q", lists[0].Title)
+	}
+}
+
+func TestUndoRecurringCompletion(t *testing.T) {
+	a := newTestApp(t)
+	list, _ := a.CreateList("One")
+	task, _ := a.CreateTask(list.ID, "Water plants")
+	if _, err := a.SetTaskRecurrence(task.ID, "FREQ=DAILY"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.ToggleTaskCompletion(task.ID); err != nil {
+		t.Fatal(err)
+	}
+	count := func() int {
+		tasks, _ := a.GetTasksByListID(list.ID)
+		return len(tasks)
+	}
+	if got := count(); got != 2 {
+		t.Fatalf("tasks after completing = %d, want the next occurrence too", got)
+	}
+	if _, err := a.Undo(); err != nil || count() != 1 {
+		t.Errorf("Undo() = %v with %d tasks, want the occurrence removed", err, count())
+	}
+	if _, err := a.Redo(); err != nil || count() != 2 {
+		t.Errorf("Redo() = %v with %d tasks, want the occurrence back", err, count())
+	}
+}
+
+func TestUndoConflict(t *testing.T) {
+	a := newTestApp(t)
+	list, _ := a.CreateList("One")
+	task, _ := a.CreateTask(list.ID, "a")
+	renamed, err := a.UpdateTask(task.ID, "b", false, task.Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Another window renames the task again
+	if _, err := a.store.UpdateTask(a.ctx, task.ID, "c", false, renamed.Version); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = a.Undo()
+	var conflict *server.ConflictError
+	if !errors.Is(err, server.ErrConflict) || !errors.As(err, &conflict) {
+		t.Fatalf("Undo() error = %v, want a conflict", err)
+	}
+	if current, ok := conflict.Current.(*server.Task); !ok || current.TaskName != "c" {
+		t.Errorf("Current = %+v, want the task as it is now", conflict.Current)
+	}
+	if got, _ := a.GetTask(task.ID); got.TaskName != "c" {
+		t.Errorf("Name = %q after a failed undo, want it unchanged", got.TaskName)
+	}
+
+	// Reordering elsewhere bumps versions but leaves the renamed field alone
+	other, _ := a.CreateTask(list.ID, "d")
+	if _, err := a.UpdateTask(other.ID, "e", false, other.Version); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.store.ReorderTasks(a.ctx, list.ID, []string{other.ID, task.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Undo(); err != nil {
+		t.Fatalf("Undo() after a reorder: %v", err)
+	}
+	if got, _ := a.GetTask(other.ID); got.TaskName != "d" {
+		t.Errorf("Name = %q after undo, want the old name", got.TaskName)
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	server "github.com/HolySxn/To-Do/internal"
+)
+
+func TestImportWorkspaceUndo(t *testing.T) {
+	a := newTestApp(t)
+	list, _ := a.CreateList("Home")
+	task, _ := a.CreateTask(list.ID, "Laundry")
+	subtask, _ := a.CreateSubTask(task.ID, "Fold")
+
+	doc, err := a.ExportWorkspace()
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := a.ImportWorkspace(*doc, "merge", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Created != (server.ImportCounts{}) || report.Updated != (server.ImportCounts{}) {
+		t.Errorf("report of merging the export = %+v, want nothing created or updated", report)
+	}
+	if lists, _ := a.GetAllLists(); len(lists) != 1 {
+		t.Errorf("GetAllLists() = %d lists, want 1", len(lists))
+	}
+
+	// A merge updates the rows it matches and adds the others
+	doc.Lists[0].Tasks[0].TaskName = "Laundry and ironing"
+	doc.Lists[0].Tasks[0].Tags = []string{"Chores"}
+	doc.Lists[0].Tasks[0].SubTasks[0].Completed = true
+	doc.Lists[0].Tasks = append(doc.Lists[0].Tasks, server.WorkspaceTask{TaskName: "Iron"})
+	report, err = a.ImportWorkspace(*doc, "merge", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Created != (server.ImportCounts{Tasks: 1, Tags: 1}) || report.Updated != (server.ImportCounts{Tasks: 1, SubTasks: 1}) {
+		t.Errorf("report = %+v, want Iron and Chores created and Laundry and Fold updated", report)
+	}
+	tasks, _ := a.GetTasksByListID(list.ID)
+	if len(tasks) != 2 {
+		t.Fatalf("GetTasksByListID() = %+v, want Laundry and Iron", tasks)
+	}
+	iron := tasks[1]
+
+	if _, err := a.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := a.GetTask(task.ID); got == nil || got.TaskName != "Laundry" {
+		t.Errorf("GetTask() after undo = %+v, want the old name", got)
+	}
+	if tags, _ := a.GetTaskTags(task.ID); len(tags) != 0 {
+		t.Errorf("GetTaskTags() after undo = %+v, want none", tags)
+	}
+	if got, _ := a.GetSubTask(subtask.ID); got == nil || got.Completed {
+		t.Errorf("GetSubTask() after undo = %+v, want it open", got)
+	}
+	if tasks, _ := a.GetTasksByListID(list.ID); len(tasks) != 1 {
+		t.Errorf("GetTasksByListID() after undo = %+v, want Iron in the trash", tasks)
+	}
+
+	if _, err := a.Redo(); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := a.GetTask(task.ID); got == nil || got.TaskName != "Laundry and ironing" {
+		t.Errorf("GetTask() after redo = %+v, want the new name", got)
+	}
+	if tags, _ := a.GetTaskTags(task.ID); len(tags) != 1 || tags[0].Name != "Chores" {
+		t.Errorf("GetTaskTags() after redo = %+v, want Chores", tags)
+	}
+	if _, err := a.GetTask(iron.ID); err != nil {
+		t.Errorf("GetTask() of Iron after redo: %v", err)
+	}
+
+	// Undoing fails when an updated row was changed since
+	if _, err := a.UpdateTask(task.ID, "Washing", false, 0); err != nil {
+		t.Fatal(err)
+	}
+	a.history.Clear()
+	report, err = a.ImportWorkspace(*doc, "merge", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Updated.Tasks != 1 {
+		t.Fatalf("report = %+v, want Laundry updated", report)
+	}
+	// Changed elsewhere, so that the import is still the last change recorded
+	if _, err := a.store.UpdateTask(a.ctx, task.ID, "Ironing", false, 0); err != nil {
+		t.Fatal(err)
+	}
+	var conflict *server.ConflictError
+	if _, err := a.Undo(); !errors.As(err, &conflict) {
+		t.Errorf("Undo() after a later rename: err = %v, want a ConflictError", err)
+	}
+}
+
+func TestImportWorkspaceReplace(t *testing.T) {
+	a := newTestApp(t)
+	list, _ := a.CreateList("Home")
+	a.CreateTask(list.ID, "Laundry")
+	doc, err := a.ExportWorkspace()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc.Lists[0].Title = "  "
+	if _, err := a.ImportWorkspace(*doc, "replace", false); !errors.Is(err, server.ErrInvalidInput) {
+		t.Errorf("ImportWorkspace() with an empty title: err = %v, want ErrInvalidInput", err)
+	}
+
+	doc.Lists[0].Title = "House"
+	report, err := a.ImportWorkspace(*doc, "replace", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Deleted != (server.ImportCounts{Lists: 1, Tasks: 1}) || report.Created != (server.ImportCounts{Lists: 1, Tasks: 1}) {
+		t.Errorf("report = %+v, want Home replaced", report)
+	}
+	if got, _ := a.GetList(list.ID); got == nil || got.Title != "House" {
+		t.Errorf("GetList() = %+v, want it renamed", got)
+	}
+	if history := a.GetHistory(); len(history.Undo) != 0 {
+		t.Errorf("GetHistory() after replace = %+v, want it cleared", history)
+	}
+}